@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/tokuhirom/sact/internal"
+)
+
+const appRunUsage = "usage: sact apprun apply -f app.yaml [-y] [-dry-run]"
+
+// runAppRun runs the "sact apprun" subcommands
+func runAppRun(client *internal.SakuraClient, args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 || args[0] != "apply" {
+		return errors.New(appRunUsage)
+	}
+
+	fs := flag.NewFlagSet("apprun apply", flag.ContinueOnError)
+	file := fs.String("f", "", "Path to the application spec (YAML)")
+	yes := fs.Bool("y", false, "Apply without asking for confirmation")
+	dryRun := fs.Bool("dry-run", false, "Only print the plan")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *file == "" {
+		return errors.New(appRunUsage)
+	}
+
+	spec, err := internal.LoadAppRunApplySpec(*file)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	plan, err := client.PlanAppRunApply(ctx, spec)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprint(stdout, plan.String()); err != nil {
+		return err
+	}
+	if !plan.HasChanges() || *dryRun {
+		return nil
+	}
+
+	if !*yes {
+		if _, err := fmt.Fprint(stdout, "\nApply? [y/N]: "); err != nil {
+			return err
+		}
+		answer, _ := bufio.NewReader(stdin).ReadString('\n')
+		if strings.ToLower(strings.TrimSpace(answer)) != "y" {
+			_, err := fmt.Fprintln(stdout, "Cancelled")
+			return err
+		}
+	}
+
+	version, err := client.ApplyAppRunPlan(ctx, plan)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(stdout, "Activated v%d of %s\n", version, spec.Application)
+	return err
+}
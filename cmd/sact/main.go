@@ -72,7 +72,19 @@ func main() {
 	}
 	slog.Info("Client created", slog.String("zone", config.DefaultZone))
 
-	p := tea.NewProgram(internal.InitialModel(client, config.DefaultZone))
+	if flag.NArg() > 0 && flag.Arg(0) == "apprun" {
+		if err := runAppRun(client, flag.Args()[1:], os.Stdin, os.Stdout); err != nil {
+			slog.Error("Command failed", slog.Any("error", err))
+			_, err := fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			if err != nil {
+				slog.Error("Failed to write to stderr", slog.Any("error", err))
+			}
+			os.Exit(1)
+		}
+		return
+	}
+
+	p := tea.NewProgram(internal.InitialModel(client, config.DefaultZone).WithConfig(config))
 	if _, err := p.Run(); err != nil {
 		slog.Error("Program failed", slog.Any("error", err))
 		_, err := fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
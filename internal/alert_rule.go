@@ -0,0 +1,291 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	monitoringsuite "github.com/sacloud/monitoring-suite-api-go"
+	v1 "github.com/sacloud/monitoring-suite-api-go/apis/v1"
+)
+
+// MonitoringAlertProject is an alert project with the routings that send its alerts to notification targets
+type MonitoringAlertProject struct {
+	ID            string
+	Name          string
+	Notifications []v1.NotificationRouting
+}
+
+// MonitoringAlertRule is an alert rule of a metrics storage and the alerts it is firing
+type MonitoringAlertRule struct {
+	v1.AlertRule
+	Project MonitoringAlertProject
+	Firing  []v1.History
+}
+
+// MonitoringAlertRules are the alert rules of one metrics storage, across alert projects
+type MonitoringAlertRules struct {
+	Projects []MonitoringAlertProject
+	Rules    []MonitoringAlertRule
+}
+
+// AlertThreshold is the warning or critical level of an alert rule
+type AlertThreshold struct {
+	Enabled   bool
+	Threshold string
+	Duration  time.Duration
+}
+
+// AlertRuleSpec is the editable part of an alert rule
+type AlertRuleSpec struct {
+	ProjectID string
+	Name      string
+	Query     string
+	Warning   AlertThreshold
+	Critical  AlertThreshold
+}
+
+// Warning returns the warning level of the rule
+func (r MonitoringAlertRule) Warning() AlertThreshold {
+	return AlertThreshold{
+		Enabled:   r.EnabledWarning.Or(false),
+		Threshold: r.ThresholdWarning.Or(""),
+		Duration:  time.Duration(r.ThresholdDurationWarning.Or(0)) * time.Second,
+	}
+}
+
+// Critical returns the critical level of the rule
+func (r MonitoringAlertRule) Critical() AlertThreshold {
+	return AlertThreshold{
+		Enabled:   r.EnabledCritical.Or(false),
+		Threshold: r.ThresholdCritical.Or(""),
+		Duration:  time.Duration(r.ThresholdDurationCritical.Or(0)) * time.Second,
+	}
+}
+
+func (t AlertThreshold) String() string {
+	if t.Threshold == "" {
+		return "-"
+	}
+	s := t.Threshold
+	if t.Duration > 0 {
+		s += " for " + formatAlertDuration(t.Duration)
+	}
+	if !t.Enabled {
+		s += " (disabled)"
+	}
+	return s
+}
+
+// formatAlertDuration formats whole durations without zero units, e.g. 5m instead of 5m0s
+func formatAlertDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// parseAlertLabels decodes the labels of an alert, which are sent as a JSON object
+func parseAlertLabels(labels string) map[string]string {
+	var m map[string]string
+	if err := json.Unmarshal([]byte(labels), &m); err != nil {
+		return nil
+	}
+	return m
+}
+
+// NotifiedTargets returns the notification targets whose routing labels all match the alert
+func (p MonitoringAlertProject) NotifiedTargets(alert v1.History) []v1.NotificationTarget {
+	labels := parseAlertLabels(alert.Labels)
+	var targets []v1.NotificationTarget
+	for _, n := range p.Notifications {
+		if slices.ContainsFunc(n.MatchLabels, func(l v1.MatchLabelsItem) bool { return labels[l.Name] != l.Value }) {
+			continue
+		}
+		targets = append(targets, n.NotificationTarget)
+	}
+	return targets
+}
+
+// formatNotificationTarget returns the description of a target, or its URL without one
+func formatNotificationTarget(t v1.NotificationTarget) string {
+	if desc := getOptString(t.Description); desc != "" {
+		return desc
+	}
+	return t.URL
+}
+
+// formatMatchLabels formats routing labels as severity=critical, team=web
+func formatMatchLabels(labels []v1.MatchLabelsItem) string {
+	if len(labels) == 0 {
+		return "all alerts"
+	}
+	parts := make([]string, len(labels))
+	for i, l := range labels {
+		parts[i] = l.Name + "=" + l.Value
+	}
+	return strings.Join(parts, ", ")
+}
+
+// parseAlertDuration parses "5m" or a number of seconds
+func parseAlertDuration(value, label string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	if sec, err := strconv.Atoi(value); err == nil {
+		return time.Duration(sec) * time.Second, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 || d%time.Second != 0 {
+		return 0, fmt.Errorf("%s must be a duration such as 5m", label)
+	}
+	return d, nil
+}
+
+// ListMonitoringAlertRules fetches the alert rules that evaluate the given metrics storage,
+// with the open alerts of the rules that are firing
+func (c *SakuraClient) ListMonitoringAlertRules(ctx context.Context, storageID string) (*MonitoringAlertRules, error) {
+	slog.Info("Fetching alert rules", slog.String("storageID", storageID))
+
+	monClient, err := c.getMonitoringClient()
+	if err != nil {
+		return nil, err
+	}
+
+	projects, err := monitoringsuite.NewAlertProjectOp(monClient).List(ctx, nil, nil)
+	if err != nil {
+		slog.Error("Failed to fetch alert projects", slog.Any("error", err))
+		return nil, err
+	}
+
+	// Rules are listed per project, so every project is asked; notification routings and
+	// open alerts are only fetched for the projects and rules that concern this storage
+	ruleOp := monitoringsuite.NewAlertRuleOp(monClient)
+	rules := make([][]v1.AlertRule, len(projects))
+	errs := make([]error, len(projects))
+	forEachConcurrently(len(projects), func(i int) {
+		rules[i], errs[i] = ruleOp.List(ctx, getNilInt64AsString(projects[i].ResourceID), nil, nil)
+	})
+	if err := errors.Join(errs...); err != nil {
+		slog.Error("Failed to fetch alert rules", slog.Any("error", err))
+		return nil, err
+	}
+
+	result := &MonitoringAlertRules{}
+	var used []int
+	for i, p := range projects {
+		result.Projects = append(result.Projects, MonitoringAlertProject{ID: getNilInt64AsString(p.ResourceID), Name: getOptString(p.Name)})
+		rules[i] = slices.DeleteFunc(rules[i], func(rule v1.AlertRule) bool {
+			return getNilInt64AsString(rule.MetricsStorageID) != storageID
+		})
+		if len(rules[i]) > 0 {
+			used = append(used, i)
+		}
+	}
+
+	notificationOp := monitoringsuite.NewNotificationRoutingOp(monClient)
+	errs = make([]error, len(used))
+	forEachConcurrently(len(used), func(j int) {
+		project := &result.Projects[used[j]]
+		project.Notifications, errs[j] = notificationOp.List(ctx, project.ID, nil, nil)
+		slices.SortFunc(project.Notifications, func(a, b v1.NotificationRouting) int {
+			return a.Order.Or(0) - b.Order.Or(0)
+		})
+	})
+	if err := errors.Join(errs...); err != nil {
+		slog.Error("Failed to fetch notification routings", slog.Any("error", err))
+		return nil, err
+	}
+
+	for _, i := range used {
+		for _, rule := range rules[i] {
+			result.Rules = append(result.Rules, MonitoringAlertRule{AlertRule: rule, Project: result.Projects[i]})
+		}
+	}
+	errs = make([]error, len(result.Rules))
+	forEachConcurrently(len(result.Rules), func(i int) {
+		r := &result.Rules[i]
+		if !r.Open {
+			return
+		}
+		open := true
+		r.Firing, errs[i] = ruleOp.ListHistories(ctx, r.Project.ID, r.UID, monitoringsuite.AlertRuleListHistoriesParams{Open: &open})
+	})
+	if err := errors.Join(errs...); err != nil {
+		slog.Error("Failed to fetch open alerts", slog.Any("error", err))
+		return nil, err
+	}
+
+	slog.Info("Successfully fetched alert rules", slog.Int("count", len(result.Rules)))
+	return result, nil
+}
+
+// alertThresholdParams returns the API parameters of a threshold; an empty threshold is sent as null
+func alertThresholdParams(t AlertThreshold) (enabled *bool, threshold *string, duration *int64) {
+	sec := int64(t.Duration / time.Second)
+	if t.Threshold != "" {
+		threshold = &t.Threshold
+	}
+	return &t.Enabled, threshold, &sec
+}
+
+// CreateMonitoringAlertRule creates an alert rule that evaluates the given metrics storage
+func (c *SakuraClient) CreateMonitoringAlertRule(ctx context.Context, storageID string, spec AlertRuleSpec) error {
+	slog.Info("Creating alert rule", slog.String("project", spec.ProjectID), slog.String("name", spec.Name))
+
+	monClient, err := c.getMonitoringClient()
+	if err != nil {
+		return err
+	}
+	params := monitoringsuite.AlertRuleCreateParams{MetricsStorageID: storageID, Name: &spec.Name, Query: spec.Query}
+	params.EnabledWarning, params.ThresholdWarning, params.ThresholdDurationWarning = alertThresholdParams(spec.Warning)
+	params.EnabledCritical, params.ThresholdCritical, params.ThresholdDurationCritical = alertThresholdParams(spec.Critical)
+	if _, err := monitoringsuite.NewAlertRuleOp(monClient).Create(ctx, spec.ProjectID, params); err != nil {
+		slog.Error("Failed to create alert rule", slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+// UpdateMonitoringAlertRule replaces the name, query and thresholds of an alert rule
+func (c *SakuraClient) UpdateMonitoringAlertRule(ctx context.Context, rule MonitoringAlertRule, spec AlertRuleSpec) error {
+	slog.Info("Updating alert rule", slog.String("uid", rule.UID.String()))
+
+	monClient, err := c.getMonitoringClient()
+	if err != nil {
+		return err
+	}
+	params := monitoringsuite.AlertRuleUpdateParams{Name: &spec.Name, Query: &spec.Query}
+	params.EnabledWarning, params.ThresholdWarning, params.ThresholdDurationWarning = alertThresholdParams(spec.Warning)
+	params.EnabledCritical, params.ThresholdCritical, params.ThresholdDurationCritical = alertThresholdParams(spec.Critical)
+	if _, err := monitoringsuite.NewAlertRuleOp(monClient).Update(ctx, rule.Project.ID, rule.UID, params); err != nil {
+		slog.Error("Failed to update alert rule", slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+// DeleteMonitoringAlertRule deletes an alert rule
+func (c *SakuraClient) DeleteMonitoringAlertRule(ctx context.Context, rule MonitoringAlertRule) error {
+	slog.Info("Deleting alert rule", slog.String("uid", rule.UID.String()))
+
+	monClient, err := c.getMonitoringClient()
+	if err != nil {
+		return err
+	}
+	if err := monitoringsuite.NewAlertRuleOp(monClient).Delete(ctx, rule.Project.ID, rule.UID); err != nil {
+		slog.Error("Failed to delete alert rule", slog.Any("error", err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/sacloud/monitoring-suite-api-go/apis/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testAlertProjects = []MonitoringAlertProject{
+	{ID: "113000000501", Name: "prod", Notifications: []v1.NotificationRouting{
+		{
+			NotificationTarget: v1.NotificationTarget{URL: "https://notice.example/ops", Description: v1.NewOptString("ops")},
+			MatchLabels:        []v1.MatchLabelsItem{{Name: "severity", Value: "critical"}},
+		},
+		{NotificationTarget: v1.NotificationTarget{URL: "https://notice.example/all"}},
+	}},
+	{ID: "113000000502", Name: "staging"},
+}
+
+func TestBuildAlertRuleSpec(t *testing.T) {
+	values := map[string]string{
+		"project":            "staging",
+		"name":               "cpu-high",
+		"query":              ` avg by (instance) (rate(node_cpu_seconds_total{mode!="idle"}[5m])) `,
+		"warning.enabled":    "yes",
+		"warning.threshold":  "> 0.8",
+		"warning.duration":   "5m",
+		"critical.enabled":   "no",
+		"critical.threshold": "",
+		"critical.duration":  "300",
+	}
+	spec, err := buildAlertRuleSpec(values, testAlertProjects)
+	require.NoError(t, err)
+	assert.Equal(t, "113000000502", spec.ProjectID)
+	assert.Equal(t, `avg by (instance) (rate(node_cpu_seconds_total{mode!="idle"}[5m]))`, spec.Query)
+	assert.Equal(t, AlertThreshold{Enabled: true, Threshold: "> 0.8", Duration: 5 * time.Minute}, spec.Warning)
+	assert.Equal(t, AlertThreshold{Duration: 5 * time.Minute}, spec.Critical)
+
+	// Editing has no project field
+	delete(values, "project")
+	spec, err = buildAlertRuleSpec(values, testAlertProjects)
+	require.NoError(t, err)
+	assert.Empty(t, spec.ProjectID)
+
+	for key, tt := range map[string]struct {
+		value string
+		err   string
+	}{
+		"project":          {"dev", `unknown alert project "dev"`},
+		"query":            {`rate(x[5m]`, `invalid PromQL: col 5: unclosed "("`},
+		"warning.duration": {"5 minutes", "Warning duration must be a duration such as 5m"},
+		"critical.enabled": {"yes", "critical threshold is required when it is enabled"},
+	} {
+		invalid := map[string]string{"project": "prod"}
+		for k, v := range values {
+			invalid[k] = v
+		}
+		invalid[key] = tt.value
+		_, err := buildAlertRuleSpec(invalid, testAlertProjects)
+		if assert.Error(t, err, key) {
+			assert.Contains(t, err.Error(), tt.err)
+		}
+	}
+}
+
+func TestAlertThresholdString(t *testing.T) {
+	assert.Equal(t, "> 80 for 1h", AlertThreshold{Enabled: true, Threshold: "> 80", Duration: time.Hour}.String())
+	assert.Equal(t, "> 80 for 1h30m (disabled)", AlertThreshold{Threshold: "> 80", Duration: 90 * time.Minute}.String())
+	assert.Equal(t, "-", AlertThreshold{Enabled: true}.String())
+}
+
+func TestNotifiedTargets(t *testing.T) {
+	project := testAlertProjects[0]
+	critical := v1.History{Labels: `{"severity":"critical","instance":"web1"}`}
+	warning := v1.History{Labels: `{"severity":"warning"}`}
+
+	names := func(targets []v1.NotificationTarget) []string {
+		var s []string
+		for _, t := range targets {
+			s = append(s, formatNotificationTarget(t))
+		}
+		return s
+	}
+	assert.Equal(t, []string{"ops", "https://notice.example/all"}, names(project.NotifiedTargets(critical)))
+	assert.Equal(t, []string{"https://notice.example/all"}, names(project.NotifiedTargets(warning)))
+}
@@ -75,9 +75,10 @@ type AppRunWorkerNode struct {
 	ArchiveVersion string
 	CreatedAt      string
 	ErrorMessage   string
-	IPAddresses    []string // flattened from network interfaces
-	ClusterID      string   // parent cluster ID
-	ASGID          string   // parent ASG ID
+	IPAddresses    []string          // flattened from network interfaces
+	ClusterID      string            // parent cluster ID
+	ASGID          string            // parent ASG ID
+	Containers     []AppRunContainer // containers running on the node, across applications
 }
 
 // Implement list.Item interface for AppRunWorkerNode
@@ -120,12 +121,13 @@ type AppRunLB struct {
 
 // AppRunLBInterface represents a load balancer network interface
 type AppRunLBInterface struct {
-	Index          int16
-	Upstream       string
-	VIP            string
-	DefaultGateway string
-	NetmaskLen     int16
-	IPPool         []string
+	Index           int16
+	Upstream        string
+	VIP             string
+	VirtualRouterID int16
+	DefaultGateway  string
+	NetmaskLen      int16
+	IPPool          []string
 }
 
 // AppRunLBDetail contains detailed information about a load balancer
@@ -134,6 +136,35 @@ type AppRunLBDetail struct {
 	NameServers []string
 	Interfaces  []AppRunLBInterface
 	Deleting    bool
+	Nodes       []AppRunLBNode
+}
+
+// AppRunLBNode represents a node (appliance) of a load balancer
+type AppRunLBNode struct {
+	ID             string
+	ResourceID     string
+	Status         string
+	ArchiveVersion string
+	CreatedAt      string
+	ErrorMessage   string
+	IPAddresses    []string // non-VIP addresses of all interfaces
+	VIPs           []string
+}
+
+// HasError reports whether the node is unhealthy or failed to be created
+func (n AppRunLBNode) HasError() bool {
+	return n.Status == string(apprun.LoadBalancerNodeStatusUnhealthy) || n.ErrorMessage != ""
+}
+
+// ErrorNodeCount returns the number of nodes in an error state
+func (d *AppRunLBDetail) ErrorNodeCount() int {
+	count := 0
+	for _, node := range d.Nodes {
+		if node.HasError() {
+			count++
+		}
+	}
+	return count
 }
 
 // AppRunApplication represents an application for list display
@@ -817,6 +848,10 @@ func (c *SakuraClient) GetAppRunLBDetail(ctx context.Context, clusterID, asgID,
 		if iface.Vip.Set {
 			vip = iface.Vip.Value
 		}
+		vrid := int16(0)
+		if iface.VirtualRouterID.Set {
+			vrid = iface.VirtualRouterID.Value
+		}
 		gw := ""
 		if iface.DefaultGateway.Set {
 			gw = iface.DefaultGateway.Value
@@ -827,12 +862,13 @@ func (c *SakuraClient) GetAppRunLBDetail(ctx context.Context, clusterID, asgID,
 		}
 
 		interfaces[i] = AppRunLBInterface{
-			Index:          iface.InterfaceIndex,
-			Upstream:       iface.Upstream,
-			VIP:            vip,
-			DefaultGateway: gw,
-			NetmaskLen:     netmask,
-			IPPool:         ipPool,
+			Index:           iface.InterfaceIndex,
+			Upstream:        iface.Upstream,
+			VIP:             vip,
+			VirtualRouterID: vrid,
+			DefaultGateway:  gw,
+			NetmaskLen:      netmask,
+			IPPool:          ipPool,
 		}
 	}
 
@@ -850,6 +886,155 @@ func (c *SakuraClient) GetAppRunLBDetail(ctx context.Context, clusterID, asgID,
 		Deleting:    lb.Deleting,
 	}
 
+	nodes, err := c.ListAppRunLBNodes(ctx, clusterID, asgID, lbID)
+	if err != nil {
+		slog.Error("Failed to fetch AppRun LB nodes", slog.String("lbID", lbID), slog.Any("error", err))
+		// Continue without nodes
+	}
+	for i, node := range nodes {
+		if !node.HasError() {
+			continue
+		}
+		// Fetch the latest state of failing nodes individually
+		latest, err := c.GetAppRunLBNode(ctx, clusterID, asgID, lbID, node.ID)
+		if err != nil {
+			slog.Warn("Failed to fetch AppRun LB node", slog.String("nodeID", node.ID), slog.Any("error", err))
+			continue
+		}
+		nodes[i] = *latest
+	}
+	detail.Nodes = nodes
+
 	slog.Info("Successfully fetched AppRun LB detail", slog.String("lbID", lbID))
 	return detail, nil
 }
+
+func convertAppRunLBNode(id apprun.LoadBalancerNodeID, resourceID apprun.NilString, status apprun.LoadBalancerNodeStatus,
+	interfaces []apprun.ReadLoadBalancerNodeInterface, archiveVersion, createError apprun.OptString, created int) AppRunLBNode {
+	node := AppRunLBNode{
+		ID:     uuid.UUID(id).String(),
+		Status: string(status),
+	}
+	if !resourceID.Null {
+		node.ResourceID = resourceID.Value
+	}
+	if archiveVersion.Set {
+		node.ArchiveVersion = archiveVersion.Value
+	}
+	if createError.Set {
+		node.ErrorMessage = createError.Value
+	}
+	if created > 0 {
+		node.CreatedAt = time.Unix(int64(created), 0).Format("2006-01-02 15:04:05")
+	}
+	for _, iface := range interfaces {
+		for _, addr := range iface.Addresses {
+			if addr.Vip {
+				node.VIPs = append(node.VIPs, addr.Address)
+			} else {
+				node.IPAddresses = append(node.IPAddresses, addr.Address)
+			}
+		}
+	}
+	return node
+}
+
+// ListAppRunLBNodes fetches the nodes of a load balancer
+func (c *SakuraClient) ListAppRunLBNodes(ctx context.Context, clusterID, asgID, lbID string) ([]AppRunLBNode, error) {
+	slog.Info("Fetching AppRun LB nodes", slog.String("lbID", lbID))
+
+	client, err := c.GetAppRunClient()
+	if err != nil {
+		slog.Error("Failed to get AppRun client", slog.Any("error", err))
+		return nil, err
+	}
+
+	parsedCluster, err := uuid.Parse(clusterID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cluster ID: %w", err)
+	}
+	parsedASG, err := uuid.Parse(asgID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ASG ID: %w", err)
+	}
+	parsedLB, err := uuid.Parse(lbID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LB ID: %w", err)
+	}
+
+	var allNodes []AppRunLBNode
+	var cursor apprun.OptLoadBalancerID
+
+	for {
+		params := apprun.ListLoadBalancerNodesParams{
+			ClusterID:          apprun.ClusterID(parsedCluster),
+			AutoScalingGroupID: apprun.AutoScalingGroupID(parsedASG),
+			LoadBalancerID:     apprun.LoadBalancerID(parsedLB),
+			MaxItems:           30,
+			Cursor:             cursor,
+		}
+
+		resp, err := client.ListLoadBalancerNodes(ctx, params)
+		if err != nil {
+			slog.Error("Failed to fetch AppRun LB nodes", slog.Any("error", err))
+			return nil, err
+		}
+
+		for _, node := range resp.LoadBalancerNodes {
+			allNodes = append(allNodes, convertAppRunLBNode(node.LoadBalancerNodeID, node.ResourceID, node.Status,
+				node.Interfaces, node.ArchiveVersion, node.CreateErrorMessage, node.Created))
+		}
+
+		if !resp.NextCursor.Set {
+			break
+		}
+		cursor = apprun.NewOptLoadBalancerID(apprun.LoadBalancerID(resp.NextCursor.Value))
+	}
+
+	slog.Info("Successfully fetched AppRun LB nodes", slog.Int("count", len(allNodes)))
+	return allNodes, nil
+}
+
+// GetAppRunLBNode fetches a single load balancer node
+func (c *SakuraClient) GetAppRunLBNode(ctx context.Context, clusterID, asgID, lbID, nodeID string) (*AppRunLBNode, error) {
+	slog.Info("Fetching AppRun LB node", slog.String("lbID", lbID), slog.String("nodeID", nodeID))
+
+	client, err := c.GetAppRunClient()
+	if err != nil {
+		slog.Error("Failed to get AppRun client", slog.Any("error", err))
+		return nil, err
+	}
+
+	parsedCluster, err := uuid.Parse(clusterID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cluster ID: %w", err)
+	}
+	parsedASG, err := uuid.Parse(asgID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ASG ID: %w", err)
+	}
+	parsedLB, err := uuid.Parse(lbID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LB ID: %w", err)
+	}
+	parsedNode, err := uuid.Parse(nodeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LB node ID: %w", err)
+	}
+
+	resp, err := client.GetLoadBalancerNode(ctx, apprun.GetLoadBalancerNodeParams{
+		ClusterID:          apprun.ClusterID(parsedCluster),
+		AutoScalingGroupID: apprun.AutoScalingGroupID(parsedASG),
+		LoadBalancerID:     apprun.LoadBalancerID(parsedLB),
+		LoadBalancerNodeID: apprun.LoadBalancerNodeID(parsedNode),
+	})
+	if err != nil {
+		slog.Error("Failed to fetch AppRun LB node", slog.String("nodeID", nodeID), slog.Any("error", err))
+		return nil, err
+	}
+
+	n := resp.LoadBalancerNode
+	node := convertAppRunLBNode(n.LoadBalancerNodeID, n.ResourceID, n.Status,
+		n.Interfaces, n.ArchiveVersion, n.CreateErrorMessage, n.Created)
+	return &node, nil
+}
@@ -0,0 +1,280 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+
+	apprun "github.com/tokuhirom/sact/pkg/openapi/apprun_dedicated"
+)
+
+// AppRunApplySpec declares an application and the version it should run,
+// as read by "sact apprun apply"
+type AppRunApplySpec struct {
+	Cluster     string            `yaml:"cluster"`
+	Application string            `yaml:"application"`
+	Version     AppRunVersionSpec `yaml:"version"`
+}
+
+// LoadAppRunApplySpec reads and validates an application spec file
+func LoadAppRunApplySpec(path string) (*AppRunApplySpec, error) {
+	data, err := os.ReadFile(expandHome(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spec: %w", err)
+	}
+	return parseAppRunApplySpec(data)
+}
+
+// appRunSpecEnvRef matches a ${NAME} reference in an environment variable value of a spec
+var appRunSpecEnvRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandAppRunSpecEnv replaces ${NAME} references with the environment of sact. Any other '$',
+// e.g. in a password or a regular expression, is kept as is. Referencing an undefined variable
+// is an error rather than an empty value.
+func expandAppRunSpecEnv(value string) (string, error) {
+	var missing []string
+	expanded := appRunSpecEnvRef.ReplaceAllStringFunc(value, func(ref string) string {
+		name := appRunSpecEnvRef.FindStringSubmatch(ref)[1]
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return v
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable %s is not set", strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
+
+// parseAppRunApplySpec decodes a spec, rejecting unknown keys so that typos do not go unnoticed.
+// Environment variable values may reference the environment of sact as ${NAME}, which keeps
+// secrets out of the spec file.
+func parseAppRunApplySpec(data []byte) (*AppRunApplySpec, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var spec AppRunApplySpec
+	if err := dec.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("failed to parse spec: %w", err)
+	}
+
+	if spec.Cluster == "" {
+		return nil, fmt.Errorf("cluster is required")
+	}
+	if spec.Application == "" {
+		return nil, fmt.Errorf("application is required")
+	}
+	if spec.Version.ScalingMode == "" {
+		spec.Version.ScalingMode = string(apprun.ScalingModeManual)
+	}
+	for i := range spec.Version.Env {
+		env := &spec.Version.Env[i]
+		value, err := expandAppRunSpecEnv(env.Value)
+		if err != nil {
+			return nil, fmt.Errorf("env %s: %w", env.Key, err)
+		}
+		env.Value = value
+	}
+	if err := spec.Version.toCreateRequest().Validate(); err != nil {
+		return nil, fmt.Errorf("invalid version spec: %w", err)
+	}
+	return &spec, nil
+}
+
+// AppRunApplyPlan is what "sact apprun apply" is going to change
+type AppRunApplyPlan struct {
+	Spec          *AppRunApplySpec
+	ClusterID     string
+	ApplicationID string // empty when the application does not exist yet
+	ActiveVersion int32  // 0 when no version is active
+	Changes       []AppRunVersionDiffRow
+}
+
+// HasChanges reports whether a new version has to be created
+func (p *AppRunApplyPlan) HasChanges() bool {
+	return len(p.Changes) > 0
+}
+
+// String renders the plan for the terminal
+func (p *AppRunApplyPlan) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Cluster:     %s (%s)\n", p.Spec.Cluster, p.ClusterID)
+	if p.ApplicationID == "" {
+		fmt.Fprintf(&b, "Application: %s (will be created)\n", p.Spec.Application)
+	} else {
+		fmt.Fprintf(&b, "Application: %s (%s)\n", p.Spec.Application, p.ApplicationID)
+	}
+
+	if !p.HasChanges() {
+		fmt.Fprintf(&b, "\nNo changes: v%d already matches the spec\n", p.ActiveVersion)
+		return b.String()
+	}
+
+	if p.ActiveVersion == 0 {
+		b.WriteString("\nA first version will be created and activated:\n")
+	} else {
+		fmt.Fprintf(&b, "\nA new version will be created and activated, replacing v%d:\n", p.ActiveVersion)
+	}
+	for _, row := range p.Changes {
+		switch {
+		case row.Base == "-":
+			fmt.Fprintf(&b, "  + %s: %s\n", row.Field, row.Target)
+		case row.Target == "-":
+			fmt.Fprintf(&b, "  - %s: %s\n", row.Field, row.Base)
+		default:
+			fmt.Fprintf(&b, "  ~ %s: %s -> %s\n", row.Field, row.Base, row.Target)
+		}
+	}
+	return b.String()
+}
+
+// PlanAppRunApply compares the spec with the active version of the application
+func (c *SakuraClient) PlanAppRunApply(ctx context.Context, spec *AppRunApplySpec) (*AppRunApplyPlan, error) {
+	clusters, err := c.ListAppRunClusters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	plan := &AppRunApplyPlan{Spec: spec}
+	var clusterIDs []string
+	for _, cluster := range clusters {
+		if cluster.Name == spec.Cluster {
+			clusterIDs = append(clusterIDs, cluster.ID)
+		}
+	}
+	switch len(clusterIDs) {
+	case 0:
+		return nil, fmt.Errorf("cluster %q not found", spec.Cluster)
+	case 1:
+		plan.ClusterID = clusterIDs[0]
+	default:
+		return nil, fmt.Errorf("%d clusters are named %q: %s", len(clusterIDs), spec.Cluster, strings.Join(clusterIDs, ", "))
+	}
+
+	apps, err := c.ListAppRunApplications(ctx, plan.ClusterID)
+	if err != nil {
+		return nil, err
+	}
+	var matched []AppRunApplication
+	for _, app := range apps {
+		if app.Name == spec.Application {
+			matched = append(matched, app)
+		}
+	}
+	if len(matched) > 1 {
+		appIDs := make([]string, len(matched))
+		for i, app := range matched {
+			appIDs[i] = app.ID
+		}
+		return nil, fmt.Errorf("%d applications in cluster %q are named %q: %s", len(matched), spec.Cluster, spec.Application, strings.Join(appIDs, ", "))
+	}
+	if len(matched) == 1 {
+		plan.ApplicationID = matched[0].ID
+		plan.ActiveVersion = matched[0].ActiveVersion
+	}
+
+	// Without an active version every field of the spec is new
+	active := &AppRunVersionDetail{Spec: AppRunVersionSpec{}}
+	if plan.ActiveVersion != 0 {
+		active, err = c.GetAppRunVersionDetail(ctx, plan.ApplicationID, plan.ActiveVersion)
+		if err != nil {
+			return nil, err
+		}
+	}
+	comparison := &AppRunVersionComparison{Base: active, Target: &AppRunVersionDetail{Spec: spec.Version}}
+	for _, row := range comparison.Rows() {
+		if !row.Changed {
+			continue
+		}
+		if plan.ActiveVersion == 0 {
+			row.Base = "-"
+		}
+		plan.Changes = append(plan.Changes, row)
+	}
+
+	// Secret values cannot be read back and compared. A value given in the spec is deployed
+	// as a rotation; an empty one keeps the value of the active version.
+	if plan.ActiveVersion != 0 {
+		for _, env := range spec.Version.Env {
+			field := "Env " + env.Key
+			if !env.Secret || env.Value == "" || slices.ContainsFunc(plan.Changes, func(row AppRunVersionDiffRow) bool { return row.Field == field }) {
+				continue
+			}
+			plan.Changes = append(plan.Changes, AppRunVersionDiffRow{
+				Field:   field,
+				Base:    appRunSecretMask,
+				Target:  appRunSecretMask + " (new value)",
+				Changed: true,
+			})
+		}
+	}
+
+	if plan.ActiveVersion == 0 {
+		// There is no previous version to keep secret values from
+		for _, env := range spec.Version.Env {
+			if env.Secret && env.Value == "" {
+				return nil, fmt.Errorf("secret %s needs a value for the first version", env.Key)
+			}
+		}
+	}
+	return plan, nil
+}
+
+// ApplyAppRunPlan creates the application if needed, then creates and activates a new
+// version. It returns the active version, which is unchanged when the plan has no changes.
+func (c *SakuraClient) ApplyAppRunPlan(ctx context.Context, plan *AppRunApplyPlan) (int32, error) {
+	if !plan.HasChanges() {
+		return plan.ActiveVersion, nil
+	}
+
+	appID := plan.ApplicationID
+	if appID == "" {
+		var err error
+		appID, err = c.CreateAppRunApplication(ctx, plan.ClusterID, plan.Spec.Application)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	version, err := c.CreateAppRunVersion(ctx, appID, plan.Spec.Version)
+	if err != nil {
+		return 0, err
+	}
+	if err := c.ActivateAppRunVersion(ctx, appID, version); err != nil {
+		return 0, fmt.Errorf("created v%d but failed to activate it: %w", version, err)
+	}
+	return version, nil
+}
+
+// CreateAppRunApplication creates an application without versions and returns its ID
+func (c *SakuraClient) CreateAppRunApplication(ctx context.Context, clusterID, name string) (string, error) {
+	slog.Info("Creating AppRun application", slog.String("clusterID", clusterID), slog.String("name", name))
+
+	client, err := c.GetAppRunClient()
+	if err != nil {
+		slog.Error("Failed to get AppRun client", slog.Any("error", err))
+		return "", err
+	}
+
+	parsed, err := uuid.Parse(clusterID)
+	if err != nil {
+		return "", fmt.Errorf("invalid cluster ID: %w", err)
+	}
+
+	resp, err := client.CreateApplication(ctx, &apprun.CreateApplication{
+		Name:      name,
+		ClusterID: apprun.ClusterID(parsed),
+	})
+	if err != nil {
+		slog.Error("Failed to create AppRun application", slog.String("name", name), slog.Any("error", err))
+		return "", err
+	}
+	return uuid.UUID(resp.Application.ApplicationID).String(), nil
+}
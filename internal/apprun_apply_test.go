@@ -0,0 +1,212 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apprun "github.com/tokuhirom/sact/pkg/openapi/apprun_dedicated"
+)
+
+// testAppRunApplyYAML matches v1 of newTestFakeAppRun
+const testAppRunApplyYAML = `
+cluster: prod
+application: web
+version:
+  image: registry.example.com/web:1.0
+  cpu: 500
+  memory: 1024
+  scalingMode: manual
+  fixedScale: 2
+  cmd: [serve, --port, "8080"]
+  exposedPorts:
+    - targetPort: 8080
+      loadBalancerPort: 443
+      host: [example.com]
+      healthCheck:
+        path: /healthz
+        intervalSeconds: 10
+        timeoutSeconds: 5
+  env:
+    - key: MODE
+      value: prod
+    - key: TOKEN
+      value: ${TEST_APPRUN_TOKEN}
+      secret: true
+`
+
+func TestParseAppRunApplySpec(t *testing.T) {
+	t.Setenv("TEST_APPRUN_TOKEN", "s3cret")
+	spec, err := parseAppRunApplySpec([]byte(testAppRunApplyYAML))
+	require.NoError(t, err)
+	assert.Equal(t, "prod", spec.Cluster)
+	assert.Equal(t, int64(500), spec.Version.CPU)
+	assert.Equal(t, []string{"serve", "--port", "8080"}, spec.Version.Cmd)
+	require.NotNil(t, spec.Version.ExposedPorts[0].HealthCheck)
+	assert.Equal(t, "/healthz", spec.Version.ExposedPorts[0].HealthCheck.Path)
+	assert.Equal(t, AppRunEnvVar{Key: "TOKEN", Value: "s3cret", Secret: true}, spec.Version.Env[1])
+
+	spec, err = parseAppRunApplySpec([]byte("cluster: prod\napplication: web\nversion: {image: web, cpu: 100, memory: 128, fixedScale: 1}\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "manual", spec.Version.ScalingMode)
+
+	_, err = parseAppRunApplySpec([]byte("cluster: prod\napplication: web\nversion: {image: web, cpus: 100}\n"))
+	assert.ErrorContains(t, err, "field cpus not found")
+
+	_, err = parseAppRunApplySpec([]byte("cluster: prod\nversion: {image: web}\n"))
+	assert.ErrorContains(t, err, "application is required")
+
+	_, err = parseAppRunApplySpec([]byte("cluster: prod\napplication: web\nversion: {image: web, cpu: 10, memory: 128}\n"))
+	assert.ErrorContains(t, err, "invalid version spec")
+}
+
+func TestExpandAppRunSpecEnv(t *testing.T) {
+	t.Setenv("TEST_APPRUN_HOST", "db.internal")
+	t.Setenv("TEST_APPRUN_EMPTY", "")
+
+	value, err := expandAppRunSpecEnv("postgres://${TEST_APPRUN_HOST}/app${TEST_APPRUN_EMPTY}")
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://db.internal/app", value)
+
+	// Only ${NAME} is expanded
+	value, err = expandAppRunSpecEnv(`pa$$w0rd $TEST_APPRUN_HOST ^\d+$ ${not a name}`)
+	require.NoError(t, err)
+	assert.Equal(t, `pa$$w0rd $TEST_APPRUN_HOST ^\d+$ ${not a name}`, value)
+
+	_, err = expandAppRunSpecEnv("${TEST_APPRUN_UNDEFINED}")
+	assert.EqualError(t, err, "environment variable TEST_APPRUN_UNDEFINED is not set")
+	_, err = parseAppRunApplySpec([]byte(testAppRunApplyYAML))
+	assert.ErrorContains(t, err, "env TOKEN: environment variable TEST_APPRUN_TOKEN is not set")
+}
+
+// fakeAppRunApply adds the cluster list and application creation to fakeAppRun
+type fakeAppRunApply struct {
+	*fakeAppRun
+	appsCreated []*apprun.CreateApplication
+}
+
+func (f *fakeAppRunApply) ListClusters(_ context.Context, _ apprun.ListClustersParams) (*apprun.ListClusterResponse, error) {
+	return &apprun.ListClusterResponse{Clusters: []apprun.ReadClusterDetail{{
+		Name:      "prod",
+		ClusterID: apprun.ClusterID(uuid.MustParse(testAppRunClusterID)),
+	}}}, nil
+}
+
+func (f *fakeAppRunApply) CreateApplication(_ context.Context, req *apprun.CreateApplication) (*apprun.CreateApplicationResponse, error) {
+	f.appsCreated = append(f.appsCreated, req)
+	return &apprun.CreateApplicationResponse{Application: apprun.CreatedApplication{
+		ApplicationID: apprun.ApplicationID(uuid.New()),
+	}}, nil
+}
+
+func TestAppRunApply(t *testing.T) {
+	fake := &fakeAppRunApply{fakeAppRun: newTestFakeAppRun()}
+	client := newFakeAppRunClient(t, fake)
+	ctx := context.Background()
+
+	// The secret keeps its previous value, so a spec matching v1 changes nothing
+	t.Setenv("TEST_APPRUN_TOKEN", "")
+	spec, err := parseAppRunApplySpec([]byte(testAppRunApplyYAML))
+	require.NoError(t, err)
+	plan, err := client.PlanAppRunApply(ctx, spec)
+	require.NoError(t, err)
+	assert.Equal(t, testAppRunAppID, plan.ApplicationID)
+	assert.False(t, plan.HasChanges())
+	assert.Contains(t, plan.String(), "No changes: v1 already matches the spec")
+	version, err := client.ApplyAppRunPlan(ctx, plan)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), version)
+	assert.Empty(t, fake.created)
+
+	// A secret value in the spec cannot be compared, so it is deployed as a rotation
+	t.Setenv("TEST_APPRUN_TOKEN", "rotated")
+	rotated, err := parseAppRunApplySpec([]byte(testAppRunApplyYAML))
+	require.NoError(t, err)
+	plan, err = client.PlanAppRunApply(ctx, rotated)
+	require.NoError(t, err)
+	assert.True(t, plan.HasChanges())
+	assert.Equal(t, "  ~ Env TOKEN: ******** -> ******** (new value)\n", strings.SplitAfterN(plan.String(), "replacing v1:\n", 2)[1])
+
+	spec.Version.Image = "registry.example.com/web:1.1"
+	spec.Version.Env = spec.Version.Env[1:]
+	plan, err = client.PlanAppRunApply(ctx, spec)
+	require.NoError(t, err)
+	out := plan.String()
+	assert.Contains(t, out, "replacing v1")
+	assert.Contains(t, out, "  ~ Image: registry.example.com/web:1.0 -> registry.example.com/web:1.1\n")
+	assert.Contains(t, out, "  - Env MODE: prod\n")
+	assert.NotContains(t, out, "CPU")
+
+	version, err = client.ApplyAppRunPlan(ctx, plan)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), version)
+	assert.Equal(t, int32(2), fake.activeVersion)
+	require.Len(t, fake.created, 1)
+	assert.Equal(t, "registry.example.com/web:1.1", fake.created[0].Image)
+
+	// A missing application is created together with its first version
+	spec.Application = "api"
+	plan, err = client.PlanAppRunApply(ctx, spec)
+	assert.ErrorContains(t, err, "secret TOKEN needs a value for the first version")
+
+	spec.Version.Env[0].Value = "s3cret"
+	plan, err = client.PlanAppRunApply(ctx, spec)
+	require.NoError(t, err)
+	assert.Empty(t, plan.ApplicationID)
+	out = plan.String()
+	assert.Contains(t, out, "Application: api (will be created)")
+	assert.Contains(t, out, "  + Image: registry.example.com/web:1.1\n")
+	_, err = client.ApplyAppRunPlan(ctx, plan)
+	require.NoError(t, err)
+	require.Len(t, fake.appsCreated, 1)
+	assert.Equal(t, "api", fake.appsCreated[0].Name)
+}
+
+// fakeAppRunDuplicates returns two clusters or two applications with the same name
+type fakeAppRunDuplicates struct {
+	*fakeAppRun
+	duplicate string // "cluster" or "application"
+}
+
+const testAppRunDuplicateID = "0d0d0d0d-0000-4000-8000-000000000002"
+
+func (f *fakeAppRunDuplicates) ListClusters(ctx context.Context, params apprun.ListClustersParams) (*apprun.ListClusterResponse, error) {
+	resp, err := f.fakeAppRun.ListClusters(ctx, params)
+	if err == nil && f.duplicate == "cluster" {
+		resp.Clusters = append(resp.Clusters, apprun.ReadClusterDetail{
+			ClusterID: apprun.ClusterID(uuid.MustParse(testAppRunDuplicateID)),
+			Name:      "prod",
+		})
+	}
+	return resp, err
+}
+
+func (f *fakeAppRunDuplicates) ListApplications(ctx context.Context, params apprun.ListApplicationsParams) (*apprun.ListApplicationResponse, error) {
+	resp, err := f.fakeAppRun.ListApplications(ctx, params)
+	if err == nil && f.duplicate == "application" {
+		dup := resp.Applications[0]
+		dup.ApplicationID = apprun.ApplicationID(uuid.MustParse(testAppRunDuplicateID))
+		resp.Applications = append(resp.Applications, dup)
+	}
+	return resp, err
+}
+
+func TestAppRunApplyDuplicateNames(t *testing.T) {
+	fake := &fakeAppRunDuplicates{fakeAppRun: newTestFakeAppRun(), duplicate: "cluster"}
+	client := newFakeAppRunClient(t, fake)
+	t.Setenv("TEST_APPRUN_TOKEN", "")
+	spec, err := parseAppRunApplySpec([]byte(testAppRunApplyYAML))
+	require.NoError(t, err)
+
+	_, err = client.PlanAppRunApply(context.Background(), spec)
+	assert.EqualError(t, err, fmt.Sprintf(`2 clusters are named "prod": %s, %s`, testAppRunClusterID, testAppRunDuplicateID))
+
+	fake.duplicate = "application"
+	_, err = client.PlanAppRunApply(context.Background(), spec)
+	assert.EqualError(t, err, fmt.Sprintf(`2 applications in cluster "prod" are named "web": %s, %s`, testAppRunAppID, testAppRunDuplicateID))
+}
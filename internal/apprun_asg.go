@@ -0,0 +1,317 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+
+	apprun "github.com/tokuhirom/sact/pkg/openapi/apprun_dedicated"
+)
+
+// AppRunServiceClass is a plan available for worker nodes or load balancers
+type AppRunServiceClass struct {
+	Path      string
+	Name      string
+	NodeCount int16 // number of nodes, for load balancer classes
+}
+
+// AppRunASGSpec describes an auto scaling group to create
+type AppRunASGSpec struct {
+	Name         string
+	Zone         string
+	ServiceClass string // service class path
+	MinNodes     int32
+	MaxNodes     int32
+	NameServers  []string
+	Interfaces   []AppRunASGInterface
+}
+
+// AppRunLBSpec describes a load balancer to create
+type AppRunLBSpec struct {
+	Name         string
+	ServiceClass string // service class path
+	NameServers  []string
+	Interfaces   []AppRunLBInterface
+}
+
+// ListAppRunWorkerServiceClasses fetches the service classes available for worker nodes
+func (c *SakuraClient) ListAppRunWorkerServiceClasses(ctx context.Context) ([]AppRunServiceClass, error) {
+	slog.Info("Fetching AppRun worker service classes")
+
+	client, err := c.GetAppRunClient()
+	if err != nil {
+		slog.Error("Failed to get AppRun client", slog.Any("error", err))
+		return nil, err
+	}
+
+	resp, err := client.ListWorkerServiceClasses(ctx)
+	if err != nil {
+		slog.Error("Failed to fetch AppRun worker service classes", slog.Any("error", err))
+		return nil, err
+	}
+
+	classes := make([]AppRunServiceClass, len(resp.WorkerServiceClasses))
+	for i, sc := range resp.WorkerServiceClasses {
+		classes[i] = AppRunServiceClass{Path: sc.Path, Name: sc.Name}
+	}
+	return classes, nil
+}
+
+// ListAppRunLBServiceClasses fetches the service classes available for load balancers
+func (c *SakuraClient) ListAppRunLBServiceClasses(ctx context.Context) ([]AppRunServiceClass, error) {
+	slog.Info("Fetching AppRun LB service classes")
+
+	client, err := c.GetAppRunClient()
+	if err != nil {
+		slog.Error("Failed to get AppRun client", slog.Any("error", err))
+		return nil, err
+	}
+
+	resp, err := client.ListLbServiceClasses(ctx)
+	if err != nil {
+		slog.Error("Failed to fetch AppRun LB service classes", slog.Any("error", err))
+		return nil, err
+	}
+
+	classes := make([]AppRunServiceClass, len(resp.LbServiceClasses))
+	for i, sc := range resp.LbServiceClasses {
+		classes[i] = AppRunServiceClass{Path: sc.Path, Name: sc.Name, NodeCount: sc.NodeCount}
+	}
+	return classes, nil
+}
+
+// toIPRanges converts "start-end" strings to API IP ranges
+func toIPRanges(pool []string) []apprun.IpRange {
+	ranges := make([]apprun.IpRange, 0, len(pool))
+	for _, r := range pool {
+		start, end, _ := strings.Cut(r, "-")
+		ranges = append(ranges, apprun.IpRange{Start: apprun.IPv4(start), End: apprun.IPv4(end)})
+	}
+	return ranges
+}
+
+func toIPv4s(addrs []string) []apprun.IPv4 {
+	if len(addrs) == 0 {
+		return nil
+	}
+	result := make([]apprun.IPv4, len(addrs))
+	for i, addr := range addrs {
+		result[i] = apprun.IPv4(addr)
+	}
+	return result
+}
+
+// CreateAppRunASG creates an auto scaling group in a cluster and returns its ID
+func (c *SakuraClient) CreateAppRunASG(ctx context.Context, clusterID string, spec AppRunASGSpec) (string, error) {
+	slog.Info("Creating AppRun ASG", slog.String("clusterID", clusterID), slog.String("name", spec.Name))
+
+	client, err := c.GetAppRunClient()
+	if err != nil {
+		slog.Error("Failed to get AppRun client", slog.Any("error", err))
+		return "", err
+	}
+
+	parsed, err := uuid.Parse(clusterID)
+	if err != nil {
+		return "", fmt.Errorf("invalid cluster ID: %w", err)
+	}
+
+	req := &apprun.CreateAutoScalingGroup{
+		Name:                   spec.Name,
+		Zone:                   spec.Zone,
+		NameServers:            toIPv4s(spec.NameServers),
+		WorkerServiceClassPath: spec.ServiceClass,
+		MinNodes:               spec.MinNodes,
+		MaxNodes:               spec.MaxNodes,
+	}
+	for _, iface := range spec.Interfaces {
+		ni := apprun.AutoScalingGroupNodeInterface{
+			InterfaceIndex: iface.Index,
+			Upstream:       iface.Upstream,
+			IpPool:         toIPRanges(iface.IPPool),
+			ConnectsToLB:   iface.ConnectsToLB,
+		}
+		if iface.NetmaskLen > 0 {
+			ni.NetmaskLen = apprun.NewOptInt16(iface.NetmaskLen)
+		}
+		if iface.DefaultGateway != "" {
+			ni.DefaultGateway = apprun.NewOptString(iface.DefaultGateway)
+		}
+		if iface.PacketFilterID != "" {
+			ni.PacketFilterID = apprun.NewOptString(iface.PacketFilterID)
+		}
+		req.Interfaces = append(req.Interfaces, ni)
+	}
+
+	resp, err := client.CreateAutoScalingGroup(ctx, req, apprun.CreateAutoScalingGroupParams{ClusterID: apprun.ClusterID(parsed)})
+	if err != nil {
+		slog.Error("Failed to create AppRun ASG", slog.String("name", spec.Name), slog.Any("error", err))
+		return "", err
+	}
+	return uuid.UUID(resp.AutoScalingGroup.AutoScalingGroupID).String(), nil
+}
+
+// DeleteAppRunASG deletes an auto scaling group
+func (c *SakuraClient) DeleteAppRunASG(ctx context.Context, clusterID, asgID string) error {
+	slog.Info("Deleting AppRun ASG", slog.String("clusterID", clusterID), slog.String("asgID", asgID))
+
+	client, err := c.GetAppRunClient()
+	if err != nil {
+		slog.Error("Failed to get AppRun client", slog.Any("error", err))
+		return err
+	}
+
+	parsedCluster, err := uuid.Parse(clusterID)
+	if err != nil {
+		return fmt.Errorf("invalid cluster ID: %w", err)
+	}
+	parsedASG, err := uuid.Parse(asgID)
+	if err != nil {
+		return fmt.Errorf("invalid ASG ID: %w", err)
+	}
+
+	err = client.DeleteAutoScalingGroup(ctx, apprun.DeleteAutoScalingGroupParams{
+		ClusterID:          apprun.ClusterID(parsedCluster),
+		AutoScalingGroupID: apprun.AutoScalingGroupID(parsedASG),
+	})
+	if err != nil {
+		slog.Error("Failed to delete AppRun ASG", slog.String("asgID", asgID), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+// CreateAppRunLB creates a load balancer for an auto scaling group and returns its ID
+func (c *SakuraClient) CreateAppRunLB(ctx context.Context, clusterID, asgID string, spec AppRunLBSpec) (string, error) {
+	slog.Info("Creating AppRun LB", slog.String("asgID", asgID), slog.String("name", spec.Name))
+
+	client, err := c.GetAppRunClient()
+	if err != nil {
+		slog.Error("Failed to get AppRun client", slog.Any("error", err))
+		return "", err
+	}
+
+	parsedCluster, err := uuid.Parse(clusterID)
+	if err != nil {
+		return "", fmt.Errorf("invalid cluster ID: %w", err)
+	}
+	parsedASG, err := uuid.Parse(asgID)
+	if err != nil {
+		return "", fmt.Errorf("invalid ASG ID: %w", err)
+	}
+
+	req := &apprun.CreateLoadBalancer{
+		Name:             spec.Name,
+		ServiceClassPath: spec.ServiceClass,
+		NameServers:      toIPv4s(spec.NameServers),
+	}
+	for _, iface := range spec.Interfaces {
+		li := apprun.LoadBalancerInterface{
+			InterfaceIndex: iface.Index,
+			Upstream:       iface.Upstream,
+			IpPool:         toIPRanges(iface.IPPool),
+		}
+		if iface.NetmaskLen > 0 {
+			li.NetmaskLen = apprun.NewOptInt16(iface.NetmaskLen)
+		}
+		if iface.DefaultGateway != "" {
+			li.DefaultGateway = apprun.NewOptString(iface.DefaultGateway)
+		}
+		if iface.VIP != "" {
+			li.Vip = apprun.NewOptString(iface.VIP)
+			li.VirtualRouterID = apprun.NewOptInt16(iface.VirtualRouterID)
+		}
+		req.Interfaces = append(req.Interfaces, li)
+	}
+
+	resp, err := client.CreateLoadBalancer(ctx, req, apprun.CreateLoadBalancerParams{
+		ClusterID:          apprun.ClusterID(parsedCluster),
+		AutoScalingGroupID: apprun.AutoScalingGroupID(parsedASG),
+	})
+	if err != nil {
+		slog.Error("Failed to create AppRun LB", slog.String("name", spec.Name), slog.Any("error", err))
+		return "", err
+	}
+	return uuid.UUID(resp.LoadBalancer.LoadBalancerID).String(), nil
+}
+
+// DeleteAppRunLB deletes a load balancer
+func (c *SakuraClient) DeleteAppRunLB(ctx context.Context, clusterID, asgID, lbID string) error {
+	slog.Info("Deleting AppRun LB", slog.String("asgID", asgID), slog.String("lbID", lbID))
+
+	client, err := c.GetAppRunClient()
+	if err != nil {
+		slog.Error("Failed to get AppRun client", slog.Any("error", err))
+		return err
+	}
+
+	parsedCluster, err := uuid.Parse(clusterID)
+	if err != nil {
+		return fmt.Errorf("invalid cluster ID: %w", err)
+	}
+	parsedASG, err := uuid.Parse(asgID)
+	if err != nil {
+		return fmt.Errorf("invalid ASG ID: %w", err)
+	}
+	parsedLB, err := uuid.Parse(lbID)
+	if err != nil {
+		return fmt.Errorf("invalid LB ID: %w", err)
+	}
+
+	err = client.DeleteLoadBalancer(ctx, apprun.DeleteLoadBalancerParams{
+		ClusterID:          apprun.ClusterID(parsedCluster),
+		AutoScalingGroupID: apprun.AutoScalingGroupID(parsedASG),
+		LoadBalancerID:     apprun.LoadBalancerID(parsedLB),
+	})
+	if err != nil {
+		slog.Error("Failed to delete AppRun LB", slog.String("lbID", lbID), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+// appRunResourceName is the naming rule for ASGs and LBs
+var appRunResourceName = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,20}$`)
+
+// serviceClassNote lists the service classes for a form
+func serviceClassNote(classes []AppRunServiceClass) string {
+	names := make([]string, len(classes))
+	for i, sc := range classes {
+		names[i] = sc.Name
+		if sc.NodeCount > 0 {
+			names[i] += fmt.Sprintf(" (%d nodes)", sc.NodeCount)
+		}
+	}
+	return "Service classes: " + strings.Join(names, ", ")
+}
+
+// resolveServiceClass returns the path of the service class given by name or path
+func resolveServiceClass(classes []AppRunServiceClass, value string) (string, error) {
+	for _, sc := range classes {
+		if sc.Path == value || sc.Name == value {
+			return sc.Path, nil
+		}
+	}
+	return "", fmt.Errorf("unknown service class %q", value)
+}
+
+// parseIPv4List parses a comma separated list of IPv4 addresses
+func parseIPv4List(value, label string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var addrs []string
+	for _, addr := range strings.Split(value, ",") {
+		addr = strings.TrimSpace(addr)
+		if ip := net.ParseIP(addr); ip == nil || ip.To4() == nil {
+			return nil, fmt.Errorf("%s: %q is not an IPv4 address", label, addr)
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
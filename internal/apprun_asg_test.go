@@ -0,0 +1,214 @@
+package internal
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apprun "github.com/tokuhirom/sact/pkg/openapi/apprun_dedicated"
+)
+
+var testAppRunServiceClasses = []AppRunServiceClass{
+	{Path: "cloud/plan/2core4gb", Name: "2core4gb"},
+	{Path: "cloud/plan/4core8gb", Name: "4core8gb"},
+}
+
+func TestBuildAppRunASGSpec(t *testing.T) {
+	values := map[string]string{
+		"name":          "workers",
+		"zone":          "tk1b",
+		"serviceClass":  "4core8gb",
+		"minNodes":      "1",
+		"maxNodes":      "3",
+		"nameServers":   "133.242.0.3, 133.242.0.4",
+		"eth0.upstream": "shared",
+		"eth0.lb":       "yes",
+		"eth1.upstream": "113000000001",
+		"eth1.ipPool":   "192.168.0.10-192.168.0.20",
+		"eth1.netmask":  "24",
+		"eth1.gateway":  "192.168.0.1",
+		"eth1.lb":       "no",
+	}
+	spec, err := buildAppRunASGSpec(values, testAppRunServiceClasses)
+	require.NoError(t, err)
+	assert.Equal(t, "cloud/plan/4core8gb", spec.ServiceClass)
+	assert.Equal(t, []string{"133.242.0.3", "133.242.0.4"}, spec.NameServers)
+	require.Len(t, spec.Interfaces, 2)
+	assert.Equal(t, AppRunASGInterface{Index: 0, Upstream: "shared", ConnectsToLB: true}, spec.Interfaces[0])
+	assert.Equal(t, AppRunASGInterface{
+		Index:          1,
+		Upstream:       "113000000001",
+		NetmaskLen:     24,
+		DefaultGateway: "192.168.0.1",
+		IPPool:         []string{"192.168.0.10-192.168.0.20"},
+	}, spec.Interfaces[1])
+
+	invalid := []struct {
+		key, value, err string
+	}{
+		{"name", "web server", "name must be"},
+		{"serviceClass", "8core", "unknown service class"},
+		{"minNodes", "4", "1 <= min <= max <= 10"},
+		{"maxNodes", "11", "1 <= min <= max <= 10"},
+		{"nameServers", "dns.example.com", "is not an IPv4 address"},
+		{"eth0.netmask", "24", "cannot be set on the shared segment"},
+		{"eth1.ipPool", "", "IP pool and netmask are required"},
+		{"eth1.ipPool", "192.168.0.10", "start-end"},
+		{"eth1.netmask", "30", "between 8 and 29"},
+		{"eth1.lb", "yes", "only one interface"},
+	}
+	for _, tc := range invalid {
+		broken := map[string]string{}
+		for k, v := range values {
+			broken[k] = v
+		}
+		broken[tc.key] = tc.value
+		_, err := buildAppRunASGSpec(broken, testAppRunServiceClasses)
+		assert.ErrorContains(t, err, tc.err, "%s=%q", tc.key, tc.value)
+	}
+}
+
+func TestBuildAppRunLBSpec(t *testing.T) {
+	values := map[string]string{
+		"name":          "front",
+		"serviceClass":  "cloud/plan/2core4gb",
+		"eth0.upstream": "113000000001",
+		"eth0.ipPool":   "192.168.0.30-192.168.0.31",
+		"eth0.netmask":  "24",
+		"eth0.vip":      "192.168.0.32",
+		"eth0.vrid":     "10",
+	}
+	spec, err := buildAppRunLBSpec(values, testAppRunServiceClasses)
+	require.NoError(t, err)
+	require.Len(t, spec.Interfaces, 1)
+	assert.Equal(t, "192.168.0.32", spec.Interfaces[0].VIP)
+	assert.Equal(t, int16(10), spec.Interfaces[0].VirtualRouterID)
+
+	values["eth0.vrid"] = ""
+	_, err = buildAppRunLBSpec(values, testAppRunServiceClasses)
+	assert.ErrorContains(t, err, "virtual router ID")
+}
+
+// fakeAppRunASGs is an in-memory AppRun Dedicated API serving ASG creation and deletion
+type fakeAppRunASGs struct {
+	apprun.UnimplementedHandler
+
+	mu      sync.Mutex
+	asgs    []apprun.ReadAutoScalingGroupDetail
+	created []*apprun.CreateAutoScalingGroup
+}
+
+func (f *fakeAppRunASGs) ListWorkerServiceClasses(_ context.Context) (*apprun.ListWorkerServiceClassResponse, error) {
+	var classes []apprun.ReadWorkerServiceClass
+	for _, sc := range testAppRunServiceClasses {
+		classes = append(classes, apprun.ReadWorkerServiceClass{Path: sc.Path, Name: sc.Name})
+	}
+	return &apprun.ListWorkerServiceClassResponse{WorkerServiceClasses: classes}, nil
+}
+
+func (f *fakeAppRunASGs) ListAutoScalingGroups(_ context.Context, _ apprun.ListAutoScalingGroupsParams) (*apprun.ListAutoScalingGroupResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &apprun.ListAutoScalingGroupResponse{AutoScalingGroups: f.asgs}, nil
+}
+
+func (f *fakeAppRunASGs) CreateAutoScalingGroup(_ context.Context, req *apprun.CreateAutoScalingGroup, _ apprun.CreateAutoScalingGroupParams) (*apprun.CreateAutoScalingGroupResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.created = append(f.created, req)
+	id := apprun.AutoScalingGroupID(uuid.New())
+	f.asgs = append(f.asgs, apprun.ReadAutoScalingGroupDetail{
+		AutoScalingGroupID:     id,
+		Name:                   req.Name,
+		Zone:                   req.Zone,
+		WorkerServiceClassPath: req.WorkerServiceClassPath,
+		MinNodes:               req.MinNodes,
+		MaxNodes:               req.MaxNodes,
+		Interfaces:             req.Interfaces,
+	})
+	return &apprun.CreateAutoScalingGroupResponse{AutoScalingGroup: apprun.CreatedAutoScalingGroup{AutoScalingGroupID: id}}, nil
+}
+
+func (f *fakeAppRunASGs) DeleteAutoScalingGroup(_ context.Context, params apprun.DeleteAutoScalingGroupParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, asg := range f.asgs {
+		if asg.AutoScalingGroupID == params.AutoScalingGroupID {
+			f.asgs = append(f.asgs[:i], f.asgs[i+1:]...)
+			return nil
+		}
+	}
+	return assert.AnError
+}
+
+func (f *fakeAppRunASGs) ListApplications(_ context.Context, _ apprun.ListApplicationsParams) (*apprun.ListApplicationResponse, error) {
+	return &apprun.ListApplicationResponse{}, nil
+}
+
+func (f *fakeAppRunASGs) ListCertificate(_ context.Context, _ apprun.ListCertificateParams) (*apprun.ListCertificateResponse, error) {
+	return &apprun.ListCertificateResponse{}, nil
+}
+
+func TestAppRunASGCreateAndDelete(t *testing.T) {
+	fake := &fakeAppRunASGs{}
+	client := newFakeAppRunClient(t, fake)
+
+	m := InitialModel(client, "tk1b")
+	m.loading = false
+	m.resourceType = ResourceTypeAppRunDedicated
+	m.appRunDrilldownLevel = 1
+	m.appRunSelectedClusterID = testAppRunClusterID
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'A'}})
+	m = updated.(model)
+	require.NotNil(t, cmd)
+	updated, _ = m.Update(cmd())
+	m = updated.(model)
+	require.NotNil(t, m.form)
+	assert.Contains(t, m.form.view(), "Service classes: 2core4gb, 4core8gb")
+	values := m.form.values()
+	assert.Equal(t, "tk1b", values["zone"])
+	assert.Equal(t, "2core4gb", values["serviceClass"])
+
+	m.form.fields[0].input.SetValue("workers")
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	m = updated.(model)
+	assert.Nil(t, m.form)
+	done := cmd().(actionDoneMsg)
+	require.NoError(t, done.err)
+	assert.Equal(t, "Created ASG workers (1-2 nodes)", done.message)
+	require.Len(t, fake.created, 1)
+	assert.Equal(t, "cloud/plan/2core4gb", fake.created[0].WorkerServiceClassPath)
+	require.Len(t, fake.created[0].Interfaces, 1)
+	assert.True(t, fake.created[0].Interfaces[0].ConnectsToLB)
+
+	updated, cmd = m.Update(done)
+	m = updated.(model)
+	updated, _ = m.Update(cmd())
+	m = updated.(model)
+	require.Len(t, m.list.Items(), 1)
+	m.list.Select(0)
+
+	// Deleting requires typing the ASG name
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	m = updated.(model)
+	require.NotNil(t, m.form)
+	m.form.fields[0].input.SetValue("worker")
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	m = updated.(model)
+	assert.Nil(t, cmd)
+	require.NotNil(t, m.form)
+	assert.Contains(t, m.form.err, "type the name exactly")
+
+	m.form.fields[0].input.SetValue("workers")
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	done = cmd().(actionDoneMsg)
+	require.NoError(t, done.err)
+	assert.Equal(t, "Deleted ASG workers", done.message)
+	assert.Empty(t, fake.asgs)
+}
@@ -0,0 +1,379 @@
+package internal
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	apprun "github.com/tokuhirom/sact/pkg/openapi/apprun_dedicated"
+)
+
+// appRunCertificateExpiryWarning is how close to expiry a certificate is flagged
+const appRunCertificateExpiryWarning = 30 * 24 * time.Hour
+
+// AppRunCertificate represents a TLS certificate registered in a cluster
+type AppRunCertificate struct {
+	ID         string
+	Name       string
+	CommonName string
+	SANs       []string
+	NotBefore  time.Time
+	NotAfter   time.Time
+	CreatedAt  string
+	UpdatedAt  string
+	ClusterID  string // parent cluster ID
+}
+
+// Implement list.Item interface for AppRunCertificate
+func (c AppRunCertificate) FilterValue() string {
+	return c.Name + " " + c.CommonName
+}
+
+func (c AppRunCertificate) Title() string {
+	return c.Name
+}
+
+func (c AppRunCertificate) Description() string {
+	return fmt.Sprintf("CN: %s | %s", c.CommonName, c.ExpiryStatus(time.Now()))
+}
+
+// ExpiryStatus describes when the certificate expires relative to now
+func (c AppRunCertificate) ExpiryStatus(now time.Time) string {
+	return certificateExpiryStatus(c.NotAfter, now)
+}
+
+func certificateExpiryStatus(notAfter, now time.Time) string {
+	if notAfter.IsZero() {
+		return "expiry unknown"
+	}
+	date := notAfter.Local().Format("2006-01-02")
+	left := notAfter.Sub(now)
+	switch {
+	case left <= 0:
+		return fmt.Sprintf("EXPIRED %s", date)
+	case left < appRunCertificateExpiryWarning:
+		return fmt.Sprintf("expires %s (in %d days!)", date, int(left.Hours()/24))
+	}
+	return fmt.Sprintf("expires %s", date)
+}
+
+// CertificateUpload is a certificate read from local PEM files, ready to be sent
+type CertificateUpload struct {
+	CertificatePEM  string
+	PrivateKeyPEM   string
+	IntermediatePEM string
+	// Parsed from the leaf certificate
+	CommonName string
+	SANs       []string
+	NotAfter   time.Time
+}
+
+// Summary describes the parsed certificate for confirmations and status messages
+func (u *CertificateUpload) Summary() string {
+	s := fmt.Sprintf("CN=%s", u.CommonName)
+	if len(u.SANs) > 0 {
+		s += fmt.Sprintf(", SANs=%s", strings.Join(u.SANs, ","))
+	}
+	return s + ", " + certificateExpiryStatus(u.NotAfter, time.Now())
+}
+
+// expandHome replaces a leading ~ with the user's home directory
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+// LoadCertificateFiles reads a certificate, its private key and an optional intermediate
+// chain from PEM files, and checks that the key matches the certificate.
+// When chainPath is empty, certificates following the leaf in certPath are used as the chain.
+func LoadCertificateFiles(certPath, keyPath, chainPath string) (*CertificateUpload, error) {
+	certData, err := os.ReadFile(expandHome(certPath))
+	if err != nil {
+		return nil, fmt.Errorf("certificate: %w", err)
+	}
+	keyData, err := os.ReadFile(expandHome(keyPath))
+	if err != nil {
+		return nil, fmt.Errorf("private key: %w", err)
+	}
+	var chainData []byte
+	if chainPath != "" {
+		chainData, err = os.ReadFile(expandHome(chainPath))
+		if err != nil {
+			return nil, fmt.Errorf("intermediate certificate: %w", err)
+		}
+	}
+	return parseCertificateUpload(certData, keyData, chainData)
+}
+
+// parseCertificateUpload validates PEM data and extracts the leaf certificate details
+func parseCertificateUpload(certData, keyData, chainData []byte) (*CertificateUpload, error) {
+	var certs []*pem.Block
+	for rest := certData; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type == "CERTIFICATE" {
+			certs = append(certs, block)
+		}
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no PEM certificate found in the certificate file")
+	}
+
+	leaf, err := x509.ParseCertificate(certs[0].Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate: %w", err)
+	}
+	if _, err := tls.X509KeyPair(pem.EncodeToMemory(certs[0]), keyData); err != nil {
+		return nil, fmt.Errorf("private key does not match the certificate: %w", err)
+	}
+	if time.Now().After(leaf.NotAfter) {
+		return nil, fmt.Errorf("certificate expired on %s", leaf.NotAfter.Local().Format("2006-01-02"))
+	}
+
+	upload := &CertificateUpload{
+		CertificatePEM: string(pem.EncodeToMemory(certs[0])),
+		PrivateKeyPEM:  string(keyData),
+		CommonName:     leaf.Subject.CommonName,
+		SANs:           leaf.DNSNames,
+		NotAfter:       leaf.NotAfter,
+	}
+	for _, ip := range leaf.IPAddresses {
+		upload.SANs = append(upload.SANs, ip.String())
+	}
+
+	if len(chainData) > 0 {
+		if block, _ := pem.Decode(chainData); block == nil || block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf("no PEM certificate found in the intermediate certificate file")
+		}
+		upload.IntermediatePEM = string(chainData)
+	} else {
+		var chain strings.Builder
+		for _, block := range certs[1:] {
+			chain.Write(pem.EncodeToMemory(block))
+		}
+		upload.IntermediatePEM = chain.String()
+	}
+
+	return upload, nil
+}
+
+func convertAppRunCertificate(cert apprun.ReadCertificate, clusterID string) AppRunCertificate {
+	result := AppRunCertificate{
+		ID:         uuid.UUID(cert.CertificateID).String(),
+		Name:       cert.Name,
+		CommonName: cert.CommonName,
+		SANs:       cert.SubjectAlternativeNames,
+		ClusterID:  clusterID,
+	}
+	if cert.NotBeforeSec > 0 {
+		result.NotBefore = time.Unix(int64(cert.NotBeforeSec), 0)
+	}
+	if cert.NotAfterSec > 0 {
+		result.NotAfter = time.Unix(int64(cert.NotAfterSec), 0)
+	}
+	if cert.Created > 0 {
+		result.CreatedAt = time.Unix(int64(cert.Created), 0).Format("2006-01-02 15:04:05")
+	}
+	if cert.Updated > 0 {
+		result.UpdatedAt = time.Unix(int64(cert.Updated), 0).Format("2006-01-02 15:04:05")
+	}
+	return result
+}
+
+// ListAppRunCertificates fetches all certificates of a cluster
+func (c *SakuraClient) ListAppRunCertificates(ctx context.Context, clusterID string) ([]AppRunCertificate, error) {
+	slog.Info("Fetching AppRun certificates", slog.String("clusterID", clusterID))
+
+	client, err := c.GetAppRunClient()
+	if err != nil {
+		slog.Error("Failed to get AppRun client", slog.Any("error", err))
+		return nil, err
+	}
+
+	parsed, err := uuid.Parse(clusterID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cluster ID: %w", err)
+	}
+
+	var allCerts []AppRunCertificate
+	var cursor apprun.OptCertificateID
+
+	for {
+		resp, err := client.ListCertificate(ctx, apprun.ListCertificateParams{
+			ClusterID: apprun.ClusterID(parsed),
+			MaxItems:  30,
+			Cursor:    cursor,
+		})
+		if err != nil {
+			slog.Error("Failed to fetch AppRun certificates", slog.Any("error", err))
+			return nil, err
+		}
+
+		for _, cert := range resp.Certificates {
+			allCerts = append(allCerts, convertAppRunCertificate(cert, clusterID))
+		}
+
+		if !resp.NextCursor.Set {
+			break
+		}
+		cursor = resp.NextCursor
+	}
+
+	slog.Info("Successfully fetched AppRun certificates", slog.Int("count", len(allCerts)))
+	return allCerts, nil
+}
+
+// GetAppRunCertificate fetches a single certificate
+func (c *SakuraClient) GetAppRunCertificate(ctx context.Context, clusterID, certificateID string) (*AppRunCertificate, error) {
+	slog.Info("Fetching AppRun certificate", slog.String("certificateID", certificateID))
+
+	client, err := c.GetAppRunClient()
+	if err != nil {
+		slog.Error("Failed to get AppRun client", slog.Any("error", err))
+		return nil, err
+	}
+
+	parsedCluster, err := uuid.Parse(clusterID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cluster ID: %w", err)
+	}
+	parsedCert, err := uuid.Parse(certificateID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate ID: %w", err)
+	}
+
+	resp, err := client.GetCertificate(ctx, apprun.GetCertificateParams{
+		ClusterID:     apprun.ClusterID(parsedCluster),
+		CertificateID: apprun.CertificateID(parsedCert),
+	})
+	if err != nil {
+		slog.Error("Failed to fetch AppRun certificate", slog.String("certificateID", certificateID), slog.Any("error", err))
+		return nil, err
+	}
+
+	cert := convertAppRunCertificate(resp.Certificate, clusterID)
+	return &cert, nil
+}
+
+func optCertificatePEM(s string) apprun.OptString {
+	if s == "" {
+		return apprun.OptString{}
+	}
+	return apprun.NewOptString(s)
+}
+
+// CreateAppRunCertificate registers a certificate in a cluster and returns its ID
+func (c *SakuraClient) CreateAppRunCertificate(ctx context.Context, clusterID, name string, upload *CertificateUpload) (string, error) {
+	slog.Info("Creating AppRun certificate", slog.String("clusterID", clusterID), slog.String("name", name))
+
+	client, err := c.GetAppRunClient()
+	if err != nil {
+		slog.Error("Failed to get AppRun client", slog.Any("error", err))
+		return "", err
+	}
+
+	parsed, err := uuid.Parse(clusterID)
+	if err != nil {
+		return "", fmt.Errorf("invalid cluster ID: %w", err)
+	}
+
+	resp, err := client.CreateCertificate(ctx, &apprun.CreateCertificate{
+		Name:                       name,
+		CertificatePem:             upload.CertificatePEM,
+		PrivatekeyPem:              upload.PrivateKeyPEM,
+		IntermediateCertificatePem: optCertificatePEM(upload.IntermediatePEM),
+	}, apprun.CreateCertificateParams{ClusterID: apprun.ClusterID(parsed)})
+	if err != nil {
+		slog.Error("Failed to create AppRun certificate", slog.String("name", name), slog.Any("error", err))
+		return "", err
+	}
+
+	return uuid.UUID(resp.Certificate.CertificateID).String(), nil
+}
+
+// UpdateAppRunCertificate replaces the certificate and key of an existing certificate
+func (c *SakuraClient) UpdateAppRunCertificate(ctx context.Context, clusterID, certificateID, name string, upload *CertificateUpload) error {
+	slog.Info("Updating AppRun certificate", slog.String("certificateID", certificateID), slog.String("name", name))
+
+	client, err := c.GetAppRunClient()
+	if err != nil {
+		slog.Error("Failed to get AppRun client", slog.Any("error", err))
+		return err
+	}
+
+	parsedCluster, err := uuid.Parse(clusterID)
+	if err != nil {
+		return fmt.Errorf("invalid cluster ID: %w", err)
+	}
+	parsedCert, err := uuid.Parse(certificateID)
+	if err != nil {
+		return fmt.Errorf("invalid certificate ID: %w", err)
+	}
+
+	err = client.UpdateCertificate(ctx, &apprun.UpdateCertificate{
+		Name:                       name,
+		CertificatePem:             upload.CertificatePEM,
+		PrivatekeyPem:              upload.PrivateKeyPEM,
+		IntermediateCertificatePem: optCertificatePEM(upload.IntermediatePEM),
+	}, apprun.UpdateCertificateParams{
+		ClusterID:     apprun.ClusterID(parsedCluster),
+		CertificateID: apprun.CertificateID(parsedCert),
+	})
+	if err != nil {
+		slog.Error("Failed to update AppRun certificate", slog.String("certificateID", certificateID), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+// DeleteAppRunCertificate deletes a certificate from a cluster
+func (c *SakuraClient) DeleteAppRunCertificate(ctx context.Context, clusterID, certificateID string) error {
+	slog.Info("Deleting AppRun certificate", slog.String("certificateID", certificateID))
+
+	client, err := c.GetAppRunClient()
+	if err != nil {
+		slog.Error("Failed to get AppRun client", slog.Any("error", err))
+		return err
+	}
+
+	parsedCluster, err := uuid.Parse(clusterID)
+	if err != nil {
+		return fmt.Errorf("invalid cluster ID: %w", err)
+	}
+	parsedCert, err := uuid.Parse(certificateID)
+	if err != nil {
+		return fmt.Errorf("invalid certificate ID: %w", err)
+	}
+
+	err = client.DeleteCertificate(ctx, apprun.DeleteCertificateParams{
+		ClusterID:     apprun.ClusterID(parsedCluster),
+		CertificateID: apprun.CertificateID(parsedCert),
+	})
+	if err != nil {
+		slog.Error("Failed to delete AppRun certificate", slog.String("certificateID", certificateID), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+// appRunCertificateName is the naming rule for certificates; unlike ASGs and LBs a '.' is allowed
+var appRunCertificateName = regexp.MustCompile(`^[a-zA-Z0-9_.-]{1,20}$`)
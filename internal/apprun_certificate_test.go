@@ -0,0 +1,215 @@
+package internal
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apprun "github.com/tokuhirom/sact/pkg/openapi/apprun_dedicated"
+)
+
+// writeTestCertificate writes a self-signed certificate and its key as PEM files in dir
+func writeTestCertificate(t *testing.T, dir, name, cn string, sans []string, notAfter time.Time) (certPath, keyPath string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		DNSNames:     sans,
+		NotBefore:    notAfter.Add(-90 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPath = filepath.Join(dir, name+".crt")
+	keyPath = filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certPath, keyPath
+}
+
+func TestLoadCertificateFiles(t *testing.T) {
+	dir := t.TempDir()
+	notAfter := time.Now().Add(365 * 24 * time.Hour).Truncate(time.Second)
+	certPath, keyPath := writeTestCertificate(t, dir, "site", "example.com", []string{"example.com", "www.example.com"}, notAfter)
+	caPath, otherKeyPath := writeTestCertificate(t, dir, "ca", "Example CA", nil, notAfter)
+
+	upload, err := LoadCertificateFiles(certPath, keyPath, "")
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", upload.CommonName)
+	assert.Equal(t, []string{"example.com", "www.example.com"}, upload.SANs)
+	assert.True(t, notAfter.Equal(upload.NotAfter))
+	assert.Empty(t, upload.IntermediatePEM)
+	assert.Contains(t, upload.Summary(), "CN=example.com, SANs=example.com,www.example.com, expires ")
+
+	// Certificates after the leaf in a full chain file become the intermediate chain
+	site, err := os.ReadFile(certPath)
+	require.NoError(t, err)
+	ca, err := os.ReadFile(caPath)
+	require.NoError(t, err)
+	fullchain := filepath.Join(dir, "fullchain.pem")
+	require.NoError(t, os.WriteFile(fullchain, append(site, ca...), 0o600))
+	upload, err = LoadCertificateFiles(fullchain, keyPath, "")
+	require.NoError(t, err)
+	assert.Equal(t, string(site), upload.CertificatePEM)
+	assert.Equal(t, string(ca), upload.IntermediatePEM)
+
+	_, err = LoadCertificateFiles(certPath, otherKeyPath, "")
+	assert.ErrorContains(t, err, "private key does not match the certificate")
+
+	_, err = LoadCertificateFiles(keyPath, keyPath, "")
+	assert.ErrorContains(t, err, "no PEM certificate found")
+
+	_, err = LoadCertificateFiles(certPath, keyPath, keyPath)
+	assert.ErrorContains(t, err, "intermediate certificate")
+
+	expiredCert, expiredKey := writeTestCertificate(t, dir, "expired", "old.example.com", nil, time.Now().Add(-time.Hour))
+	_, err = LoadCertificateFiles(expiredCert, expiredKey, "")
+	assert.ErrorContains(t, err, "certificate expired on")
+}
+
+func TestCertificateExpiryStatus(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.Local)
+	assert.Equal(t, "expires 2025-12-01", certificateExpiryStatus(now.AddDate(0, 6, 0), now))
+	assert.Equal(t, "expires 2025-06-11 (in 10 days!)", certificateExpiryStatus(now.AddDate(0, 0, 10), now))
+	assert.Equal(t, "EXPIRED 2025-05-31", certificateExpiryStatus(now.AddDate(0, 0, -1), now))
+	assert.Equal(t, "expiry unknown", certificateExpiryStatus(time.Time{}, now))
+}
+
+// fakeAppRunCertificates is an in-memory AppRun Dedicated API serving cluster certificates
+type fakeAppRunCertificates struct {
+	apprun.UnimplementedHandler
+
+	mu      sync.Mutex
+	certs   []apprun.ReadCertificate
+	created []*apprun.CreateCertificate
+}
+
+func (f *fakeAppRunCertificates) ListAutoScalingGroups(_ context.Context, _ apprun.ListAutoScalingGroupsParams) (*apprun.ListAutoScalingGroupResponse, error) {
+	return &apprun.ListAutoScalingGroupResponse{}, nil
+}
+
+func (f *fakeAppRunCertificates) ListApplications(_ context.Context, _ apprun.ListApplicationsParams) (*apprun.ListApplicationResponse, error) {
+	return &apprun.ListApplicationResponse{}, nil
+}
+
+func (f *fakeAppRunCertificates) ListCertificate(_ context.Context, _ apprun.ListCertificateParams) (*apprun.ListCertificateResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &apprun.ListCertificateResponse{Certificates: f.certs}, nil
+}
+
+func (f *fakeAppRunCertificates) CreateCertificate(_ context.Context, req *apprun.CreateCertificate, _ apprun.CreateCertificateParams) (*apprun.CreateCertificateResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.created = append(f.created, req)
+	id := apprun.CertificateID(uuid.New())
+	f.certs = append(f.certs, apprun.ReadCertificate{
+		CertificateID:           id,
+		Name:                    req.Name,
+		CommonName:              "example.com",
+		SubjectAlternativeNames: []string{"example.com"},
+		NotAfterSec:             int(time.Now().Add(24 * time.Hour).Unix()),
+	})
+	return &apprun.CreateCertificateResponse{Certificate: apprun.CreatedCertificate{CertificateID: id}}, nil
+}
+
+func (f *fakeAppRunCertificates) DeleteCertificate(_ context.Context, params apprun.DeleteCertificateParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, cert := range f.certs {
+		if cert.CertificateID == params.CertificateID {
+			f.certs = append(f.certs[:i], f.certs[i+1:]...)
+			return nil
+		}
+	}
+	return assert.AnError
+}
+
+func TestAppRunCertificateUploadAndDelete(t *testing.T) {
+	fake := &fakeAppRunCertificates{}
+	client := newFakeAppRunClient(t, fake)
+	dir := t.TempDir()
+	certPath, keyPath := writeTestCertificate(t, dir, "site", "example.com", []string{"example.com"}, time.Now().Add(24*time.Hour))
+
+	m := InitialModel(client, "tk1b")
+	m.loading = false
+	m.resourceType = ResourceTypeAppRunDedicated
+	m.appRunDrilldownLevel = 1
+	m.appRunSelectedClusterID = testAppRunClusterID
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'C'}})
+	m = updated.(model)
+	require.NotNil(t, m.form)
+	m.form.fields[0].input.SetValue("example")
+	m.form.fields[1].input.SetValue(certPath)
+	m.form.fields[2].input.SetValue(certPath)
+
+	// A key that does not match the certificate is rejected before anything is sent
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	m = updated.(model)
+	assert.Nil(t, cmd)
+	require.NotNil(t, m.form)
+	assert.Contains(t, m.form.err, "private key")
+
+	m.form.fields[2].input.SetValue(keyPath)
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	m = updated.(model)
+	assert.Nil(t, m.form)
+	done := cmd().(actionDoneMsg)
+	require.NoError(t, done.err)
+	assert.Contains(t, done.message, "Uploaded certificate example (CN=example.com")
+	require.Len(t, fake.created, 1)
+	assert.Equal(t, "example", fake.created[0].Name)
+	assert.False(t, fake.created[0].IntermediateCertificatePem.Set)
+
+	updated, cmd = m.Update(done)
+	m = updated.(model)
+	updated, _ = m.Update(cmd())
+	m = updated.(model)
+	require.Len(t, m.list.Items(), 1)
+	cert := m.list.Items()[0].(AppRunCertificate)
+	assert.Equal(t, "example.com", cert.CommonName)
+
+	m.list.Select(0)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	m = updated.(model)
+	assert.Equal(t, "Delete certificate example (CN=example.com)?", m.confirmMessage)
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	done = cmd().(actionDoneMsg)
+	require.NoError(t, done.err)
+	assert.Empty(t, fake.certs)
+}
+
+func TestAppRunCertificateFormName(t *testing.T) {
+	f := newAppRunCertificateForm("Upload certificate", nil, func(string, *CertificateUpload) tea.Cmd { return nil })
+	for name, want := range map[string]string{
+		"":                          "name must be",
+		"example com":               "name must be",
+		"example/com":               "name must be",
+		"a-very-long-certificate-1": "name must be",
+		"example.com_2026":          "certificate and private key files are required",
+	} {
+		_, err := f.submit(map[string]string{"name": name})
+		assert.ErrorContains(t, err, want, name)
+	}
+}
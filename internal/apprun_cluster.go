@@ -0,0 +1,184 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	apprun "github.com/tokuhirom/sact/pkg/openapi/apprun_dedicated"
+)
+
+// AppRunClusterSpec describes a cluster to create
+type AppRunClusterSpec struct {
+	Name               string
+	Ports              []AppRunPort
+	LetsEncryptEmail   string // empty to disable Let's Encrypt
+	ServicePrincipalID string
+}
+
+// CreateAppRunCluster creates a cluster and returns its ID
+func (c *SakuraClient) CreateAppRunCluster(ctx context.Context, spec AppRunClusterSpec) (string, error) {
+	slog.Info("Creating AppRun cluster", slog.String("name", spec.Name))
+
+	client, err := c.GetAppRunClient()
+	if err != nil {
+		slog.Error("Failed to get AppRun client", slog.Any("error", err))
+		return "", err
+	}
+
+	req := &apprun.CreateCluster{
+		Name:               spec.Name,
+		Ports:              []apprun.CreateLoadBalancerPort{},
+		ServicePrincipalID: spec.ServicePrincipalID,
+	}
+	if spec.LetsEncryptEmail != "" {
+		req.LetsEncryptEmail = apprun.NewOptString(spec.LetsEncryptEmail)
+	}
+	for _, p := range spec.Ports {
+		req.Ports = append(req.Ports, apprun.CreateLoadBalancerPort{
+			Port:     p.Port,
+			Protocol: apprun.CreateLoadBalancerPortProtocol(p.Protocol),
+		})
+	}
+
+	resp, err := client.CreateCluster(ctx, req)
+	if err != nil {
+		slog.Error("Failed to create AppRun cluster", slog.String("name", spec.Name), slog.Any("error", err))
+		return "", err
+	}
+	return uuid.UUID(resp.Cluster.ClusterID).String(), nil
+}
+
+// UpdateAppRunCluster changes the Let's Encrypt email and service principal of a cluster.
+// An empty email disables Let's Encrypt. The load balancer ports cannot be changed.
+func (c *SakuraClient) UpdateAppRunCluster(ctx context.Context, clusterID, letsEncryptEmail, servicePrincipalID string) error {
+	slog.Info("Updating AppRun cluster", slog.String("clusterID", clusterID))
+
+	client, err := c.GetAppRunClient()
+	if err != nil {
+		slog.Error("Failed to get AppRun client", slog.Any("error", err))
+		return err
+	}
+
+	parsed, err := uuid.Parse(clusterID)
+	if err != nil {
+		return fmt.Errorf("invalid cluster ID: %w", err)
+	}
+
+	req := &apprun.UpdateCluster{ServicePrincipalID: servicePrincipalID}
+	if letsEncryptEmail != "" {
+		req.LetsEncryptEmail = apprun.NewOptString(letsEncryptEmail)
+	}
+	err = client.UpdateCluster(ctx, req, apprun.UpdateClusterParams{ClusterID: apprun.ClusterID(parsed)})
+	if err != nil {
+		slog.Error("Failed to update AppRun cluster", slog.String("clusterID", clusterID), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+// DeleteAppRunCluster deletes a cluster. It refuses while applications remain in the cluster.
+func (c *SakuraClient) DeleteAppRunCluster(ctx context.Context, clusterID string) error {
+	apps, err := c.ListAppRunApplications(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+	if len(apps) > 0 {
+		names := make([]string, len(apps))
+		for i, app := range apps {
+			names[i] = app.Name
+		}
+		return fmt.Errorf("the cluster still has %d applications: %s", len(apps), strings.Join(names, ", "))
+	}
+
+	slog.Info("Deleting AppRun cluster", slog.String("clusterID", clusterID))
+
+	client, err := c.GetAppRunClient()
+	if err != nil {
+		slog.Error("Failed to get AppRun client", slog.Any("error", err))
+		return err
+	}
+
+	parsed, err := uuid.Parse(clusterID)
+	if err != nil {
+		return fmt.Errorf("invalid cluster ID: %w", err)
+	}
+
+	if err := client.DeleteCluster(ctx, apprun.DeleteClusterParams{ClusterID: apprun.ClusterID(parsed)}); err != nil {
+		slog.Error("Failed to delete AppRun cluster", slog.String("clusterID", clusterID), slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+// formatAppRunPorts formats ports as "80/http, 443/https"
+func formatAppRunPorts(ports []AppRunPort) string {
+	parts := make([]string, len(ports))
+	for i, p := range ports {
+		parts[i] = fmt.Sprintf("%d/%s", p.Port, p.Protocol)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// parseAppRunPorts parses load balancer ports written as "80/http, 443/https"
+func parseAppRunPorts(value string) ([]AppRunPort, error) {
+	if value == "" {
+		return nil, nil
+	}
+	var ports []AppRunPort
+	seen := map[uint16]bool{}
+	for _, part := range strings.Split(value, ",") {
+		portStr, protocol, ok := strings.Cut(strings.TrimSpace(part), "/")
+		if !ok {
+			return nil, fmt.Errorf("ports must be written as port/protocol, e.g. 443/https")
+		}
+		port, err := strconv.ParseUint(portStr, 10, 16)
+		if err != nil || port == 0 {
+			return nil, fmt.Errorf("invalid port %q", portStr)
+		}
+		switch apprun.CreateLoadBalancerPortProtocol(protocol) {
+		case apprun.CreateLoadBalancerPortProtocolHTTP, apprun.CreateLoadBalancerPortProtocolHTTPS, apprun.CreateLoadBalancerPortProtocolTCP:
+		default:
+			return nil, fmt.Errorf("protocol of port %d must be http, https or tcp", port)
+		}
+		if seen[uint16(port)] {
+			return nil, fmt.Errorf("port %d is listed twice", port)
+		}
+		seen[uint16(port)] = true
+		ports = append(ports, AppRunPort{Port: uint16(port), Protocol: protocol})
+	}
+	if len(ports) > 5 {
+		return nil, fmt.Errorf("at most 5 ports can be opened")
+	}
+	return ports, nil
+}
+
+var appRunEmailPattern = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+
+// validateLetsEncrypt checks the email and that port 80 is open for the HTTP-01 challenge
+func validateLetsEncrypt(email string, ports []AppRunPort) error {
+	if email == "" {
+		return nil
+	}
+	if !appRunEmailPattern.MatchString(email) {
+		return fmt.Errorf("invalid Let's Encrypt email %q", email)
+	}
+	for _, p := range ports {
+		if p.Port == 80 && p.Protocol == "http" {
+			return nil
+		}
+	}
+	return fmt.Errorf("port 80/http is required for the Let's Encrypt HTTP-01 challenge")
+}
+
+func validateServicePrincipalID(id string) error {
+	if len(id) != 12 {
+		return fmt.Errorf("service principal ID must be 12 characters")
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package internal
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apprun "github.com/tokuhirom/sact/pkg/openapi/apprun_dedicated"
+)
+
+func TestParseAppRunPorts(t *testing.T) {
+	ports, err := parseAppRunPorts("80/http, 443/https,5432/tcp")
+	require.NoError(t, err)
+	assert.Equal(t, []AppRunPort{{80, "http"}, {443, "https"}, {5432, "tcp"}}, ports)
+	assert.Equal(t, "80/http, 443/https, 5432/tcp", formatAppRunPorts(ports))
+
+	for value, msg := range map[string]string{
+		"443":                                 "port/protocol",
+		"0/http":                              "invalid port",
+		"443/udp":                             "must be http, https or tcp",
+		"80/http, 80/tcp":                     "listed twice",
+		"1/tcp,2/tcp,3/tcp,4/tcp,5/tcp,6/tcp": "at most 5 ports",
+	} {
+		_, err := parseAppRunPorts(value)
+		assert.ErrorContains(t, err, msg, value)
+	}
+
+	assert.NoError(t, validateLetsEncrypt("ops@example.com", ports))
+	assert.ErrorContains(t, validateLetsEncrypt("ops@example.com", ports[1:]), "port 80/http is required")
+	assert.ErrorContains(t, validateLetsEncrypt("ops", ports), "invalid Let's Encrypt email")
+}
+
+// fakeAppRunClusters is an in-memory AppRun Dedicated API with a single cluster
+type fakeAppRunClusters struct {
+	apprun.UnimplementedHandler
+
+	mu      sync.Mutex
+	apps    []string
+	updated *apprun.UpdateCluster
+	deleted bool
+}
+
+func (f *fakeAppRunClusters) GetCluster(_ context.Context, params apprun.GetClusterParams) (*apprun.GetClusterResponse, error) {
+	return &apprun.GetClusterResponse{Cluster: apprun.ReadClusterDetail{
+		ClusterID:           params.ClusterID,
+		Name:                "prod",
+		Ports:               []apprun.ReadLoadBalancerPort{{Port: 80, Protocol: "http"}, {Port: 443, Protocol: "https"}},
+		ServicePrincipalID:  "113000000001",
+		HasLetsEncryptEmail: true,
+	}}, nil
+}
+
+func (f *fakeAppRunClusters) UpdateCluster(_ context.Context, req *apprun.UpdateCluster, _ apprun.UpdateClusterParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.updated = req
+	return nil
+}
+
+func (f *fakeAppRunClusters) ListApplications(_ context.Context, _ apprun.ListApplicationsParams) (*apprun.ListApplicationResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resp := &apprun.ListApplicationResponse{}
+	for _, name := range f.apps {
+		resp.Applications = append(resp.Applications, apprun.ReadApplicationDetail{
+			ApplicationID: apprun.ApplicationID(uuid.New()),
+			Name:          name,
+			ClusterID:     apprun.ClusterID(uuid.MustParse(testAppRunClusterID)),
+		})
+	}
+	return resp, nil
+}
+
+func (f *fakeAppRunClusters) DeleteCluster(_ context.Context, _ apprun.DeleteClusterParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deleted = true
+	return nil
+}
+
+func newAppRunClusterListModel(client *SakuraClient) model {
+	m := InitialModel(client, "tk1b")
+	m.loading = false
+	m.resourceType = ResourceTypeAppRunDedicated
+	m.list.SetItems([]list.Item{AppRunCluster{ID: testAppRunClusterID, Name: "prod", HasLetsEncrypt: true, ServicePrincipal: "113000000001"}})
+	return m
+}
+
+func TestAppRunClusterEdit(t *testing.T) {
+	fake := &fakeAppRunClusters{}
+	m := newAppRunClusterListModel(newFakeAppRunClient(t, fake))
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'E'}})
+	m = updated.(model)
+	updated, _ = m.Update(cmd())
+	m = updated.(model)
+	require.NotNil(t, m.form)
+	assert.Contains(t, m.form.view(), "LB ports: 80/http, 443/https (cannot be changed) | Let's Encrypt: enabled")
+	assert.Equal(t, "113000000001", m.form.values()["servicePrincipalID"])
+
+	// Leaving the email empty would silently disable Let's Encrypt
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	m = updated.(model)
+	assert.Nil(t, cmd)
+	assert.Contains(t, m.form.err, "enter the email again")
+
+	m.form.fields[0].input.SetValue("-")
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	done := cmd().(actionDoneMsg)
+	require.NoError(t, done.err)
+	assert.Equal(t, "Updated cluster prod (Let's Encrypt disabled)", done.message)
+	require.NotNil(t, fake.updated)
+	assert.False(t, fake.updated.LetsEncryptEmail.Set)
+	assert.Equal(t, "113000000001", fake.updated.ServicePrincipalID)
+}
+
+func TestAppRunClusterDelete(t *testing.T) {
+	fake := &fakeAppRunClusters{apps: []string{"web", "api"}}
+	m := newAppRunClusterListModel(newFakeAppRunClient(t, fake))
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	m = updated.(model)
+	require.NotNil(t, m.form)
+	m.form.fields[0].input.SetValue("prod")
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	done := cmd().(actionDoneMsg)
+	assert.EqualError(t, done.err, "the cluster still has 2 applications: web, api")
+	assert.False(t, fake.deleted)
+
+	fake.apps = nil
+	done = cmd().(actionDoneMsg)
+	require.NoError(t, done.err)
+	assert.Equal(t, "Deleted cluster prod", done.message)
+	assert.True(t, fake.deleted)
+}
@@ -0,0 +1,287 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	apprun "github.com/tokuhirom/sact/pkg/openapi/apprun_dedicated"
+)
+
+// AppRunContainer is a container running on a worker node
+type AppRunContainer struct {
+	ID              string
+	ApplicationID   string
+	ApplicationName string
+	Version         int64
+	Image           string
+	State           string
+	Status          string
+	CPUUsagePercent float32
+}
+
+// AppRunDesiredContainer is a container scheduled onto a worker node but not yet running
+type AppRunDesiredContainer struct {
+	ApplicationID string
+	Version       int64
+	Image         string
+	CPUMillis     int64
+	MemoryMB      int64
+}
+
+// AppRunContainerNode is the placement of an application's containers on one worker node
+type AppRunContainerNode struct {
+	NodeID      string
+	CollectedAt string
+	Containers  []AppRunContainer
+	Desired     []AppRunDesiredContainer
+	// Resolved from the cluster's auto scaling groups; nil if the node was not found
+	WorkerNode *AppRunWorkerNode
+	ASGName    string
+}
+
+// AppRunContainerPlacement shows where the containers of an application run
+type AppRunContainerPlacement struct {
+	App   AppRunApplication
+	Nodes []AppRunContainerNode
+}
+
+// ContainerCount returns the number of running containers over all nodes
+func (p *AppRunContainerPlacement) ContainerCount() int {
+	count := 0
+	for _, node := range p.Nodes {
+		count += len(node.Containers)
+	}
+	return count
+}
+
+// matches reports whether the placement node ID refers to the worker node
+func (w AppRunWorkerNode) matches(nodeID string) bool {
+	return nodeID != "" && (w.ID == nodeID || w.ResourceID == nodeID)
+}
+
+// ListAppRunApplicationContainers fetches the containers of an application per worker node
+func (c *SakuraClient) ListAppRunApplicationContainers(ctx context.Context, applicationID string) ([]AppRunContainerNode, error) {
+	slog.Info("Fetching AppRun application containers", slog.String("applicationID", applicationID))
+
+	client, err := c.GetAppRunClient()
+	if err != nil {
+		slog.Error("Failed to get AppRun client", slog.Any("error", err))
+		return nil, err
+	}
+
+	appID, err := parseAppRunApplicationID(applicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.GetApplicationContainers(ctx, apprun.GetApplicationContainersParams{
+		ApplicationID: appID,
+	})
+	if err != nil {
+		slog.Error("Failed to fetch AppRun application containers",
+			slog.String("applicationID", applicationID),
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	nodes := make([]AppRunContainerNode, 0, len(resp.Nodes))
+	for _, n := range resp.Nodes {
+		node := AppRunContainerNode{NodeID: n.NodeID}
+		if !n.ContainersStats.Null {
+			stats := n.ContainersStats.Value
+			if stats.CollectedAtSec > 0 {
+				node.CollectedAt = time.Unix(stats.CollectedAtSec, 0).Format("2006-01-02 15:04:05")
+			}
+			for _, ct := range stats.Containers {
+				// The API reports every container on the node; keep only this application's
+				if ct.ApplicationID != "" && ct.ApplicationID != applicationID {
+					continue
+				}
+				node.Containers = append(node.Containers, AppRunContainer{
+					ID:              ct.ID,
+					ApplicationID:   ct.ApplicationID,
+					Version:         ct.ApplicationVersion,
+					Image:           ct.Image,
+					State:           ct.State,
+					Status:          ct.Status,
+					CPUUsagePercent: ct.CpuUsagePercent,
+				})
+			}
+		}
+		if !n.Desired.Null {
+			for _, d := range n.Desired.Value.Containers {
+				if d.ApplicationID != "" && d.ApplicationID != applicationID {
+					continue
+				}
+				node.Desired = append(node.Desired, AppRunDesiredContainer{
+					ApplicationID: d.ApplicationID,
+					Version:       d.ApplicationVersion,
+					Image:         d.Image,
+					CPUMillis:     d.CpuMillis,
+					MemoryMB:      d.MemoryMB,
+				})
+			}
+		}
+		nodes = append(nodes, node)
+	}
+
+	slog.Info("Successfully fetched AppRun application containers", slog.Int("nodes", len(nodes)))
+	return nodes, nil
+}
+
+// GetAppRunContainerPlacement fetches the containers of an application and resolves
+// the worker node and ASG each of them runs on
+func (c *SakuraClient) GetAppRunContainerPlacement(ctx context.Context, app AppRunApplication) (*AppRunContainerPlacement, error) {
+	nodes, err := c.ListAppRunApplicationContainers(ctx, app.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range nodes {
+		for j := range nodes[i].Containers {
+			nodes[i].Containers[j].ApplicationName = app.Name
+		}
+	}
+
+	asgs, err := c.ListAppRunASGs(ctx, app.ClusterID)
+	if err != nil {
+		return nil, err
+	}
+	for _, asg := range asgs {
+		workerNodes, err := c.ListAppRunWorkerNodes(ctx, app.ClusterID, asg.ID)
+		if err != nil {
+			slog.Warn("Failed to fetch AppRun Worker Nodes",
+				slog.String("asgID", asg.ID),
+				slog.Any("error", err))
+			continue
+		}
+		for _, wn := range workerNodes {
+			for i := range nodes {
+				if nodes[i].WorkerNode == nil && wn.matches(nodes[i].NodeID) {
+					nodes[i].WorkerNode = &wn
+					nodes[i].ASGName = asg.Name
+				}
+			}
+		}
+	}
+
+	return &AppRunContainerPlacement{App: app, Nodes: nodes}, nil
+}
+
+// attachAppRunNodeContainers fills the Containers of each worker node with the
+// containers of all applications in the cluster running on it
+func (c *SakuraClient) attachAppRunNodeContainers(ctx context.Context, clusterID string, workerNodes []AppRunWorkerNode) error {
+	apps, err := c.ListAppRunApplications(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+	for _, app := range apps {
+		nodes, err := c.ListAppRunApplicationContainers(ctx, app.ID)
+		if err != nil {
+			slog.Warn("Failed to fetch AppRun application containers",
+				slog.String("applicationID", app.ID),
+				slog.Any("error", err))
+			continue
+		}
+		for _, node := range nodes {
+			for i := range workerNodes {
+				if !workerNodes[i].matches(node.NodeID) {
+					continue
+				}
+				for _, ct := range node.Containers {
+					ct.ApplicationName = app.Name
+					workerNodes[i].Containers = append(workerNodes[i].Containers, ct)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// SetAppRunWorkerNodeDraining drains a worker node, moving its containers to other nodes,
+// or makes a drained node accept containers again
+func (c *SakuraClient) SetAppRunWorkerNodeDraining(ctx context.Context, node AppRunWorkerNode, draining bool) error {
+	slog.Info("Updating AppRun worker node draining state",
+		slog.String("workerNodeID", node.ID),
+		slog.Bool("draining", draining))
+
+	client, err := c.GetAppRunClient()
+	if err != nil {
+		slog.Error("Failed to get AppRun client", slog.Any("error", err))
+		return err
+	}
+
+	parsedCluster, err := uuid.Parse(node.ClusterID)
+	if err != nil {
+		return fmt.Errorf("invalid cluster ID: %w", err)
+	}
+	parsedASG, err := uuid.Parse(node.ASGID)
+	if err != nil {
+		return fmt.Errorf("invalid ASG ID: %w", err)
+	}
+	parsedNode, err := uuid.Parse(node.ID)
+	if err != nil {
+		return fmt.Errorf("invalid worker node ID: %w", err)
+	}
+
+	err = client.UpdateWorkerNodeDrainingState(ctx, &apprun.UpdateWorkerNodeDrainingRequest{Draining: draining},
+		apprun.UpdateWorkerNodeDrainingStateParams{
+			ClusterID:          apprun.ClusterID(parsedCluster),
+			AutoScalingGroupID: apprun.AutoScalingGroupID(parsedASG),
+			WorkerNodeID:       apprun.WorkerNodeID(parsedNode),
+		})
+	if err != nil {
+		slog.Error("Failed to update AppRun worker node draining state",
+			slog.String("workerNodeID", node.ID),
+			slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+// ListAppRunWorkerNodesWithContainers lists the worker nodes of an ASG together with
+// the application containers running on them
+func (c *SakuraClient) ListAppRunWorkerNodesWithContainers(ctx context.Context, clusterID, asgID string) ([]AppRunWorkerNode, error) {
+	nodes, err := c.ListAppRunWorkerNodes(ctx, clusterID, asgID)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.attachAppRunNodeContainers(ctx, clusterID, nodes); err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+// containerSummary describes the containers on the node grouped by application version,
+// e.g. "web v2 x2, api v1"
+func (w AppRunWorkerNode) containerSummary() string {
+	var keys []string
+	counts := map[string]int{}
+	for _, ct := range w.Containers {
+		key := fmt.Sprintf("%s v%d", ct.ApplicationName, ct.Version)
+		if counts[key] == 0 {
+			keys = append(keys, key)
+		}
+		counts[key]++
+	}
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = key
+		if counts[key] > 1 {
+			parts[i] = fmt.Sprintf("%s x%d", key, counts[key])
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// displayName returns the resource ID of the node, or its worker node ID while it is being created
+func (w AppRunWorkerNode) displayName() string {
+	if w.ResourceID != "" {
+		return w.ResourceID
+	}
+	return w.ID
+}
@@ -0,0 +1,826 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apprun "github.com/tokuhirom/sact/pkg/openapi/apprun_dedicated"
+)
+
+var (
+	testAppRunClusterID = "11111111-1111-1111-1111-111111111111"
+	testAppRunAppID     = "22222222-2222-2222-2222-222222222222"
+	testAppRunASGID     = "33333333-3333-3333-3333-333333333333"
+	testAppRunNodeIDs   = []string{"44444444-4444-4444-4444-444444444401", "44444444-4444-4444-4444-444444444402"}
+)
+
+type allowAllSecurity struct{}
+
+func (allowAllSecurity) HandleBasicAuth(ctx context.Context, _ apprun.OperationName, _ apprun.BasicAuth) (context.Context, error) {
+	return ctx, nil
+}
+
+// newFakeAppRunClient returns a SakuraClient whose AppRun Dedicated API is served by h
+func newFakeAppRunClient(t *testing.T, h apprun.Handler) *SakuraClient {
+	server, err := apprun.NewServer(h, allowAllSecurity{})
+	require.NoError(t, err)
+	ts := httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+
+	client, err := apprun.NewClient(ts.URL, &apprunSecuritySource{username: "token", password: "secret"})
+	require.NoError(t, err)
+	return &SakuraClient{zone: "tk1b", apprunClient: client}
+}
+
+// fakeAppRun is an in-memory AppRun Dedicated API with a single application
+type fakeAppRun struct {
+	apprun.UnimplementedHandler
+
+	mu            sync.Mutex
+	activeVersion int32
+	desiredCount  int32
+	versions      []apprun.ReadApplicationVersionDetail
+	created       []*apprun.CreateApplicationVersion
+	nodes         []apprun.ReadWorkerNodeSummary
+	placement     []apprun.NodeContainerPlacementInfo
+}
+
+func (f *fakeAppRun) GetApplication(_ context.Context, params apprun.GetApplicationParams) (*apprun.GetApplicationResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &apprun.GetApplicationResponse{Application: apprun.ReadApplicationDetail{
+		ApplicationID: params.ApplicationID,
+		Name:          "web",
+		ClusterID:     apprun.ClusterID(uuid.MustParse(testAppRunClusterID)),
+		ActiveVersion: apprun.NewNilInt32(f.activeVersion),
+		DesiredCount:  apprun.NewNilInt32(f.desiredCount),
+	}}, nil
+}
+
+func (f *fakeAppRun) GetApplicationVersion(_ context.Context, params apprun.GetApplicationVersionParams) (*apprun.GetApplicationVersionResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, v := range f.versions {
+		if v.Version == params.Version {
+			return &apprun.GetApplicationVersionResponse{ApplicationVersion: v}, nil
+		}
+	}
+	return nil, assert.AnError
+}
+
+func (f *fakeAppRun) ListApplicationVersions(_ context.Context, _ apprun.ListApplicationVersionsParams) (*apprun.ListApplicationVersionResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resp := &apprun.ListApplicationVersionResponse{}
+	for _, v := range f.versions {
+		resp.Versions = append(resp.Versions, apprun.ApplicationVersionDeploymentStatus{
+			Version:         v.Version,
+			Image:           v.Image,
+			ActiveNodeCount: v.ActiveNodeCount,
+			Created:         v.Created,
+		})
+	}
+	return resp, nil
+}
+
+func (f *fakeAppRun) CreateApplicationVersion(_ context.Context, req *apprun.CreateApplicationVersion, _ apprun.CreateApplicationVersionParams) (*apprun.CreateApplicationVersionResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.created = append(f.created, req)
+	version := apprun.ApplicationVersionNumber(len(f.versions) + 1)
+	f.versions = append(f.versions, apprun.ReadApplicationVersionDetail{
+		Version: version,
+		CPU:     req.CPU,
+		Memory:  req.Memory,
+		Image:   req.Image,
+	})
+	return &apprun.CreateApplicationVersionResponse{
+		ApplicationVersion: apprun.ReadApplicationVersionSummary{Version: version},
+	}, nil
+}
+
+func (f *fakeAppRun) UpdateApplication(_ context.Context, req *apprun.UpdateApplication, _ apprun.UpdateApplicationParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.activeVersion = req.ActiveVersion.Value
+	return nil
+}
+
+func (f *fakeAppRun) DeleteApplicationVersion(_ context.Context, params apprun.DeleteApplicationVersionParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, v := range f.versions {
+		if v.Version == params.Version {
+			f.versions = append(f.versions[:i], f.versions[i+1:]...)
+			return nil
+		}
+	}
+	return assert.AnError
+}
+
+func (f *fakeAppRun) ListApplications(_ context.Context, _ apprun.ListApplicationsParams) (*apprun.ListApplicationResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &apprun.ListApplicationResponse{Applications: []apprun.ReadApplicationDetail{{
+		ApplicationID: apprun.ApplicationID(uuid.MustParse(testAppRunAppID)),
+		Name:          "web",
+		ClusterID:     apprun.ClusterID(uuid.MustParse(testAppRunClusterID)),
+		ActiveVersion: apprun.NewNilInt32(f.activeVersion),
+		DesiredCount:  apprun.NewNilInt32(f.desiredCount),
+	}}}, nil
+}
+
+func (f *fakeAppRun) GetApplicationContainers(_ context.Context, _ apprun.GetApplicationContainersParams) (*apprun.GetApplicationContainersResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &apprun.GetApplicationContainersResponse{Nodes: f.placement}, nil
+}
+
+func (f *fakeAppRun) ListClusters(_ context.Context, _ apprun.ListClustersParams) (*apprun.ListClusterResponse, error) {
+	return &apprun.ListClusterResponse{Clusters: []apprun.ReadClusterDetail{{
+		ClusterID: apprun.ClusterID(uuid.MustParse(testAppRunClusterID)),
+		Name:      "prod",
+	}}}, nil
+}
+
+func (f *fakeAppRun) testASG() apprun.ReadAutoScalingGroupDetail {
+	return apprun.ReadAutoScalingGroupDetail{
+		AutoScalingGroupID:     apprun.AutoScalingGroupID(uuid.MustParse(testAppRunASGID)),
+		Name:                   "workers",
+		Zone:                   "tk1b",
+		WorkerServiceClassPath: "cloud/plan/4core8gb",
+		MinNodes:               1,
+		MaxNodes:               3,
+		WorkerNodeCount:        int32(len(f.nodes)),
+		Interfaces: []apprun.AutoScalingGroupNodeInterface{{
+			InterfaceIndex: 0,
+			Upstream:       "shared",
+			ConnectsToLB:   true,
+		}},
+	}
+}
+
+func (f *fakeAppRun) ListAutoScalingGroups(_ context.Context, _ apprun.ListAutoScalingGroupsParams) (*apprun.ListAutoScalingGroupResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &apprun.ListAutoScalingGroupResponse{AutoScalingGroups: []apprun.ReadAutoScalingGroupDetail{f.testASG()}}, nil
+}
+
+func (f *fakeAppRun) GetAutoScalingGroup(_ context.Context, _ apprun.GetAutoScalingGroupParams) (*apprun.GetAutoScalingGroupResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &apprun.GetAutoScalingGroupResponse{AutoScalingGroup: f.testASG()}, nil
+}
+
+func (f *fakeAppRun) ListWorkerNodes(_ context.Context, _ apprun.ListWorkerNodesParams) (*apprun.ListWorkerNodesResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &apprun.ListWorkerNodesResponse{WorkerNodes: slices.Clone(f.nodes)}, nil
+}
+
+func (f *fakeAppRun) ListLoadBalancers(_ context.Context, _ apprun.ListLoadBalancersParams) (*apprun.ListLoadBalancersResponse, error) {
+	return &apprun.ListLoadBalancersResponse{LoadBalancers: []apprun.ReadLoadBalancerSummary{{
+		LoadBalancerID:   apprun.LoadBalancerID(uuid.MustParse(testAppRunLBID)),
+		Name:             "web-lb",
+		ServiceClassPath: "cloud/plan/lb-small",
+		NameServers:      []apprun.IPv4{"133.242.0.3"},
+	}}}, nil
+}
+
+func (f *fakeAppRun) ListLoadBalancerNodes(ctx context.Context, params apprun.ListLoadBalancerNodesParams) (*apprun.ListLoadBalancerNodesResponse, error) {
+	return (&fakeAppRunLB{}).ListLoadBalancerNodes(ctx, params)
+}
+
+func (f *fakeAppRun) UpdateWorkerNodeDrainingState(_ context.Context, req *apprun.UpdateWorkerNodeDrainingRequest, params apprun.UpdateWorkerNodeDrainingStateParams) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, node := range f.nodes {
+		if node.WorkerNodeID == params.WorkerNodeID {
+			f.nodes[i].Draining = req.Draining
+			return nil
+		}
+	}
+	return assert.AnError
+}
+
+// testAppRunVersion returns a minimal valid version for the fake API
+func testAppRunVersion(version int32, image string, activeNodes int64) apprun.ReadApplicationVersionDetail {
+	return apprun.ReadApplicationVersionDetail{
+		Version:         apprun.ApplicationVersionNumber(version),
+		CPU:             500,
+		Memory:          1024,
+		ScalingMode:     apprun.ScalingModeManual,
+		FixedScale:      apprun.NewOptInt32(2),
+		Image:           image,
+		ActiveNodeCount: activeNodes,
+	}
+}
+
+// testAppRunContainer returns a running container of the test application
+func testAppRunContainer(id string, version int64) apprun.ApplicationContainerSummary {
+	return apprun.ApplicationContainerSummary{
+		ID:                 id,
+		Image:              "registry.example.com/web:1.0",
+		State:              "running",
+		Status:             "Up 5 minutes",
+		CpuUsagePercent:    12.5,
+		ApplicationID:      testAppRunAppID,
+		ApplicationVersion: version,
+	}
+}
+
+// withWorkerNodes adds two worker nodes to the test ASG, with the given containers placed on the first
+func (f *fakeAppRun) withWorkerNodes(containers ...apprun.ApplicationContainerSummary) *fakeAppRun {
+	for i, id := range testAppRunNodeIDs {
+		f.nodes = append(f.nodes, apprun.ReadWorkerNodeSummary{
+			WorkerNodeID: apprun.WorkerNodeID(uuid.MustParse(id)),
+			ResourceID:   apprun.NewNilString(fmt.Sprintf("11300000000%d", i+1)),
+			Status:       apprun.WorkerNodeStatusHealthy,
+			Created:      1721203200,
+		})
+	}
+	f.placement = []apprun.NodeContainerPlacementInfo{{
+		NodeID: testAppRunNodeIDs[0],
+		ContainersStats: apprun.NewNilApplicationContainersStats(apprun.ApplicationContainersStats{
+			CollectedAtSec: 1721203200,
+			Containers:     containers,
+		}),
+		Desired: apprun.NilApplicationPeekDesiredContainersResponse{Null: true},
+	}}
+	return f
+}
+
+func newTestFakeAppRun() *fakeAppRun {
+	return &fakeAppRun{
+		activeVersion: 1,
+		desiredCount:  2,
+		versions: []apprun.ReadApplicationVersionDetail{{
+			Version:         1,
+			CPU:             500,
+			Memory:          1024,
+			ScalingMode:     apprun.ScalingModeManual,
+			FixedScale:      apprun.NewOptInt32(2),
+			Image:           "registry.example.com/web:1.0",
+			Cmd:             []string{"serve", "--port", "8080"},
+			ActiveNodeCount: 2,
+			ExposedPorts: []apprun.ExposedPort{{
+				TargetPort:       8080,
+				LoadBalancerPort: apprun.NewNilPort(443),
+				Host:             []string{"example.com"},
+				HealthCheck: apprun.NewNilHealthCheck(apprun.HealthCheck{
+					Path: "/healthz", IntervalSeconds: 10, TimeoutSeconds: 5,
+				}),
+			}},
+			Env: []apprun.ReadEnvironmentVariable{
+				{Key: "MODE", Value: apprun.NewNilString("prod")},
+				{Key: "TOKEN", Value: apprun.NilString{Null: true}, Secret: true},
+			},
+		}},
+	}
+}
+
+func TestAppRunVersionSpecRoundTrip(t *testing.T) {
+	fake := newTestFakeAppRun()
+	client := newFakeAppRunClient(t, fake)
+
+	detail, err := client.GetAppRunVersionDetail(t.Context(), testAppRunAppID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "registry.example.com/web:1.0", detail.Spec.Image)
+	assert.Equal(t, int32(2), detail.Spec.FixedScale)
+	assert.Equal(t, &AppRunHealthCheck{Path: "/healthz", IntervalSeconds: 10, TimeoutSeconds: 5}, detail.Spec.ExposedPorts[0].HealthCheck)
+	assert.Equal(t, []AppRunEnvVar{{Key: "MODE", Value: "prod"}, {Key: "TOKEN", Secret: true}}, detail.Spec.Env)
+
+	spec := detail.Spec
+	spec.Image = "registry.example.com/web:1.1"
+	version, err := client.CreateAppRunVersion(t.Context(), testAppRunAppID, spec)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), version)
+
+	req := fake.created[0]
+	assert.Equal(t, "registry.example.com/web:1.1", req.Image)
+	assert.Equal(t, apprun.RegistryPasswordActionKeep, req.RegistryPasswordAction)
+	assert.Equal(t, apprun.Port(443), req.ExposedPorts[0].LoadBalancerPort.Value)
+	// The secret value is omitted so that the previous value is kept
+	assert.False(t, req.Env[1].Value.Set)
+	assert.Equal(t, "prod", req.Env[0].Value.Value)
+
+	require.NoError(t, client.ActivateAppRunVersion(t.Context(), testAppRunAppID, version))
+	status, err := client.GetAppRunApplicationStatus(t.Context(), testAppRunAppID)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), status.ActiveVersion)
+}
+
+func TestBuildAppRunVersionSpec(t *testing.T) {
+	base := AppRunVersionSpec{
+		Image:       "web:1.0",
+		CPU:         500,
+		Memory:      1024,
+		ScalingMode: "manual",
+		FixedScale:  2,
+		Env: []AppRunEnvVar{
+			{Key: "MODE", Value: "prod"},
+			{Key: "OLD", Value: "x"},
+			{Key: "TOKEN", Secret: true},
+		},
+		ExposedPorts: []AppRunExposedPort{{TargetPort: 8080}},
+	}
+	values := map[string]string{
+		"image":       "web:1.1",
+		"cpu":         "500",
+		"memory":      "1024",
+		"scalingMode": "manual",
+		"fixedScale":  "3",
+		"cmd":         "serve --debug",
+		"env:MODE":    "staging",
+		"env:OLD":     "-",
+		"env:TOKEN":   "",
+		"addEnv":      "NEW=1=2",
+	}
+
+	spec, err := buildAppRunVersionSpec(base, values)
+	require.NoError(t, err)
+	assert.Equal(t, "web:1.1", spec.Image)
+	assert.Equal(t, int32(3), spec.FixedScale)
+	assert.Equal(t, []string{"serve", "--debug"}, spec.Cmd)
+	assert.Equal(t, []AppRunEnvVar{
+		{Key: "MODE", Value: "staging"},
+		{Key: "TOKEN", Secret: true},
+		{Key: "NEW", Value: "1=2"},
+	}, spec.Env)
+	assert.Equal(t, base.ExposedPorts, spec.ExposedPorts)
+
+	values["scalingMode"] = "cpu"
+	_, err = buildAppRunVersionSpec(base, values)
+	assert.ErrorContains(t, err, "min/max scale")
+
+	values["scalingMode"] = "manual"
+	values["addEnv"] = "MODE=x"
+	_, err = buildAppRunVersionSpec(base, values)
+	assert.ErrorContains(t, err, "already exists")
+}
+
+func TestAppRunCmdRoundTrip(t *testing.T) {
+	for _, cmd := range [][]string{
+		{"serve", "--debug"},
+		{"sh", "-c", "echo a b"},
+		{"printf", "it's %s\n", ""},
+		{"grep", `"quoted"`, `back\slash`},
+	} {
+		line := formatAppRunCmd(cmd)
+		parsed, err := parseAppRunCmd(line)
+		require.NoError(t, err, line)
+		assert.Equal(t, cmd, parsed, line)
+	}
+	assert.Equal(t, `sh -c 'echo a b'`, formatAppRunCmd([]string{"sh", "-c", "echo a b"}))
+
+	parsed, err := parseAppRunCmd(`sh -c "echo \"a b\"" x\ y`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sh", "-c", `echo "a b"`, "x y"}, parsed)
+	_, err = parseAppRunCmd(`sh -c 'echo`)
+	assert.ErrorContains(t, err, "unterminated")
+
+	// An untouched command keeps its arguments
+	base := AppRunVersionSpec{Image: "web:1.0", CPU: 500, Memory: 1024, ScalingMode: "manual", FixedScale: 1,
+		Cmd: []string{"sh", "-c", "echo a b"}}
+	values := map[string]string{"image": "web:1.0", "cpu": "500", "memory": "1024", "scalingMode": "manual",
+		"fixedScale": "1", "cmd": formatAppRunCmd(base.Cmd)}
+	spec, err := buildAppRunVersionSpec(base, values)
+	require.NoError(t, err)
+	assert.Equal(t, base.Cmd, spec.Cmd)
+
+	values["cmd"] = `sh -c 'echo a b c'`
+	spec, err = buildAppRunVersionSpec(base, values)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sh", "-c", "echo a b c"}, spec.Cmd)
+}
+
+func TestAppRunDeployAndRollout(t *testing.T) {
+	fake := newTestFakeAppRun()
+	client := newFakeAppRunClient(t, fake)
+
+	m := InitialModel(client, "tk1b")
+	m.loading = false
+	m.resourceType = ResourceTypeAppRunDedicated
+	m.appRunDrilldownLevel = 2
+	m.appRunSelectedClusterID = testAppRunClusterID
+	m.appRunSelectedAppID = testAppRunAppID
+	m.appRunSelectedAppName = "web"
+	m.appRunActiveVersion = 1
+
+	// D loads the active version and opens a pre-filled form
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'D'}})
+	m = updated.(model)
+	require.NotNil(t, cmd)
+	updated, _ = m.Update(cmd())
+	m = updated.(model)
+	require.NotNil(t, m.form)
+	assert.Equal(t, "registry.example.com/web:1.0", m.form.values()["image"])
+	assert.Equal(t, "serve --port 8080", m.form.values()["cmd"])
+
+	m.form.fields[0].input.SetValue("registry.example.com/web:1.1")
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	m = updated.(model)
+	assert.Nil(t, m.form)
+	created := cmd().(appRunVersionCreatedMsg)
+	require.NoError(t, created.err)
+	assert.True(t, created.activated)
+	assert.Equal(t, int32(2), fake.activeVersion)
+
+	updated, _ = m.Update(created)
+	m = updated.(model)
+	require.NotNil(t, m.appRunRollout)
+	assert.Equal(t, int32(2), m.appRunActiveVersion)
+
+	// Rollout is tracked until the new version runs on all desired nodes
+	updated, _ = m.Update(appRunRolloutStatusMsg{version: 2, activeVersion: 2, activeNodes: 1, desiredCount: 2})
+	m = updated.(model)
+	assert.Equal(t, "Rolling out v2: 1/2 nodes", m.statusMessage)
+	require.NotNil(t, m.appRunRollout)
+
+	updated, _ = m.Update(appRunRolloutStatusMsg{version: 2, activeVersion: 2, activeNodes: 2, desiredCount: 2})
+	m = updated.(model)
+	assert.Equal(t, "Rollout of v2 complete (2/2 nodes)", m.statusMessage)
+	assert.Nil(t, m.appRunRollout)
+}
+
+// newAppRunVersionListModel returns a model showing the version list of the test application
+func newAppRunVersionListModel(t *testing.T, client *SakuraClient) model {
+	m := InitialModel(client, "tk1b")
+	m.loading = false
+	m.resourceType = ResourceTypeAppRunDedicated
+	m.appRunDrilldownLevel = 2
+	m.appRunSelectedClusterID = testAppRunClusterID
+	m.appRunSelectedAppID = testAppRunAppID
+	m.appRunSelectedAppName = "web"
+	m.appRunActiveVersion = 2
+
+	updated, _ := m.Update(loadAppRunVersions(client, testAppRunAppID, testAppRunClusterID, 2)())
+	return updated.(model)
+}
+
+func TestAppRunRollbackAndDelete(t *testing.T) {
+	fake := newTestFakeAppRun()
+	fake.versions = append(fake.versions,
+		testAppRunVersion(2, "registry.example.com/web:1.1", 2),
+		testAppRunVersion(3, "registry.example.com/web:1.2", 0),
+	)
+	fake.versions[0].ActiveNodeCount = 0
+	fake.activeVersion = 2
+	client := newFakeAppRunClient(t, fake)
+	m := newAppRunVersionListModel(t, client)
+	require.Len(t, m.list.Items(), 3)
+
+	// The active version cannot be deleted
+	m.list.Select(1)
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	m = updated.(model)
+	assert.Equal(t, "", m.confirmMessage)
+	assert.Contains(t, m.statusMessage, "in use")
+
+	// Rolling back shows the image change
+	m.list.Select(0)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	m = updated.(model)
+	assert.Equal(t, "Activate v1? image: v2 registry.example.com/web:1.1 -> v1 registry.example.com/web:1.0", m.confirmMessage)
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	m = updated.(model)
+	updated, _ = m.Update(cmd())
+	m = updated.(model)
+	assert.Equal(t, int32(1), fake.activeVersion)
+	assert.Equal(t, int32(1), m.appRunActiveVersion)
+	require.NotNil(t, m.appRunRollout)
+	assert.Equal(t, int32(1), m.appRunRollout.version)
+
+	// Unused versions can be deleted
+	m.list.Select(2)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	m = updated.(model)
+	assert.Equal(t, "Delete v3 (registry.example.com/web:1.2)?", m.confirmMessage)
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	m = updated.(model)
+	done := cmd().(actionDoneMsg)
+	require.NoError(t, done.err)
+	assert.Len(t, fake.versions, 2)
+}
+
+func TestAppRunVersionComparisonRows(t *testing.T) {
+	base := &AppRunVersionDetail{Version: 1, Spec: AppRunVersionSpec{
+		Image:       "web:1.0",
+		CPU:         500,
+		Memory:      1024,
+		ScalingMode: "manual",
+		FixedScale:  2,
+		ExposedPorts: []AppRunExposedPort{{
+			TargetPort:       8080,
+			LoadBalancerPort: 443,
+			HealthCheck:      &AppRunHealthCheck{Path: "/healthz", IntervalSeconds: 10, TimeoutSeconds: 5},
+		}},
+		Env: []AppRunEnvVar{{Key: "API_KEY", Secret: true}, {Key: "MODE", Value: "prod"}, {Key: "TOKEN", Secret: true}},
+	}}
+	target := &AppRunVersionDetail{Version: 2, Spec: base.Spec}
+	target.Spec.Image = "web:1.1"
+	target.Spec.ExposedPorts = []AppRunExposedPort{{TargetPort: 8080, LoadBalancerPort: 443}}
+	// API_KEY turns from a secret into a plain variable
+	target.Spec.Env = []AppRunEnvVar{{Key: "API_KEY", Value: "k"}, {Key: "TOKEN", Value: "new-secret", Secret: true}, {Key: "DEBUG", Value: "1"}}
+
+	rows := (&AppRunVersionComparison{Base: base, Target: target}).Rows()
+	changed := map[string]AppRunVersionDiffRow{}
+	for _, row := range rows {
+		if row.Changed {
+			changed[row.Field] = row
+		}
+	}
+
+	assert.Equal(t, []string{"Env API_KEY", "Env DEBUG", "Env MODE", "Image", "Port 8080 Health"}, slices.Sorted(maps.Keys(changed)))
+	assert.Equal(t, AppRunVersionDiffRow{Field: "Env API_KEY", Base: appRunSecretMask, Target: "k", Changed: true}, changed["Env API_KEY"])
+	assert.Equal(t, AppRunVersionDiffRow{Field: "Env MODE", Base: "prod", Target: "-", Changed: true}, changed["Env MODE"])
+	for _, row := range rows {
+		// Secret values are never shown
+		assert.NotContains(t, row.Target, "new-secret")
+	}
+
+	rendered := renderAppRunVersionComparison(&AppRunVersionComparison{AppName: "web", Base: base, Target: target})
+	assert.Contains(t, rendered, "v1 -> v2")
+	assert.Contains(t, rendered, "Changed: 5 of")
+	assert.Contains(t, rendered, appRunSecretMask)
+}
+
+func TestAppRunVersionCompareKeys(t *testing.T) {
+	fake := newTestFakeAppRun()
+	fake.versions = append(fake.versions,
+		testAppRunVersion(2, "registry.example.com/web:1.1", 0),
+		testAppRunVersion(3, "registry.example.com/web:1.2", 0),
+	)
+	client := newFakeAppRunClient(t, fake)
+	m := newAppRunVersionListModel(t, client)
+
+	// Without a mark the selected version is compared with the active one
+	m.list.Select(2)
+	assert.Equal(t, int32(2), m.appRunCompareBase(m.list.SelectedItem().(AppRunVersion)))
+
+	m.list.Select(0)
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	m = updated.(model)
+	assert.Equal(t, m.list.Items()[0].(AppRunVersion).Version, m.appRunCompareVersion)
+	assert.Contains(t, m.View(), "+v")
+
+	m.windowWidth, m.windowHeight = 160, 60
+	m.list.Select(2)
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	m = updated.(model)
+	assert.True(t, m.detailMode)
+	updated, _ = m.Update(cmd())
+	m = updated.(model)
+	require.NotNil(t, m.appRunVersionComparison)
+	assert.Equal(t, int32(1), m.appRunVersionComparison.Base.Version)
+	assert.Equal(t, int32(3), m.appRunVersionComparison.Target.Version)
+	assert.Contains(t, m.View(), "v1 -> v3")
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(model)
+	assert.False(t, m.detailMode)
+	assert.Nil(t, m.appRunVersionComparison)
+}
+
+func TestAppRunVersionCompareMarkSurvivesReload(t *testing.T) {
+	fake := newTestFakeAppRun()
+	fake.versions = append(fake.versions,
+		testAppRunVersion(2, "registry.example.com/web:1.1", 0),
+		testAppRunVersion(3, "registry.example.com/web:1.2", 0),
+	)
+	client := newFakeAppRunClient(t, fake)
+	m := newAppRunVersionListModel(t, client)
+
+	m.list.Select(0)
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	m = updated.(model)
+	marked := m.appRunCompareVersion
+	require.NotZero(t, marked)
+
+	// The periodic reload during a rollout keeps the mark
+	updated, _ = m.Update(loadAppRunVersions(client, testAppRunAppID, testAppRunClusterID, 2)())
+	m = updated.(model)
+	assert.Equal(t, marked, m.appRunCompareVersion)
+
+	// The mark is dropped once the marked version is gone
+	fake.versions = slices.DeleteFunc(fake.versions, func(v apprun.ReadApplicationVersionDetail) bool { return int32(v.Version) == marked })
+	updated, _ = m.Update(loadAppRunVersions(client, testAppRunAppID, testAppRunClusterID, 2)())
+	m = updated.(model)
+	assert.Zero(t, m.appRunCompareVersion)
+}
+
+func TestAppRunContainerPlacement(t *testing.T) {
+	fake := newTestFakeAppRun().withWorkerNodes(
+		testAppRunContainer("c0ffee000001aaaa", 1),
+		testAppRunContainer("c0ffee000002bbbb", 1),
+	)
+	fake.placement = append(fake.placement, apprun.NodeContainerPlacementInfo{
+		NodeID:          "55555555-5555-5555-5555-555555555555",
+		ContainersStats: apprun.NilApplicationContainersStats{Null: true},
+		Desired: apprun.NewNilApplicationPeekDesiredContainersResponse(apprun.ApplicationPeekDesiredContainersResponse{
+			Containers: []apprun.ApplicationDesiredContainer{{
+				ApplicationID: testAppRunAppID, ApplicationVersion: 1, CpuMillis: 500, MemoryMB: 1024, Image: "registry.example.com/web:1.0",
+			}},
+		}),
+	})
+	client := newFakeAppRunClient(t, fake)
+
+	placement, err := client.GetAppRunContainerPlacement(t.Context(), AppRunApplication{
+		ID: testAppRunAppID, Name: "web", ClusterID: testAppRunClusterID, ActiveVersion: 1,
+	})
+	require.NoError(t, err)
+	require.Len(t, placement.Nodes, 2)
+	assert.Equal(t, 2, placement.ContainerCount())
+	require.NotNil(t, placement.Nodes[0].WorkerNode)
+	assert.Equal(t, "113000000001", placement.Nodes[0].WorkerNode.ResourceID)
+	assert.Equal(t, "workers", placement.Nodes[0].ASGName)
+	assert.Equal(t, "web", placement.Nodes[0].Containers[0].ApplicationName)
+	// Nodes that are not in any ASG are kept, unresolved
+	assert.Nil(t, placement.Nodes[1].WorkerNode)
+	assert.Len(t, placement.Nodes[1].Desired, 1)
+
+	rendered := renderAppRunContainerPlacement(placement, 0)
+	assert.Contains(t, rendered, "Node 113000000001 [ASG workers, healthy]")
+	assert.Contains(t, rendered, "c0ffee000001")
+	assert.Contains(t, rendered, "(pending)")
+}
+
+func TestAppRunContainerPlacementCrossLink(t *testing.T) {
+	fake := newTestFakeAppRun().withWorkerNodes(testAppRunContainer("c0ffee000001aaaa", 1))
+	client := newFakeAppRunClient(t, fake)
+	m := newAppRunVersionListModel(t, client)
+	m.windowWidth, m.windowHeight = 160, 60
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'p'}})
+	m = updated.(model)
+	assert.True(t, m.detailMode)
+	updated, _ = m.Update(cmd())
+	m = updated.(model)
+	require.NotNil(t, m.appRunPlacement)
+	assert.Contains(t, m.View(), "AppRun Containers: web")
+
+	// Enter opens the ASG detail with the node hosting the containers selected
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	assert.Nil(t, m.appRunPlacement)
+	updated, _ = m.Update(cmd())
+	m = updated.(model)
+	require.NotNil(t, m.appRunASGDetail)
+	assert.Equal(t, 0, m.detailCursor)
+	require.Len(t, m.appRunWorkerNodes, 2)
+	assert.Equal(t, "web", m.appRunWorkerNodes[0].Containers[0].ApplicationName)
+	assert.Empty(t, m.appRunWorkerNodes[1].Containers)
+	assert.Contains(t, m.View(), "web v1 running (Up 5 minutes)")
+}
+
+func TestAppRunWorkerNodeDrain(t *testing.T) {
+	fake := newTestFakeAppRun().withWorkerNodes(
+		testAppRunContainer("c0ffee000001aaaa", 1),
+		testAppRunContainer("c0ffee000002bbbb", 1),
+	)
+	client := newFakeAppRunClient(t, fake)
+	m := newAppRunVersionListModel(t, client)
+	m.windowWidth, m.windowHeight = 160, 60
+	m.detailMode = true
+	updated, _ := m.Update(loadAppRunASGDetail(client, testAppRunClusterID, testAppRunASGID)())
+	m = updated.(model)
+	require.Len(t, m.appRunWorkerNodes, 2)
+
+	// The confirmation lists the containers that will be moved
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	m = updated.(model)
+	assert.Equal(t, "Drain node 113000000001? 2 containers will be moved: web v1 x2", m.confirmMessage)
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	m = updated.(model)
+	updated, cmd = m.Update(cmd())
+	m = updated.(model)
+	assert.True(t, fake.nodes[0].Draining)
+	require.NotNil(t, m.appRunDrain)
+
+	// The node is refreshed until no containers are left on it
+	updated, cmd = m.Update(cmd())
+	m = updated.(model)
+	assert.Equal(t, "Draining node 113000000001: 2 containers remaining", m.statusMessage)
+	assert.NotNil(t, cmd)
+	assert.True(t, m.appRunWorkerNodes[0].Draining)
+
+	fake.mu.Lock()
+	fake.placement[0].NodeID = testAppRunNodeIDs[1]
+	fake.mu.Unlock()
+	updated, _ = m.Update(checkAppRunDrain(client, *m.appRunDrain)())
+	m = updated.(model)
+	assert.Equal(t, "Node 113000000001 drained: no containers left", m.statusMessage)
+	assert.Nil(t, m.appRunDrain)
+	assert.Len(t, m.appRunWorkerNodes[1].Containers, 2)
+
+	// A drained node can be undrained
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	m = updated.(model)
+	assert.Equal(t, "Undrain node 113000000001? It will accept containers again", m.confirmMessage)
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	m = updated.(model)
+	updated, _ = m.Update(cmd())
+	m = updated.(model)
+	assert.False(t, fake.nodes[0].Draining)
+	assert.Equal(t, "Node 113000000001 accepts containers again", m.statusMessage)
+
+	// The cursor stays valid when the ASG has no worker nodes left
+	updated, _ = m.Update(appRunDrainStatusMsg{node: m.appRunWorkerNodes[0]})
+	m = updated.(model)
+	assert.Empty(t, m.appRunWorkerNodes)
+	assert.Equal(t, 0, m.detailCursor)
+}
+
+// fakeAppRunLB serves a load balancer with a healthy node and a node that failed to be created
+type fakeAppRunLB struct {
+	apprun.UnimplementedHandler
+
+	nodeGets int
+}
+
+var (
+	testAppRunLBID      = "66666666-6666-6666-6666-666666666666"
+	testAppRunLBNodeIDs = []string{"77777777-7777-7777-7777-777777777701", "77777777-7777-7777-7777-777777777702"}
+)
+
+func (f *fakeAppRunLB) GetLoadBalancer(_ context.Context, _ apprun.GetLoadBalancerParams) (*apprun.GetLoadBalancerResponse, error) {
+	return &apprun.GetLoadBalancerResponse{LoadBalancer: apprun.ReadLoadBalancerDetail{
+		LoadBalancerID:   apprun.LoadBalancerID(uuid.MustParse(testAppRunLBID)),
+		Name:             "web-lb",
+		ServiceClassPath: "cloud/plan/lb-small",
+		NameServers:      []apprun.IPv4{"133.242.0.3"},
+		Interfaces: []apprun.LoadBalancerInterface{{
+			InterfaceIndex: 0,
+			Upstream:       "shared",
+			Vip:            apprun.NewOptString("203.0.113.10"),
+		}},
+	}}, nil
+}
+
+func (f *fakeAppRunLB) ListLoadBalancerNodes(_ context.Context, _ apprun.ListLoadBalancerNodesParams) (*apprun.ListLoadBalancerNodesResponse, error) {
+	return &apprun.ListLoadBalancerNodesResponse{LoadBalancerNodes: []apprun.ReadLoadBalancerNodeSummary{
+		{
+			LoadBalancerNodeID: apprun.LoadBalancerNodeID(uuid.MustParse(testAppRunLBNodeIDs[0])),
+			ResourceID:         apprun.NewNilString("113000000101"),
+			Status:             apprun.LoadBalancerNodeStatusHealthy,
+			Interfaces: []apprun.ReadLoadBalancerNodeInterface{{
+				InterfaceIndex: 0,
+				Addresses: []apprun.ReadLoadBalancerNodeInterfaceAddress{
+					{Address: "203.0.113.11"},
+					{Address: "203.0.113.10", Vip: true},
+				},
+			}},
+			Created: 1721203200,
+		},
+		{
+			LoadBalancerNodeID: apprun.LoadBalancerNodeID(uuid.MustParse(testAppRunLBNodeIDs[1])),
+			ResourceID:         apprun.NilString{Null: true},
+			Status:             apprun.LoadBalancerNodeStatusUnhealthy,
+			Created:            1721203200,
+		},
+	}}, nil
+}
+
+func (f *fakeAppRunLB) GetLoadBalancerNode(_ context.Context, params apprun.GetLoadBalancerNodeParams) (*apprun.GetLoadBalancerNodeResponse, error) {
+	f.nodeGets++
+	return &apprun.GetLoadBalancerNodeResponse{LoadBalancerNode: apprun.ReadLoadBalancerNode{
+		LoadBalancerNodeID: params.LoadBalancerNodeID,
+		ResourceID:         apprun.NilString{Null: true},
+		Status:             apprun.LoadBalancerNodeStatusUnhealthy,
+		CreateErrorMessage: apprun.NewOptString("no available IP address"),
+		Created:            1721203200,
+	}}, nil
+}
+
+func TestAppRunLBNodes(t *testing.T) {
+	fake := &fakeAppRunLB{}
+	client := newFakeAppRunClient(t, fake)
+
+	detail, err := client.GetAppRunLBDetail(t.Context(), testAppRunClusterID, testAppRunASGID, testAppRunLBID)
+	require.NoError(t, err)
+	require.Len(t, detail.Nodes, 2)
+	assert.Equal(t, []string{"203.0.113.11"}, detail.Nodes[0].IPAddresses)
+	assert.Equal(t, []string{"203.0.113.10"}, detail.Nodes[0].VIPs)
+	assert.False(t, detail.Nodes[0].HasError())
+	// Only the failing node is fetched individually for its error message
+	assert.Equal(t, 1, fake.nodeGets)
+	assert.Equal(t, "no available IP address", detail.Nodes[1].ErrorMessage)
+	assert.Equal(t, 1, detail.ErrorNodeCount())
+
+	rendered := renderAppRunLBDetail(detail)
+	assert.Contains(t, rendered, "(1 in error)")
+	assert.Contains(t, rendered, "113000000101")
+	assert.Contains(t, rendered, "(VIP: 203.0.113.10)")
+	assert.Contains(t, rendered, "Error: no available IP address")
+}
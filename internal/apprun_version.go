@@ -0,0 +1,563 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+
+	apprun "github.com/tokuhirom/sact/pkg/openapi/apprun_dedicated"
+)
+
+// AppRunVersionSpec is the deployable specification of an application version.
+// The yaml tags follow the field names of the CreateApplicationVersion API.
+type AppRunVersionSpec struct {
+	Image             string              `yaml:"image"`
+	CPU               int64               `yaml:"cpu"`
+	Memory            int64               `yaml:"memory"`
+	ScalingMode       string              `yaml:"scalingMode"`
+	FixedScale        int32               `yaml:"fixedScale,omitempty"` // 0 when unset
+	MinScale          int32               `yaml:"minScale,omitempty"`
+	MaxScale          int32               `yaml:"maxScale,omitempty"`
+	ScaleInThreshold  int32               `yaml:"scaleInThreshold,omitempty"`
+	ScaleOutThreshold int32               `yaml:"scaleOutThreshold,omitempty"`
+	Cmd               []string            `yaml:"cmd,omitempty"`
+	RegistryUsername  string              `yaml:"registryUsername,omitempty"`
+	ExposedPorts      []AppRunExposedPort `yaml:"exposedPorts,omitempty"`
+	Env               []AppRunEnvVar      `yaml:"env,omitempty"`
+}
+
+// AppRunExposedPort is a port exposed by an application
+type AppRunExposedPort struct {
+	TargetPort       uint16             `yaml:"targetPort"`
+	LoadBalancerPort uint16             `yaml:"loadBalancerPort,omitempty"` // 0 when not exposed on the load balancer
+	UseLetsEncrypt   bool               `yaml:"useLetsEncrypt,omitempty"`
+	Host             []string           `yaml:"host,omitempty"`
+	HealthCheck      *AppRunHealthCheck `yaml:"healthCheck,omitempty"`
+}
+
+// AppRunHealthCheck is the load balancer health check of an exposed port
+type AppRunHealthCheck struct {
+	Path            string `yaml:"path"`
+	IntervalSeconds int32  `yaml:"intervalSeconds"`
+	TimeoutSeconds  int32  `yaml:"timeoutSeconds"`
+}
+
+// AppRunEnvVar is an environment variable of an application version.
+// Values of secret variables cannot be read back and are empty.
+type AppRunEnvVar struct {
+	Key    string `yaml:"key"`
+	Value  string `yaml:"value"`
+	Secret bool   `yaml:"secret,omitempty"`
+}
+
+// AppRunVersionDetail contains the full specification of an application version
+type AppRunVersionDetail struct {
+	Version         int32
+	ActiveNodeCount int64
+	CreatedAt       string
+	Spec            AppRunVersionSpec
+}
+
+// AppRunApplicationStatus is the deployment state of an application
+type AppRunApplicationStatus struct {
+	ActiveVersion int32
+	DesiredCount  int32
+}
+
+func optInt32(v int32) apprun.OptInt32 {
+	if v == 0 {
+		return apprun.OptInt32{}
+	}
+	return apprun.NewOptInt32(v)
+}
+
+func convertAppRunVersionSpec(v *apprun.ReadApplicationVersionDetail) AppRunVersionSpec {
+	spec := AppRunVersionSpec{
+		Image:             v.Image,
+		CPU:               v.CPU,
+		Memory:            v.Memory,
+		ScalingMode:       string(v.ScalingMode),
+		FixedScale:        v.FixedScale.Value,
+		MinScale:          v.MinScale.Value,
+		MaxScale:          v.MaxScale.Value,
+		ScaleInThreshold:  v.ScaleInThreshold.Value,
+		ScaleOutThreshold: v.ScaleOutThreshold.Value,
+		Cmd:               v.Cmd,
+	}
+	if !v.RegistryUsername.Null {
+		spec.RegistryUsername = v.RegistryUsername.Value
+	}
+	for _, p := range v.ExposedPorts {
+		port := AppRunExposedPort{
+			TargetPort:     uint16(p.TargetPort),
+			UseLetsEncrypt: p.UseLetsEncrypt,
+			Host:           p.Host,
+		}
+		if !p.LoadBalancerPort.Null {
+			port.LoadBalancerPort = uint16(p.LoadBalancerPort.Value)
+		}
+		if !p.HealthCheck.Null {
+			port.HealthCheck = &AppRunHealthCheck{
+				Path:            p.HealthCheck.Value.Path,
+				IntervalSeconds: p.HealthCheck.Value.IntervalSeconds,
+				TimeoutSeconds:  p.HealthCheck.Value.TimeoutSeconds,
+			}
+		}
+		spec.ExposedPorts = append(spec.ExposedPorts, port)
+	}
+	for _, e := range v.Env {
+		env := AppRunEnvVar{Key: e.Key, Secret: e.Secret}
+		if !e.Value.Null {
+			env.Value = e.Value.Value
+		}
+		spec.Env = append(spec.Env, env)
+	}
+	return spec
+}
+
+// toCreateRequest builds the API request for a new version.
+// Secret variables without a value keep the value of the previous version.
+func (s AppRunVersionSpec) toCreateRequest() *apprun.CreateApplicationVersion {
+	req := &apprun.CreateApplicationVersion{
+		CPU:                    s.CPU,
+		Memory:                 s.Memory,
+		ScalingMode:            apprun.ScalingMode(s.ScalingMode),
+		FixedScale:             optInt32(s.FixedScale),
+		MinScale:               optInt32(s.MinScale),
+		MaxScale:               optInt32(s.MaxScale),
+		ScaleInThreshold:       optInt32(s.ScaleInThreshold),
+		ScaleOutThreshold:      optInt32(s.ScaleOutThreshold),
+		Image:                  s.Image,
+		Cmd:                    s.Cmd,
+		RegistryUsername:       apprun.NilString{Null: true},
+		RegistryPassword:       apprun.NilString{Null: true},
+		RegistryPasswordAction: apprun.RegistryPasswordActionKeep,
+		ExposedPorts:           []apprun.ExposedPort{},
+		Env:                    []apprun.CreateEnvironmentVariable{},
+	}
+	if req.Cmd == nil {
+		req.Cmd = []string{}
+	}
+	if s.RegistryUsername != "" {
+		req.RegistryUsername = apprun.NewNilString(s.RegistryUsername)
+	}
+	for _, p := range s.ExposedPorts {
+		port := apprun.ExposedPort{
+			TargetPort:       apprun.Port(p.TargetPort),
+			LoadBalancerPort: apprun.NilPort{Null: true},
+			UseLetsEncrypt:   p.UseLetsEncrypt,
+			Host:             p.Host,
+			HealthCheck:      apprun.NilHealthCheck{Null: true},
+		}
+		if port.Host == nil {
+			port.Host = []string{}
+		}
+		if p.LoadBalancerPort != 0 {
+			port.LoadBalancerPort = apprun.NewNilPort(apprun.Port(p.LoadBalancerPort))
+		}
+		if p.HealthCheck != nil {
+			port.HealthCheck = apprun.NewNilHealthCheck(apprun.HealthCheck{
+				Path:            p.HealthCheck.Path,
+				IntervalSeconds: p.HealthCheck.IntervalSeconds,
+				TimeoutSeconds:  p.HealthCheck.TimeoutSeconds,
+			})
+		}
+		req.ExposedPorts = append(req.ExposedPorts, port)
+	}
+	for _, e := range s.Env {
+		env := apprun.CreateEnvironmentVariable{Key: e.Key, Secret: e.Secret}
+		if !e.Secret || e.Value != "" {
+			env.Value = apprun.NewOptString(e.Value)
+		}
+		req.Env = append(req.Env, env)
+	}
+	return req
+}
+
+func parseAppRunApplicationID(applicationID string) (apprun.ApplicationID, error) {
+	parsed, err := uuid.Parse(applicationID)
+	if err != nil {
+		return apprun.ApplicationID{}, fmt.Errorf("invalid application ID: %w", err)
+	}
+	return apprun.ApplicationID(parsed), nil
+}
+
+// GetAppRunVersionDetail fetches the full specification of an application version
+func (c *SakuraClient) GetAppRunVersionDetail(ctx context.Context, applicationID string, version int32) (*AppRunVersionDetail, error) {
+	slog.Info("Fetching AppRun version detail",
+		slog.String("applicationID", applicationID),
+		slog.Int("version", int(version)))
+
+	client, err := c.GetAppRunClient()
+	if err != nil {
+		slog.Error("Failed to get AppRun client", slog.Any("error", err))
+		return nil, err
+	}
+
+	appID, err := parseAppRunApplicationID(applicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.GetApplicationVersion(ctx, apprun.GetApplicationVersionParams{
+		ApplicationID: appID,
+		Version:       apprun.ApplicationVersionNumber(version),
+	})
+	if err != nil {
+		slog.Error("Failed to fetch AppRun version detail", slog.Any("error", err))
+		return nil, err
+	}
+
+	v := resp.ApplicationVersion
+	createdAt := ""
+	if v.Created > 0 {
+		createdAt = time.Unix(int64(v.Created), 0).Format("2006-01-02 15:04:05")
+	}
+
+	return &AppRunVersionDetail{
+		Version:         int32(v.Version),
+		ActiveNodeCount: v.ActiveNodeCount,
+		CreatedAt:       createdAt,
+		Spec:            convertAppRunVersionSpec(&v),
+	}, nil
+}
+
+// CreateAppRunVersion creates a new application version and returns its number.
+// The version is not deployed until it is activated.
+func (c *SakuraClient) CreateAppRunVersion(ctx context.Context, applicationID string, spec AppRunVersionSpec) (int32, error) {
+	slog.Info("Creating AppRun version",
+		slog.String("applicationID", applicationID),
+		slog.String("image", spec.Image))
+
+	client, err := c.GetAppRunClient()
+	if err != nil {
+		slog.Error("Failed to get AppRun client", slog.Any("error", err))
+		return 0, err
+	}
+
+	appID, err := parseAppRunApplicationID(applicationID)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := client.CreateApplicationVersion(ctx, spec.toCreateRequest(), apprun.CreateApplicationVersionParams{
+		ApplicationID: appID,
+	})
+	if err != nil {
+		slog.Error("Failed to create AppRun version", slog.Any("error", err))
+		return 0, err
+	}
+
+	version := int32(resp.ApplicationVersion.Version)
+	slog.Info("Successfully created AppRun version", slog.Int("version", int(version)))
+	return version, nil
+}
+
+// ActivateAppRunVersion makes the given version the active version of an application
+func (c *SakuraClient) ActivateAppRunVersion(ctx context.Context, applicationID string, version int32) error {
+	slog.Info("Activating AppRun version",
+		slog.String("applicationID", applicationID),
+		slog.Int("version", int(version)))
+
+	client, err := c.GetAppRunClient()
+	if err != nil {
+		slog.Error("Failed to get AppRun client", slog.Any("error", err))
+		return err
+	}
+
+	appID, err := parseAppRunApplicationID(applicationID)
+	if err != nil {
+		return err
+	}
+
+	err = client.UpdateApplication(ctx, &apprun.UpdateApplication{
+		ActiveVersion: apprun.NewNilInt32(version),
+	}, apprun.UpdateApplicationParams{ApplicationID: appID})
+	if err != nil {
+		slog.Error("Failed to activate AppRun version", slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+// GetAppRunApplicationStatus fetches the active version and desired container count of an application
+func (c *SakuraClient) GetAppRunApplicationStatus(ctx context.Context, applicationID string) (*AppRunApplicationStatus, error) {
+	client, err := c.GetAppRunClient()
+	if err != nil {
+		slog.Error("Failed to get AppRun client", slog.Any("error", err))
+		return nil, err
+	}
+
+	appID, err := parseAppRunApplicationID(applicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.GetApplication(ctx, apprun.GetApplicationParams{ApplicationID: appID})
+	if err != nil {
+		slog.Error("Failed to fetch AppRun application", slog.Any("error", err))
+		return nil, err
+	}
+
+	status := &AppRunApplicationStatus{}
+	if !resp.Application.ActiveVersion.Null {
+		status.ActiveVersion = resp.Application.ActiveVersion.Value
+	}
+	if !resp.Application.DesiredCount.Null {
+		status.DesiredCount = resp.Application.DesiredCount.Value
+	}
+	return status, nil
+}
+
+// formatAppRunCmd joins the arguments into a shell-style command line, single-quoting the
+// arguments that parseAppRunCmd would otherwise split or unquote
+func formatAppRunCmd(cmd []string) string {
+	args := make([]string, 0, len(cmd))
+	for _, arg := range cmd {
+		if arg != "" && !strings.ContainsFunc(arg, func(r rune) bool {
+			return unicode.IsSpace(r) || strings.ContainsRune(`'"\`, r)
+		}) {
+			args = append(args, arg)
+			continue
+		}
+		args = append(args, "'"+strings.ReplaceAll(arg, "'", `'\''`)+"'")
+	}
+	return strings.Join(args, " ")
+}
+
+// parseAppRunCmd splits a command line into arguments. Single quotes keep their content as is,
+// double quotes and backslashes work as in a POSIX shell; nothing is expanded.
+func parseAppRunCmd(s string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg, escaped := false, false
+	var quote rune
+	for _, r := range s {
+		switch {
+		case escaped:
+			arg.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case quote == '"':
+			switch r {
+			case '"':
+				quote = 0
+			case '\\':
+				escaped = true
+			default:
+				arg.WriteRune(r)
+			}
+		case r == '\\':
+			escaped, inArg = true, true
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("cmd has an unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, fmt.Errorf("cmd ends with a backslash")
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
+
+// DeleteAppRunVersion deletes an application version that is no longer used
+func (c *SakuraClient) DeleteAppRunVersion(ctx context.Context, applicationID string, version int32) error {
+	slog.Info("Deleting AppRun version",
+		slog.String("applicationID", applicationID),
+		slog.Int("version", int(version)))
+
+	client, err := c.GetAppRunClient()
+	if err != nil {
+		slog.Error("Failed to get AppRun client", slog.Any("error", err))
+		return err
+	}
+
+	appID, err := parseAppRunApplicationID(applicationID)
+	if err != nil {
+		return err
+	}
+
+	err = client.DeleteApplicationVersion(ctx, apprun.DeleteApplicationVersionParams{
+		ApplicationID: appID,
+		Version:       apprun.ApplicationVersionNumber(version),
+	})
+	if err != nil {
+		slog.Error("Failed to delete AppRun version", slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+// AppRunVersionComparison holds two versions of an application to be compared
+type AppRunVersionComparison struct {
+	AppName string
+	Base    *AppRunVersionDetail
+	Target  *AppRunVersionDetail
+}
+
+// AppRunVersionDiffRow is a single compared attribute of two versions
+type AppRunVersionDiffRow struct {
+	Field   string
+	Base    string
+	Target  string
+	Changed bool
+}
+
+// appRunSecretMask is shown instead of secret environment variable values
+const appRunSecretMask = "********"
+
+// CompareAppRunVersions fetches the full specifications of two versions of an application
+func (c *SakuraClient) CompareAppRunVersions(ctx context.Context, applicationID string, base, target int32) (*AppRunVersionComparison, error) {
+	baseDetail, err := c.GetAppRunVersionDetail(ctx, applicationID, base)
+	if err != nil {
+		return nil, err
+	}
+	targetDetail := baseDetail
+	if target != base {
+		targetDetail, err = c.GetAppRunVersionDetail(ctx, applicationID, target)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &AppRunVersionComparison{Base: baseDetail, Target: targetDetail}, nil
+}
+
+// Rows returns the compared attributes of both versions
+func (c *AppRunVersionComparison) Rows() []AppRunVersionDiffRow {
+	base := c.Base.Spec.diffFields()
+	target := c.Target.Spec.diffFields()
+
+	// Keep the field order of the base version and append fields only in the target
+	var fields []string
+	seen := map[string]bool{}
+	for _, list := range [][]appRunDiffField{base, target} {
+		for _, f := range list {
+			if !seen[f.name] {
+				seen[f.name] = true
+				fields = append(fields, f.name)
+			}
+		}
+	}
+
+	lookup := func(list []appRunDiffField, name string) (appRunDiffField, bool) {
+		for _, f := range list {
+			if f.name == name {
+				return f, true
+			}
+		}
+		return appRunDiffField{}, false
+	}
+
+	rows := make([]AppRunVersionDiffRow, 0, len(fields))
+	for _, name := range fields {
+		b, bok := lookup(base, name)
+		t, tok := lookup(target, name)
+		row := AppRunVersionDiffRow{Field: name, Base: "-", Target: "-"}
+		if bok {
+			row.Base = b.value
+		}
+		if tok {
+			row.Target = t.value
+		}
+		// Secret values are masked, so two secrets can only be compared by presence
+		row.Changed = bok != tok || b.secret != t.secret || (!b.secret && row.Base != row.Target)
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+type appRunDiffField struct {
+	name   string
+	value  string
+	secret bool
+}
+
+// diffFields flattens the spec into named fields for comparison
+func (s AppRunVersionSpec) diffFields() []appRunDiffField {
+	optional := func(n int32) string {
+		if n == 0 {
+			return "-"
+		}
+		return strconv.Itoa(int(n))
+	}
+	cmd := "-"
+	if len(s.Cmd) > 0 {
+		cmd = formatAppRunCmd(s.Cmd)
+	}
+	registryUser := "-"
+	if s.RegistryUsername != "" {
+		registryUser = s.RegistryUsername
+	}
+
+	fields := []appRunDiffField{
+		{name: "Image", value: s.Image},
+		{name: "CPU", value: strconv.FormatInt(s.CPU, 10)},
+		{name: "Memory", value: strconv.FormatInt(s.Memory, 10)},
+		{name: "Scaling Mode", value: s.ScalingMode},
+		{name: "Fixed Scale", value: optional(s.FixedScale)},
+		{name: "Min Scale", value: optional(s.MinScale)},
+		{name: "Max Scale", value: optional(s.MaxScale)},
+		{name: "Scale-in Threshold", value: optional(s.ScaleInThreshold)},
+		{name: "Scale-out Threshold", value: optional(s.ScaleOutThreshold)},
+		{name: "Cmd", value: cmd},
+		{name: "Registry User", value: registryUser},
+	}
+
+	for _, p := range s.ExposedPorts {
+		lb := "LB -"
+		if p.LoadBalancerPort != 0 {
+			lb = fmt.Sprintf("LB %d", p.LoadBalancerPort)
+		}
+		if len(p.Host) > 0 {
+			lb += " " + strings.Join(p.Host, ",")
+		}
+		if p.UseLetsEncrypt {
+			lb += " (Let's Encrypt)"
+		}
+		fields = append(fields, appRunDiffField{name: fmt.Sprintf("Port %d", p.TargetPort), value: lb})
+
+		hc := "-"
+		if p.HealthCheck != nil {
+			hc = fmt.Sprintf("%s every %ds timeout %ds", p.HealthCheck.Path, p.HealthCheck.IntervalSeconds, p.HealthCheck.TimeoutSeconds)
+		}
+		fields = append(fields, appRunDiffField{name: fmt.Sprintf("Port %d Health", p.TargetPort), value: hc})
+	}
+
+	env := make([]AppRunEnvVar, len(s.Env))
+	copy(env, s.Env)
+	slices.SortFunc(env, func(a, b AppRunEnvVar) int { return strings.Compare(a.Key, b.Key) })
+	for _, e := range env {
+		value := e.Value
+		if e.Secret {
+			value = appRunSecretMask
+		}
+		fields = append(fields, appRunDiffField{name: "Env " + e.Key, value: value, secret: e.Secret})
+	}
+	return fields
+}
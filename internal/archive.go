@@ -27,6 +27,7 @@ type ArchiveDetail struct {
 	Tags            []string
 	SourceDiskID    string
 	SourceArchiveID string
+	SourceZone      string // zone of the source archive when it was copied from another zone
 	BundleInfo      string
 	ModifiedAt      string
 }
@@ -142,6 +143,11 @@ func (c *SakuraClient) GetArchiveDetail(ctx context.Context, archiveID string) (
 	if !a.SourceArchiveID.IsEmpty() {
 		sourceArchiveID = a.SourceArchiveID.String()
 	}
+	sourceZone := ""
+	if a.SourceInfo != nil && !a.SourceInfo.ID.IsEmpty() {
+		sourceArchiveID = a.SourceInfo.ID.String()
+		sourceZone = a.SourceInfo.ZoneName
+	}
 
 	// Get bundle info
 	bundleInfo := ""
@@ -163,6 +169,7 @@ func (c *SakuraClient) GetArchiveDetail(ctx context.Context, archiveID string) (
 		Tags:            a.Tags,
 		SourceDiskID:    sourceDiskID,
 		SourceArchiveID: sourceArchiveID,
+		SourceZone:      sourceZone,
 		BundleInfo:      bundleInfo,
 		ModifiedAt:      modifiedAt,
 	}
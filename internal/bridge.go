@@ -140,14 +140,7 @@ func (c *SakuraClient) GetBridgeDetail(ctx context.Context, bridgeID string) (*B
 	}
 
 	// Convert bridge info (switches)
-	switches := make([]BridgeSwitchInfo, 0, len(b.BridgeInfo))
-	for _, sw := range b.BridgeInfo {
-		switches = append(switches, BridgeSwitchInfo{
-			ID:       sw.ID.String(),
-			Name:     sw.Name,
-			ZoneName: sw.ZoneName,
-		})
-	}
+	switches := bridgeSwitches(b)
 
 	// Get switch in zone info
 	var switchInZone *BridgeSwitchInfo
@@ -181,3 +174,16 @@ func (c *SakuraClient) GetBridgeDetail(ctx context.Context, bridgeID string) (*B
 
 	return detail, nil
 }
+
+// bridgeSwitches returns the switches connected to a bridge across zones
+func bridgeSwitches(b *iaas.Bridge) []BridgeSwitchInfo {
+	switches := make([]BridgeSwitchInfo, 0, len(b.BridgeInfo))
+	for _, sw := range b.BridgeInfo {
+		switches = append(switches, BridgeSwitchInfo{
+			ID:       sw.ID.String(),
+			Name:     sw.Name,
+			ZoneName: sw.ZoneName,
+		})
+	}
+	return switches
+}
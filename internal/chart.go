@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// chartWidth is the default number of columns used for sparkline charts in detail views
+const chartWidth = 60
+
+var sparkRunes = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline renders values as a single-line bar chart of at most width columns.
+// When there are more values than columns, values are averaged into buckets.
+func sparkline(values []float64, width int) string {
+	if len(values) == 0 || width <= 0 {
+		return ""
+	}
+
+	buckets := values
+	if len(values) > width {
+		buckets = make([]float64, width)
+		for i := range buckets {
+			start := i * len(values) / width
+			end := (i + 1) * len(values) / width
+			sum := 0.0
+			for _, v := range values[start:end] {
+				sum += v
+			}
+			buckets[i] = sum / float64(end-start)
+		}
+	}
+
+	minV, maxV := math.Inf(1), math.Inf(-1)
+	for _, v := range buckets {
+		minV = math.Min(minV, v)
+		maxV = math.Max(maxV, v)
+	}
+
+	var b strings.Builder
+	for _, v := range buckets {
+		idx := 0
+		if maxV > minV {
+			idx = int((v - minV) / (maxV - minV) * float64(len(sparkRunes)-1))
+		}
+		b.WriteRune(sparkRunes[idx])
+	}
+	return b.String()
+}
+
+// renderSeries renders a labelled sparkline with its latest and maximum values
+func renderSeries(label string, values []float64, format func(float64) string) string {
+	if len(values) == 0 {
+		return fmt.Sprintf("  %-10s (no data)\n", label)
+	}
+	maxV := values[0]
+	for _, v := range values {
+		maxV = math.Max(maxV, v)
+	}
+	return fmt.Sprintf("  %-10s %s\n  %-10s last: %s  max: %s\n",
+		label,
+		sparkline(values, chartWidth),
+		"",
+		format(values[len(values)-1]),
+		format(maxV))
+}
+
+// formatBPS formats a bits-per-second value with a human readable unit
+func formatBPS(v float64) string {
+	switch {
+	case v >= 1e9:
+		return fmt.Sprintf("%.1f Gbps", v/1e9)
+	case v >= 1e6:
+		return fmt.Sprintf("%.1f Mbps", v/1e6)
+	case v >= 1e3:
+		return fmt.Sprintf("%.1f Kbps", v/1e3)
+	default:
+		return fmt.Sprintf("%.0f bps", v)
+	}
+}
+
+// formatPercent formats a percentage value
+func formatPercent(v float64) string {
+	return fmt.Sprintf("%.1f%%", v)
+}
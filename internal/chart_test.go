@@ -0,0 +1,33 @@
+package internal
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSparkline(t *testing.T) {
+	assert.Equal(t, "", sparkline(nil, 10))
+	assert.Equal(t, "▁▄█", sparkline([]float64{0, 50, 100}, 10))
+
+	// Flat series renders at the lowest level
+	assert.Equal(t, "▁▁▁", sparkline([]float64{5, 5, 5}, 10))
+
+	// Long series are bucketed to the requested width
+	values := make([]float64, 100)
+	for i := range values {
+		values[i] = float64(i)
+	}
+	out := sparkline(values, 20)
+	assert.Equal(t, 20, utf8.RuneCountInString(out))
+	assert.Equal(t, '▁', []rune(out)[0])
+	assert.Equal(t, '█', []rune(out)[19])
+}
+
+func TestNFSUsedPercent(t *testing.T) {
+	// 100GB volume with 25GB free
+	assert.InDelta(t, 75.0, nfsUsedPercent(25*1024*1024, 100), 0.001)
+	assert.Equal(t, 0.0, nfsUsedPercent(0, 0))
+	assert.Equal(t, 0.0, nfsUsedPercent(200*1024*1024, 100))
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"sync"
 
 	client "github.com/sacloud/api-client-go"
 	"github.com/sacloud/iaas-api-go"
@@ -76,6 +77,7 @@ const (
 	ResourceTypeMonitoringLogStorage
 	ResourceTypeMonitoringMetricsStorage
 	ResourceTypeMonitoringTraceStorage
+	ResourceTypeMonitoringRouting
 )
 
 // AllResourceTypes returns all available resource types
@@ -102,6 +104,16 @@ var AllResourceTypes = []ResourceType{
 	ResourceTypeMonitoringLogStorage,
 	ResourceTypeMonitoringMetricsStorage,
 	ResourceTypeMonitoringTraceStorage,
+	ResourceTypeMonitoringRouting,
+}
+
+// IsGlobal reports whether the resources are not bound to a zone
+func (r ResourceType) IsGlobal() bool {
+	switch r {
+	case ResourceTypeDNS, ResourceTypeELB, ResourceTypeGSLB, ResourceTypeSSHKey, ResourceTypeSimpleMonitor, ResourceTypeContainerRegistry, ResourceTypeAppRunDedicated, ResourceTypeMonitoringLogStorage, ResourceTypeMonitoringMetricsStorage, ResourceTypeMonitoringTraceStorage:
+		return true
+	}
+	return false
 }
 
 func (r ResourceType) String() string {
@@ -150,6 +162,8 @@ func (r ResourceType) String() string {
 		return "Monitoring Suite - Metrics Storage"
 	case ResourceTypeMonitoringTraceStorage:
 		return "Monitoring Suite - Trace Storage"
+	case ResourceTypeMonitoringRouting:
+		return "Monitoring Suite - Routing"
 	default:
 		return "Unknown"
 	}
@@ -252,3 +266,23 @@ func (c *SakuraClient) GetAppRunClient() (*apprun.Client, error) {
 	c.apprunClient = apprunClient
 	return c.apprunClient, nil
 }
+
+// listFetchConcurrency is the number of per-item API calls made at once while loading a list
+const listFetchConcurrency = 4
+
+// forEachConcurrently calls fn for 0..n-1, running at most listFetchConcurrency calls at once.
+// fn must only write to state owned by its index.
+func forEachConcurrently(n int, fn func(i int)) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, listFetchConcurrency)
+	for i := range n {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}()
+	}
+	wg.Wait()
+}
@@ -1,8 +1,11 @@
 package internal
 
 import (
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -29,3 +32,22 @@ func TestProxyLBList(t *testing.T) {
 		t.Logf("elb %d: %+v", i, elb)
 	}
 }
+
+func TestForEachConcurrently(t *testing.T) {
+	var running, peak atomic.Int32
+	done := make([]bool, 10)
+	forEachConcurrently(len(done), func(i int) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		done[i] = true
+		running.Add(-1)
+	})
+	assert.Equal(t, []bool{true, true, true, true, true, true, true, true, true, true}, done)
+	assert.LessOrEqual(t, int(peak.Load()), listFetchConcurrency)
+}
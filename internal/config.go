@@ -3,12 +3,108 @@ package internal
 import (
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 )
 
 type Config struct {
-	DefaultZone string `toml:"default_zone"`
+	DefaultZone     string                          `toml:"default_zone"`
+	Registries      map[string]RegistryConfig       `toml:"registries"`
+	MetricsStorages map[string]MetricsStorageConfig `toml:"metrics_storages"`
+	LogStorages     map[string]LogStorageConfig     `toml:"log_storages"`
+	TraceStorages   map[string]TraceStorageConfig   `toml:"trace_storages"`
+	// Observe holds the metrics expressions of the observe view, keyed by server, database or apprun
+	Observe map[string][]SavedQuery `toml:"observe"`
+}
+
+// RegistryConfig holds the credentials used to browse a container registry.
+// Entries are keyed by the registry FQDN (or name).
+type RegistryConfig struct {
+	Username string `toml:"username"`
+	Password string `toml:"password"`
+	// URL overrides the default https://<FQDN> endpoint, e.g. http://localhost:5000
+	URL string `toml:"url"`
+}
+
+// Registry returns the registry settings for the given FQDN or name
+func (c *Config) Registry(fqdn, name string) RegistryConfig {
+	if rc, ok := c.Registries[fqdn]; ok {
+		return rc
+	}
+	return c.Registries[name]
+}
+
+// MetricsStorageConfig holds the query settings of a metrics storage.
+// Entries are keyed by the storage resource ID (or name).
+type MetricsStorageConfig struct {
+	// URL overrides the Prometheus API base URL, e.g. http://localhost:9090
+	URL string `toml:"url"`
+	// Token is used instead of the first access key of the storage
+	Token   string       `toml:"token"`
+	Queries []SavedQuery `toml:"queries"`
+	// ResourceLabel is the label holding the resource ID of routed metrics, resource_id by default
+	ResourceLabel string `toml:"resource_label"`
+}
+
+// SavedQuery is a named PromQL query offered in the query panel
+type SavedQuery struct {
+	Name  string `toml:"name"`
+	Query string `toml:"query"`
+}
+
+// MetricsStorage returns the metrics storage settings for the given resource ID or name
+func (c *Config) MetricsStorage(resourceID, name string) MetricsStorageConfig {
+	if mc, ok := c.MetricsStorages[resourceID]; ok {
+		return mc
+	}
+	return c.MetricsStorages[name]
+}
+
+// LogStorageConfig holds the query settings of a log storage.
+// Entries are keyed by the storage resource ID (or name).
+type LogStorageConfig struct {
+	// URL overrides the log query API base URL, e.g. http://localhost:3100
+	URL string `toml:"url"`
+	// Token is used instead of the first access key of the storage
+	Token string `toml:"token"`
+	// Selector is the LogQL stream selector the text filter is applied to
+	Selector string `toml:"selector"`
+	// ResourceLabel is the label holding the resource ID of routed logs, resource_id by default
+	ResourceLabel string `toml:"resource_label"`
+}
+
+// LogStorage returns the log storage settings for the given resource ID or name
+func (c *Config) LogStorage(resourceID, name string) LogStorageConfig {
+	if lc, ok := c.LogStorages[resourceID]; ok {
+		return lc
+	}
+	return c.LogStorages[name]
+}
+
+// TraceStorageConfig holds the query settings of a trace storage.
+// Entries are keyed by the storage resource ID (or name).
+type TraceStorageConfig struct {
+	// URL overrides the trace query API base URL, e.g. http://localhost:3200
+	URL string `toml:"url"`
+	// Token is used instead of the first access key of the storage
+	Token string `toml:"token"`
+}
+
+// TraceStorage returns the trace storage settings for the given resource ID or name
+func (c *Config) TraceStorage(resourceID, name string) TraceStorageConfig {
+	if tc, ok := c.TraceStorages[resourceID]; ok {
+		return tc
+	}
+	return c.TraceStorages[name]
+}
+
+// ObserveQueries returns the metrics expressions of the observe view for a kind of resource
+func (c *Config) ObserveQueries(kind string) []SavedQuery {
+	if queries, ok := c.Observe[strings.ToLower(kind)]; ok {
+		return queries
+	}
+	return defaultObserveQueries
 }
 
 func LoadConfig() (*Config, error) {
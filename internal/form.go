@@ -0,0 +1,188 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// formField is a single labelled text input in a form
+type formField struct {
+	key   string
+	label string
+	input textinput.Model
+}
+
+// form is a simple vertical list of text inputs used for create/edit operations.
+// submit validates the values and returns the command that performs the operation.
+type form struct {
+	title  string
+	note   string // optional explanation shown under the title
+	fields []formField
+	focus  int
+	err    string
+	submit func(values map[string]string) (tea.Cmd, error)
+}
+
+func newForm(title string, submit func(values map[string]string) (tea.Cmd, error)) *form {
+	return &form{
+		title:  title,
+		submit: submit,
+	}
+}
+
+// addField appends a text input with an initial value
+func (f *form) addField(key, label, value string) {
+	ti := textinput.New()
+	ti.CharLimit = 4096
+	ti.Width = 60
+	ti.SetValue(value)
+	if len(f.fields) == 0 {
+		ti.Focus()
+	}
+	f.fields = append(f.fields, formField{key: key, label: label, input: ti})
+}
+
+// setPlaceholder sets the placeholder text of the most recently added field
+func (f *form) setPlaceholder(placeholder string) {
+	f.fields[len(f.fields)-1].input.Placeholder = placeholder
+}
+
+// values returns the current values keyed by field key
+func (f *form) values() map[string]string {
+	values := make(map[string]string, len(f.fields))
+	for _, field := range f.fields {
+		values[field.key] = strings.TrimSpace(field.input.Value())
+	}
+	return values
+}
+
+func (f *form) setFocus(index int) {
+	f.fields[f.focus].input.Blur()
+	f.focus = (index + len(f.fields)) % len(f.fields)
+	f.fields[f.focus].input.Focus()
+}
+
+// update handles a key press. It returns closed=true when the form should be dismissed,
+// together with the command to run (nil when cancelled).
+func (f *form) update(msg tea.KeyMsg) (cmd tea.Cmd, closed bool) {
+	switch msg.String() {
+	case "esc":
+		return nil, true
+	case "tab", "down":
+		f.setFocus(f.focus + 1)
+		return nil, false
+	case "shift+tab", "up":
+		f.setFocus(f.focus - 1)
+		return nil, false
+	case "enter":
+		if f.focus < len(f.fields)-1 {
+			f.setFocus(f.focus + 1)
+			return nil, false
+		}
+		return f.trySubmit()
+	case "ctrl+s":
+		return f.trySubmit()
+	}
+
+	f.fields[f.focus].input, cmd = f.fields[f.focus].input.Update(msg)
+	return cmd, false
+}
+
+func (f *form) trySubmit() (tea.Cmd, bool) {
+	cmd, err := f.submit(f.values())
+	if err != nil {
+		f.err = err.Error()
+		return nil, false
+	}
+	return cmd, true
+}
+
+func (f *form) view() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render(f.title))
+	b.WriteString("\n")
+	if f.note != "" {
+		b.WriteString(helpStyle.Render(f.note))
+		b.WriteString("\n\n")
+	}
+
+	labelWidth := 0
+	for _, field := range f.fields {
+		labelWidth = max(labelWidth, len(field.label))
+	}
+
+	for i, field := range f.fields {
+		label := fmt.Sprintf("%-*s", labelWidth, field.label)
+		if i == f.focus {
+			b.WriteString(selectedStyle.Render("> " + label))
+		} else {
+			b.WriteString("  " + label)
+		}
+		b.WriteString(" ")
+		b.WriteString(field.input.View())
+		b.WriteString("\n")
+	}
+
+	if f.err != "" {
+		b.WriteString("\n")
+		b.WriteString(errorStyle.Render("Error: " + f.err))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("tab/↑/↓: move | Enter: next/submit | ctrl+s: submit | Esc: cancel"))
+	return b.String()
+}
+
+// newTypedNameForm builds a confirmation form for destructive operations: the operation
+// only runs after one of names has been typed exactly
+func newTypedNameForm(title, note string, names []string, run func(name string) tea.Cmd) *form {
+	f := newForm(title, func(values map[string]string) (tea.Cmd, error) {
+		for _, name := range names {
+			if values["name"] == name {
+				return run(name), nil
+			}
+		}
+		return nil, fmt.Errorf("type the name exactly to confirm")
+	})
+	f.note = note
+	f.addField("name", "Name", "")
+	return f
+}
+
+// parseFormInt parses an integer form value, reporting the field label on error
+func parseFormInt(values map[string]string, key, label string) (int, error) {
+	v := values[key]
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a number", label)
+	}
+	return n, nil
+}
+
+// parseFormBool parses a yes/no form value
+func parseFormBool(values map[string]string, key, label string) (bool, error) {
+	switch strings.ToLower(values[key]) {
+	case "y", "yes", "true":
+		return true, nil
+	case "", "n", "no", "false":
+		return false, nil
+	}
+	return false, fmt.Errorf("%s must be yes or no", label)
+}
+
+// formatFormBool formats b as a yes/no form value
+func formatFormBool(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
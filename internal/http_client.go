@@ -0,0 +1,44 @@
+package internal
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// httpAPIClient is the base of the clients of plain HTTP APIs: the registry and the
+// log, metrics and trace query APIs of the monitoring storages
+type httpAPIClient struct {
+	baseURL string
+	// token is sent as a bearer token when set. The registry client replaces it with the
+	// token obtained from the registry's auth service.
+	token      string
+	httpClient *http.Client
+}
+
+// newHTTPAPIClient creates a client for the API at baseURL.
+// baseURL may omit the scheme, in which case https is used.
+func newHTTPAPIClient(baseURL, token string) httpAPIClient {
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "https://" + baseURL
+	}
+	return httpAPIClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		token:      token,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// newRequest creates a request for path under the base URL, authorized with the bearer token
+func (c *httpAPIClient) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	return req, nil
+}
@@ -23,9 +23,12 @@ type LoadBalancer struct {
 
 // LoadBalancerServer represents a real server in a VIP
 type LoadBalancerServer struct {
-	IPAddress string
-	Port      int
-	Enabled   bool
+	IPAddress  string
+	Port       int
+	Enabled    bool
+	Status     string // health status reported by LoadBalancerOp.Status ("up"/"down")
+	ActiveConn int
+	CPS        int
 }
 
 // LoadBalancerVIP represents a virtual IP configuration
@@ -35,6 +38,7 @@ type LoadBalancerVIP struct {
 	DelayLoop        int
 	SorryServer      string
 	Description      string
+	CPS              int
 	Servers          []LoadBalancerServer
 }
 
@@ -170,6 +174,18 @@ func (c *SakuraClient) GetLoadBalancerDetail(ctx context.Context, loadBalancerID
 		})
 	}
 
+	// Merge runtime status of real servers
+	status, err := lbOp.Status(ctx, c.zone, id)
+	if err != nil {
+		slog.Warn("Failed to fetch load balancer status",
+			slog.String("zone", c.zone),
+			slog.String("loadBalancerID", loadBalancerID),
+			slog.Any("error", err))
+		// Continue without status
+	} else {
+		mergeLoadBalancerStatus(vips, status.Status)
+	}
+
 	// Convert tags
 	tags := make([]string, 0, len(lb.Tags))
 	tags = append(tags, lb.Tags...)
@@ -201,3 +217,126 @@ func (c *SakuraClient) GetLoadBalancerDetail(ctx context.Context, loadBalancerID
 
 	return detail, nil
 }
+
+// mergeLoadBalancerStatus copies runtime status into the configured VIPs and real servers
+func mergeLoadBalancerStatus(vips []LoadBalancerVIP, statuses []*iaas.LoadBalancerStatus) {
+	for _, st := range statuses {
+		for i := range vips {
+			vip := &vips[i]
+			if vip.VirtualIPAddress != st.VirtualIPAddress || vip.Port != st.Port.Int() {
+				continue
+			}
+			vip.CPS = st.CPS.Int()
+			for _, srvStatus := range st.Servers {
+				for j := range vip.Servers {
+					srv := &vip.Servers[j]
+					if srv.IPAddress == srvStatus.IPAddress && srv.Port == srvStatus.Port.Int() {
+						srv.Status = string(srvStatus.Status)
+						srv.ActiveConn = srvStatus.ActiveConn.Int()
+						srv.CPS = srvStatus.CPS.Int()
+					}
+				}
+			}
+		}
+	}
+}
+
+// RealServerCount returns the number of real servers across all VIPs
+func (d *LoadBalancerDetail) RealServerCount() int {
+	count := 0
+	for _, vip := range d.VIPs {
+		count += len(vip.Servers)
+	}
+	return count
+}
+
+// RealServerAt returns the VIP and server index of the n-th real server across all VIPs
+func (d *LoadBalancerDetail) RealServerAt(n int) (vipIndex, serverIndex int, ok bool) {
+	for i, vip := range d.VIPs {
+		if n < len(vip.Servers) {
+			return i, n, true
+		}
+		n -= len(vip.Servers)
+	}
+	return 0, 0, false
+}
+
+// findLoadBalancerServer returns the real server srv of the VIP vip in the given settings.
+// Servers are matched by address and port so that a VIP or server added or reordered since
+// the detail was loaded does not make another server match.
+func findLoadBalancerServer(vips []*iaas.LoadBalancerVirtualIPAddress, vip LoadBalancerVIP, srv LoadBalancerServer) (*iaas.LoadBalancerServer, error) {
+	for _, v := range vips {
+		if v.VirtualIPAddress != vip.VirtualIPAddress || v.Port.Int() != vip.Port {
+			continue
+		}
+		for _, s := range v.Servers {
+			if s.IPAddress == srv.IPAddress && s.Port.Int() == srv.Port {
+				return s, nil
+			}
+		}
+		return nil, fmt.Errorf("real server %s:%d not found on VIP %s:%d", srv.IPAddress, srv.Port, vip.VirtualIPAddress, vip.Port)
+	}
+	return nil, fmt.Errorf("VIP %s:%d not found", vip.VirtualIPAddress, vip.Port)
+}
+
+// SetLoadBalancerServerEnabled enables or disables a real server and applies the settings
+func (c *SakuraClient) SetLoadBalancerServerEnabled(ctx context.Context, loadBalancerID string, vip LoadBalancerVIP, srv LoadBalancerServer, enabled bool) error {
+	if c.zone == "" {
+		slog.Error("Zone is not set in client")
+		return fmt.Errorf("zone is not set")
+	}
+
+	slog.Info("Updating load balancer real server",
+		slog.String("zone", c.zone),
+		slog.String("loadBalancerID", loadBalancerID),
+		slog.String("vip", fmt.Sprintf("%s:%d", vip.VirtualIPAddress, vip.Port)),
+		slog.String("server", fmt.Sprintf("%s:%d", srv.IPAddress, srv.Port)),
+		slog.Bool("enabled", enabled))
+
+	lbOp := iaas.NewLoadBalancerOp(c.caller)
+
+	id := types.StringID(loadBalancerID)
+
+	// Read the latest settings so that concurrent changes are not overwritten
+	lb, err := lbOp.Read(ctx, c.zone, id)
+	if err != nil {
+		slog.Error("Failed to fetch load balancer",
+			slog.String("zone", c.zone),
+			slog.String("loadBalancerID", loadBalancerID),
+			slog.Any("error", err))
+		return err
+	}
+
+	server, err := findLoadBalancerServer(lb.VirtualIPAddresses, vip, srv)
+	if err != nil {
+		return err
+	}
+	server.Enabled = types.StringFlag(enabled)
+
+	_, err = lbOp.UpdateSettings(ctx, c.zone, id, &iaas.LoadBalancerUpdateSettingsRequest{
+		VirtualIPAddresses: lb.VirtualIPAddresses,
+		SettingsHash:       lb.SettingsHash,
+	})
+	if err != nil {
+		slog.Error("Failed to update load balancer settings",
+			slog.String("zone", c.zone),
+			slog.String("loadBalancerID", loadBalancerID),
+			slog.Any("error", err))
+		return err
+	}
+
+	// Apply the new settings to the running appliance
+	if err := lbOp.Config(ctx, c.zone, id); err != nil {
+		slog.Error("Failed to apply load balancer settings",
+			slog.String("zone", c.zone),
+			slog.String("loadBalancerID", loadBalancerID),
+			slog.Any("error", err))
+		return err
+	}
+
+	slog.Info("Successfully updated load balancer real server",
+		slog.String("zone", c.zone),
+		slog.String("loadBalancerID", loadBalancerID))
+
+	return nil
+}
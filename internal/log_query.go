@@ -0,0 +1,192 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultLogSelector matches every stream sent through the OpenTelemetry Collector
+const defaultLogSelector = `{service_name=~".+"}`
+
+// logQueryLimit is the maximum number of entries fetched by one query
+const logQueryLimit = 1000
+
+// LogQueryClient queries a Loki-compatible log query HTTP API
+type LogQueryClient struct {
+	httpAPIClient
+}
+
+// LogEntry is a single log line of a stream
+type LogEntry struct {
+	Time   time.Time
+	Labels map[string]string
+	Line   string
+}
+
+// LogViewWindows are the time ranges cycled through in the log viewer
+var LogViewWindows = []struct {
+	Label    string
+	Duration time.Duration
+}{
+	{"15m", 15 * time.Minute},
+	{"1h", time.Hour},
+	{"6h", 6 * time.Hour},
+	{"24h", 24 * time.Hour},
+}
+
+// NewLogQueryClient creates a client for the log query API at baseURL.
+// baseURL may omit the scheme, in which case https is used.
+func NewLogQueryClient(baseURL, token string) *LogQueryClient {
+	return &LogQueryClient{newHTTPAPIClient(baseURL, token)}
+}
+
+// Query returns the latest limit entries between start and end, oldest first
+func (c *LogQueryClient) Query(ctx context.Context, query string, start, end time.Time, limit int) ([]LogEntry, error) {
+	slog.Info("Querying logs", slog.String("query", query), slog.Time("start", start), slog.Time("end", end))
+
+	params := url.Values{
+		"query":     {query},
+		"start":     {strconv.FormatInt(start.UnixNano(), 10)},
+		"end":       {strconv.FormatInt(end.UnixNano(), 10)},
+		"limit":     {strconv.Itoa(limit)},
+		"direction": {"backward"},
+	}
+	req, err := c.newRequest(ctx, http.MethodGet, "/loki/api/v1/query_range?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		slog.Error("Failed to query logs", slog.Any("error", err))
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		// Loki returns the reason as plain text
+		return nil, fmt.Errorf("log query failed: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return parseLogQueryResponse(body)
+}
+
+// parseLogQueryResponse decodes a streams result into entries sorted by time
+func parseLogQueryResponse(body []byte) ([]LogEntry, error) {
+	var resp struct {
+		Status string `json:"status"`
+		Data   struct {
+			ResultType string `json:"resultType"`
+			Result     []struct {
+				Stream map[string]string `json:"stream"`
+				Values [][2]string       `json:"values"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("invalid log query response: %w", err)
+	}
+	if resp.Status != "success" {
+		return nil, fmt.Errorf("log query failed: status %s", resp.Status)
+	}
+	if resp.Data.ResultType != "streams" {
+		return nil, fmt.Errorf("unsupported result type %q, the query must select log lines", resp.Data.ResultType)
+	}
+
+	var entries []LogEntry
+	for _, stream := range resp.Data.Result {
+		for _, v := range stream.Values {
+			ns, err := strconv.ParseInt(v[0], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid log timestamp %q", v[0])
+			}
+			entries = append(entries, LogEntry{Time: time.Unix(0, ns), Labels: stream.Stream, Line: v[1]})
+		}
+	}
+	slices.SortStableFunc(entries, func(a, b LogEntry) int {
+		return a.Time.Compare(b.Time)
+	})
+	return entries, nil
+}
+
+// buildLogQuery applies a case-sensitive text filter to a stream selector
+func buildLogQuery(selector, filter string) string {
+	if selector == "" {
+		selector = defaultLogSelector
+	}
+	if filter == "" {
+		return selector
+	}
+	return selector + " |= " + strconv.Quote(filter)
+}
+
+// Severity returns error, warn, info or debug from the level label, or from a level
+// keyword among the first words of the line. It returns "" when the level is unknown.
+func (e LogEntry) Severity() string {
+	for _, key := range []string{"level", "severity", "severity_text", "detected_level"} {
+		if level := normalizeSeverity(e.Labels[key]); level != "" {
+			return level
+		}
+	}
+	words := strings.FieldsFunc(e.Line, func(r rune) bool {
+		return !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z')
+	})
+	// The level comes early in common formats: "2024-01-02T03:04:05Z ERROR ...", level=warn,
+	// {"level":"warn",...}. Lowercase words only count after a level key, so "no error" does not.
+	for i, word := range words[:min(len(words), 5)] {
+		afterKey := i > 0 && slices.Contains([]string{"level", "lvl", "severity"}, strings.ToLower(words[i-1]))
+		if word != strings.ToUpper(word) && !afterKey {
+			continue
+		}
+		if level := normalizeSeverity(word); level != "" {
+			return level
+		}
+	}
+	return ""
+}
+
+func normalizeSeverity(level string) string {
+	switch strings.ToLower(level) {
+	case "fatal", "critical", "crit", "error", "err", "emerg", "alert":
+		return "error"
+	case "warn", "warning":
+		return "warn"
+	case "info", "notice":
+		return "info"
+	case "debug", "trace":
+		return "debug"
+	}
+	return ""
+}
+
+// SaveLogEntries writes entries to path as tab separated time, severity and line
+func SaveLogEntries(path string, entries []LogEntry) error {
+	f, err := os.Create(expandHome(path))
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		severity := e.Severity()
+		if severity == "" {
+			severity = "-"
+		}
+		if _, err := fmt.Fprintf(f, "%s\t%s\t%s\n", e.Time.Format(time.RFC3339Nano), severity, e.Line); err != nil {
+			_ = f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
@@ -0,0 +1,124 @@
+package internal
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLoki is a minimal Loki query API stand-in serving the lines it holds
+type fakeLoki struct {
+	mu      sync.Mutex
+	lines   []LogEntry
+	queries []string
+}
+
+func (f *fakeLoki) add(t time.Time, level, line string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lines = append(f.lines, LogEntry{Time: t, Labels: map[string]string{"service_name": "web", "level": level}, Line: line})
+}
+
+func newFakeLoki(t *testing.T) (*httptest.Server, *fakeLoki) {
+	fake := &fakeLoki{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer t0ken" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/loki/api/v1/query_range" {
+			http.NotFound(w, r)
+			return
+		}
+		q := r.URL.Query()
+		query := q.Get("query")
+		if strings.HasPrefix(query, "{bad") {
+			http.Error(w, "parse error at line 1, col 5: syntax error", http.StatusBadRequest)
+			return
+		}
+		start, _ := strconv.ParseInt(q.Get("start"), 10, 64)
+		_, filter, _ := strings.Cut(query, " |= ")
+		filter, _ = strconv.Unquote(filter)
+
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		fake.queries = append(fake.queries, query)
+		// One stream per level, newest first as with direction=backward
+		streams := map[string][]string{}
+		for i := len(fake.lines) - 1; i >= 0; i-- {
+			e := fake.lines[i]
+			if e.Time.UnixNano() < start || !strings.Contains(e.Line, filter) {
+				continue
+			}
+			streams[e.Labels["level"]] = append(streams[e.Labels["level"]],
+				fmt.Sprintf(`["%d",%q]`, e.Time.UnixNano(), e.Line))
+		}
+		var result []string
+		for level, values := range streams {
+			result = append(result, fmt.Sprintf(`{"stream":{"service_name":"web","level":%q},"values":[%s]}`, level, strings.Join(values, ",")))
+		}
+		_, _ = fmt.Fprintf(w, `{"status":"success","data":{"resultType":"streams","result":[%s]}}`, strings.Join(result, ","))
+	}))
+	t.Cleanup(server.Close)
+	return server, fake
+}
+
+func TestLogQueryClient(t *testing.T) {
+	server, fake := newFakeLoki(t)
+	now := time.Now()
+	fake.add(now.Add(-3*time.Minute), "info", "GET /healthz 200")
+	fake.add(now.Add(-2*time.Minute), "error", "GET /api 500")
+	fake.add(now.Add(-time.Minute), "info", "GET /api 200")
+
+	client := NewLogQueryClient(server.URL, "t0ken")
+	entries, err := client.Query(t.Context(), buildLogQuery("", ""), now.Add(-time.Hour), now, logQueryLimit)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "GET /healthz 200", entries[0].Line)
+	assert.Equal(t, "GET /api 500", entries[1].Line)
+	assert.Equal(t, "error", entries[1].Severity())
+
+	entries, err = client.Query(t.Context(), buildLogQuery("", "/api"), now.Add(-time.Hour), now, logQueryLimit)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, `{service_name=~".+"} |= "/api"`, fake.queries[1])
+
+	_, err = client.Query(t.Context(), "{bad", now.Add(-time.Hour), now, logQueryLimit)
+	assert.EqualError(t, err, "log query failed: 400 Bad Request: parse error at line 1, col 5: syntax error")
+}
+
+func TestLogEntrySeverity(t *testing.T) {
+	for line, want := range map[string]string{
+		"2024-01-02T03:04:05Z ERROR connection refused": "error",
+		`{"level":"warning","msg":"slow query"}`:        "warn",
+		"[DEBUG] cache miss":                            "debug",
+		"ts=2024-01-02 level=info msg=ok":               "info",
+		"GET / 200 no error":                            "",
+		"plain message":                                 "",
+	} {
+		assert.Equal(t, want, LogEntry{Line: line}.Severity(), line)
+	}
+	assert.Equal(t, "error", LogEntry{Labels: map[string]string{"severity_text": "FATAL"}, Line: "INFO"}.Severity())
+}
+
+func TestSaveLogEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.log")
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, SaveLogEntries(path, []LogEntry{
+		{Time: at, Labels: map[string]string{"level": "warn"}, Line: "disk almost full"},
+		{Time: at.Add(time.Second), Line: "started"},
+	}))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01-02T03:04:05Z\twarn\tdisk almost full\n2024-01-02T03:04:06Z\t-\tstarted\n", string(data))
+}
@@ -0,0 +1,218 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PrometheusClient queries a Prometheus-compatible HTTP API
+type PrometheusClient struct {
+	httpAPIClient
+}
+
+// PromSample is a single value of a series
+type PromSample struct {
+	Time  time.Time
+	Value float64
+}
+
+// PromSeries is a labelled series of a query result. Instant queries have one sample per series.
+type PromSeries struct {
+	Labels  map[string]string
+	Samples []PromSample
+}
+
+// PromResult is the result of a PromQL query
+type PromResult struct {
+	ResultType string // vector, matrix, scalar or string
+	Series     []PromSeries
+}
+
+// MetricsQueryWindow is the time range of a query; zero Duration means an instant query
+type MetricsQueryWindow struct {
+	Label    string
+	Duration time.Duration
+	Step     time.Duration
+}
+
+// MetricsQueryWindows are the windows cycled through in the query panel
+var MetricsQueryWindows = []MetricsQueryWindow{
+	{Label: "instant"},
+	{Label: "1h", Duration: time.Hour, Step: time.Minute},
+	{Label: "6h", Duration: 6 * time.Hour, Step: 5 * time.Minute},
+	{Label: "24h", Duration: 24 * time.Hour, Step: 20 * time.Minute},
+}
+
+// NewPrometheusClient creates a client for the Prometheus API at baseURL.
+// baseURL may omit the scheme, in which case https is used.
+func NewPrometheusClient(baseURL, token string) *PrometheusClient {
+	return &PrometheusClient{newHTTPAPIClient(baseURL, token)}
+}
+
+// Query runs a PromQL query, as an instant query or over the given window ending at now
+func (p *PrometheusClient) Query(ctx context.Context, query string, window MetricsQueryWindow, now time.Time) (*PromResult, error) {
+	slog.Info("Running PromQL query", slog.String("query", query), slog.String("window", window.Label))
+
+	params := url.Values{"query": {query}}
+	path := "/api/v1/query"
+	if window.Duration == 0 {
+		params.Set("time", strconv.FormatInt(now.Unix(), 10))
+	} else {
+		path = "/api/v1/query_range"
+		params.Set("start", strconv.FormatInt(now.Add(-window.Duration).Unix(), 10))
+		params.Set("end", strconv.FormatInt(now.Unix(), 10))
+		params.Set("step", strconv.Itoa(int(window.Step.Seconds())))
+	}
+
+	req, err := p.newRequest(ctx, http.MethodPost, path, strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		slog.Error("Failed to run PromQL query", slog.Any("error", err))
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		// Prometheus explains rejected queries in an error envelope, proxies in front of it do not
+		if reason, ok := parsePromError(body); ok {
+			return nil, fmt.Errorf("query failed: %s", reason)
+		}
+		return nil, fmt.Errorf("query failed: %s", resp.Status)
+	}
+	return parsePromResponse(body)
+}
+
+// promResponse is the envelope of Prometheus API responses
+type promResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+// parsePromError returns "errorType: error" when body is a Prometheus error envelope
+func parsePromError(body []byte) (string, bool) {
+	var resp promResponse
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Status != "error" {
+		return "", false
+	}
+	return fmt.Sprintf("%s: %s", resp.ErrorType, resp.Error), true
+}
+
+// parsePromResponse decodes a Prometheus API query response
+func parsePromResponse(body []byte) (*PromResult, error) {
+	var resp promResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("invalid query response: %w", err)
+	}
+	if resp.Status != "success" {
+		return nil, fmt.Errorf("query failed: %s: %s", resp.ErrorType, resp.Error)
+	}
+
+	result := &PromResult{ResultType: resp.Data.ResultType}
+	switch resp.Data.ResultType {
+	case "vector", "matrix":
+		var series []struct {
+			Metric map[string]string `json:"metric"`
+			Value  []any             `json:"value"`
+			Values [][]any           `json:"values"`
+		}
+		if err := json.Unmarshal(resp.Data.Result, &series); err != nil {
+			return nil, fmt.Errorf("invalid query result: %w", err)
+		}
+		for _, s := range series {
+			ps := PromSeries{Labels: s.Metric}
+			values := s.Values
+			if s.Value != nil {
+				values = [][]any{s.Value}
+			}
+			for _, v := range values {
+				sample, err := parsePromSample(v)
+				if err != nil {
+					return nil, err
+				}
+				ps.Samples = append(ps.Samples, sample)
+			}
+			if len(ps.Samples) == 0 {
+				continue
+			}
+			result.Series = append(result.Series, ps)
+		}
+	case "scalar", "string":
+		var v []any
+		if err := json.Unmarshal(resp.Data.Result, &v); err != nil {
+			return nil, fmt.Errorf("invalid query result: %w", err)
+		}
+		sample, err := parsePromSample(v)
+		if err != nil {
+			return nil, err
+		}
+		result.Series = []PromSeries{{Samples: []PromSample{sample}}}
+	default:
+		return nil, fmt.Errorf("unsupported result type %q", resp.Data.ResultType)
+	}
+	return result, nil
+}
+
+// parsePromSample decodes a [<unix time>, "<value>"] pair
+func parsePromSample(v []any) (PromSample, error) {
+	if len(v) != 2 {
+		return PromSample{}, fmt.Errorf("invalid sample %v", v)
+	}
+	ts, ok := v[0].(float64)
+	if !ok {
+		return PromSample{}, fmt.Errorf("invalid sample time %v", v[0])
+	}
+	s, ok := v[1].(string)
+	if !ok {
+		return PromSample{}, fmt.Errorf("invalid sample value %v", v[1])
+	}
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return PromSample{}, fmt.Errorf("invalid sample value %q", s)
+	}
+	sec := int64(ts)
+	return PromSample{Time: time.Unix(sec, int64((ts-float64(sec))*1e9)), Value: value}, nil
+}
+
+// formatPromLabels formats labels as {__name__="up", job="node"} with the metric name first
+func formatPromLabels(labels map[string]string) string {
+	name := labels["__name__"]
+	var parts []string
+	for _, k := range slices.Sorted(maps.Keys(labels)) {
+		if k != "__name__" {
+			parts = append(parts, fmt.Sprintf("%s=%q", k, labels[k]))
+		}
+	}
+	if len(parts) == 0 && name != "" {
+		return name
+	}
+	return name + "{" + strings.Join(parts, ", ") + "}"
+}
+
+// formatPromValue formats a sample value compactly
+func formatPromValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', 6, 64)
+}
@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakePrometheus starts a minimal Prometheus HTTP API stand-in that requires a bearer token.
+// The parameters of the last query are recorded.
+func newFakePrometheus(t *testing.T) (*httptest.Server, *url.Values) {
+	var params url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer t0ken" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte("unauthorized"))
+			return
+		}
+		require.NoError(t, r.ParseForm())
+		params = r.PostForm
+		query := r.PostForm.Get("query")
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case query == "bad(":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"unexpected end of input"}`))
+		case query == "scalar(1)":
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"scalar","result":[1700000000,"1"]}}`))
+		case r.URL.Path == "/prometheus/api/v1/query":
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[
+				{"metric":{"__name__":"up","job":"node","instance":"web1"},"value":[1700000000,"1"]},
+				{"metric":{"__name__":"up","job":"node","instance":"web2"},"value":[1700000000,"0"]}]}}`))
+		case r.URL.Path == "/prometheus/api/v1/query_range":
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[
+				{"metric":{"instance":"web1"},"values":[[1699999880,"0.5"],[1699999940,"1.5"],[1700000000,"1"]]},
+				{"metric":{"instance":"web2"},"values":[]}]}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &params
+}
+
+func TestPrometheusClient(t *testing.T) {
+	server, params := newFakePrometheus(t)
+	client := NewPrometheusClient(server.URL+"/prometheus/", "t0ken")
+	now := time.Unix(1700000000, 0)
+
+	result, err := client.Query(t.Context(), "up", MetricsQueryWindows[0], now)
+	require.NoError(t, err)
+	assert.Equal(t, "vector", result.ResultType)
+	require.Len(t, result.Series, 2)
+	assert.Equal(t, `up{instance="web1", job="node"}`, formatPromLabels(result.Series[0].Labels))
+	assert.Equal(t, []PromSample{{Time: now, Value: 1}}, result.Series[0].Samples)
+	assert.Equal(t, "1700000000", params.Get("time"))
+
+	// Series without samples are dropped
+	result, err = client.Query(t.Context(), "rate(x[5m])", MetricsQueryWindows[1], now)
+	require.NoError(t, err)
+	assert.Equal(t, "matrix", result.ResultType)
+	assert.Equal(t, "1699996400", params.Get("start"))
+	assert.Equal(t, "60", params.Get("step"))
+	require.Len(t, result.Series, 1)
+	assert.Len(t, result.Series[0].Samples, 3)
+	assert.Equal(t, 1.5, result.Series[0].Samples[1].Value)
+
+	result, err = client.Query(t.Context(), "scalar(1)", MetricsQueryWindows[0], now)
+	require.NoError(t, err)
+	assert.Equal(t, "{}", formatPromLabels(result.Series[0].Labels))
+
+	_, err = client.Query(t.Context(), "bad(", MetricsQueryWindows[0], now)
+	assert.EqualError(t, err, "query failed: bad_data: unexpected end of input")
+
+	_, err = NewPrometheusClient(server.URL+"/prometheus", "wrong").Query(t.Context(), "up", MetricsQueryWindows[0], now)
+	assert.EqualError(t, err, "query failed: 401 Unauthorized")
+}
+
+func TestNewPrometheusClientAddsScheme(t *testing.T) {
+	assert.Equal(t, "https://example.metrics.sakura.ad.jp/prometheus", NewPrometheusClient("example.metrics.sakura.ad.jp/prometheus", "").baseURL)
+	assert.Equal(t, "http://localhost:9090", NewPrometheusClient("http://localhost:9090/", "").baseURL)
+}
@@ -5,14 +5,22 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
+	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	v1 "github.com/sacloud/monitoring-suite-api-go/apis/v1"
+
+	apprun "github.com/tokuhirom/sact/pkg/openapi/apprun_dedicated"
 )
 
 var (
@@ -41,10 +49,14 @@ var (
 	upStatusStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
 	downStatusStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
 	otherStatusStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+	errorStyle        = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
 )
 
 // Custom delegate for single-line resource display (handles Server and Switch)
-type resourceDelegate struct{}
+type resourceDelegate struct {
+	marked      map[string]bool // IDs of the SimpleMonitors marked for a bulk action
+	compareBase int32           // AppRun version marked as the comparison base, 0 if none
+}
 
 func (d resourceDelegate) Height() int                             { return 1 }
 func (d resourceDelegate) Spacing() int                            { return 0 }
@@ -272,17 +284,30 @@ func (d resourceDelegate) Render(w io.Writer, m list.Model, index int, item list
 		case "down":
 			statusStyle = downStatusStyle
 		}
+		// Highlight nearly full volumes
+		usedStr := "-"
+		usedStyle := lipgloss.NewStyle()
+		if nfs.HasUsage {
+			usedStr = fmt.Sprintf("%.0f%%", nfs.UsedPercent)
+			if nfs.UsedPercent >= 90 {
+				usedStyle = errorStyle
+			} else if nfs.UsedPercent >= 80 {
+				usedStyle = otherStatusStyle
+			}
+		}
 		if index == m.Index() {
-			str = selectedItemStyle.Render(fmt.Sprintf("> %-40s %-20s %-15s %s",
+			str = selectedItemStyle.Render(fmt.Sprintf("> %-40s %-20s %-15s %s %s",
 				nfs.Name,
 				nfs.ID,
 				nfs.SwitchName,
+				usedStyle.Render(fmt.Sprintf("%5s", usedStr)),
 				statusStyle.Render(nfs.InstanceStatus)))
 		} else {
-			str = itemStyle.Render(fmt.Sprintf("  %-40s %-20s %-15s %s",
+			str = itemStyle.Render(fmt.Sprintf("  %-40s %-20s %-15s %s %s",
 				nfs.Name,
 				nfs.ID,
 				nfs.SwitchName,
+				usedStyle.Render(fmt.Sprintf("%5s", usedStr)),
 				statusStyle.Render(nfs.InstanceStatus)))
 		}
 	} else if sshKey, ok := item.(SSHKey); ok {
@@ -319,18 +344,36 @@ func (d resourceDelegate) Render(w io.Writer, m list.Model, index int, item list
 		if sm.Enabled {
 			enabledStr = "ON"
 		}
+		health := sm.Health
+		healthStyle := otherStatusStyle
+		switch {
+		case health == "":
+			health = "-"
+		case sm.IsUnhealthy():
+			healthStyle = errorStyle
+		default:
+			healthStyle = upStatusStyle
+		}
+		mark := " "
+		if d.marked[sm.ID] {
+			mark = "*"
+		}
 		if index == m.Index() {
-			str = selectedItemStyle.Render(fmt.Sprintf("> %-40s %-20s %-10s %s",
+			str = selectedItemStyle.Render(fmt.Sprintf(">%s%-40s %-20s %-10s %-7s %s",
+				mark,
 				sm.Name,
 				sm.ID,
 				sm.Protocol,
-				enabledStr))
+				enabledStr,
+				healthStyle.Render(health)))
 		} else {
-			str = itemStyle.Render(fmt.Sprintf("  %-40s %-20s %-10s %s",
+			str = itemStyle.Render(fmt.Sprintf(" %s%-40s %-20s %-10s %-7s %s",
+				mark,
 				sm.Name,
 				sm.ID,
 				sm.Protocol,
-				enabledStr))
+				enabledStr,
+				healthStyle.Render(health)))
 		}
 	} else if br, ok := item.(Bridge); ok {
 		// Handle Bridge
@@ -422,6 +465,17 @@ func (d resourceDelegate) Render(w io.Writer, m list.Model, index int, item list
 				versionStr,
 				app.DesiredCount))
 		}
+	} else if cert, ok := item.(AppRunCertificate); ok {
+		// Handle AppRunCertificate
+		line := fmt.Sprintf("[Cert] %-30s %-30s %s",
+			cert.Name,
+			cert.CommonName,
+			cert.ExpiryStatus(time.Now()))
+		if index == m.Index() {
+			str = selectedItemStyle.Render("> " + line)
+		} else {
+			str = itemStyle.Render("  " + line)
+		}
 	} else if ver, ok := item.(AppRunVersion); ok {
 		// Handle AppRunVersion
 		// Truncate image name if too long
@@ -429,9 +483,11 @@ func (d resourceDelegate) Render(w io.Writer, m list.Model, index int, item list
 		if len(image) > 40 {
 			image = image[:37] + "..."
 		}
-		// Show "*" marker for active version
+		// Show "*" marker for active version and "+" for the comparison base
 		activeMarker := " "
-		if ver.IsActive {
+		if ver.Version == d.compareBase {
+			activeMarker = "+"
+		} else if ver.IsActive {
 			activeMarker = "*"
 		}
 		if index == m.Index() {
@@ -498,6 +554,10 @@ func (d resourceDelegate) Render(w io.Writer, m list.Model, index int, item list
 				resourceID,
 				retention))
 		}
+	} else if lr, ok := item.(MonitoringLogRouting); ok {
+		str = renderRoutingRow(index == m.Index(), "logs", lr.Title(), getOptNilInt64AsString(lr.ResourceID), lr.Publisher.Code, lr.Variant, getOptString(lr.LogStorage.Name))
+	} else if mr, ok := item.(MonitoringMetricsRouting); ok {
+		str = renderRoutingRow(index == m.Index(), "metrics", mr.Title(), getOptNilInt64AsString(mr.ResourceID), mr.Publisher.Code, mr.Variant, getOptString(mr.MetricsStorage.Name))
 	} else {
 		return
 	}
@@ -505,6 +565,18 @@ func (d resourceDelegate) Render(w io.Writer, m list.Model, index int, item list
 	fmt.Fprint(w, str)
 }
 
+// renderRoutingRow renders a log or metrics routing in the routing list
+func renderRoutingRow(selected bool, kind, uid, resourceID, publisher, variant, storage string) string {
+	if resourceID == "" {
+		resourceID = "-"
+	}
+	row := fmt.Sprintf("%-9s %-8s %-14s %-16s %-20s %s", kind, uid, resourceID, publisher, variant, storage)
+	if selected {
+		return selectedItemStyle.Render("> " + row)
+	}
+	return itemStyle.Render("  " + row)
+}
+
 type model struct {
 	client                  *SakuraClient
 	list                    list.Model
@@ -544,19 +616,58 @@ type model struct {
 	appRunClusterDetail     *AppRunClusterDetail
 	appRunLBDetail          *AppRunLBDetail
 	appRunASGDetail         *AppRunASGDetail
+	appRunVersionComparison *AppRunVersionComparison
 	appRunWorkerNodes       []AppRunWorkerNode
+	appRunPlacement         *AppRunContainerPlacement
+	appRunCertificateDetail *AppRunCertificate
 	// AppRun Dedicated drilldown state
 	appRunDrilldownLevel           int    // 0: Cluster, 1: ASG+App, 2: LB (from ASG) or Version (from App), 3: Version detail
 	appRunSelectedClusterID        string // selected cluster ID for ASG/App list
 	appRunSelectedASGID            string // selected ASG ID for LB list
 	appRunSelectedAppID            string // selected Application ID for Version list
 	appRunActiveVersion            int32  // active version of selected application
+	appRunSelectedAppName          string // name of selected application
+	appRunRollout                  *appRunRollout
+	appRunDrain                    *appRunDrain
+	appRunFocusNodeID              string // worker node to select when the ASG detail is opened from a container placement
 	detailLoading                  bool
 	resourceType                   ResourceType
 	monitoringLogStorageDetail     *MonitoringLogStorageDetail
 	monitoringMetricsStorageDetail *MonitoringMetricsStorageDetail
 	monitoringTraceStorageDetail   *MonitoringTraceStorageDetail
 	detailViewport                 viewport.Model
+	// Detail view row selection and action confirmation
+	detailCursor   int     // selected row in detail views that support actions
+	confirmMessage string  // non-empty while waiting for y/n confirmation
+	confirmCmd     tea.Cmd // command to run when the user confirms
+	statusMessage  string  // result of the last action
+	form           *form   // active create/edit form, if any
+	config         *Config
+	// Container registry image browser, opened from the registry detail
+	registryBrowser *registryBrowser
+	// PromQL query panel, opened from the metrics storage detail
+	metricsQuery *metricsQuery
+	alertRules   *alertRuleBrowser
+	// Log viewer, opened from the log storage detail
+	logViewer *logViewer
+	// Trace explorer, opened from the trace storage detail
+	traceExplorer *traceExplorer
+	// Access keys of the log, metrics or trace storage shown in the detail
+	storageKeys *storageKeyBrowser
+	// Recent logs and metrics of the server, database or AppRun application shown in the detail
+	observeView *observeView
+	// Details left by following a related ID, reopened by esc/backspace
+	detailStack []detailLocation
+	// Network topology of the current zone
+	topology *topologyView
+	// SimpleMonitor list filter and detail window
+	simpleMonitors             []SimpleMonitor // unfiltered list
+	simpleMonitorUnhealthyOnly bool
+	simpleMonitorWindow        int             // index into SimpleMonitorWindows
+	simpleMonitorMarks         map[string]bool // IDs marked for a bulk enable/disable
+	// AppRun version marked as the base for comparisons, 0 if none
+	appRunCompareVersion int32
+	appRunCompareAppID   string // application the marked version belongs to
 	// Resource type selector
 	resourceSelectMode   bool
 	resourceSelectCursor int
@@ -774,6 +885,7 @@ type appRunLBsLoadedMsg struct {
 type appRunClusterContentsLoadedMsg struct {
 	asgs      []AppRunASG
 	apps      []AppRunApplication
+	certs     []AppRunCertificate
 	clusterID string
 	err       error
 }
@@ -826,2137 +938,6253 @@ type monitoringTraceStorageDetailLoadedMsg struct {
 	err    error
 }
 
-func loadServers(client *SakuraClient) tea.Cmd {
+type monitoringRoutingsLoadedMsg struct {
+	logRoutings     []MonitoringLogRouting
+	metricsRoutings []MonitoringMetricsRouting
+	err             error
+}
+
+// routingChoicesLoadedMsg carries what the routing form picks from
+type routingChoicesLoadedMsg struct {
+	choices *RoutingChoices
+	err     error
+}
+
+type registryRepositoriesLoadedMsg struct {
+	repositories []string
+	err          error
+}
+
+type registryTagsLoadedMsg struct {
+	repository string
+	tags       []RegistryTag
+	err        error
+}
+
+// registryBrowser holds the state of the container registry image browser
+type registryBrowser struct {
+	client       *RegistryClient
+	endpoint     string
+	username     string
+	repositories []string
+	repository   string // selected repository; empty while showing the catalog
+	tags         []RegistryTag
+}
+
+// rowCount returns the number of selectable rows at the current level
+func (b *registryBrowser) rowCount() int {
+	if b.repository == "" {
+		return len(b.repositories)
+	}
+	return len(b.tags)
+}
+
+type metricsQueryResultMsg struct {
+	query  string
+	result *PromResult
+	err    error
+}
+
+// metricsQuery holds the state of the PromQL query panel, opened from the metrics storage detail
+type metricsQuery struct {
+	client   *PrometheusClient // nil until the access key has been fetched
+	endpoint string
+	saved    []SavedQuery
+	query    string // last query run
+	window   int    // index into MetricsQueryWindows
+	chart    bool   // show sparklines instead of a table
+	result   *PromResult
+	err      error
+}
+
+type alertRulesLoadedMsg struct {
+	rules *MonitoringAlertRules
+	err   error
+}
+
+// alertRuleBrowser holds the alert rules listed in the metrics storage detail
+type alertRuleBrowser struct {
+	rules *MonitoringAlertRules // nil until loaded
+	err   error
+}
+
+// logEntriesLoadedMsg carries the result of a log query. seq is 0 for a full reload,
+// or the follow sequence of the poll that appends new entries.
+type logEntriesLoadedMsg struct {
+	entries []LogEntry
+	seq     int
+	err     error
+}
+
+type logFollowTickMsg struct {
+	seq int
+}
+
+// logViewerMaxEntries caps the entries kept while following
+const logViewerMaxEntries = 5000
+
+// logViewer holds the state of the log viewer, opened from the log storage detail
+type logViewer struct {
+	client    *LogQueryClient // nil until the access key has been fetched
+	endpoint  string
+	selector  string
+	filter    string
+	window    int // index into LogViewWindows
+	follow    bool
+	followSeq int // bumped whenever polling restarts so that stale polls are ignored
+	entries   []LogEntry
+	err       error
+}
+
+// query returns the LogQL query for the current filter
+func (v *logViewer) query() string {
+	return buildLogQuery(v.selector, v.filter)
+}
+
+// reload queries the whole window again. Polls in flight are discarded and
+// following resumes from the reloaded entries.
+func (v *logViewer) reload() tea.Cmd {
+	v.followSeq++
+	return queryLogs(v.client, v.query(), time.Now().Add(-LogViewWindows[v.window].Duration), 0)
+}
+
+type topologyLoadedMsg struct {
+	topology *Topology
+	err      error
+}
+
+// topologyView holds the network topology of the zone and the format it is shown in
+type topologyView struct {
+	topology *Topology
+	format   int // index into TopologyFormats
+}
+
+type observationLoadedMsg struct {
+	obs *Observation
+	err error
+}
+
+// observeView holds the recent logs and metrics of a resource, opened from its detail
+type observeView struct {
+	target ObserveTarget
+	obs    *Observation // nil until loaded
+	err    error
+	// detailCursor is the cursor of the underlying detail, restored when the view is closed
+	detailCursor int
+}
+
+type tracesFoundMsg struct {
+	traces []TraceSummary
+	err    error
+}
+
+type traceLoadedMsg struct {
+	trace *Trace
+	err   error
+}
+
+// traceExplorer holds the state of the trace explorer, opened from the trace storage detail
+type traceExplorer struct {
+	client      *TraceQueryClient // nil until the access key has been fetched
+	endpoint    string
+	search      TraceSearch
+	window      int // index into TraceSearchWindows
+	traces      []TraceSummary
+	err         error
+	trace       *Trace // selected trace; nil while showing the search results
+	rows        []SpanRow
+	traceCursor int // search result under the cursor while a trace is shown
+}
+
+// searchCmd runs the search over the current window
+func (e *traceExplorer) searchCmd() tea.Cmd {
+	return searchTraces(e.client, e.search, time.Now().Add(-TraceSearchWindows[e.window].Duration))
+}
+
+// storageTokenLoadedMsg carries the first access key token of a storage whose query panel is open
+type storageTokenLoadedMsg struct {
+	kind  StorageKind
+	token string
+	err   error
+}
+
+type storageKeysLoadedMsg struct {
+	keys []StorageAccessKey
+	err  error
+}
+
+type storageKeyCreatedMsg struct {
+	key *StorageAccessKey
+	err error
+}
+
+// storageKeyBrowser holds the access keys of a monitoring storage
+type storageKeyBrowser struct {
+	ref     MonitoringStorageRef
+	keys    []StorageAccessKey
+	loaded  bool
+	err     error
+	created *StorageAccessKey // shown with its secret until the browser is closed
+	snippet string            // last ingestion snippet copied
+}
+
+type appRunCertificateLoadedMsg struct {
+	cert *AppRunCertificate
+	err  error
+}
+
+type appRunContainerPlacementLoadedMsg struct {
+	placement *AppRunContainerPlacement
+	err       error
+}
+
+// appRunClusterEditLoadedMsg carries the cluster to open the edit form for
+type appRunClusterEditLoadedMsg struct {
+	detail *AppRunClusterDetail
+	err    error
+}
+
+// appRunServiceClassesLoadedMsg carries the service classes for the ASG form (asg nil)
+// or the LB form of asg
+type appRunServiceClassesLoadedMsg struct {
+	asg     *AppRunASG
+	classes []AppRunServiceClass
+	err     error
+}
+
+type appRunVersionSpecLoadedMsg struct {
+	app    AppRunApplication
+	detail *AppRunVersionDetail
+	err    error
+}
+
+type appRunVersionCreatedMsg struct {
+	app       AppRunApplication
+	version   int32
+	activated bool
+	err       error
+}
+
+type appRunVersionComparisonLoadedMsg struct {
+	comparison *AppRunVersionComparison
+	err        error
+}
+
+type appRunVersionActivatedMsg struct {
+	app     AppRunApplication
+	version int32
+	err     error
+}
+
+// appRunRollout tracks a version being rolled out after activation
+type appRunRollout struct {
+	app     AppRunApplication
+	version int32
+	started time.Time
+}
+
+// appRunRolloutTimeout is how long a rollout is tracked before giving up
+const appRunRolloutTimeout = 15 * time.Minute
+
+type appRunRolloutTickMsg struct{}
+
+type appRunRolloutStatusMsg struct {
+	version       int32
+	activeVersion int32
+	activeNodes   int64
+	desiredCount  int32
+	err           error
+}
+
+type appRunDrainingSetMsg struct {
+	node     AppRunWorkerNode
+	draining bool
+	err      error
+}
+
+// appRunDrain tracks a worker node being drained until no containers are left on it
+type appRunDrain struct {
+	node    AppRunWorkerNode
+	started time.Time
+}
+
+// appRunDrainTimeout is how long a drain is tracked before giving up
+const appRunDrainTimeout = 15 * time.Minute
+
+type appRunDrainTickMsg struct{}
+
+type appRunDrainStatusMsg struct {
+	node  AppRunWorkerNode
+	nodes []AppRunWorkerNode // all worker nodes of the ASG
+	err   error
+}
+
+// actionDoneMsg is sent when a mutating operation has finished
+type actionDoneMsg struct {
+	message string
+	err     error
+	reload  tea.Cmd // command to refresh the affected view
+}
+
+func setLoadBalancerServerEnabled(client *SakuraClient, loadBalancerID string, vip LoadBalancerVIP, srv LoadBalancerServer, enabled bool) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		servers, err := client.ListServers(ctx)
-		return serversLoadedMsg{servers: servers, err: err}
+		err := client.SetLoadBalancerServerEnabled(ctx, loadBalancerID, vip, srv, enabled)
+		if err != nil {
+			slog.Error("Failed to update load balancer real server", slog.Any("error", err))
+			return actionDoneMsg{err: err}
+		}
+		action := "disabled"
+		if enabled {
+			action = "enabled"
+		}
+		return actionDoneMsg{
+			message: fmt.Sprintf("Real server %s", action),
+			reload:  loadLoadBalancerDetail(client, loadBalancerID),
+		}
 	}
 }
 
-func loadSwitches(client *SakuraClient) tea.Cmd {
+// setSimpleMonitorsEnabled enables or disables the given simple monitors and reloads the list
+func setSimpleMonitorsEnabled(client *SakuraClient, simpleMonitorIDs []string, enabled bool) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		switches, err := client.ListSwitches(ctx)
-		return switchesLoadedMsg{switches: switches, err: err}
+		action := "disabled"
+		if enabled {
+			action = "enabled"
+		}
+		for i, id := range simpleMonitorIDs {
+			if err := client.SetSimpleMonitorEnabled(ctx, id, enabled); err != nil {
+				err = fmt.Errorf("%d of %d simple monitor(s) %s, failed on %s: %w", i, len(simpleMonitorIDs), action, id, err)
+				return actionDoneMsg{err: err, reload: loadSimpleMonitors(client)}
+			}
+		}
+		return actionDoneMsg{
+			message: fmt.Sprintf("%d simple monitor(s) %s", len(simpleMonitorIDs), action),
+			reload:  loadSimpleMonitors(client),
+		}
 	}
 }
 
-func loadAuthStatus(client *SakuraClient) tea.Cmd {
+func setSimpleMonitorEnabled(client *SakuraClient, simpleMonitorID string, enabled bool, window time.Duration) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		authStatus, err := client.GetAuthStatus(ctx)
-		if err != nil {
-			slog.Error("Failed to load auth status", slog.Any("error", err))
-			return authStatusLoadedMsg{err: err}
+		if err := client.SetSimpleMonitorEnabled(ctx, simpleMonitorID, enabled); err != nil {
+			return actionDoneMsg{err: err}
+		}
+		action := "disabled"
+		if enabled {
+			action = "enabled"
+		}
+		return actionDoneMsg{
+			message: fmt.Sprintf("Simple monitor %s", action),
+			reload:  loadSimpleMonitorDetail(client, simpleMonitorID, window),
 		}
-		slog.Info("Auth status loaded successfully", slog.String("account", authStatus.AccountName))
-		return authStatusLoadedMsg{accountName: authStatus.AccountName}
 	}
 }
 
-func loadServerDetail(client *SakuraClient, serverID string) tea.Cmd {
+func updateSimpleMonitorParams(client *SakuraClient, simpleMonitorID string, params SimpleMonitorParams, window time.Duration) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		detail, err := client.GetServerDetail(ctx, serverID)
-		if err != nil {
-			slog.Error("Failed to load server detail", slog.Any("error", err))
-			return serverDetailLoadedMsg{err: err}
+		if err := client.UpdateSimpleMonitorParams(ctx, simpleMonitorID, params); err != nil {
+			return actionDoneMsg{err: err}
+		}
+		return actionDoneMsg{
+			message: "Simple monitor updated",
+			reload:  loadSimpleMonitorDetail(client, simpleMonitorID, window),
 		}
-		slog.Info("Server detail loaded successfully", slog.String("serverID", serverID))
-		return serverDetailLoadedMsg{detail: detail}
 	}
 }
 
-func loadSwitchDetail(client *SakuraClient, switchID string) tea.Cmd {
+func createSimpleMonitorFrom(client *SakuraClient, sourceID, target string, params SimpleMonitorParams) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		detail, err := client.GetSwitchDetail(ctx, switchID)
+		id, err := client.CreateSimpleMonitorFrom(ctx, sourceID, target, params)
 		if err != nil {
-			slog.Error("Failed to load switch detail", slog.Any("error", err))
-			return switchDetailLoadedMsg{err: err}
+			return actionDoneMsg{err: err}
+		}
+		return actionDoneMsg{
+			message: fmt.Sprintf("Created simple monitor %s for %s", id, target),
 		}
-		slog.Info("Switch detail loaded successfully", slog.String("switchID", switchID))
-		return switchDetailLoadedMsg{detail: detail}
 	}
 }
 
-func loadDNS(client *SakuraClient) tea.Cmd {
+func loadRegistryRepositories(client *RegistryClient) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		dnsList, err := client.ListDNS(ctx)
-		return dnsLoadedMsg{dnsList: dnsList, err: err}
+		repositories, err := client.ListRepositories(ctx)
+		return registryRepositoriesLoadedMsg{repositories: repositories, err: err}
 	}
 }
 
-func loadDNSDetail(client *SakuraClient, dnsID string) tea.Cmd {
+func loadRegistryTags(client *RegistryClient, repository string) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		detail, err := client.GetDNSDetail(ctx, dnsID)
-		if err != nil {
-			slog.Error("Failed to load DNS detail", slog.Any("error", err))
-			return dnsDetailLoadedMsg{err: err}
-		}
-		slog.Info("DNS detail loaded successfully", slog.String("dnsID", dnsID))
-		return dnsDetailLoadedMsg{detail: detail}
+		tags, err := client.ListTags(ctx, repository)
+		return registryTagsLoadedMsg{repository: repository, tags: tags, err: err}
 	}
 }
 
-func loadELB(client *SakuraClient) tea.Cmd {
+func deleteRegistryManifest(client *RegistryClient, repository string, tag RegistryTag) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		elbList, err := client.ListELB(ctx)
-		return elbLoadedMsg{elbList: elbList, err: err}
+		if err := client.DeleteManifest(ctx, repository, tag.Digest); err != nil {
+			return actionDoneMsg{err: err}
+		}
+		return actionDoneMsg{
+			message: fmt.Sprintf("Deleted %s:%s (%s)", repository, tag.Name, shortDigest(tag.Digest)),
+			reload:  loadRegistryTags(client, repository),
+		}
 	}
 }
 
-func loadELBDetail(client *SakuraClient, elbID string) tea.Cmd {
+func runMetricsQuery(client *PrometheusClient, query string, window MetricsQueryWindow) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		detail, err := client.GetELBDetail(ctx, elbID)
-		if err != nil {
-			slog.Error("Failed to load ELB detail", slog.Any("error", err))
-			return elbDetailLoadedMsg{err: err}
-		}
-		slog.Info("ELB detail loaded successfully", slog.String("elbID", elbID))
-		return elbDetailLoadedMsg{detail: detail}
+		result, err := client.Query(ctx, query, window, time.Now())
+		return metricsQueryResultMsg{query: query, result: result, err: err}
 	}
 }
 
-func loadGSLB(client *SakuraClient) tea.Cmd {
+func loadAlertRules(client *SakuraClient, storageID string) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		gslbList, err := client.ListGSLB(ctx)
-		return gslbLoadedMsg{gslbList: gslbList, err: err}
+		rules, err := client.ListMonitoringAlertRules(ctx, storageID)
+		return alertRulesLoadedMsg{rules: rules, err: err}
 	}
 }
 
-func loadGSLBDetail(client *SakuraClient, gslbID string) tea.Cmd {
+func createAlertRule(client *SakuraClient, storageID string, spec AlertRuleSpec) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		detail, err := client.GetGSLBDetail(ctx, gslbID)
-		if err != nil {
-			slog.Error("Failed to load GSLB detail", slog.Any("error", err))
-			return gslbDetailLoadedMsg{err: err}
+		if err := client.CreateMonitoringAlertRule(ctx, storageID, spec); err != nil {
+			return actionDoneMsg{err: err}
+		}
+		return actionDoneMsg{
+			message: fmt.Sprintf("Created alert rule %s", spec.Name),
+			reload:  loadAlertRules(client, storageID),
 		}
-		slog.Info("GSLB detail loaded successfully", slog.String("gslbID", gslbID))
-		return gslbDetailLoadedMsg{detail: detail}
 	}
 }
 
-func loadDB(client *SakuraClient) tea.Cmd {
+func updateAlertRule(client *SakuraClient, storageID string, rule MonitoringAlertRule, spec AlertRuleSpec) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		dbList, err := client.ListDB(ctx)
-		return dbLoadedMsg{dbList: dbList, err: err}
+		if err := client.UpdateMonitoringAlertRule(ctx, rule, spec); err != nil {
+			return actionDoneMsg{err: err}
+		}
+		return actionDoneMsg{
+			message: fmt.Sprintf("Updated alert rule %s", spec.Name),
+			reload:  loadAlertRules(client, storageID),
+		}
 	}
 }
 
-func loadDBDetail(client *SakuraClient, dbID string) tea.Cmd {
+func deleteAlertRule(client *SakuraClient, storageID string, rule MonitoringAlertRule) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		detail, err := client.GetDBDetail(ctx, dbID)
-		if err != nil {
-			slog.Error("Failed to load DB detail", slog.Any("error", err))
-			return dbDetailLoadedMsg{err: err}
+		if err := client.DeleteMonitoringAlertRule(ctx, rule); err != nil {
+			return actionDoneMsg{err: err}
+		}
+		return actionDoneMsg{
+			message: fmt.Sprintf("Deleted alert rule %s", getOptString(rule.Name)),
+			reload:  loadAlertRules(client, storageID),
 		}
-		slog.Info("DB detail loaded successfully", slog.String("dbID", dbID))
-		return dbDetailLoadedMsg{detail: detail}
 	}
 }
 
-func loadDisks(client *SakuraClient) tea.Cmd {
+func queryLogs(client *LogQueryClient, query string, start time.Time, seq int) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		disks, err := client.ListDisks(ctx)
-		return disksLoadedMsg{disks: disks, err: err}
+		entries, err := client.Query(ctx, query, start, time.Now(), logQueryLimit)
+		return logEntriesLoadedMsg{entries: entries, seq: seq, err: err}
 	}
 }
 
-func loadDiskDetail(client *SakuraClient, diskID string) tea.Cmd {
+func loadTopology(client *SakuraClient) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		detail, err := client.GetDiskDetail(ctx, diskID)
-		if err != nil {
-			slog.Error("Failed to load disk detail", slog.Any("error", err))
-			return diskDetailLoadedMsg{err: err}
+		topology, err := client.GetTopology(ctx)
+		return topologyLoadedMsg{topology: topology, err: err}
+	}
+}
+
+func saveTopology(path string, topology *Topology, format string) tea.Cmd {
+	return func() tea.Msg {
+		if err := SaveTopology(path, topology, format); err != nil {
+			return actionDoneMsg{err: err}
 		}
-		slog.Info("Disk detail loaded successfully", slog.String("diskID", diskID))
-		return diskDetailLoadedMsg{detail: detail}
+		return actionDoneMsg{message: fmt.Sprintf("Saved %s topology to %s", format, path)}
 	}
 }
 
-func loadArchives(client *SakuraClient) tea.Cmd {
+func observeResource(client *SakuraClient, target ObserveTarget, cfg *Config) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		archives, err := client.ListArchives(ctx)
-		return archivesLoadedMsg{archives: archives, err: err}
+		obs, err := client.ObserveResource(ctx, target, cfg)
+		return observationLoadedMsg{obs: obs, err: err}
 	}
 }
 
-func loadArchiveDetail(client *SakuraClient, archiveID string) tea.Cmd {
+func searchTraces(client *TraceQueryClient, search TraceSearch, start time.Time) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		detail, err := client.GetArchiveDetail(ctx, archiveID)
-		if err != nil {
-			slog.Error("Failed to load archive detail", slog.Any("error", err))
-			return archiveDetailLoadedMsg{err: err}
-		}
-		slog.Info("Archive detail loaded successfully", slog.String("archiveID", archiveID))
-		return archiveDetailLoadedMsg{detail: detail}
+		traces, err := client.Search(ctx, search, start, time.Now(), traceSearchLimit)
+		return tracesFoundMsg{traces: traces, err: err}
 	}
 }
 
-func loadInternet(client *SakuraClient) tea.Cmd {
+func loadTrace(client *TraceQueryClient, traceID string) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		internet, err := client.ListInternet(ctx)
-		return internetLoadedMsg{internet: internet, err: err}
+		trace, err := client.Trace(ctx, traceID)
+		return traceLoadedMsg{trace: trace, err: err}
 	}
 }
 
-func loadInternetDetail(client *SakuraClient, internetID string) tea.Cmd {
+func loadStorageToken(client *SakuraClient, ref MonitoringStorageRef) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		detail, err := client.GetInternetDetail(ctx, internetID)
-		if err != nil {
-			slog.Error("Failed to load internet detail", slog.Any("error", err))
-			return internetDetailLoadedMsg{err: err}
-		}
-		slog.Info("Internet detail loaded successfully", slog.String("internetID", internetID))
-		return internetDetailLoadedMsg{detail: detail}
+		token, err := client.GetMonitoringStorageToken(ctx, ref)
+		return storageTokenLoadedMsg{kind: ref.Kind, token: token, err: err}
 	}
 }
 
-func loadVPCRouters(client *SakuraClient) tea.Cmd {
+func loadStorageKeys(client *SakuraClient, ref MonitoringStorageRef) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		vpcRouters, err := client.ListVPCRouters(ctx)
-		return vpcRoutersLoadedMsg{vpcRouters: vpcRouters, err: err}
+		keys, err := client.ListMonitoringStorageKeys(ctx, ref)
+		return storageKeysLoadedMsg{keys: keys, err: err}
 	}
 }
 
-func loadVPCRouterDetail(client *SakuraClient, vpcRouterID string) tea.Cmd {
+func createStorageKey(client *SakuraClient, ref MonitoringStorageRef, description string) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		detail, err := client.GetVPCRouterDetail(ctx, vpcRouterID)
-		if err != nil {
-			slog.Error("Failed to load VPC router detail", slog.Any("error", err))
-			return vpcRouterDetailLoadedMsg{err: err}
+		key, err := client.CreateMonitoringStorageKey(ctx, ref, description)
+		return storageKeyCreatedMsg{key: key, err: err}
+	}
+}
+
+func deleteStorageKey(client *SakuraClient, ref MonitoringStorageRef, key StorageAccessKey) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		if err := client.DeleteMonitoringStorageKey(ctx, ref, key.UID); err != nil {
+			return actionDoneMsg{err: err}
+		}
+		return actionDoneMsg{
+			message: fmt.Sprintf("Revoked access key %s of %s", key.UID, ref.Title()),
+			reload:  loadStorageKeys(client, ref),
 		}
-		slog.Info("VPC router detail loaded successfully", slog.String("vpcRouterID", vpcRouterID))
-		return vpcRouterDetailLoadedMsg{detail: detail}
 	}
 }
 
-func loadPacketFilters(client *SakuraClient) tea.Cmd {
+// clipboardOutput is the terminal the OSC 52 clipboard sequence is written to
+var clipboardOutput io.Writer = os.Stdout
+
+// copyToClipboard copies text with OSC 52, which also works over SSH. It is called from
+// Update rather than from a Cmd so that the sequence is not written from another goroutine.
+func (m *model) copyToClipboard(text, what string) {
+	termenv.NewOutput(clipboardOutput).Copy(text)
+	m.statusMessage = fmt.Sprintf("Copied %s to the clipboard", what)
+}
+
+func loadAppRunCertificate(client *SakuraClient, clusterID, certificateID string) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		packetFilters, err := client.ListPacketFilters(ctx)
-		return packetFiltersLoadedMsg{packetFilters: packetFilters, err: err}
+		cert, err := client.GetAppRunCertificate(ctx, clusterID, certificateID)
+		return appRunCertificateLoadedMsg{cert: cert, err: err}
 	}
 }
 
-func loadPacketFilterDetail(client *SakuraClient, packetFilterID string) tea.Cmd {
+func createAppRunCertificate(client *SakuraClient, clusterID, name string, upload *CertificateUpload) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		detail, err := client.GetPacketFilterDetail(ctx, packetFilterID)
-		if err != nil {
-			slog.Error("Failed to load packet filter detail", slog.Any("error", err))
-			return packetFilterDetailLoadedMsg{err: err}
+		if _, err := client.CreateAppRunCertificate(ctx, clusterID, name, upload); err != nil {
+			return actionDoneMsg{err: err}
+		}
+		return actionDoneMsg{
+			message: fmt.Sprintf("Uploaded certificate %s (%s)", name, upload.Summary()),
+			reload:  loadAppRunClusterContents(client, clusterID),
 		}
-		slog.Info("Packet filter detail loaded successfully", slog.String("packetFilterID", packetFilterID))
-		return packetFilterDetailLoadedMsg{detail: detail}
 	}
 }
 
-func loadLoadBalancers(client *SakuraClient) tea.Cmd {
+func updateAppRunCertificate(client *SakuraClient, cert AppRunCertificate, name string, upload *CertificateUpload) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		loadBalancers, err := client.ListLoadBalancers(ctx)
-		return loadBalancersLoadedMsg{loadBalancers: loadBalancers, err: err}
+		if err := client.UpdateAppRunCertificate(ctx, cert.ClusterID, cert.ID, name, upload); err != nil {
+			return actionDoneMsg{err: err}
+		}
+		return actionDoneMsg{
+			message: fmt.Sprintf("Replaced certificate %s (%s)", name, upload.Summary()),
+			reload:  loadAppRunClusterContents(client, cert.ClusterID),
+		}
 	}
 }
 
-func loadLoadBalancerDetail(client *SakuraClient, loadBalancerID string) tea.Cmd {
+func deleteAppRunCertificate(client *SakuraClient, cert AppRunCertificate) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		detail, err := client.GetLoadBalancerDetail(ctx, loadBalancerID)
-		if err != nil {
-			slog.Error("Failed to load load balancer detail", slog.Any("error", err))
-			return loadBalancerDetailLoadedMsg{err: err}
+		if err := client.DeleteAppRunCertificate(ctx, cert.ClusterID, cert.ID); err != nil {
+			return actionDoneMsg{err: err}
+		}
+		return actionDoneMsg{
+			message: fmt.Sprintf("Deleted certificate %s", cert.Name),
+			reload:  loadAppRunClusterContents(client, cert.ClusterID),
 		}
-		slog.Info("Load balancer detail loaded successfully", slog.String("loadBalancerID", loadBalancerID))
-		return loadBalancerDetailLoadedMsg{detail: detail}
 	}
 }
 
-func loadNFS(client *SakuraClient) tea.Cmd {
+func loadAppRunClusterForEdit(client *SakuraClient, clusterID string) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		nfsList, err := client.ListNFS(ctx)
-		return nfsLoadedMsg{nfsList: nfsList, err: err}
+		detail, err := client.GetAppRunClusterDetail(ctx, clusterID)
+		return appRunClusterEditLoadedMsg{detail: detail, err: err}
 	}
 }
 
-func loadNFSDetail(client *SakuraClient, nfsID string) tea.Cmd {
+func createAppRunCluster(client *SakuraClient, spec AppRunClusterSpec) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		detail, err := client.GetNFSDetail(ctx, nfsID)
-		if err != nil {
-			slog.Error("Failed to load NFS detail", slog.Any("error", err))
-			return nfsDetailLoadedMsg{err: err}
+		if _, err := client.CreateAppRunCluster(ctx, spec); err != nil {
+			return actionDoneMsg{err: err}
+		}
+		return actionDoneMsg{
+			message: fmt.Sprintf("Created cluster %s (%s)", spec.Name, formatAppRunPorts(spec.Ports)),
+			reload:  loadAppRunClusters(client),
 		}
-		slog.Info("NFS detail loaded successfully", slog.String("nfsID", nfsID))
-		return nfsDetailLoadedMsg{detail: detail}
 	}
 }
 
-func loadSSHKeys(client *SakuraClient) tea.Cmd {
+func updateAppRunCluster(client *SakuraClient, cluster *AppRunClusterDetail, email, servicePrincipalID string) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		sshKeys, err := client.ListSSHKeys(ctx)
-		return sshKeysLoadedMsg{sshKeys: sshKeys, err: err}
+		if err := client.UpdateAppRunCluster(ctx, cluster.ID, email, servicePrincipalID); err != nil {
+			return actionDoneMsg{err: err}
+		}
+		letsEncrypt := "Let's Encrypt disabled"
+		if email != "" {
+			letsEncrypt = "Let's Encrypt: " + email
+		}
+		return actionDoneMsg{
+			message: fmt.Sprintf("Updated cluster %s (%s)", cluster.Name, letsEncrypt),
+			reload:  loadAppRunClusters(client),
+		}
 	}
 }
 
-func loadSSHKeyDetail(client *SakuraClient, sshKeyID string) tea.Cmd {
+func deleteAppRunCluster(client *SakuraClient, cluster AppRunCluster) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		detail, err := client.GetSSHKeyDetail(ctx, sshKeyID)
-		if err != nil {
-			slog.Error("Failed to load SSH key detail", slog.Any("error", err))
-			return sshKeyDetailLoadedMsg{err: err}
+		if err := client.DeleteAppRunCluster(ctx, cluster.ID); err != nil {
+			return actionDoneMsg{err: err}
+		}
+		return actionDoneMsg{
+			message: fmt.Sprintf("Deleted cluster %s", cluster.Name),
+			reload:  loadAppRunClusters(client),
 		}
-		slog.Info("SSH key detail loaded successfully", slog.String("sshKeyID", sshKeyID))
-		return sshKeyDetailLoadedMsg{detail: detail}
 	}
 }
 
-func loadAutoBackups(client *SakuraClient) tea.Cmd {
+func loadAppRunWorkerServiceClasses(client *SakuraClient) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		autoBackups, err := client.ListAutoBackups(ctx)
-		return autoBackupsLoadedMsg{autoBackups: autoBackups, err: err}
+		classes, err := client.ListAppRunWorkerServiceClasses(ctx)
+		return appRunServiceClassesLoadedMsg{classes: classes, err: err}
 	}
 }
 
-func loadAutoBackupDetail(client *SakuraClient, autoBackupID string) tea.Cmd {
+func loadAppRunLBServiceClasses(client *SakuraClient, asg AppRunASG) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		detail, err := client.GetAutoBackupDetail(ctx, autoBackupID)
-		if err != nil {
-			slog.Error("Failed to load auto backup detail", slog.Any("error", err))
-			return autoBackupDetailLoadedMsg{err: err}
-		}
-		slog.Info("Auto backup detail loaded successfully", slog.String("autoBackupID", autoBackupID))
-		return autoBackupDetailLoadedMsg{detail: detail}
+		classes, err := client.ListAppRunLBServiceClasses(ctx)
+		return appRunServiceClassesLoadedMsg{asg: &asg, classes: classes, err: err}
 	}
 }
 
-func loadSimpleMonitors(client *SakuraClient) tea.Cmd {
+func createAppRunASG(client *SakuraClient, clusterID string, spec AppRunASGSpec) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		simpleMonitors, err := client.ListSimpleMonitors(ctx)
-		return simpleMonitorsLoadedMsg{simpleMonitors: simpleMonitors, err: err}
+		if _, err := client.CreateAppRunASG(ctx, clusterID, spec); err != nil {
+			return actionDoneMsg{err: err}
+		}
+		return actionDoneMsg{
+			message: fmt.Sprintf("Created ASG %s (%d-%d nodes)", spec.Name, spec.MinNodes, spec.MaxNodes),
+			reload:  loadAppRunClusterContents(client, clusterID),
+		}
 	}
 }
 
-func loadSimpleMonitorDetail(client *SakuraClient, simpleMonitorID string) tea.Cmd {
+func deleteAppRunASG(client *SakuraClient, asg AppRunASG) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		detail, err := client.GetSimpleMonitorDetail(ctx, simpleMonitorID)
-		if err != nil {
-			slog.Error("Failed to load simple monitor detail", slog.Any("error", err))
-			return simpleMonitorDetailLoadedMsg{err: err}
+		if err := client.DeleteAppRunASG(ctx, asg.ClusterID, asg.ID); err != nil {
+			return actionDoneMsg{err: err}
+		}
+		return actionDoneMsg{
+			message: fmt.Sprintf("Deleted ASG %s", asg.Name),
+			reload:  loadAppRunClusterContents(client, asg.ClusterID),
 		}
-		slog.Info("Simple monitor detail loaded successfully", slog.String("simpleMonitorID", simpleMonitorID))
-		return simpleMonitorDetailLoadedMsg{detail: detail}
 	}
 }
 
-func loadBridges(client *SakuraClient) tea.Cmd {
+func createAppRunLB(client *SakuraClient, asg AppRunASG, spec AppRunLBSpec) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		bridges, err := client.ListBridges(ctx)
-		return bridgesLoadedMsg{bridges: bridges, err: err}
+		if _, err := client.CreateAppRunLB(ctx, asg.ClusterID, asg.ID, spec); err != nil {
+			return actionDoneMsg{err: err}
+		}
+		return actionDoneMsg{
+			message: fmt.Sprintf("Created LB %s", spec.Name),
+			reload:  loadAppRunASGDetail(client, asg.ClusterID, asg.ID),
+		}
 	}
 }
 
-func loadBridgeDetail(client *SakuraClient, bridgeID string) tea.Cmd {
+func deleteAppRunLB(client *SakuraClient, asg AppRunASG, lb AppRunLBDetail) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		detail, err := client.GetBridgeDetail(ctx, bridgeID)
-		if err != nil {
-			slog.Error("Failed to load bridge detail", slog.Any("error", err))
-			return bridgeDetailLoadedMsg{err: err}
+		if err := client.DeleteAppRunLB(ctx, asg.ClusterID, asg.ID, lb.ID); err != nil {
+			return actionDoneMsg{err: err}
+		}
+		return actionDoneMsg{
+			message: fmt.Sprintf("Deleted LB %s", lb.Name),
+			reload:  loadAppRunASGDetail(client, asg.ClusterID, asg.ID),
 		}
-		slog.Info("Bridge detail loaded successfully", slog.String("bridgeID", bridgeID))
-		return bridgeDetailLoadedMsg{detail: detail}
 	}
 }
 
-func loadContainerRegistries(client *SakuraClient) tea.Cmd {
+func loadAppRunContainerPlacement(client *SakuraClient, app AppRunApplication) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		containerRegistries, err := client.ListContainerRegistries(ctx)
-		return containerRegistriesLoadedMsg{containerRegistries: containerRegistries, err: err}
+		placement, err := client.GetAppRunContainerPlacement(ctx, app)
+		return appRunContainerPlacementLoadedMsg{placement: placement, err: err}
 	}
 }
 
-func loadContainerRegistryDetail(client *SakuraClient, containerRegistryID string) tea.Cmd {
+func loadAppRunVersionSpec(client *SakuraClient, app AppRunApplication, version int32) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		detail, err := client.GetContainerRegistryDetail(ctx, containerRegistryID)
-		if err != nil {
-			slog.Error("Failed to load container registry detail", slog.Any("error", err))
-			return containerRegistryDetailLoadedMsg{err: err}
-		}
-		slog.Info("Container registry detail loaded successfully", slog.String("containerRegistryID", containerRegistryID))
-		return containerRegistryDetailLoadedMsg{detail: detail}
+		detail, err := client.GetAppRunVersionDetail(ctx, app.ID, version)
+		return appRunVersionSpecLoadedMsg{app: app, detail: detail, err: err}
 	}
 }
 
-func loadAppRunClusters(client *SakuraClient) tea.Cmd {
+// createAppRunVersion creates a new version and optionally activates it
+func createAppRunVersion(client *SakuraClient, app AppRunApplication, spec AppRunVersionSpec, activate bool) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		clusters, err := client.ListAppRunClusters(ctx)
-		return appRunClustersLoadedMsg{clusters: clusters, err: err}
+		version, err := client.CreateAppRunVersion(ctx, app.ID, spec)
+		if err != nil {
+			return appRunVersionCreatedMsg{app: app, err: err}
+		}
+		if activate {
+			if err := client.ActivateAppRunVersion(ctx, app.ID, version); err != nil {
+				return appRunVersionCreatedMsg{app: app, version: version, err: fmt.Errorf("created v%d but failed to activate: %w", version, err)}
+			}
+		}
+		return appRunVersionCreatedMsg{app: app, version: version, activated: activate}
 	}
 }
 
-func loadAppRunClusterDetail(client *SakuraClient, clusterID string) tea.Cmd {
+func loadAppRunVersionComparison(client *SakuraClient, app AppRunApplication, base, target int32) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		detail, err := client.GetAppRunClusterDetail(ctx, clusterID)
+		comparison, err := client.CompareAppRunVersions(ctx, app.ID, base, target)
 		if err != nil {
-			slog.Error("Failed to load AppRun cluster detail", slog.Any("error", err))
-			return appRunClusterDetailLoadedMsg{err: err}
+			return appRunVersionComparisonLoadedMsg{err: err}
 		}
-		slog.Info("AppRun cluster detail loaded successfully", slog.String("clusterID", clusterID))
-		return appRunClusterDetailLoadedMsg{detail: detail}
+		comparison.AppName = app.Name
+		return appRunVersionComparisonLoadedMsg{comparison: comparison}
 	}
 }
 
-func loadAppRunASGs(client *SakuraClient, clusterID string) tea.Cmd {
+func activateAppRunVersion(client *SakuraClient, app AppRunApplication, version int32) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		asgs, err := client.ListAppRunASGs(ctx, clusterID)
-		return appRunASGsLoadedMsg{asgs: asgs, clusterID: clusterID, err: err}
+		err := client.ActivateAppRunVersion(ctx, app.ID, version)
+		return appRunVersionActivatedMsg{app: app, version: version, err: err}
 	}
 }
 
-func loadAppRunLBs(client *SakuraClient, clusterID, asgID string) tea.Cmd {
+func deleteAppRunVersion(client *SakuraClient, app AppRunApplication, version int32) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		lbs, err := client.ListAppRunLBs(ctx, clusterID, asgID)
-		return appRunLBsLoadedMsg{lbs: lbs, clusterID: clusterID, asgID: asgID, err: err}
+		if err := client.DeleteAppRunVersion(ctx, app.ID, version); err != nil {
+			return actionDoneMsg{err: err}
+		}
+		return actionDoneMsg{
+			message: fmt.Sprintf("Deleted v%d", version),
+			reload:  loadAppRunVersions(client, app.ID, app.ClusterID, app.ActiveVersion),
+		}
 	}
 }
 
-func loadAppRunClusterContents(client *SakuraClient, clusterID string) tea.Cmd {
+// checkAppRunRollout fetches how many nodes run the version being rolled out
+func checkAppRunRollout(client *SakuraClient, rollout appRunRollout) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		asgs, err := client.ListAppRunASGs(ctx, clusterID)
+		status, err := client.GetAppRunApplicationStatus(ctx, rollout.app.ID)
 		if err != nil {
-			return appRunClusterContentsLoadedMsg{err: err}
+			return appRunRolloutStatusMsg{version: rollout.version, err: err}
 		}
-		apps, err := client.ListAppRunApplications(ctx, clusterID)
+		versions, err := client.ListAppRunVersions(ctx, rollout.app.ID, rollout.app.ClusterID, status.ActiveVersion)
 		if err != nil {
-			return appRunClusterContentsLoadedMsg{err: err}
+			return appRunRolloutStatusMsg{version: rollout.version, err: err}
 		}
-		return appRunClusterContentsLoadedMsg{asgs: asgs, apps: apps, clusterID: clusterID}
+		msg := appRunRolloutStatusMsg{
+			version:       rollout.version,
+			activeVersion: status.ActiveVersion,
+			desiredCount:  status.DesiredCount,
+		}
+		for _, v := range versions {
+			if v.Version == rollout.version {
+				msg.activeNodes = v.ActiveNodeCount
+			}
+		}
+		return msg
 	}
 }
 
-func loadAppRunVersions(client *SakuraClient, applicationID, clusterID string, activeVersion int32) tea.Cmd {
+func setAppRunWorkerNodeDraining(client *SakuraClient, node AppRunWorkerNode, draining bool) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		versions, err := client.ListAppRunVersions(ctx, applicationID, clusterID, activeVersion)
-		return appRunVersionsLoadedMsg{versions: versions, applicationID: applicationID, clusterID: clusterID, err: err}
+		err := client.SetAppRunWorkerNodeDraining(ctx, node, draining)
+		return appRunDrainingSetMsg{node: node, draining: draining, err: err}
 	}
 }
 
-func loadAppRunLBDetail(client *SakuraClient, clusterID, asgID, lbID string) tea.Cmd {
+// checkAppRunDrain refreshes the worker nodes of the ASG of the node being drained
+func checkAppRunDrain(client *SakuraClient, drain appRunDrain) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		detail, err := client.GetAppRunLBDetail(ctx, clusterID, asgID, lbID)
-		if err != nil {
-			slog.Error("Failed to load AppRun LB detail", slog.Any("error", err))
-			return appRunLBDetailLoadedMsg{err: err}
-		}
-		slog.Info("AppRun LB detail loaded successfully", slog.String("lbID", lbID))
-		return appRunLBDetailLoadedMsg{detail: detail}
+		nodes, err := client.ListAppRunWorkerNodesWithContainers(ctx, drain.node.ClusterID, drain.node.ASGID)
+		return appRunDrainStatusMsg{node: drain.node, nodes: nodes, err: err}
 	}
 }
 
-func loadAppRunASGDetail(client *SakuraClient, clusterID, asgID string) tea.Cmd {
+func bootNFS(client *SakuraClient, nfsID string) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		detail, err := client.GetAppRunASGDetail(ctx, clusterID, asgID)
-		if err != nil {
-			slog.Error("Failed to load AppRun ASG detail", slog.Any("error", err))
-			return appRunASGDetailLoadedMsg{err: err}
+		if err := client.BootNFS(ctx, nfsID); err != nil {
+			return actionDoneMsg{err: err}
 		}
-		// Also load worker nodes
-		workerNodes, err := client.ListAppRunWorkerNodes(ctx, clusterID, asgID)
-		if err != nil {
-			slog.Error("Failed to load AppRun Worker Nodes", slog.Any("error", err))
-			return appRunASGDetailLoadedMsg{detail: detail, err: err}
+		return actionDoneMsg{
+			message: "NFS boot requested",
+			reload:  loadNFSDetail(client, nfsID),
 		}
-		// Also load LBs and their details
-		lbs, err := client.ListAppRunLBs(ctx, clusterID, asgID)
-		if err != nil {
-			slog.Error("Failed to load AppRun LBs", slog.Any("error", err))
-			// Continue without LBs
-		} else {
-			// Fetch detail for each LB
-			for _, lb := range lbs {
-				lbDetail, err := client.GetAppRunLBDetail(ctx, clusterID, asgID, lb.ID)
-				if err != nil {
-					slog.Error("Failed to load AppRun LB detail", slog.String("lbID", lb.ID), slog.Any("error", err))
-					continue
-				}
-				detail.LoadBalancers = append(detail.LoadBalancers, *lbDetail)
-			}
+	}
+}
+
+func shutdownNFS(client *SakuraClient, nfsID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		if err := client.ShutdownNFS(ctx, nfsID); err != nil {
+			return actionDoneMsg{err: err}
+		}
+		return actionDoneMsg{
+			message: "NFS shutdown requested",
+			reload:  loadNFSDetail(client, nfsID),
 		}
-		slog.Info("AppRun ASG detail loaded successfully", slog.String("asgID", asgID))
-		return appRunASGDetailLoadedMsg{detail: detail, workerNodes: workerNodes}
 	}
 }
 
-func loadMonitoringLogStorages(client *SakuraClient) tea.Cmd {
+func loadServers(client *SakuraClient) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		storages, err := client.ListMonitoringLogStorages(ctx)
-		return monitoringLogStoragesLoadedMsg{storages: storages, err: err}
+		servers, err := client.ListServers(ctx)
+		return serversLoadedMsg{servers: servers, err: err}
 	}
 }
 
-func loadMonitoringLogStorageDetail(client *SakuraClient, resourceID string) tea.Cmd {
+func loadSwitches(client *SakuraClient) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		detail, err := client.GetMonitoringLogStorageDetail(ctx, resourceID)
+		switches, err := client.ListSwitches(ctx)
+		return switchesLoadedMsg{switches: switches, err: err}
+	}
+}
+
+func loadAuthStatus(client *SakuraClient) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		authStatus, err := client.GetAuthStatus(ctx)
 		if err != nil {
-			slog.Error("Failed to load log storage detail", slog.Any("error", err))
-			return monitoringLogStorageDetailLoadedMsg{err: err}
+			slog.Error("Failed to load auth status", slog.Any("error", err))
+			return authStatusLoadedMsg{err: err}
 		}
-		slog.Info("Log storage detail loaded successfully", slog.String("resourceID", resourceID))
-		return monitoringLogStorageDetailLoadedMsg{detail: detail}
+		slog.Info("Auth status loaded successfully", slog.String("account", authStatus.AccountName))
+		return authStatusLoadedMsg{accountName: authStatus.AccountName}
 	}
 }
 
-func loadMonitoringMetricsStorages(client *SakuraClient) tea.Cmd {
+func loadServerDetail(client *SakuraClient, serverID string) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		storages, err := client.ListMonitoringMetricsStorages(ctx)
-		return monitoringMetricsStoragesLoadedMsg{storages: storages, err: err}
+		detail, err := client.GetServerDetail(ctx, serverID)
+		if err != nil {
+			slog.Error("Failed to load server detail", slog.Any("error", err))
+			return serverDetailLoadedMsg{err: err}
+		}
+		slog.Info("Server detail loaded successfully", slog.String("serverID", serverID))
+		return serverDetailLoadedMsg{detail: detail}
 	}
 }
 
-func loadMonitoringMetricsStorageDetail(client *SakuraClient, resourceID string) tea.Cmd {
+func loadSwitchDetail(client *SakuraClient, switchID string) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		detail, err := client.GetMonitoringMetricsStorageDetail(ctx, resourceID)
+		detail, err := client.GetSwitchDetail(ctx, switchID)
 		if err != nil {
-			slog.Error("Failed to load metrics storage detail", slog.Any("error", err))
-			return monitoringMetricsStorageDetailLoadedMsg{err: err}
+			slog.Error("Failed to load switch detail", slog.Any("error", err))
+			return switchDetailLoadedMsg{err: err}
 		}
-		slog.Info("Metrics storage detail loaded successfully", slog.String("resourceID", resourceID))
-		return monitoringMetricsStorageDetailLoadedMsg{detail: detail}
+		slog.Info("Switch detail loaded successfully", slog.String("switchID", switchID))
+		return switchDetailLoadedMsg{detail: detail}
 	}
 }
 
-func loadMonitoringTraceStorages(client *SakuraClient) tea.Cmd {
+func loadDNS(client *SakuraClient) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		storages, err := client.ListMonitoringTraceStorages(ctx)
-		return monitoringTraceStoragesLoadedMsg{storages: storages, err: err}
+		dnsList, err := client.ListDNS(ctx)
+		return dnsLoadedMsg{dnsList: dnsList, err: err}
 	}
 }
 
-func loadMonitoringTraceStorageDetail(client *SakuraClient, resourceID string) tea.Cmd {
+func loadDNSDetail(client *SakuraClient, dnsID string) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		detail, err := client.GetMonitoringTraceStorageDetail(ctx, resourceID)
+		detail, err := client.GetDNSDetail(ctx, dnsID)
 		if err != nil {
-			slog.Error("Failed to load trace storage detail", slog.Any("error", err))
-			return monitoringTraceStorageDetailLoadedMsg{err: err}
+			slog.Error("Failed to load DNS detail", slog.Any("error", err))
+			return dnsDetailLoadedMsg{err: err}
 		}
-		slog.Info("Trace storage detail loaded successfully", slog.String("resourceID", resourceID))
-		return monitoringTraceStorageDetailLoadedMsg{detail: detail}
+		slog.Info("DNS detail loaded successfully", slog.String("dnsID", dnsID))
+		return dnsDetailLoadedMsg{detail: detail}
 	}
 }
 
-func InitialModel(client *SakuraClient, defaultZone string) model {
-	zones := []string{"tk1a", "tk1b", "is1a", "is1b", "is1c"}
-
-	cursor := 0
-	for i, zone := range zones {
-		if zone == defaultZone {
-			cursor = i
-			break
-		}
+func loadELB(client *SakuraClient) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		elbList, err := client.ListELB(ctx)
+		return elbLoadedMsg{elbList: elbList, err: err}
 	}
+}
 
-	// Create list with custom delegate
-	delegate := resourceDelegate{}
-	resourceList := list.New([]list.Item{}, delegate, 0, 0)
-	resourceList.SetShowTitle(false)
-	resourceList.SetShowStatusBar(false)
-	resourceList.SetFilteringEnabled(false) // Disable built-in filtering
+func loadELBDetail(client *SakuraClient, elbID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		detail, err := client.GetELBDetail(ctx, elbID)
+		if err != nil {
+			slog.Error("Failed to load ELB detail", slog.Any("error", err))
+			return elbDetailLoadedMsg{err: err}
+		}
+		slog.Info("ELB detail loaded successfully", slog.String("elbID", elbID))
+		return elbDetailLoadedMsg{detail: detail}
+	}
+}
 
-	// Initialize search input
-	ti := textinput.New()
-	ti.Placeholder = "Search..."
-	ti.CharLimit = 50
+func loadGSLB(client *SakuraClient) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		gslbList, err := client.ListGSLB(ctx)
+		return gslbLoadedMsg{gslbList: gslbList, err: err}
+	}
+}
 
-	return model{
-		client:       client,
-		list:         resourceList,
-		zones:        zones,
-		currentZone:  defaultZone,
-		cursor:       cursor,
-		loading:      true,
-		searchInput:  ti,
-		resourceType: ResourceTypeServer,
+func loadGSLBDetail(client *SakuraClient, gslbID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		detail, err := client.GetGSLBDetail(ctx, gslbID)
+		if err != nil {
+			slog.Error("Failed to load GSLB detail", slog.Any("error", err))
+			return gslbDetailLoadedMsg{err: err}
+		}
+		slog.Info("GSLB detail loaded successfully", slog.String("gslbID", gslbID))
+		return gslbDetailLoadedMsg{detail: detail}
 	}
 }
 
-func (m model) Init() tea.Cmd {
-	slog.Info("Initializing TUI model", slog.String("zone", m.currentZone))
-	return tea.Batch(
-		loadServers(m.client),
-		loadAuthStatus(m.client),
-	)
+func loadDB(client *SakuraClient) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		dbList, err := client.ListDB(ctx)
+		return dbLoadedMsg{dbList: dbList, err: err}
+	}
 }
 
-func (m *model) performSearch() {
-	m.searchMatches = []int{}
-	m.currentMatch = -1
+func loadDBDetail(client *SakuraClient, dbID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		detail, err := client.GetDBDetail(ctx, dbID)
+		if err != nil {
+			slog.Error("Failed to load DB detail", slog.Any("error", err))
+			return dbDetailLoadedMsg{err: err}
+		}
+		slog.Info("DB detail loaded successfully", slog.String("dbID", dbID))
+		return dbDetailLoadedMsg{detail: detail}
+	}
+}
 
-	query := strings.ToLower(m.searchQuery)
-	if query == "" {
-		return
+func loadDisks(client *SakuraClient) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		disks, err := client.ListDisks(ctx)
+		return disksLoadedMsg{disks: disks, err: err}
 	}
+}
 
-	items := m.list.Items()
-	for i, item := range items {
-		// Handle Server
-		if server, ok := item.(Server); ok {
-			if strings.Contains(strings.ToLower(server.Name), query) ||
-				strings.Contains(strings.ToLower(server.ID), query) {
-				m.searchMatches = append(m.searchMatches, i)
-			}
-			continue
-		}
-		// Handle Switch
-		if sw, ok := item.(Switch); ok {
-			if strings.Contains(strings.ToLower(sw.Name), query) ||
-				strings.Contains(strings.ToLower(sw.ID), query) ||
-				strings.Contains(strings.ToLower(sw.Desc), query) ||
-				strings.Contains(strings.ToLower(sw.DefaultRoute), query) {
-				m.searchMatches = append(m.searchMatches, i)
-			}
-			continue
-		}
-		// Handle DNS
-		if dns, ok := item.(DNS); ok {
-			if strings.Contains(strings.ToLower(dns.Name), query) ||
-				strings.Contains(strings.ToLower(dns.ID), query) ||
-				strings.Contains(strings.ToLower(dns.Desc), query) {
-				m.searchMatches = append(m.searchMatches, i)
-			}
-			continue
-		}
-		// Handle ELB
-		if elb, ok := item.(ELB); ok {
-			if strings.Contains(strings.ToLower(elb.Name), query) ||
-				strings.Contains(strings.ToLower(elb.ID), query) ||
-				strings.Contains(strings.ToLower(elb.Desc), query) ||
-				strings.Contains(strings.ToLower(elb.VIP), query) {
-				m.searchMatches = append(m.searchMatches, i)
-			}
-			continue
+func loadDiskDetail(client *SakuraClient, diskID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		detail, err := client.GetDiskDetail(ctx, diskID)
+		if err != nil {
+			slog.Error("Failed to load disk detail", slog.Any("error", err))
+			return diskDetailLoadedMsg{err: err}
 		}
-		// Handle GSLB
-		if gslb, ok := item.(GSLB); ok {
-			if strings.Contains(strings.ToLower(gslb.Name), query) ||
-				strings.Contains(strings.ToLower(gslb.ID), query) ||
-				strings.Contains(strings.ToLower(gslb.Desc), query) ||
-				strings.Contains(strings.ToLower(gslb.FQDN), query) {
-				m.searchMatches = append(m.searchMatches, i)
-			}
-			continue
+		slog.Info("Disk detail loaded successfully", slog.String("diskID", diskID))
+		return diskDetailLoadedMsg{detail: detail}
+	}
+}
+
+func loadArchives(client *SakuraClient) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		archives, err := client.ListArchives(ctx)
+		return archivesLoadedMsg{archives: archives, err: err}
+	}
+}
+
+func loadArchiveDetail(client *SakuraClient, archiveID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		detail, err := client.GetArchiveDetail(ctx, archiveID)
+		if err != nil {
+			slog.Error("Failed to load archive detail", slog.Any("error", err))
+			return archiveDetailLoadedMsg{err: err}
 		}
-		// Handle DB
-		if db, ok := item.(DB); ok {
-			if strings.Contains(strings.ToLower(db.Name), query) ||
-				strings.Contains(strings.ToLower(db.ID), query) ||
-				strings.Contains(strings.ToLower(db.Desc), query) ||
-				strings.Contains(strings.ToLower(db.DBType), query) {
-				m.searchMatches = append(m.searchMatches, i)
-			}
+		slog.Info("Archive detail loaded successfully", slog.String("archiveID", archiveID))
+		return archiveDetailLoadedMsg{detail: detail}
+	}
+}
+
+func loadInternet(client *SakuraClient) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		internet, err := client.ListInternet(ctx)
+		return internetLoadedMsg{internet: internet, err: err}
+	}
+}
+
+func loadInternetDetail(client *SakuraClient, internetID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		detail, err := client.GetInternetDetail(ctx, internetID)
+		if err != nil {
+			slog.Error("Failed to load internet detail", slog.Any("error", err))
+			return internetDetailLoadedMsg{err: err}
 		}
+		slog.Info("Internet detail loaded successfully", slog.String("internetID", internetID))
+		return internetDetailLoadedMsg{detail: detail}
 	}
+}
 
-	slog.Info("Search performed", slog.String("query", query), slog.Int("matches", len(m.searchMatches)))
+func loadVPCRouters(client *SakuraClient) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		vpcRouters, err := client.ListVPCRouters(ctx)
+		return vpcRoutersLoadedMsg{vpcRouters: vpcRouters, err: err}
+	}
+}
 
-	// Jump to first match
-	if len(m.searchMatches) > 0 {
-		m.currentMatch = 0
-		m.list.Select(m.searchMatches[0])
+func loadVPCRouterDetail(client *SakuraClient, vpcRouterID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		detail, err := client.GetVPCRouterDetail(ctx, vpcRouterID)
+		if err != nil {
+			slog.Error("Failed to load VPC router detail", slog.Any("error", err))
+			return vpcRouterDetailLoadedMsg{err: err}
+		}
+		slog.Info("VPC router detail loaded successfully", slog.String("vpcRouterID", vpcRouterID))
+		return vpcRouterDetailLoadedMsg{detail: detail}
 	}
 }
 
-func (m *model) nextMatch() {
-	if len(m.searchMatches) == 0 {
-		return
+func loadPacketFilters(client *SakuraClient) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		packetFilters, err := client.ListPacketFilters(ctx)
+		return packetFiltersLoadedMsg{packetFilters: packetFilters, err: err}
 	}
-	m.currentMatch = (m.currentMatch + 1) % len(m.searchMatches)
-	m.list.Select(m.searchMatches[m.currentMatch])
-	slog.Info("Next match", slog.Int("match", m.currentMatch+1), slog.Int("total", len(m.searchMatches)))
 }
 
-func (m *model) prevMatch() {
-	if len(m.searchMatches) == 0 {
-		return
+func loadPacketFilterDetail(client *SakuraClient, packetFilterID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		detail, err := client.GetPacketFilterDetail(ctx, packetFilterID)
+		if err != nil {
+			slog.Error("Failed to load packet filter detail", slog.Any("error", err))
+			return packetFilterDetailLoadedMsg{err: err}
+		}
+		slog.Info("Packet filter detail loaded successfully", slog.String("packetFilterID", packetFilterID))
+		return packetFilterDetailLoadedMsg{detail: detail}
 	}
-	m.currentMatch--
-	if m.currentMatch < 0 {
-		m.currentMatch = len(m.searchMatches) - 1
+}
+
+func loadLoadBalancers(client *SakuraClient) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		loadBalancers, err := client.ListLoadBalancers(ctx)
+		return loadBalancersLoadedMsg{loadBalancers: loadBalancers, err: err}
 	}
-	m.list.Select(m.searchMatches[m.currentMatch])
-	slog.Info("Previous match", slog.Int("match", m.currentMatch+1), slog.Int("total", len(m.searchMatches)))
 }
 
-func (m model) getTableHeader() string {
-	switch m.resourceType {
-	case ResourceTypeServer:
-		return fmt.Sprintf("  %-40s %-20s %s", "Name", "ID", "Status")
-	case ResourceTypeSwitch:
-		return fmt.Sprintf("  %-40s %-20s %s", "Name", "ID", "Servers")
-	case ResourceTypeDNS:
-		return fmt.Sprintf("  %-40s %-20s %s", "Name", "ID", "Records")
-	case ResourceTypeELB:
-		return fmt.Sprintf("  %-40s %-20s %-15s %s", "Name", "ID", "VIP", "Servers")
-	case ResourceTypeGSLB:
-		return fmt.Sprintf("  %-40s %-20s %s", "Name", "ID", "FQDN")
-	case ResourceTypeDB:
-		return fmt.Sprintf("  %-40s %-20s %-10s %s", "Name", "ID", "Type", "Status")
-	case ResourceTypeDisk:
-		return fmt.Sprintf("  %-40s %-20s %6s %-10s %s", "Name", "ID", "Size", "Connection", "Server")
-	case ResourceTypeArchive:
-		return fmt.Sprintf("  %-40s %-20s %6s %-10s %s", "Name", "ID", "Size", "Scope", "Availability")
-	case ResourceTypeInternet:
-		return fmt.Sprintf("  %-40s %-20s %10s %s", "Name", "ID", "Bandwidth", "Switch ID")
-	case ResourceTypeVPCRouter:
-		return fmt.Sprintf("  %-40s %-20s %-10s %-8s %s", "Name", "ID", "Plan", "Version", "Status")
-	case ResourceTypePacketFilter:
-		return fmt.Sprintf("  %-40s %-20s %s", "Name", "ID", "Rules")
-	case ResourceTypeLoadBalancer:
-		return fmt.Sprintf("  %-40s %-20s %s  %s", "Name", "ID", "VIPs", "Status")
-	case ResourceTypeNFS:
-		return fmt.Sprintf("  %-40s %-20s %-15s %s", "Name", "ID", "Switch", "Status")
-	case ResourceTypeSSHKey:
-		return fmt.Sprintf("  %-40s %-20s %s", "Name", "ID", "Fingerprint")
-	case ResourceTypeAutoBackup:
-		return fmt.Sprintf("  %-40s %-20s %s  %s", "Name", "ID", "Max", "Weekdays")
-	case ResourceTypeSimpleMonitor:
-		return fmt.Sprintf("  %-40s %-20s %-10s %s", "Name", "ID", "Protocol", "Enabled")
-	case ResourceTypeBridge:
-		return fmt.Sprintf("  %-40s %-20s %-10s %s", "Name", "ID", "Region", "Switches")
-	case ResourceTypeContainerRegistry:
-		return fmt.Sprintf("  %-40s %-20s %-12s %s", "Name", "ID", "AccessLevel", "Users")
-	case ResourceTypeAppRunDedicated:
-		switch m.appRunDrilldownLevel {
-		case 0:
-			return fmt.Sprintf("  %-40s %-36s %s", "Name", "ID", "ASG")
-		case 1:
-			return "  [ASG] + [Applications]"
-		case 2:
-			if m.appRunSelectedAppID != "" {
-				// Version list
-				return fmt.Sprintf("  %-8s %5s  %s", "Version", "Nodes", "Image")
-			}
-			// LB list
-			return fmt.Sprintf("  %-40s %-36s %s", "Name", "ID", "ServiceClass")
+func loadLoadBalancerDetail(client *SakuraClient, loadBalancerID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		detail, err := client.GetLoadBalancerDetail(ctx, loadBalancerID)
+		if err != nil {
+			slog.Error("Failed to load load balancer detail", slog.Any("error", err))
+			return loadBalancerDetailLoadedMsg{err: err}
 		}
-		return ""
-	default:
-		return ""
+		slog.Info("Load balancer detail loaded successfully", slog.String("loadBalancerID", loadBalancerID))
+		return loadBalancerDetailLoadedMsg{detail: detail}
 	}
 }
 
-func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	switch msg := msg.(type) {
-	case tea.WindowSizeMsg:
-		m.windowHeight = msg.Height
-		m.windowWidth = msg.Width
-		slog.Debug("Window size updated", slog.Int("height", msg.Height), slog.Int("width", msg.Width))
+func loadNFS(client *SakuraClient) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		nfsList, err := client.ListNFS(ctx)
+		return nfsLoadedMsg{nfsList: nfsList, err: err}
+	}
+}
 
-		// Update list size - account for header area
-		headerHeight := 8 // Title + Account + Zone + spacing
-		if m.accountName == "" {
-			headerHeight--
+func loadNFSDetail(client *SakuraClient, nfsID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		detail, err := client.GetNFSDetail(ctx, nfsID)
+		if err != nil {
+			slog.Error("Failed to load NFS detail", slog.Any("error", err))
+			return nfsDetailLoadedMsg{err: err}
 		}
-		if m.searchMode {
-			headerHeight++ // Add line for search input
+		slog.Info("NFS detail loaded successfully", slog.String("nfsID", nfsID))
+		return nfsDetailLoadedMsg{detail: detail}
+	}
+}
+
+func loadSSHKeys(client *SakuraClient) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		sshKeys, err := client.ListSSHKeys(ctx)
+		return sshKeysLoadedMsg{sshKeys: sshKeys, err: err}
+	}
+}
+
+func loadSSHKeyDetail(client *SakuraClient, sshKeyID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		detail, err := client.GetSSHKeyDetail(ctx, sshKeyID)
+		if err != nil {
+			slog.Error("Failed to load SSH key detail", slog.Any("error", err))
+			return sshKeyDetailLoadedMsg{err: err}
 		}
-		m.list.SetSize(msg.Width, msg.Height-headerHeight)
+		slog.Info("SSH key detail loaded successfully", slog.String("sshKeyID", sshKeyID))
+		return sshKeyDetailLoadedMsg{detail: detail}
+	}
+}
 
-		// Update detail viewport size if in detail mode
-		if m.detailMode {
-			m.detailViewport.Width = msg.Width
-			m.detailViewport.Height = msg.Height - 10
+func loadAutoBackups(client *SakuraClient) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		autoBackups, err := client.ListAutoBackups(ctx)
+		return autoBackupsLoadedMsg{autoBackups: autoBackups, err: err}
+	}
+}
+
+func loadAutoBackupDetail(client *SakuraClient, autoBackupID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		detail, err := client.GetAutoBackupDetail(ctx, autoBackupID)
+		if err != nil {
+			slog.Error("Failed to load auto backup detail", slog.Any("error", err))
+			return autoBackupDetailLoadedMsg{err: err}
 		}
-		return m, nil
+		slog.Info("Auto backup detail loaded successfully", slog.String("autoBackupID", autoBackupID))
+		return autoBackupDetailLoadedMsg{detail: detail}
+	}
+}
 
-	case tea.KeyMsg:
-		// Handle detail mode
-		if m.detailMode {
-			switch msg.String() {
-			case "esc", "q":
-				m.detailMode = false
-				m.serverDetail = nil
-				m.switchDetail = nil
-				m.dnsDetail = nil
-				m.elbDetail = nil
-				m.gslbDetail = nil
-				m.dbDetail = nil
-				m.diskDetail = nil
-				m.archiveDetail = nil
-				m.internetDetail = nil
-				m.vpcRouterDetail = nil
-				m.packetFilterDetail = nil
-				m.loadBalancerDetail = nil
-				m.nfsDetail = nil
-				m.sshKeyDetail = nil
-				m.autoBackupDetail = nil
-				m.simpleMonitorDetail = nil
-				m.bridgeDetail = nil
-				m.containerRegistryDetail = nil
-				m.appRunClusterDetail = nil
-				m.appRunLBDetail = nil
-				m.appRunASGDetail = nil
-				m.appRunWorkerNodes = nil
-				return m, nil
-			default:
-				// Pass other keys to viewport for scrolling
-				var cmd tea.Cmd
-				m.detailViewport, cmd = m.detailViewport.Update(msg)
-				return m, cmd
-			}
+func loadSimpleMonitors(client *SakuraClient) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		simpleMonitors, err := client.ListSimpleMonitors(ctx)
+		return simpleMonitorsLoadedMsg{simpleMonitors: simpleMonitors, err: err}
+	}
+}
+
+func loadSimpleMonitorDetail(client *SakuraClient, simpleMonitorID string, window time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		detail, err := client.GetSimpleMonitorDetail(ctx, simpleMonitorID, window)
+		if err != nil {
+			slog.Error("Failed to load simple monitor detail", slog.Any("error", err))
+			return simpleMonitorDetailLoadedMsg{err: err}
 		}
+		slog.Info("Simple monitor detail loaded successfully", slog.String("simpleMonitorID", simpleMonitorID))
+		return simpleMonitorDetailLoadedMsg{detail: detail}
+	}
+}
 
-		// Handle search mode
-		if m.searchMode {
-			switch msg.String() {
-			case "enter":
-				m.searchQuery = m.searchInput.Value()
-				m.searchMode = false
-				m.performSearch()
-				return m, nil
-			case "esc":
-				m.searchMode = false
-				m.searchInput.Reset()
-				return m, nil
-			default:
-				var cmd tea.Cmd
-				m.searchInput, cmd = m.searchInput.Update(msg)
-				return m, cmd
-			}
+func loadBridges(client *SakuraClient) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		bridges, err := client.ListBridges(ctx)
+		return bridgesLoadedMsg{bridges: bridges, err: err}
+	}
+}
+
+func loadBridgeDetail(client *SakuraClient, bridgeID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		detail, err := client.GetBridgeDetail(ctx, bridgeID)
+		if err != nil {
+			slog.Error("Failed to load bridge detail", slog.Any("error", err))
+			return bridgeDetailLoadedMsg{err: err}
 		}
+		slog.Info("Bridge detail loaded successfully", slog.String("bridgeID", bridgeID))
+		return bridgeDetailLoadedMsg{detail: detail}
+	}
+}
 
-		// Handle resource select mode
-		if m.resourceSelectMode {
-			switch msg.String() {
-			case "j", "down":
-				if m.resourceSelectCursor < len(AllResourceTypes)-1 {
-					m.resourceSelectCursor++
-				}
-				return m, nil
-			case "k", "up":
-				if m.resourceSelectCursor > 0 {
-					m.resourceSelectCursor--
-				}
-				return m, nil
-			case "enter":
-				selectedType := AllResourceTypes[m.resourceSelectCursor]
-				if selectedType != m.resourceType {
-					m.resourceType = selectedType
-					slog.Info("User switched resource type",
-						slog.String("type", m.resourceType.String()))
-					m.loading = true
-					// Clear search when switching resource types
-					m.searchQuery = ""
-					m.searchMatches = []int{}
-					m.currentMatch = -1
-					m.resourceSelectMode = false
-					// Load appropriate resources
-					switch m.resourceType {
-					case ResourceTypeServer:
-						return m, loadServers(m.client)
-					case ResourceTypeSwitch:
-						return m, loadSwitches(m.client)
-					case ResourceTypeDNS:
-						return m, loadDNS(m.client)
-					case ResourceTypeELB:
-						return m, loadELB(m.client)
-					case ResourceTypeGSLB:
-						return m, loadGSLB(m.client)
-					case ResourceTypeDB:
-						return m, loadDB(m.client)
-					case ResourceTypeDisk:
-						return m, loadDisks(m.client)
-					case ResourceTypeArchive:
-						return m, loadArchives(m.client)
-					case ResourceTypeInternet:
-						return m, loadInternet(m.client)
-					case ResourceTypeVPCRouter:
-						return m, loadVPCRouters(m.client)
-					case ResourceTypePacketFilter:
-						return m, loadPacketFilters(m.client)
-					case ResourceTypeLoadBalancer:
-						return m, loadLoadBalancers(m.client)
-					case ResourceTypeNFS:
-						return m, loadNFS(m.client)
-					case ResourceTypeSSHKey:
-						return m, loadSSHKeys(m.client)
-					case ResourceTypeAutoBackup:
-						return m, loadAutoBackups(m.client)
-					case ResourceTypeSimpleMonitor:
-						return m, loadSimpleMonitors(m.client)
-					case ResourceTypeBridge:
-						return m, loadBridges(m.client)
-					case ResourceTypeContainerRegistry:
-						return m, loadContainerRegistries(m.client)
-					case ResourceTypeAppRunDedicated:
-						// Reset drilldown state
-						m.appRunDrilldownLevel = 0
-						m.appRunSelectedClusterID = ""
-						m.appRunSelectedASGID = ""
-						return m, loadAppRunClusters(m.client)
-					case ResourceTypeMonitoringLogStorage:
-						return m, loadMonitoringLogStorages(m.client)
-					case ResourceTypeMonitoringMetricsStorage:
-						return m, loadMonitoringMetricsStorages(m.client)
-					case ResourceTypeMonitoringTraceStorage:
-						return m, loadMonitoringTraceStorages(m.client)
-					}
+func loadContainerRegistries(client *SakuraClient) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		containerRegistries, err := client.ListContainerRegistries(ctx)
+		return containerRegistriesLoadedMsg{containerRegistries: containerRegistries, err: err}
+	}
+}
+
+func loadContainerRegistryDetail(client *SakuraClient, containerRegistryID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		detail, err := client.GetContainerRegistryDetail(ctx, containerRegistryID)
+		if err != nil {
+			slog.Error("Failed to load container registry detail", slog.Any("error", err))
+			return containerRegistryDetailLoadedMsg{err: err}
+		}
+		slog.Info("Container registry detail loaded successfully", slog.String("containerRegistryID", containerRegistryID))
+		return containerRegistryDetailLoadedMsg{detail: detail}
+	}
+}
+
+func loadAppRunClusters(client *SakuraClient) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		clusters, err := client.ListAppRunClusters(ctx)
+		return appRunClustersLoadedMsg{clusters: clusters, err: err}
+	}
+}
+
+func loadAppRunClusterDetail(client *SakuraClient, clusterID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		detail, err := client.GetAppRunClusterDetail(ctx, clusterID)
+		if err != nil {
+			slog.Error("Failed to load AppRun cluster detail", slog.Any("error", err))
+			return appRunClusterDetailLoadedMsg{err: err}
+		}
+		slog.Info("AppRun cluster detail loaded successfully", slog.String("clusterID", clusterID))
+		return appRunClusterDetailLoadedMsg{detail: detail}
+	}
+}
+
+func loadAppRunASGs(client *SakuraClient, clusterID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		asgs, err := client.ListAppRunASGs(ctx, clusterID)
+		return appRunASGsLoadedMsg{asgs: asgs, clusterID: clusterID, err: err}
+	}
+}
+
+func loadAppRunLBs(client *SakuraClient, clusterID, asgID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		lbs, err := client.ListAppRunLBs(ctx, clusterID, asgID)
+		return appRunLBsLoadedMsg{lbs: lbs, clusterID: clusterID, asgID: asgID, err: err}
+	}
+}
+
+func loadAppRunClusterContents(client *SakuraClient, clusterID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		asgs, err := client.ListAppRunASGs(ctx, clusterID)
+		if err != nil {
+			return appRunClusterContentsLoadedMsg{err: err}
+		}
+		apps, err := client.ListAppRunApplications(ctx, clusterID)
+		if err != nil {
+			return appRunClusterContentsLoadedMsg{err: err}
+		}
+		certs, err := client.ListAppRunCertificates(ctx, clusterID)
+		if err != nil {
+			slog.Error("Failed to load AppRun certificates", slog.Any("error", err))
+			// Continue without certificates
+		}
+		return appRunClusterContentsLoadedMsg{asgs: asgs, apps: apps, certs: certs, clusterID: clusterID}
+	}
+}
+
+func loadAppRunVersions(client *SakuraClient, applicationID, clusterID string, activeVersion int32) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		versions, err := client.ListAppRunVersions(ctx, applicationID, clusterID, activeVersion)
+		return appRunVersionsLoadedMsg{versions: versions, applicationID: applicationID, clusterID: clusterID, err: err}
+	}
+}
+
+func loadAppRunLBDetail(client *SakuraClient, clusterID, asgID, lbID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		detail, err := client.GetAppRunLBDetail(ctx, clusterID, asgID, lbID)
+		if err != nil {
+			slog.Error("Failed to load AppRun LB detail", slog.Any("error", err))
+			return appRunLBDetailLoadedMsg{err: err}
+		}
+		slog.Info("AppRun LB detail loaded successfully", slog.String("lbID", lbID))
+		return appRunLBDetailLoadedMsg{detail: detail}
+	}
+}
+
+func loadAppRunASGDetail(client *SakuraClient, clusterID, asgID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		detail, err := client.GetAppRunASGDetail(ctx, clusterID, asgID)
+		if err != nil {
+			slog.Error("Failed to load AppRun ASG detail", slog.Any("error", err))
+			return appRunASGDetailLoadedMsg{err: err}
+		}
+		// Also load worker nodes
+		workerNodes, err := client.ListAppRunWorkerNodes(ctx, clusterID, asgID)
+		if err != nil {
+			slog.Error("Failed to load AppRun Worker Nodes", slog.Any("error", err))
+			return appRunASGDetailLoadedMsg{detail: detail, err: err}
+		}
+		// Also load which application containers run on each node
+		if err := client.attachAppRunNodeContainers(ctx, clusterID, workerNodes); err != nil {
+			slog.Error("Failed to load AppRun containers", slog.Any("error", err))
+			// Continue without containers
+		}
+		// Also load LBs and their details
+		lbs, err := client.ListAppRunLBs(ctx, clusterID, asgID)
+		if err != nil {
+			slog.Error("Failed to load AppRun LBs", slog.Any("error", err))
+			// Continue without LBs
+		} else {
+			// Fetch detail for each LB
+			for _, lb := range lbs {
+				lbDetail, err := client.GetAppRunLBDetail(ctx, clusterID, asgID, lb.ID)
+				if err != nil {
+					slog.Error("Failed to load AppRun LB detail", slog.String("lbID", lb.ID), slog.Any("error", err))
+					continue
 				}
-				m.resourceSelectMode = false
-				return m, nil
-			case "esc", "t", "q":
-				m.resourceSelectMode = false
+				detail.LoadBalancers = append(detail.LoadBalancers, *lbDetail)
+			}
+		}
+		slog.Info("AppRun ASG detail loaded successfully", slog.String("asgID", asgID))
+		return appRunASGDetailLoadedMsg{detail: detail, workerNodes: workerNodes}
+	}
+}
+
+// loadResources loads the list of resource type rt
+func loadResources(client *SakuraClient, rt ResourceType) tea.Cmd {
+	switch rt {
+	case ResourceTypeServer:
+		return loadServers(client)
+	case ResourceTypeSwitch:
+		return loadSwitches(client)
+	case ResourceTypeDNS:
+		return loadDNS(client)
+	case ResourceTypeELB:
+		return loadELB(client)
+	case ResourceTypeGSLB:
+		return loadGSLB(client)
+	case ResourceTypeDB:
+		return loadDB(client)
+	case ResourceTypeDisk:
+		return loadDisks(client)
+	case ResourceTypeArchive:
+		return loadArchives(client)
+	case ResourceTypeInternet:
+		return loadInternet(client)
+	case ResourceTypeVPCRouter:
+		return loadVPCRouters(client)
+	case ResourceTypePacketFilter:
+		return loadPacketFilters(client)
+	case ResourceTypeLoadBalancer:
+		return loadLoadBalancers(client)
+	case ResourceTypeNFS:
+		return loadNFS(client)
+	case ResourceTypeSSHKey:
+		return loadSSHKeys(client)
+	case ResourceTypeAutoBackup:
+		return loadAutoBackups(client)
+	case ResourceTypeSimpleMonitor:
+		return loadSimpleMonitors(client)
+	case ResourceTypeBridge:
+		return loadBridges(client)
+	case ResourceTypeContainerRegistry:
+		return loadContainerRegistries(client)
+	case ResourceTypeAppRunDedicated:
+		return loadAppRunClusters(client)
+	case ResourceTypeMonitoringLogStorage:
+		return loadMonitoringLogStorages(client)
+	case ResourceTypeMonitoringMetricsStorage:
+		return loadMonitoringMetricsStorages(client)
+	case ResourceTypeMonitoringTraceStorage:
+		return loadMonitoringTraceStorages(client)
+	case ResourceTypeMonitoringRouting:
+		return loadMonitoringRoutings(client)
+	}
+	return nil
+}
+
+// loadResourceDetail loads the detail of the resource of type rt that related IDs and the
+// back stack can open
+func loadResourceDetail(client *SakuraClient, rt ResourceType, id string) tea.Cmd {
+	switch rt {
+	case ResourceTypeServer:
+		return loadServerDetail(client, id)
+	case ResourceTypeSwitch:
+		return loadSwitchDetail(client, id)
+	case ResourceTypeDB:
+		return loadDBDetail(client, id)
+	case ResourceTypeDisk:
+		return loadDiskDetail(client, id)
+	case ResourceTypeArchive:
+		return loadArchiveDetail(client, id)
+	case ResourceTypeInternet:
+		return loadInternetDetail(client, id)
+	case ResourceTypeVPCRouter:
+		return loadVPCRouterDetail(client, id)
+	case ResourceTypeNFS:
+		return loadNFSDetail(client, id)
+	case ResourceTypeAutoBackup:
+		return loadAutoBackupDetail(client, id)
+	case ResourceTypeMonitoringLogStorage:
+		return loadMonitoringLogStorageDetail(client, id)
+	case ResourceTypeMonitoringMetricsStorage:
+		return loadMonitoringMetricsStorageDetail(client, id)
+	case ResourceTypeMonitoringTraceStorage:
+		return loadMonitoringTraceStorageDetail(client, id)
+	}
+	return nil
+}
+
+func loadMonitoringLogStorages(client *SakuraClient) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		storages, err := client.ListMonitoringLogStorages(ctx)
+		return monitoringLogStoragesLoadedMsg{storages: storages, err: err}
+	}
+}
+
+func loadMonitoringLogStorageDetail(client *SakuraClient, resourceID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		detail, err := client.GetMonitoringLogStorageDetail(ctx, resourceID)
+		if err != nil {
+			slog.Error("Failed to load log storage detail", slog.Any("error", err))
+			return monitoringLogStorageDetailLoadedMsg{err: err}
+		}
+		slog.Info("Log storage detail loaded successfully", slog.String("resourceID", resourceID))
+		return monitoringLogStorageDetailLoadedMsg{detail: detail}
+	}
+}
+
+func loadMonitoringMetricsStorages(client *SakuraClient) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		storages, err := client.ListMonitoringMetricsStorages(ctx)
+		return monitoringMetricsStoragesLoadedMsg{storages: storages, err: err}
+	}
+}
+
+func loadMonitoringMetricsStorageDetail(client *SakuraClient, resourceID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		detail, err := client.GetMonitoringMetricsStorageDetail(ctx, resourceID)
+		if err != nil {
+			slog.Error("Failed to load metrics storage detail", slog.Any("error", err))
+			return monitoringMetricsStorageDetailLoadedMsg{err: err}
+		}
+		slog.Info("Metrics storage detail loaded successfully", slog.String("resourceID", resourceID))
+		return monitoringMetricsStorageDetailLoadedMsg{detail: detail}
+	}
+}
+
+func loadMonitoringTraceStorages(client *SakuraClient) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		storages, err := client.ListMonitoringTraceStorages(ctx)
+		return monitoringTraceStoragesLoadedMsg{storages: storages, err: err}
+	}
+}
+
+// loadMonitoringStorages reloads the storage list of kind
+func loadMonitoringStorages(client *SakuraClient, kind StorageKind) tea.Cmd {
+	switch kind {
+	case StorageKindLogs:
+		return loadMonitoringLogStorages(client)
+	case StorageKindMetrics:
+		return loadMonitoringMetricsStorages(client)
+	}
+	return loadMonitoringTraceStorages(client)
+}
+
+func createMonitoringStorage(client *SakuraClient, spec MonitoringStorageSpec) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		resourceID, err := client.CreateMonitoringStorage(ctx, spec)
+		if err != nil {
+			return actionDoneMsg{err: err, reload: loadMonitoringStorages(client, spec.Kind)}
+		}
+		return actionDoneMsg{
+			message: fmt.Sprintf("Created %s %s (%s)", strings.ToLower(spec.Kind.Label()), spec.Name, resourceID),
+			reload:  loadMonitoringStorages(client, spec.Kind),
+		}
+	}
+}
+
+func updateMonitoringStorageRetention(client *SakuraClient, ref MonitoringStorageRef, days int) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		if err := client.UpdateMonitoringStorageRetention(ctx, ref.Kind, ref.ResourceID, days); err != nil {
+			return actionDoneMsg{err: err}
+		}
+		return actionDoneMsg{
+			message: fmt.Sprintf("Set the retention of %s to %d days", ref.Title(), days),
+			reload:  loadMonitoringStorages(client, ref.Kind),
+		}
+	}
+}
+
+func deleteMonitoringStorage(client *SakuraClient, ref MonitoringStorageRef) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		if err := client.DeleteMonitoringStorage(ctx, ref.Kind, ref.ResourceID); err != nil {
+			return actionDoneMsg{err: err}
+		}
+		return actionDoneMsg{
+			message: fmt.Sprintf("Deleted %s", ref.Title()),
+			reload:  loadMonitoringStorages(client, ref.Kind),
+		}
+	}
+}
+
+func loadMonitoringTraceStorageDetail(client *SakuraClient, resourceID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		detail, err := client.GetMonitoringTraceStorageDetail(ctx, resourceID)
+		if err != nil {
+			slog.Error("Failed to load trace storage detail", slog.Any("error", err))
+			return monitoringTraceStorageDetailLoadedMsg{err: err}
+		}
+		slog.Info("Trace storage detail loaded successfully", slog.String("resourceID", resourceID))
+		return monitoringTraceStorageDetailLoadedMsg{detail: detail}
+	}
+}
+
+func loadMonitoringRoutings(client *SakuraClient) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		logRoutings, err := client.ListMonitoringLogRoutings(ctx)
+		if err != nil {
+			return monitoringRoutingsLoadedMsg{err: err}
+		}
+		metricsRoutings, err := client.ListMonitoringMetricsRoutings(ctx)
+		return monitoringRoutingsLoadedMsg{logRoutings: logRoutings, metricsRoutings: metricsRoutings, err: err}
+	}
+}
+
+func loadRoutingChoices(client *SakuraClient) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		choices, err := client.LoadRoutingChoices(ctx)
+		return routingChoicesLoadedMsg{choices: choices, err: err}
+	}
+}
+
+func createMonitoringRouting(client *SakuraClient, spec RoutingSpec) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		if err := client.CreateMonitoringRouting(ctx, spec); err != nil {
+			return actionDoneMsg{err: err}
+		}
+		return actionDoneMsg{
+			message: fmt.Sprintf("Created %s routing %s", spec.Storage, spec.Describe()),
+			reload:  loadMonitoringRoutings(client),
+		}
+	}
+}
+
+func deleteMonitoringRouting(client *SakuraClient, item list.Item) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		var err error
+		switch r := item.(type) {
+		case MonitoringLogRouting:
+			err = client.DeleteMonitoringLogRouting(ctx, r)
+		case MonitoringMetricsRouting:
+			err = client.DeleteMonitoringMetricsRouting(ctx, r)
+		}
+		if err != nil {
+			return actionDoneMsg{err: err}
+		}
+		return actionDoneMsg{
+			message: fmt.Sprintf("Deleted routing %s", item.(list.DefaultItem).Title()),
+			reload:  loadMonitoringRoutings(client),
+		}
+	}
+}
+
+func InitialModel(client *SakuraClient, defaultZone string) model {
+	zones := []string{"tk1a", "tk1b", "is1a", "is1b", "is1c"}
+
+	cursor := 0
+	for i, zone := range zones {
+		if zone == defaultZone {
+			cursor = i
+			break
+		}
+	}
+
+	// Create list with custom delegate
+	delegate := resourceDelegate{}
+	resourceList := list.New([]list.Item{}, delegate, 0, 0)
+	resourceList.SetShowTitle(false)
+	resourceList.SetShowStatusBar(false)
+	resourceList.SetFilteringEnabled(false) // Disable built-in filtering
+
+	// Initialize search input
+	ti := textinput.New()
+	ti.Placeholder = "Search..."
+	ti.CharLimit = 50
+
+	return model{
+		client:       client,
+		list:         resourceList,
+		zones:        zones,
+		currentZone:  defaultZone,
+		cursor:       cursor,
+		loading:      true,
+		searchInput:  ti,
+		resourceType: ResourceTypeServer,
+		// Show the last 24 hours of SimpleMonitor response times by default
+		simpleMonitorWindow: 2,
+		config:              &Config{DefaultZone: defaultZone},
+	}
+}
+
+// WithConfig sets the loaded configuration file used for optional features
+// such as container registry credentials
+func (m model) WithConfig(config *Config) model {
+	m.config = config
+	return m
+}
+
+func (m model) Init() tea.Cmd {
+	slog.Info("Initializing TUI model", slog.String("zone", m.currentZone))
+	return tea.Batch(
+		loadServers(m.client),
+		loadAuthStatus(m.client),
+	)
+}
+
+func (m *model) performSearch() {
+	m.searchMatches = []int{}
+	m.currentMatch = -1
+
+	query := strings.ToLower(m.searchQuery)
+	if query == "" {
+		return
+	}
+
+	items := m.list.Items()
+	for i, item := range items {
+		// Handle Server
+		if server, ok := item.(Server); ok {
+			if strings.Contains(strings.ToLower(server.Name), query) ||
+				strings.Contains(strings.ToLower(server.ID), query) {
+				m.searchMatches = append(m.searchMatches, i)
+			}
+			continue
+		}
+		// Handle Switch
+		if sw, ok := item.(Switch); ok {
+			if strings.Contains(strings.ToLower(sw.Name), query) ||
+				strings.Contains(strings.ToLower(sw.ID), query) ||
+				strings.Contains(strings.ToLower(sw.Desc), query) ||
+				strings.Contains(strings.ToLower(sw.DefaultRoute), query) {
+				m.searchMatches = append(m.searchMatches, i)
+			}
+			continue
+		}
+		// Handle DNS
+		if dns, ok := item.(DNS); ok {
+			if strings.Contains(strings.ToLower(dns.Name), query) ||
+				strings.Contains(strings.ToLower(dns.ID), query) ||
+				strings.Contains(strings.ToLower(dns.Desc), query) {
+				m.searchMatches = append(m.searchMatches, i)
+			}
+			continue
+		}
+		// Handle ELB
+		if elb, ok := item.(ELB); ok {
+			if strings.Contains(strings.ToLower(elb.Name), query) ||
+				strings.Contains(strings.ToLower(elb.ID), query) ||
+				strings.Contains(strings.ToLower(elb.Desc), query) ||
+				strings.Contains(strings.ToLower(elb.VIP), query) {
+				m.searchMatches = append(m.searchMatches, i)
+			}
+			continue
+		}
+		// Handle GSLB
+		if gslb, ok := item.(GSLB); ok {
+			if strings.Contains(strings.ToLower(gslb.Name), query) ||
+				strings.Contains(strings.ToLower(gslb.ID), query) ||
+				strings.Contains(strings.ToLower(gslb.Desc), query) ||
+				strings.Contains(strings.ToLower(gslb.FQDN), query) {
+				m.searchMatches = append(m.searchMatches, i)
+			}
+			continue
+		}
+		// Handle DB
+		if db, ok := item.(DB); ok {
+			if strings.Contains(strings.ToLower(db.Name), query) ||
+				strings.Contains(strings.ToLower(db.ID), query) ||
+				strings.Contains(strings.ToLower(db.Desc), query) ||
+				strings.Contains(strings.ToLower(db.DBType), query) {
+				m.searchMatches = append(m.searchMatches, i)
+			}
+		}
+	}
+
+	slog.Info("Search performed", slog.String("query", query), slog.Int("matches", len(m.searchMatches)))
+
+	// Jump to first match
+	if len(m.searchMatches) > 0 {
+		m.currentMatch = 0
+		m.list.Select(m.searchMatches[0])
+	}
+}
+
+func (m *model) nextMatch() {
+	if len(m.searchMatches) == 0 {
+		return
+	}
+	m.currentMatch = (m.currentMatch + 1) % len(m.searchMatches)
+	m.list.Select(m.searchMatches[m.currentMatch])
+	slog.Info("Next match", slog.Int("match", m.currentMatch+1), slog.Int("total", len(m.searchMatches)))
+}
+
+func (m *model) prevMatch() {
+	if len(m.searchMatches) == 0 {
+		return
+	}
+	m.currentMatch--
+	if m.currentMatch < 0 {
+		m.currentMatch = len(m.searchMatches) - 1
+	}
+	m.list.Select(m.searchMatches[m.currentMatch])
+	slog.Info("Previous match", slog.Int("match", m.currentMatch+1), slog.Int("total", len(m.searchMatches)))
+}
+
+func (m model) getTableHeader() string {
+	switch m.resourceType {
+	case ResourceTypeServer:
+		return fmt.Sprintf("  %-40s %-20s %s", "Name", "ID", "Status")
+	case ResourceTypeSwitch:
+		return fmt.Sprintf("  %-40s %-20s %s", "Name", "ID", "Servers")
+	case ResourceTypeDNS:
+		return fmt.Sprintf("  %-40s %-20s %s", "Name", "ID", "Records")
+	case ResourceTypeELB:
+		return fmt.Sprintf("  %-40s %-20s %-15s %s", "Name", "ID", "VIP", "Servers")
+	case ResourceTypeGSLB:
+		return fmt.Sprintf("  %-40s %-20s %s", "Name", "ID", "FQDN")
+	case ResourceTypeDB:
+		return fmt.Sprintf("  %-40s %-20s %-10s %s", "Name", "ID", "Type", "Status")
+	case ResourceTypeDisk:
+		return fmt.Sprintf("  %-40s %-20s %6s %-10s %s", "Name", "ID", "Size", "Connection", "Server")
+	case ResourceTypeArchive:
+		return fmt.Sprintf("  %-40s %-20s %6s %-10s %s", "Name", "ID", "Size", "Scope", "Availability")
+	case ResourceTypeInternet:
+		return fmt.Sprintf("  %-40s %-20s %10s %s", "Name", "ID", "Bandwidth", "Switch ID")
+	case ResourceTypeVPCRouter:
+		return fmt.Sprintf("  %-40s %-20s %-10s %-8s %s", "Name", "ID", "Plan", "Version", "Status")
+	case ResourceTypePacketFilter:
+		return fmt.Sprintf("  %-40s %-20s %s", "Name", "ID", "Rules")
+	case ResourceTypeLoadBalancer:
+		return fmt.Sprintf("  %-40s %-20s %s  %s", "Name", "ID", "VIPs", "Status")
+	case ResourceTypeNFS:
+		return fmt.Sprintf("  %-40s %-20s %-15s %5s %s", "Name", "ID", "Switch", "Used", "Status")
+	case ResourceTypeSSHKey:
+		return fmt.Sprintf("  %-40s %-20s %s", "Name", "ID", "Fingerprint")
+	case ResourceTypeAutoBackup:
+		return fmt.Sprintf("  %-40s %-20s %s  %s", "Name", "ID", "Max", "Weekdays")
+	case ResourceTypeSimpleMonitor:
+		return fmt.Sprintf("  %-40s %-20s %-10s %-7s %s", "Name", "ID", "Protocol", "Enabled", "Health")
+	case ResourceTypeBridge:
+		return fmt.Sprintf("  %-40s %-20s %-10s %s", "Name", "ID", "Region", "Switches")
+	case ResourceTypeContainerRegistry:
+		return fmt.Sprintf("  %-40s %-20s %-12s %s", "Name", "ID", "AccessLevel", "Users")
+	case ResourceTypeMonitoringRouting:
+		return fmt.Sprintf("  %-9s %-8s %-14s %-16s %-20s %s", "Kind", "UID", "Resource ID", "Publisher", "Variant", "Storage")
+	case ResourceTypeAppRunDedicated:
+		switch m.appRunDrilldownLevel {
+		case 0:
+			return fmt.Sprintf("  %-40s %-36s %s", "Name", "ID", "ASG")
+		case 1:
+			return "  [ASG] + [Applications]"
+		case 2:
+			if m.appRunSelectedAppID != "" {
+				// Version list
+				return fmt.Sprintf("  %-8s %5s  %s", "Version", "Nodes", "Image")
+			}
+			// LB list
+			return fmt.Sprintf("  %-40s %-36s %s", "Name", "ID", "ServiceClass")
+		}
+		return ""
+	default:
+		return ""
+	}
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.windowHeight = msg.Height
+		m.windowWidth = msg.Width
+		slog.Debug("Window size updated", slog.Int("height", msg.Height), slog.Int("width", msg.Width))
+
+		// Update list size - account for header area
+		headerHeight := 8 // Title + Account + Zone + spacing
+		if m.accountName == "" {
+			headerHeight--
+		}
+		if m.searchMode {
+			headerHeight++ // Add line for search input
+		}
+		m.list.SetSize(msg.Width, msg.Height-headerHeight)
+
+		// Update detail viewport size if in detail mode
+		if m.detailMode {
+			m.detailViewport.Width = msg.Width
+			m.detailViewport.Height = msg.Height - 10
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		// Handle active form
+		if m.form != nil {
+			cmd, closed := m.form.update(msg)
+			if closed {
+				m.form = nil
+				if cmd != nil {
+					m.statusMessage = "Running..."
+				}
+			}
+			return m, cmd
+		}
+
+		// Handle confirmation prompt
+		if m.confirmMessage != "" {
+			switch msg.String() {
+			case "y", "Y":
+				cmd := m.confirmCmd
+				m.confirmMessage = ""
+				m.confirmCmd = nil
+				m.statusMessage = "Running..."
+				return m, cmd
+			case "n", "N", "esc", "q":
+				m.confirmMessage = ""
+				m.confirmCmd = nil
+				m.statusMessage = "Cancelled"
+				return m, nil
+			}
+			return m, nil
+		}
+
+		// Handle detail mode
+		if m.detailMode {
+			if updated, cmd, handled := m.handleDetailAction(msg.String()); handled {
+				return updated, cmd
+			}
+			if key := msg.String(); (key == "esc" || key == "backspace") && len(m.detailStack) > 0 {
+				cmd := m.popDetail()
+				return m, cmd
+			}
+			switch msg.String() {
+			case "esc", "q":
+				m.detailMode = false
+				m.detailCursor = 0
+				m.statusMessage = ""
+				m.clearDetail()
+				m.detailStack = nil
+				return m, nil
+			default:
+				// Pass other keys to viewport for scrolling
+				var cmd tea.Cmd
+				m.detailViewport, cmd = m.detailViewport.Update(msg)
+				return m, cmd
+			}
+		}
+
+		// Handle search mode
+		if m.searchMode {
+			switch msg.String() {
+			case "enter":
+				m.searchQuery = m.searchInput.Value()
+				m.searchMode = false
+				m.performSearch()
+				return m, nil
+			case "esc":
+				m.searchMode = false
+				m.searchInput.Reset()
+				return m, nil
+			default:
+				var cmd tea.Cmd
+				m.searchInput, cmd = m.searchInput.Update(msg)
+				return m, cmd
+			}
+		}
+
+		// Handle resource select mode
+		if m.resourceSelectMode {
+			switch msg.String() {
+			case "j", "down":
+				if m.resourceSelectCursor < len(AllResourceTypes)-1 {
+					m.resourceSelectCursor++
+				}
+				return m, nil
+			case "k", "up":
+				if m.resourceSelectCursor > 0 {
+					m.resourceSelectCursor--
+				}
+				return m, nil
+			case "enter":
+				selectedType := AllResourceTypes[m.resourceSelectCursor]
+				if selectedType != m.resourceType {
+					m.resourceType = selectedType
+					slog.Info("User switched resource type",
+						slog.String("type", m.resourceType.String()))
+					m.loading = true
+					// Clear search when switching resource types
+					m.searchQuery = ""
+					m.searchMatches = []int{}
+					m.currentMatch = -1
+					m.resourceSelectMode = false
+					// Load appropriate resources
+					switch m.resourceType {
+					case ResourceTypeServer:
+						return m, loadServers(m.client)
+					case ResourceTypeSwitch:
+						return m, loadSwitches(m.client)
+					case ResourceTypeDNS:
+						return m, loadDNS(m.client)
+					case ResourceTypeELB:
+						return m, loadELB(m.client)
+					case ResourceTypeGSLB:
+						return m, loadGSLB(m.client)
+					case ResourceTypeDB:
+						return m, loadDB(m.client)
+					case ResourceTypeDisk:
+						return m, loadDisks(m.client)
+					case ResourceTypeArchive:
+						return m, loadArchives(m.client)
+					case ResourceTypeInternet:
+						return m, loadInternet(m.client)
+					case ResourceTypeVPCRouter:
+						return m, loadVPCRouters(m.client)
+					case ResourceTypePacketFilter:
+						return m, loadPacketFilters(m.client)
+					case ResourceTypeLoadBalancer:
+						return m, loadLoadBalancers(m.client)
+					case ResourceTypeNFS:
+						return m, loadNFS(m.client)
+					case ResourceTypeSSHKey:
+						return m, loadSSHKeys(m.client)
+					case ResourceTypeAutoBackup:
+						return m, loadAutoBackups(m.client)
+					case ResourceTypeSimpleMonitor:
+						return m, loadSimpleMonitors(m.client)
+					case ResourceTypeBridge:
+						return m, loadBridges(m.client)
+					case ResourceTypeContainerRegistry:
+						return m, loadContainerRegistries(m.client)
+					case ResourceTypeAppRunDedicated:
+						// Reset drilldown state
+						m.appRunDrilldownLevel = 0
+						m.appRunSelectedClusterID = ""
+						m.appRunSelectedASGID = ""
+						return m, loadAppRunClusters(m.client)
+					case ResourceTypeMonitoringLogStorage:
+						return m, loadMonitoringLogStorages(m.client)
+					case ResourceTypeMonitoringMetricsStorage:
+						return m, loadMonitoringMetricsStorages(m.client)
+					case ResourceTypeMonitoringTraceStorage:
+						return m, loadMonitoringTraceStorages(m.client)
+					case ResourceTypeMonitoringRouting:
+						return m, loadMonitoringRoutings(m.client)
+					}
+				}
+				m.resourceSelectMode = false
+				return m, nil
+			case "esc", "t", "q":
+				m.resourceSelectMode = false
+				return m, nil
+			}
+			return m, nil
+		}
+
+		// Resource specific actions in the list
+		if !m.loading {
+			if updated, cmd, handled := m.handleListAction(msg.String()); handled {
+				return updated, cmd
+			}
+		}
+
+		// Normal mode
+		switch msg.String() {
+		case "ctrl+c", "q":
+			slog.Info("User requested quit")
+			m.quitting = true
+			return m, tea.Quit
+
+		case "esc":
+			// For AppRun Dedicated, go back up the drilldown hierarchy
+			if m.resourceType == ResourceTypeAppRunDedicated && m.appRunDrilldownLevel > 0 {
+				m.loading = true
+				if m.appRunDrilldownLevel == 2 {
+					// From LB or Version list, go back to ASG + Applications list
+					m.appRunDrilldownLevel = 1
+					m.appRunSelectedASGID = ""
+					m.appRunSelectedAppID = ""
+					return m, loadAppRunClusterContents(m.client, m.appRunSelectedClusterID)
+				} else if m.appRunDrilldownLevel == 1 {
+					// From ASG + Applications list, go back to Cluster list
+					m.appRunDrilldownLevel = 0
+					m.appRunSelectedClusterID = ""
+					return m, loadAppRunClusters(m.client)
+				}
+			}
+			// Otherwise ignore ESC in list view to prevent accidental exit
+			return m, nil
+
+		case "enter":
+			// Show detail based on resource type
+			m.detailStack = nil
+			if len(m.list.Items()) > 0 {
+				selectedItem := m.list.SelectedItem()
+				if server, ok := selectedItem.(Server); ok {
+					m.detailMode = true
+					m.detailLoading = true
+					return m, loadServerDetail(m.client, server.ID)
+				}
+				if sw, ok := selectedItem.(Switch); ok {
+					m.detailMode = true
+					m.detailLoading = true
+					return m, loadSwitchDetail(m.client, sw.ID)
+				}
+				if dns, ok := selectedItem.(DNS); ok {
+					m.detailMode = true
+					m.detailLoading = true
+					return m, loadDNSDetail(m.client, dns.ID)
+				}
+				if elb, ok := selectedItem.(ELB); ok {
+					m.detailMode = true
+					m.detailLoading = true
+					return m, loadELBDetail(m.client, elb.ID)
+				}
+				if gslb, ok := selectedItem.(GSLB); ok {
+					m.detailMode = true
+					m.detailLoading = true
+					return m, loadGSLBDetail(m.client, gslb.ID)
+				}
+				if db, ok := selectedItem.(DB); ok {
+					m.detailMode = true
+					m.detailLoading = true
+					return m, loadDBDetail(m.client, db.ID)
+				}
+				if disk, ok := selectedItem.(Disk); ok {
+					m.detailMode = true
+					m.detailLoading = true
+					return m, loadDiskDetail(m.client, disk.ID)
+				}
+				if archive, ok := selectedItem.(Archive); ok {
+					m.detailMode = true
+					m.detailLoading = true
+					return m, loadArchiveDetail(m.client, archive.ID)
+				}
+				if internet, ok := selectedItem.(Internet); ok {
+					m.detailMode = true
+					m.detailLoading = true
+					return m, loadInternetDetail(m.client, internet.ID)
+				}
+				if vpcRouter, ok := selectedItem.(VPCRouter); ok {
+					m.detailMode = true
+					m.detailLoading = true
+					return m, loadVPCRouterDetail(m.client, vpcRouter.ID)
+				}
+				if pf, ok := selectedItem.(PacketFilter); ok {
+					m.detailMode = true
+					m.detailLoading = true
+					return m, loadPacketFilterDetail(m.client, pf.ID)
+				}
+				if lb, ok := selectedItem.(LoadBalancer); ok {
+					m.detailMode = true
+					m.detailLoading = true
+					return m, loadLoadBalancerDetail(m.client, lb.ID)
+				}
+				if nfs, ok := selectedItem.(NFS); ok {
+					m.detailMode = true
+					m.detailLoading = true
+					return m, loadNFSDetail(m.client, nfs.ID)
+				}
+				if sshKey, ok := selectedItem.(SSHKey); ok {
+					m.detailMode = true
+					m.detailLoading = true
+					return m, loadSSHKeyDetail(m.client, sshKey.ID)
+				}
+				if ab, ok := selectedItem.(AutoBackup); ok {
+					m.detailMode = true
+					m.detailLoading = true
+					return m, loadAutoBackupDetail(m.client, ab.ID)
+				}
+				if sm, ok := selectedItem.(SimpleMonitor); ok {
+					m.detailMode = true
+					m.detailLoading = true
+					return m, loadSimpleMonitorDetail(m.client, sm.ID, SimpleMonitorWindows[m.simpleMonitorWindow])
+				}
+				if br, ok := selectedItem.(Bridge); ok {
+					m.detailMode = true
+					m.detailLoading = true
+					return m, loadBridgeDetail(m.client, br.ID)
+				}
+				if cr, ok := selectedItem.(ContainerRegistry); ok {
+					m.detailMode = true
+					m.detailLoading = true
+					return m, loadContainerRegistryDetail(m.client, cr.ID)
+				}
+				if arc, ok := selectedItem.(AppRunCluster); ok {
+					// Drilldown into ASG + Applications list
+					m.loading = true
+					m.appRunSelectedClusterID = arc.ID
+					m.appRunDrilldownLevel = 1
+					return m, loadAppRunClusterContents(m.client, arc.ID)
+				}
+				if asg, ok := selectedItem.(AppRunASG); ok {
+					// Show ASG detail with worker nodes
+					m.detailMode = true
+					m.detailLoading = true
+					m.appRunSelectedASGID = asg.ID
+					return m, loadAppRunASGDetail(m.client, asg.ClusterID, asg.ID)
+				}
+				if lb, ok := selectedItem.(AppRunLB); ok {
+					// Show LB detail
+					m.detailMode = true
+					m.detailLoading = true
+					return m, loadAppRunLBDetail(m.client, lb.ClusterID, lb.ASGID, lb.ID)
+				}
+				if app, ok := selectedItem.(AppRunApplication); ok {
+					// Drilldown into Version list
+					m.loading = true
+					m.appRunSelectedAppID = app.ID
+					m.appRunSelectedAppName = app.Name
+					m.appRunActiveVersion = app.ActiveVersion
+					m.appRunDrilldownLevel = 2
+					return m, loadAppRunVersions(m.client, app.ID, app.ClusterID, app.ActiveVersion)
+				}
+				if cert, ok := selectedItem.(AppRunCertificate); ok {
+					m.detailMode = true
+					m.detailLoading = true
+					return m, loadAppRunCertificate(m.client, cert.ClusterID, cert.ID)
+				}
+				if ver, ok := selectedItem.(AppRunVersion); ok {
+					// Show the version spec compared with the active (or marked) version
+					app, _ := m.selectedAppRunApplication()
+					m.detailMode = true
+					m.detailLoading = true
+					return m, loadAppRunVersionComparison(m.client, app, m.appRunCompareBase(ver), ver.Version)
+				}
+				if ls, ok := selectedItem.(MonitoringLogStorage); ok {
+					m.detailMode = true
+					m.detailLoading = true
+					return m, loadMonitoringLogStorageDetail(m.client, getNilInt64AsString(ls.ResourceID))
+				}
+				if ms, ok := selectedItem.(MonitoringMetricsStorage); ok {
+					m.detailMode = true
+					m.detailLoading = true
+					return m, loadMonitoringMetricsStorageDetail(m.client, getNilInt64AsString(ms.ResourceID))
+				}
+				if ts, ok := selectedItem.(MonitoringTraceStorage); ok {
+					m.detailMode = true
+					m.detailLoading = true
+					return m, loadMonitoringTraceStorageDetail(m.client, strconv.FormatInt(ts.ResourceID, 10))
+				}
+			}
+			return m, nil
+
+		case "u":
+			// Toggle the unhealthy-only filter for SimpleMonitor
+			if m.resourceType == ResourceTypeSimpleMonitor {
+				m.simpleMonitorUnhealthyOnly = !m.simpleMonitorUnhealthyOnly
+				m.setSimpleMonitorItems()
+				return m, nil
+			}
+
+		case " ":
+			// Mark SimpleMonitors for a bulk enable/disable
+			if m.resourceType == ResourceTypeSimpleMonitor {
+				if sm, ok := m.list.SelectedItem().(SimpleMonitor); ok {
+					m.toggleSimpleMonitorMark(sm.ID)
+				}
+				return m, nil
+			}
+
+		case "e":
+			// Enable/disable the marked SimpleMonitors (or the selected one)
+			if m.resourceType == ResourceTypeSimpleMonitor {
+				targets := m.simpleMonitorTargets()
+				if len(targets) == 0 {
+					return m, nil
+				}
+				// Disable if any target is enabled, so that a mixed selection gets silenced
+				enabled := true
+				for _, sm := range targets {
+					if sm.Enabled {
+						enabled = false
+						break
+					}
+				}
+				ids := make([]string, 0, len(targets))
+				for _, sm := range targets {
+					ids = append(ids, sm.ID)
+				}
+				action := "Disable"
+				if enabled {
+					action = "Enable"
+				}
+				if len(targets) == 1 {
+					m.confirmMessage = fmt.Sprintf("%s simple monitor %s?", action, targets[0].Name)
+				} else {
+					m.confirmMessage = fmt.Sprintf("%s %d simple monitors?", action, len(targets))
+				}
+				m.confirmCmd = setSimpleMonitorsEnabled(m.client, ids, enabled)
+				return m, nil
+			}
+
+		case "/":
+			m.searchMode = true
+			m.searchInput.Focus()
+			m.searchInput.Reset()
+			return m, textinput.Blink
+
+		case "n":
+			m.nextMatch()
+			return m, nil
+
+		case "N":
+			m.prevMatch()
+			return m, nil
+
+		case "t":
+			// Open resource type selector menu
+			m.resourceSelectMode = true
+			// Set cursor to current resource type
+			for i, rt := range AllResourceTypes {
+				if rt == m.resourceType {
+					m.resourceSelectCursor = i
+					break
+				}
+			}
+			return m, nil
+
+		case "z":
+			// Zone switching only affects zone-dependent resources (DNS, ELB, GSLB, SSHKey, SimpleMonitor, ContainerRegistry, AppRunCluster, Monitoring are global)
+			if m.resourceType.IsGlobal() {
+				return m, nil
+			}
+			oldZone := m.currentZone
+			m.cursor = (m.cursor + 1) % len(m.zones)
+			m.currentZone = m.zones[m.cursor]
+			slog.Info("User switched zone via keyboard",
+				slog.String("from", oldZone),
+				slog.String("to", m.currentZone))
+			m.client.SetZone(m.currentZone)
+			m.loading = true
+			// Clear search when switching zones
+			m.searchQuery = ""
+			m.searchMatches = []int{}
+			m.currentMatch = -1
+			// Load appropriate resources based on current type
+			switch m.resourceType {
+			case ResourceTypeServer:
+				return m, loadServers(m.client)
+			case ResourceTypeSwitch:
+				return m, loadSwitches(m.client)
+			case ResourceTypeDB:
+				return m, loadDB(m.client)
+			case ResourceTypeDisk:
+				return m, loadDisks(m.client)
+			case ResourceTypeArchive:
+				return m, loadArchives(m.client)
+			case ResourceTypeInternet:
+				return m, loadInternet(m.client)
+			case ResourceTypeVPCRouter:
+				return m, loadVPCRouters(m.client)
+			case ResourceTypePacketFilter:
+				return m, loadPacketFilters(m.client)
+			case ResourceTypeLoadBalancer:
+				return m, loadLoadBalancers(m.client)
+			case ResourceTypeNFS:
+				return m, loadNFS(m.client)
+			case ResourceTypeAutoBackup:
+				return m, loadAutoBackups(m.client)
+			case ResourceTypeBridge:
+				return m, loadBridges(m.client)
+			case ResourceTypeMonitoringRouting:
+				// Routings are global, but the candidate resources come from the zone
+				return m, loadMonitoringRoutings(m.client)
+			}
+			return m, nil
+
+		case "r":
+			slog.Info("User requested refresh", slog.String("zone", m.currentZone))
+			m.loading = true
+			// Refresh appropriate resources based on current type
+			return m, loadResources(m.client, m.resourceType)
+		}
+
+	case serversLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			slog.Error("Failed to load servers", slog.Any("error", msg.err))
+			m.err = msg.err
+			return m, nil
+		}
+		slog.Info("Servers loaded successfully", slog.Int("count", len(msg.servers)))
+
+		// Convert servers to list items
+		items := make([]list.Item, len(msg.servers))
+		for i, server := range msg.servers {
+			items[i] = server
+		}
+		m.list.SetItems(items)
+		return m, nil
+
+	case switchesLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			slog.Error("Failed to load switches", slog.Any("error", msg.err))
+			m.err = msg.err
+			return m, nil
+		}
+		slog.Info("Switches loaded successfully", slog.Int("count", len(msg.switches)))
+
+		// Convert switches to list items
+		items := make([]list.Item, len(msg.switches))
+		for i, sw := range msg.switches {
+			items[i] = sw
+		}
+		m.list.SetItems(items)
+		return m, nil
+
+	case authStatusLoadedMsg:
+		if msg.err != nil {
+			slog.Error("Failed to load auth status", slog.Any("error", msg.err))
+			return m, nil
+		}
+		slog.Info("Setting account name in model", slog.String("accountName", msg.accountName))
+		m.accountName = msg.accountName
+		return m, nil
+
+	case serverDetailLoadedMsg:
+		m.detailLoading = false
+		if msg.err != nil {
+			slog.Error("Failed to load server detail", slog.Any("error", msg.err))
+			m.err = msg.err
+			m.detailMode = false
+			return m, nil
+		}
+		m.serverDetail = msg.detail
+		// Setup viewport for detail view
+		content := renderServerDetail(msg.detail)
+		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
+		m.detailViewport.SetContent(content)
+		return m, nil
+
+	case switchDetailLoadedMsg:
+		m.detailLoading = false
+		if msg.err != nil {
+			slog.Error("Failed to load switch detail", slog.Any("error", msg.err))
+			m.err = msg.err
+			m.detailMode = false
+			return m, nil
+		}
+		m.switchDetail = msg.detail
+		// Setup viewport for detail view
+		content := renderSwitchDetail(msg.detail)
+		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
+		m.detailViewport.SetContent(content)
+		return m, nil
+
+	case dnsLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			slog.Error("Failed to load DNS zones", slog.Any("error", msg.err))
+			m.err = msg.err
+			return m, nil
+		}
+		slog.Info("DNS zones loaded successfully", slog.Int("count", len(msg.dnsList)))
+
+		// Convert DNS to list items
+		items := make([]list.Item, len(msg.dnsList))
+		for i, dns := range msg.dnsList {
+			items[i] = dns
+		}
+		m.list.SetItems(items)
+		return m, nil
+
+	case dnsDetailLoadedMsg:
+		m.detailLoading = false
+		if msg.err != nil {
+			slog.Error("Failed to load DNS detail", slog.Any("error", msg.err))
+			m.err = msg.err
+			m.detailMode = false
+			return m, nil
+		}
+		m.dnsDetail = msg.detail
+		// Setup viewport for detail view
+		content := renderDNSDetail(msg.detail)
+		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
+		m.detailViewport.SetContent(content)
+		return m, nil
+
+	case elbLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			slog.Error("Failed to load ELBs", slog.Any("error", msg.err))
+			m.err = msg.err
+			return m, nil
+		}
+		slog.Info("ELBs loaded successfully", slog.Int("count", len(msg.elbList)))
+
+		// Convert ELBs to list items
+		items := make([]list.Item, len(msg.elbList))
+		for i, elb := range msg.elbList {
+			items[i] = elb
+		}
+		m.list.SetItems(items)
+		return m, nil
+
+	case elbDetailLoadedMsg:
+		m.detailLoading = false
+		if msg.err != nil {
+			slog.Error("Failed to load ELB detail", slog.Any("error", msg.err))
+			m.err = msg.err
+			m.detailMode = false
+			return m, nil
+		}
+		m.elbDetail = msg.detail
+		// Setup viewport for detail view
+		content := renderELBDetail(msg.detail)
+		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
+		m.detailViewport.SetContent(content)
+		return m, nil
+
+	case gslbLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			slog.Error("Failed to load GSLBs", slog.Any("error", msg.err))
+			m.err = msg.err
+			return m, nil
+		}
+		slog.Info("GSLBs loaded successfully", slog.Int("count", len(msg.gslbList)))
+
+		// Convert GSLBs to list items
+		items := make([]list.Item, len(msg.gslbList))
+		for i, gslb := range msg.gslbList {
+			items[i] = gslb
+		}
+		m.list.SetItems(items)
+		return m, nil
+
+	case gslbDetailLoadedMsg:
+		m.detailLoading = false
+		if msg.err != nil {
+			slog.Error("Failed to load GSLB detail", slog.Any("error", msg.err))
+			m.err = msg.err
+			m.detailMode = false
+			return m, nil
+		}
+		m.gslbDetail = msg.detail
+		// Setup viewport for detail view
+		content := renderGSLBDetail(msg.detail)
+		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
+		m.detailViewport.SetContent(content)
+		return m, nil
+
+	case dbLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			slog.Error("Failed to load DBs", slog.Any("error", msg.err))
+			m.err = msg.err
+			return m, nil
+		}
+		slog.Info("DBs loaded successfully", slog.Int("count", len(msg.dbList)))
+
+		// Convert DBs to list items
+		items := make([]list.Item, len(msg.dbList))
+		for i, db := range msg.dbList {
+			items[i] = db
+		}
+		m.list.SetItems(items)
+		return m, nil
+
+	case dbDetailLoadedMsg:
+		m.detailLoading = false
+		if msg.err != nil {
+			slog.Error("Failed to load DB detail", slog.Any("error", msg.err))
+			m.err = msg.err
+			m.detailMode = false
+			return m, nil
+		}
+		m.dbDetail = msg.detail
+		// Setup viewport for detail view
+		content := renderDBDetail(msg.detail)
+		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
+		m.detailViewport.SetContent(content)
+		return m, nil
+
+	case disksLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			slog.Error("Failed to load disks", slog.Any("error", msg.err))
+			m.err = msg.err
+			return m, nil
+		}
+		slog.Info("Disks loaded successfully", slog.Int("count", len(msg.disks)))
+
+		// Convert disks to list items
+		items := make([]list.Item, len(msg.disks))
+		for i, disk := range msg.disks {
+			items[i] = disk
+		}
+		m.list.SetItems(items)
+		return m, nil
+
+	case diskDetailLoadedMsg:
+		m.detailLoading = false
+		if msg.err != nil {
+			slog.Error("Failed to load disk detail", slog.Any("error", msg.err))
+			m.err = msg.err
+			m.detailMode = false
+			return m, nil
+		}
+		m.diskDetail = msg.detail
+		// Setup viewport for detail view
+		content := m.renderLinkedDetail()
+		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
+		m.detailViewport.SetContent(content)
+		return m, nil
+
+	case archivesLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			slog.Error("Failed to load archives", slog.Any("error", msg.err))
+			m.err = msg.err
+			return m, nil
+		}
+		slog.Info("Archives loaded successfully", slog.Int("count", len(msg.archives)))
+
+		// Convert archives to list items
+		items := make([]list.Item, len(msg.archives))
+		for i, archive := range msg.archives {
+			items[i] = archive
+		}
+		m.list.SetItems(items)
+		return m, nil
+
+	case archiveDetailLoadedMsg:
+		m.detailLoading = false
+		if msg.err != nil {
+			slog.Error("Failed to load archive detail", slog.Any("error", msg.err))
+			m.err = msg.err
+			m.detailMode = false
+			return m, nil
+		}
+		m.archiveDetail = msg.detail
+		// Setup viewport for detail view
+		content := m.renderLinkedDetail()
+		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
+		m.detailViewport.SetContent(content)
+		return m, nil
+
+	case internetLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			slog.Error("Failed to load internet", slog.Any("error", msg.err))
+			m.err = msg.err
+			return m, nil
+		}
+		slog.Info("Internet loaded successfully", slog.Int("count", len(msg.internet)))
+
+		// Convert internet to list items
+		items := make([]list.Item, len(msg.internet))
+		for i, internet := range msg.internet {
+			items[i] = internet
+		}
+		m.list.SetItems(items)
+		return m, nil
+
+	case internetDetailLoadedMsg:
+		m.detailLoading = false
+		if msg.err != nil {
+			slog.Error("Failed to load internet detail", slog.Any("error", msg.err))
+			m.err = msg.err
+			m.detailMode = false
+			return m, nil
+		}
+		m.internetDetail = msg.detail
+		// Setup viewport for detail view
+		content := m.renderLinkedDetail()
+		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
+		m.detailViewport.SetContent(content)
+		return m, nil
+
+	case vpcRoutersLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			slog.Error("Failed to load VPC routers", slog.Any("error", msg.err))
+			m.err = msg.err
+			return m, nil
+		}
+		slog.Info("VPC routers loaded successfully", slog.Int("count", len(msg.vpcRouters)))
+
+		// Convert VPC routers to list items
+		items := make([]list.Item, len(msg.vpcRouters))
+		for i, vpcRouter := range msg.vpcRouters {
+			items[i] = vpcRouter
+		}
+		m.list.SetItems(items)
+		return m, nil
+
+	case vpcRouterDetailLoadedMsg:
+		m.detailLoading = false
+		if msg.err != nil {
+			slog.Error("Failed to load VPC router detail", slog.Any("error", msg.err))
+			m.err = msg.err
+			m.detailMode = false
+			return m, nil
+		}
+		m.vpcRouterDetail = msg.detail
+		// Setup viewport for detail view
+		content := m.renderLinkedDetail()
+		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
+		m.detailViewport.SetContent(content)
+		return m, nil
+
+	case packetFiltersLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			slog.Error("Failed to load packet filters", slog.Any("error", msg.err))
+			m.err = msg.err
+			return m, nil
+		}
+		slog.Info("Packet filters loaded successfully", slog.Int("count", len(msg.packetFilters)))
+
+		// Convert packet filters to list items
+		items := make([]list.Item, len(msg.packetFilters))
+		for i, pf := range msg.packetFilters {
+			items[i] = pf
+		}
+		m.list.SetItems(items)
+		return m, nil
+
+	case packetFilterDetailLoadedMsg:
+		m.detailLoading = false
+		if msg.err != nil {
+			slog.Error("Failed to load packet filter detail", slog.Any("error", msg.err))
+			m.err = msg.err
+			m.detailMode = false
+			return m, nil
+		}
+		m.packetFilterDetail = msg.detail
+		// Setup viewport for detail view
+		content := renderPacketFilterDetail(msg.detail)
+		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
+		m.detailViewport.SetContent(content)
+		return m, nil
+
+	case loadBalancersLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			slog.Error("Failed to load load balancers", slog.Any("error", msg.err))
+			m.err = msg.err
+			return m, nil
+		}
+		slog.Info("Load balancers loaded successfully", slog.Int("count", len(msg.loadBalancers)))
+
+		// Convert load balancers to list items
+		items := make([]list.Item, len(msg.loadBalancers))
+		for i, lb := range msg.loadBalancers {
+			items[i] = lb
+		}
+		m.list.SetItems(items)
+		return m, nil
+
+	case loadBalancerDetailLoadedMsg:
+		m.detailLoading = false
+		if msg.err != nil {
+			slog.Error("Failed to load load balancer detail", slog.Any("error", msg.err))
+			m.err = msg.err
+			m.detailMode = false
+			return m, nil
+		}
+		m.loadBalancerDetail = msg.detail
+		if m.detailCursor >= msg.detail.RealServerCount() {
+			m.detailCursor = 0
+		}
+		// Setup viewport for detail view
+		content := renderLoadBalancerDetail(msg.detail, m.detailCursor)
+		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
+		m.detailViewport.SetContent(content)
+		return m, nil
+
+	case nfsLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			slog.Error("Failed to load NFS appliances", slog.Any("error", msg.err))
+			m.err = msg.err
+			return m, nil
+		}
+		slog.Info("NFS appliances loaded successfully", slog.Int("count", len(msg.nfsList)))
+
+		// Convert NFS to list items
+		items := make([]list.Item, len(msg.nfsList))
+		for i, nfs := range msg.nfsList {
+			items[i] = nfs
+		}
+		m.list.SetItems(items)
+		return m, nil
+
+	case nfsDetailLoadedMsg:
+		m.detailLoading = false
+		if msg.err != nil {
+			slog.Error("Failed to load NFS detail", slog.Any("error", msg.err))
+			m.err = msg.err
+			m.detailMode = false
+			return m, nil
+		}
+		m.nfsDetail = msg.detail
+		// Setup viewport for detail view
+		content := m.renderLinkedDetail()
+		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
+		m.detailViewport.SetContent(content)
+		return m, nil
+
+	case sshKeysLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			slog.Error("Failed to load SSH keys", slog.Any("error", msg.err))
+			m.err = msg.err
+			return m, nil
+		}
+		slog.Info("SSH keys loaded successfully", slog.Int("count", len(msg.sshKeys)))
+
+		// Convert SSH keys to list items
+		items := make([]list.Item, len(msg.sshKeys))
+		for i, sshKey := range msg.sshKeys {
+			items[i] = sshKey
+		}
+		m.list.SetItems(items)
+		return m, nil
+
+	case sshKeyDetailLoadedMsg:
+		m.detailLoading = false
+		if msg.err != nil {
+			slog.Error("Failed to load SSH key detail", slog.Any("error", msg.err))
+			m.err = msg.err
+			m.detailMode = false
+			return m, nil
+		}
+		m.sshKeyDetail = msg.detail
+		// Setup viewport for detail view
+		content := renderSSHKeyDetail(msg.detail)
+		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
+		m.detailViewport.SetContent(content)
+		return m, nil
+
+	case autoBackupsLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			slog.Error("Failed to load auto backups", slog.Any("error", msg.err))
+			m.err = msg.err
+			return m, nil
+		}
+		slog.Info("Auto backups loaded successfully", slog.Int("count", len(msg.autoBackups)))
+
+		// Convert auto backups to list items
+		items := make([]list.Item, len(msg.autoBackups))
+		for i, ab := range msg.autoBackups {
+			items[i] = ab
+		}
+		m.list.SetItems(items)
+		return m, nil
+
+	case autoBackupDetailLoadedMsg:
+		m.detailLoading = false
+		if msg.err != nil {
+			slog.Error("Failed to load auto backup detail", slog.Any("error", msg.err))
+			m.err = msg.err
+			m.detailMode = false
+			return m, nil
+		}
+		m.autoBackupDetail = msg.detail
+		// Setup viewport for detail view
+		content := m.renderLinkedDetail()
+		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
+		m.detailViewport.SetContent(content)
+		return m, nil
+
+	case simpleMonitorsLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			slog.Error("Failed to load simple monitors", slog.Any("error", msg.err))
+			m.err = msg.err
+			return m, nil
+		}
+		slog.Info("Simple monitors loaded successfully", slog.Int("count", len(msg.simpleMonitors)))
+
+		m.simpleMonitors = msg.simpleMonitors
+		m.simpleMonitorMarks = nil
+		m.setSimpleMonitorItems()
+		return m, nil
+
+	case simpleMonitorDetailLoadedMsg:
+		m.detailLoading = false
+		if msg.err != nil {
+			slog.Error("Failed to load simple monitor detail", slog.Any("error", msg.err))
+			m.err = msg.err
+			m.detailMode = false
+			return m, nil
+		}
+		m.simpleMonitorDetail = msg.detail
+		// Setup viewport for detail view
+		content := renderSimpleMonitorDetail(msg.detail)
+		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
+		m.detailViewport.SetContent(content)
+		return m, nil
+
+	case bridgesLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			slog.Error("Failed to load bridges", slog.Any("error", msg.err))
+			m.err = msg.err
+			return m, nil
+		}
+		slog.Info("Bridges loaded successfully", slog.Int("count", len(msg.bridges)))
+
+		// Convert bridges to list items
+		items := make([]list.Item, len(msg.bridges))
+		for i, br := range msg.bridges {
+			items[i] = br
+		}
+		m.list.SetItems(items)
+		return m, nil
+
+	case bridgeDetailLoadedMsg:
+		m.detailLoading = false
+		if msg.err != nil {
+			slog.Error("Failed to load bridge detail", slog.Any("error", msg.err))
+			m.err = msg.err
+			m.detailMode = false
+			return m, nil
+		}
+		m.bridgeDetail = msg.detail
+		// Setup viewport for detail view
+		content := renderBridgeDetail(msg.detail)
+		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
+		m.detailViewport.SetContent(content)
+		return m, nil
+
+	case containerRegistriesLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			slog.Error("Failed to load container registries", slog.Any("error", msg.err))
+			m.err = msg.err
+			return m, nil
+		}
+		slog.Info("Container registries loaded successfully", slog.Int("count", len(msg.containerRegistries)))
+
+		// Convert container registries to list items
+		items := make([]list.Item, len(msg.containerRegistries))
+		for i, cr := range msg.containerRegistries {
+			items[i] = cr
+		}
+		m.list.SetItems(items)
+		return m, nil
+
+	case containerRegistryDetailLoadedMsg:
+		m.detailLoading = false
+		if msg.err != nil {
+			slog.Error("Failed to load container registry detail", slog.Any("error", msg.err))
+			m.err = msg.err
+			m.detailMode = false
+			return m, nil
+		}
+		m.containerRegistryDetail = msg.detail
+		// Setup viewport for detail view
+		content := renderContainerRegistryDetail(msg.detail)
+		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
+		m.detailViewport.SetContent(content)
+		return m, nil
+
+	case registryRepositoriesLoadedMsg:
+		m.detailLoading = false
+		if m.registryBrowser == nil || m.containerRegistryDetail == nil {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("Error: %v", msg.err)
+		}
+		m.registryBrowser.repositories = msg.repositories
+		m.registryBrowser.repository = ""
+		m.registryBrowser.tags = nil
+		m.detailCursor = 0
+		m.detailViewport.SetContent(renderRegistryBrowser(m.containerRegistryDetail, m.registryBrowser, m.detailCursor))
+		m.detailViewport.GotoTop()
+		return m, nil
+
+	case registryTagsLoadedMsg:
+		m.detailLoading = false
+		if m.registryBrowser == nil || m.containerRegistryDetail == nil {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("Error: %v", msg.err)
+		}
+		if m.registryBrowser.repository != msg.repository {
+			m.detailCursor = 0
+		}
+		m.registryBrowser.repository = msg.repository
+		m.registryBrowser.tags = msg.tags
+		m.detailCursor = min(m.detailCursor, max(len(msg.tags)-1, 0))
+		m.detailViewport.SetContent(renderRegistryBrowser(m.containerRegistryDetail, m.registryBrowser, m.detailCursor))
+		return m, nil
+
+	case storageTokenLoadedMsg:
+		m.detailLoading = false
+		if !m.storageQueryOpen(msg.kind) {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("Error: %v", msg.err)
+			return m, nil
+		}
+		cmd := m.connectStorageQuery(msg.kind, "", msg.token)
+		return m, cmd
+
+	case metricsQueryResultMsg:
+		m.detailLoading = false
+		q := m.metricsQuery
+		if q == nil || m.monitoringMetricsStorageDetail == nil {
+			return m, nil
+		}
+		m.statusMessage = ""
+		q.query = msg.query
+		q.result = msg.result
+		q.err = msg.err
+		m.detailViewport.SetContent(renderMetricsQuery(m.monitoringMetricsStorageDetail, q, m.detailCursor))
+		m.detailViewport.GotoTop()
+		return m, nil
+
+	case alertRulesLoadedMsg:
+		m.detailLoading = false
+		if m.alertRules == nil || m.monitoringMetricsStorageDetail == nil {
+			return m, nil
+		}
+		m.alertRules.err = msg.err
+		if msg.err == nil {
+			m.alertRules.rules = msg.rules
+			m.detailCursor = min(m.detailCursor, max(len(msg.rules.Rules)-1, 0))
+		}
+		m.detailViewport.SetContent(renderAlertRules(m.monitoringMetricsStorageDetail, m.alertRules, m.detailCursor))
+		return m, nil
+
+	case logEntriesLoadedMsg:
+		v := m.logViewer
+		if v == nil || m.monitoringLogStorageDetail == nil {
+			return m, nil
+		}
+		if msg.seq == 0 {
+			m.detailLoading = false
+			m.statusMessage = ""
+			v.entries = msg.entries
+		} else {
+			if !v.follow || msg.seq != v.followSeq {
 				return m, nil
 			}
+			if msg.err == nil {
+				v.entries = append(v.entries, msg.entries...)
+				v.entries = v.entries[max(0, len(v.entries)-logViewerMaxEntries):]
+			}
+		}
+		v.err = msg.err
+		if msg.err != nil && v.follow {
+			v.follow = false
+			m.statusMessage = "Stopped following: the query failed"
+		}
+		m.detailViewport.SetContent(renderLogViewer(m.monitoringLogStorageDetail, v))
+		if v.follow || msg.seq == 0 {
+			m.detailViewport.GotoBottom()
+		}
+		if !v.follow {
 			return m, nil
 		}
+		seq := v.followSeq
+		return m, tea.Tick(5*time.Second, func(time.Time) tea.Msg {
+			return logFollowTickMsg{seq: seq}
+		})
 
-		// Normal mode
-		switch msg.String() {
-		case "ctrl+c", "q":
-			slog.Info("User requested quit")
-			m.quitting = true
-			return m, tea.Quit
+	case logFollowTickMsg:
+		v := m.logViewer
+		if v == nil || !v.follow || msg.seq != v.followSeq {
+			return m, nil
+		}
+		return m, m.pollLogs()
 
-		case "esc":
-			// For AppRun Dedicated, go back up the drilldown hierarchy
-			if m.resourceType == ResourceTypeAppRunDedicated && m.appRunDrilldownLevel > 0 {
-				m.loading = true
-				if m.appRunDrilldownLevel == 2 {
-					// From LB or Version list, go back to ASG + Applications list
-					m.appRunDrilldownLevel = 1
-					m.appRunSelectedASGID = ""
-					m.appRunSelectedAppID = ""
-					return m, loadAppRunClusterContents(m.client, m.appRunSelectedClusterID)
-				} else if m.appRunDrilldownLevel == 1 {
-					// From ASG + Applications list, go back to Cluster list
-					m.appRunDrilldownLevel = 0
-					m.appRunSelectedClusterID = ""
-					return m, loadAppRunClusters(m.client)
-				}
+	case storageKeysLoadedMsg:
+		m.detailLoading = false
+		b := m.storageKeys
+		if b == nil {
+			return m, nil
+		}
+		b.loaded = true
+		b.err = msg.err
+		if msg.err == nil {
+			b.keys = msg.keys
+		}
+		if b.created != nil {
+			// Select the key that has just been created
+			if i := slices.IndexFunc(b.keys, func(k StorageAccessKey) bool { return k.UID == b.created.UID }); i >= 0 {
+				m.detailCursor = i
 			}
-			// Otherwise ignore ESC in list view to prevent accidental exit
+		}
+		m.detailCursor = min(m.detailCursor, max(len(b.keys)-1, 0))
+		m.detailViewport.SetContent(renderStorageKeys(b, m.detailCursor))
+		return m, nil
+
+	case storageKeyCreatedMsg:
+		b := m.storageKeys
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("Error: %v", msg.err)
+			return m, nil
+		}
+		if b == nil {
 			return m, nil
+		}
+		b.created = msg.key
+		m.statusMessage = fmt.Sprintf("Created access key %s; copy the secret now, it is not shown again", msg.key.UID)
+		m.detailLoading = true
+		m.detailViewport.GotoTop()
+		return m, loadStorageKeys(m.client, b.ref)
 
-		case "enter":
-			// Show detail based on resource type
-			if len(m.list.Items()) > 0 {
-				selectedItem := m.list.SelectedItem()
-				if server, ok := selectedItem.(Server); ok {
-					m.detailMode = true
-					m.detailLoading = true
-					return m, loadServerDetail(m.client, server.ID)
-				}
-				if sw, ok := selectedItem.(Switch); ok {
-					m.detailMode = true
-					m.detailLoading = true
-					return m, loadSwitchDetail(m.client, sw.ID)
-				}
-				if dns, ok := selectedItem.(DNS); ok {
-					m.detailMode = true
-					m.detailLoading = true
-					return m, loadDNSDetail(m.client, dns.ID)
-				}
-				if elb, ok := selectedItem.(ELB); ok {
-					m.detailMode = true
-					m.detailLoading = true
-					return m, loadELBDetail(m.client, elb.ID)
-				}
-				if gslb, ok := selectedItem.(GSLB); ok {
-					m.detailMode = true
-					m.detailLoading = true
-					return m, loadGSLBDetail(m.client, gslb.ID)
-				}
-				if db, ok := selectedItem.(DB); ok {
-					m.detailMode = true
-					m.detailLoading = true
-					return m, loadDBDetail(m.client, db.ID)
-				}
-				if disk, ok := selectedItem.(Disk); ok {
-					m.detailMode = true
-					m.detailLoading = true
-					return m, loadDiskDetail(m.client, disk.ID)
-				}
-				if archive, ok := selectedItem.(Archive); ok {
-					m.detailMode = true
-					m.detailLoading = true
-					return m, loadArchiveDetail(m.client, archive.ID)
-				}
-				if internet, ok := selectedItem.(Internet); ok {
-					m.detailMode = true
-					m.detailLoading = true
-					return m, loadInternetDetail(m.client, internet.ID)
-				}
-				if vpcRouter, ok := selectedItem.(VPCRouter); ok {
-					m.detailMode = true
-					m.detailLoading = true
-					return m, loadVPCRouterDetail(m.client, vpcRouter.ID)
-				}
-				if pf, ok := selectedItem.(PacketFilter); ok {
-					m.detailMode = true
-					m.detailLoading = true
-					return m, loadPacketFilterDetail(m.client, pf.ID)
-				}
-				if lb, ok := selectedItem.(LoadBalancer); ok {
-					m.detailMode = true
-					m.detailLoading = true
-					return m, loadLoadBalancerDetail(m.client, lb.ID)
-				}
-				if nfs, ok := selectedItem.(NFS); ok {
-					m.detailMode = true
-					m.detailLoading = true
-					return m, loadNFSDetail(m.client, nfs.ID)
-				}
-				if sshKey, ok := selectedItem.(SSHKey); ok {
-					m.detailMode = true
-					m.detailLoading = true
-					return m, loadSSHKeyDetail(m.client, sshKey.ID)
-				}
-				if ab, ok := selectedItem.(AutoBackup); ok {
-					m.detailMode = true
-					m.detailLoading = true
-					return m, loadAutoBackupDetail(m.client, ab.ID)
-				}
-				if sm, ok := selectedItem.(SimpleMonitor); ok {
-					m.detailMode = true
-					m.detailLoading = true
-					return m, loadSimpleMonitorDetail(m.client, sm.ID)
-				}
-				if br, ok := selectedItem.(Bridge); ok {
-					m.detailMode = true
-					m.detailLoading = true
-					return m, loadBridgeDetail(m.client, br.ID)
-				}
-				if cr, ok := selectedItem.(ContainerRegistry); ok {
-					m.detailMode = true
-					m.detailLoading = true
-					return m, loadContainerRegistryDetail(m.client, cr.ID)
-				}
-				if arc, ok := selectedItem.(AppRunCluster); ok {
-					// Drilldown into ASG + Applications list
-					m.loading = true
-					m.appRunSelectedClusterID = arc.ID
-					m.appRunDrilldownLevel = 1
-					return m, loadAppRunClusterContents(m.client, arc.ID)
-				}
-				if asg, ok := selectedItem.(AppRunASG); ok {
-					// Show ASG detail with worker nodes
-					m.detailMode = true
-					m.detailLoading = true
-					m.appRunSelectedASGID = asg.ID
-					return m, loadAppRunASGDetail(m.client, asg.ClusterID, asg.ID)
-				}
-				if lb, ok := selectedItem.(AppRunLB); ok {
-					// Show LB detail
-					m.detailMode = true
-					m.detailLoading = true
-					return m, loadAppRunLBDetail(m.client, lb.ClusterID, lb.ASGID, lb.ID)
-				}
-				if app, ok := selectedItem.(AppRunApplication); ok {
-					// Drilldown into Version list
-					m.loading = true
-					m.appRunSelectedAppID = app.ID
-					m.appRunActiveVersion = app.ActiveVersion
-					m.appRunDrilldownLevel = 2
-					return m, loadAppRunVersions(m.client, app.ID, app.ClusterID, app.ActiveVersion)
-				}
-				if _, ok := selectedItem.(AppRunVersion); ok {
-					// Version - show detail later
-					return m, nil
-				}
-				if ls, ok := selectedItem.(MonitoringLogStorage); ok {
-					m.detailMode = true
-					m.detailLoading = true
-					return m, loadMonitoringLogStorageDetail(m.client, getNilInt64AsString(ls.ResourceID))
-				}
-				if ms, ok := selectedItem.(MonitoringMetricsStorage); ok {
-					m.detailMode = true
-					m.detailLoading = true
-					return m, loadMonitoringMetricsStorageDetail(m.client, getNilInt64AsString(ms.ResourceID))
-				}
-				if ts, ok := selectedItem.(MonitoringTraceStorage); ok {
-					m.detailMode = true
-					m.detailLoading = true
-					return m, loadMonitoringTraceStorageDetail(m.client, strconv.FormatInt(ts.ResourceID, 10))
-				}
+	case tracesFoundMsg:
+		m.detailLoading = false
+		e := m.traceExplorer
+		if e == nil || m.monitoringTraceStorageDetail == nil {
+			return m, nil
+		}
+		m.statusMessage = ""
+		e.traces = msg.traces
+		e.err = msg.err
+		e.trace = nil
+		e.rows = nil
+		m.detailCursor = 0
+		m.detailViewport.SetContent(renderTraceExplorer(m.monitoringTraceStorageDetail, e, m.detailCursor))
+		m.detailViewport.GotoTop()
+		return m, nil
+
+	case traceLoadedMsg:
+		m.detailLoading = false
+		e := m.traceExplorer
+		if e == nil || m.monitoringTraceStorageDetail == nil {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("Error: %v", msg.err)
+			return m, nil
+		}
+		m.statusMessage = ""
+		if e.trace == nil || e.trace.ID != msg.trace.ID {
+			// Opening a trace from the search results
+			e.traceCursor = m.detailCursor
+			m.detailCursor = 0
+			m.detailViewport.GotoTop()
+		}
+		e.trace = msg.trace
+		e.rows = msg.trace.Rows()
+		m.detailCursor = min(m.detailCursor, max(len(e.rows)-1, 0))
+		m.detailViewport.SetContent(renderTraceExplorer(m.monitoringTraceStorageDetail, e, m.detailCursor))
+		return m, nil
+
+	case appRunClustersLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			slog.Error("Failed to load AppRun clusters", slog.Any("error", msg.err))
+			m.err = msg.err
+			return m, nil
+		}
+		slog.Info("AppRun clusters loaded successfully", slog.Int("count", len(msg.clusters)))
+
+		// Convert clusters to list items
+		items := make([]list.Item, len(msg.clusters))
+		for i, cluster := range msg.clusters {
+			items[i] = cluster
+		}
+		m.list.SetItems(items)
+		return m, nil
+
+	case appRunClusterDetailLoadedMsg:
+		m.detailLoading = false
+		if msg.err != nil {
+			slog.Error("Failed to load AppRun cluster detail", slog.Any("error", msg.err))
+			m.err = msg.err
+			m.detailMode = false
+			return m, nil
+		}
+		m.appRunClusterDetail = msg.detail
+		// Setup viewport for detail view
+		content := renderAppRunClusterDetail(msg.detail)
+		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
+		m.detailViewport.SetContent(content)
+		return m, nil
+
+	case appRunASGsLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			slog.Error("Failed to load AppRun ASGs", slog.Any("error", msg.err))
+			m.err = msg.err
+			return m, nil
+		}
+		slog.Info("AppRun ASGs loaded successfully", slog.Int("count", len(msg.asgs)))
+
+		items := make([]list.Item, len(msg.asgs))
+		for i, asg := range msg.asgs {
+			items[i] = asg
+		}
+		m.list.SetItems(items)
+		return m, nil
+
+	case appRunLBsLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			slog.Error("Failed to load AppRun LBs", slog.Any("error", msg.err))
+			m.err = msg.err
+			return m, nil
+		}
+		slog.Info("AppRun LBs loaded successfully", slog.Int("count", len(msg.lbs)))
+
+		items := make([]list.Item, len(msg.lbs))
+		for i, lb := range msg.lbs {
+			items[i] = lb
+		}
+		m.list.SetItems(items)
+		return m, nil
+
+	case appRunClusterContentsLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			slog.Error("Failed to load AppRun cluster contents", slog.Any("error", msg.err))
+			m.err = msg.err
+			return m, nil
+		}
+		slog.Info("AppRun cluster contents loaded", slog.Int("asgs", len(msg.asgs)), slog.Int("apps", len(msg.apps)))
+
+		// Combine ASGs, Applications and Certificates into a single list
+		items := make([]list.Item, 0, len(msg.asgs)+len(msg.apps)+len(msg.certs))
+		for _, asg := range msg.asgs {
+			items = append(items, asg)
+		}
+		for _, app := range msg.apps {
+			items = append(items, app)
+		}
+		for _, cert := range msg.certs {
+			items = append(items, cert)
+		}
+		m.list.SetItems(items)
+		return m, nil
+
+	case appRunVersionsLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			slog.Error("Failed to load AppRun versions", slog.Any("error", msg.err))
+			m.err = msg.err
+			return m, nil
+		}
+		slog.Info("AppRun versions loaded", slog.Int("count", len(msg.versions)))
+
+		items := make([]list.Item, len(msg.versions))
+		for i, ver := range msg.versions {
+			items[i] = ver
+		}
+		m.list.SetItems(items)
+		// Reloads during a rollout keep the mark while the marked version still exists
+		if msg.applicationID != m.appRunCompareAppID || !slices.ContainsFunc(msg.versions, func(v AppRunVersion) bool {
+			return v.Version == m.appRunCompareVersion
+		}) {
+			m.appRunCompareVersion = 0
+		}
+		m.setListDelegate()
+		return m, nil
+
+	case appRunLBDetailLoadedMsg:
+		m.detailLoading = false
+		if msg.err != nil {
+			slog.Error("Failed to load AppRun LB detail", slog.Any("error", msg.err))
+			m.err = msg.err
+			m.detailMode = false
+			return m, nil
+		}
+		m.appRunLBDetail = msg.detail
+		// Setup viewport for detail view
+		content := renderAppRunLBDetail(msg.detail)
+		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
+		m.detailViewport.SetContent(content)
+		return m, nil
+
+	case appRunASGDetailLoadedMsg:
+		m.detailLoading = false
+		if msg.err != nil {
+			slog.Error("Failed to load AppRun ASG detail", slog.Any("error", msg.err))
+			m.err = msg.err
+			m.detailMode = false
+			return m, nil
+		}
+		m.appRunASGDetail = msg.detail
+		m.appRunWorkerNodes = msg.workerNodes
+		// Select the node the detail was opened for, or the first one
+		m.detailCursor = 0
+		for i, node := range msg.workerNodes {
+			if node.matches(m.appRunFocusNodeID) {
+				m.detailCursor = i
 			}
+		}
+		m.appRunFocusNodeID = ""
+		// Setup viewport for detail view
+		content := renderAppRunASGDetail(msg.detail, msg.workerNodes, m.detailCursor)
+		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
+		m.detailViewport.SetContent(content)
+		return m, nil
+
+	case appRunCertificateLoadedMsg:
+		m.detailLoading = false
+		if msg.err != nil {
+			slog.Error("Failed to load AppRun certificate", slog.Any("error", msg.err))
+			m.err = msg.err
+			m.detailMode = false
 			return m, nil
+		}
+		m.appRunCertificateDetail = msg.cert
+		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
+		m.detailViewport.SetContent(renderAppRunCertificateDetail(msg.cert, time.Now()))
+		return m, nil
 
-		case "/":
-			m.searchMode = true
-			m.searchInput.Focus()
-			m.searchInput.Reset()
+	case appRunContainerPlacementLoadedMsg:
+		m.detailLoading = false
+		if msg.err != nil {
+			slog.Error("Failed to load AppRun container placement", slog.Any("error", msg.err))
+			m.err = msg.err
+			m.detailMode = false
+			return m, nil
+		}
+		m.appRunPlacement = msg.placement
+		m.detailCursor = min(m.detailCursor, max(len(msg.placement.Nodes)-1, 0))
+		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
+		m.detailViewport.SetContent(renderAppRunContainerPlacement(msg.placement, m.detailCursor))
+		return m, nil
+
+	case topologyLoadedMsg:
+		m.detailLoading = false
+		if msg.err != nil {
+			slog.Error("Failed to load topology", slog.Any("error", msg.err))
+			m.err = msg.err
+			m.detailMode = false
+			m.topology = nil
+			return m, nil
+		}
+		if m.topology == nil {
+			return m, nil
+		}
+		m.topology.topology = msg.topology
+		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
+		m.detailViewport.SetContent(renderTopology(m.topology))
+		return m, nil
+
+	case observationLoadedMsg:
+		m.detailLoading = false
+		v := m.observeView
+		if v == nil {
+			return m, nil
+		}
+		v.obs, v.err = msg.obs, msg.err
+		if msg.obs != nil {
+			m.detailCursor = min(m.detailCursor, max(len(msg.obs.Storages)-1, 0))
+		}
+		m.detailViewport.SetContent(renderObservation(v, m.detailCursor, m.detailViewport.Width))
+		return m, nil
+
+	case appRunClusterEditLoadedMsg:
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("Error: %v", msg.err)
+			return m, nil
+		}
+		m.statusMessage = ""
+		client, cluster := m.client, msg.detail
+		m.form = newAppRunClusterEditForm(cluster, func(email, servicePrincipalID string) tea.Cmd {
+			return updateAppRunCluster(client, cluster, email, servicePrincipalID)
+		})
+		return m, textinput.Blink
+
+	case appRunServiceClassesLoadedMsg:
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("Error: %v", msg.err)
+			return m, nil
+		}
+		m.statusMessage = ""
+		client := m.client
+		if asg := msg.asg; asg != nil {
+			m.form = newAppRunLBForm(asg.Name, msg.classes, func(spec AppRunLBSpec) tea.Cmd {
+				return createAppRunLB(client, *asg, spec)
+			})
 			return m, textinput.Blink
+		}
+		clusterID := m.appRunSelectedClusterID
+		m.form = newAppRunASGForm(m.currentZone, msg.classes, func(spec AppRunASGSpec) tea.Cmd {
+			return createAppRunASG(client, clusterID, spec)
+		})
+		return m, textinput.Blink
 
-		case "n":
-			m.nextMatch()
+	case appRunVersionSpecLoadedMsg:
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("Error: %v", msg.err)
 			return m, nil
+		}
+		m.statusMessage = ""
+		m.form = m.newAppRunDeployForm(msg.app, msg.detail.Spec)
+		return m, textinput.Blink
 
-		case "N":
-			m.prevMatch()
+	case appRunVersionCreatedMsg:
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("Error: %v", msg.err)
+			return m, m.reloadAppRunVersions(msg.app.ID)
+		}
+		if !msg.activated {
+			m.statusMessage = fmt.Sprintf("Created v%d", msg.version)
+			return m, m.reloadAppRunVersions(msg.app.ID)
+		}
+		return m.startAppRunRollout(msg.app, msg.version, fmt.Sprintf("Created and activated v%d", msg.version))
+
+	case appRunVersionComparisonLoadedMsg:
+		m.detailLoading = false
+		if msg.err != nil {
+			slog.Error("Failed to load AppRun versions for comparison", slog.Any("error", msg.err))
+			m.err = msg.err
+			m.detailMode = false
 			return m, nil
+		}
+		m.appRunVersionComparison = msg.comparison
+		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
+		m.detailViewport.SetContent(renderAppRunVersionComparison(msg.comparison))
+		return m, nil
 
-		case "t":
-			// Open resource type selector menu
-			m.resourceSelectMode = true
-			// Set cursor to current resource type
-			for i, rt := range AllResourceTypes {
-				if rt == m.resourceType {
-					m.resourceSelectCursor = i
-					break
-				}
-			}
+	case appRunVersionActivatedMsg:
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("Error: %v", msg.err)
 			return m, nil
+		}
+		return m.startAppRunRollout(msg.app, msg.version, fmt.Sprintf("Activated v%d", msg.version))
 
-		case "z":
-			// Zone switching only affects zone-dependent resources (DNS, ELB, GSLB, SSHKey, SimpleMonitor, ContainerRegistry, AppRunCluster, Monitoring are global)
-			if m.resourceType == ResourceTypeDNS || m.resourceType == ResourceTypeELB || m.resourceType == ResourceTypeGSLB || m.resourceType == ResourceTypeSSHKey || m.resourceType == ResourceTypeSimpleMonitor || m.resourceType == ResourceTypeContainerRegistry || m.resourceType == ResourceTypeAppRunDedicated || m.resourceType == ResourceTypeMonitoringLogStorage || m.resourceType == ResourceTypeMonitoringMetricsStorage || m.resourceType == ResourceTypeMonitoringTraceStorage {
-				return m, nil
-			}
-			oldZone := m.currentZone
-			m.cursor = (m.cursor + 1) % len(m.zones)
-			m.currentZone = m.zones[m.cursor]
-			slog.Info("User switched zone via keyboard",
-				slog.String("from", oldZone),
-				slog.String("to", m.currentZone))
-			m.client.SetZone(m.currentZone)
-			m.loading = true
-			// Clear search when switching zones
-			m.searchQuery = ""
-			m.searchMatches = []int{}
-			m.currentMatch = -1
-			// Load appropriate resources based on current type
-			switch m.resourceType {
-			case ResourceTypeServer:
-				return m, loadServers(m.client)
-			case ResourceTypeSwitch:
-				return m, loadSwitches(m.client)
-			case ResourceTypeDB:
-				return m, loadDB(m.client)
-			case ResourceTypeDisk:
-				return m, loadDisks(m.client)
-			case ResourceTypeArchive:
-				return m, loadArchives(m.client)
-			case ResourceTypeInternet:
-				return m, loadInternet(m.client)
-			case ResourceTypeVPCRouter:
-				return m, loadVPCRouters(m.client)
-			case ResourceTypePacketFilter:
-				return m, loadPacketFilters(m.client)
-			case ResourceTypeLoadBalancer:
-				return m, loadLoadBalancers(m.client)
-			case ResourceTypeNFS:
-				return m, loadNFS(m.client)
-			case ResourceTypeAutoBackup:
-				return m, loadAutoBackups(m.client)
-			case ResourceTypeBridge:
-				return m, loadBridges(m.client)
+	case appRunRolloutTickMsg:
+		if m.appRunRollout == nil {
+			return m, nil
+		}
+		return m, checkAppRunRollout(m.client, *m.appRunRollout)
+
+	case appRunRolloutStatusMsg:
+		rollout := m.appRunRollout
+		if rollout == nil || rollout.version != msg.version {
+			return m, nil
+		}
+		reload := m.reloadAppRunVersions(rollout.app.ID)
+		switch {
+		case msg.err != nil:
+			m.appRunRollout = nil
+			m.statusMessage = fmt.Sprintf("Error: rollout of v%d: %v", msg.version, msg.err)
+			return m, nil
+		case msg.activeVersion != msg.version:
+			m.appRunRollout = nil
+			m.statusMessage = fmt.Sprintf("Rollout of v%d stopped: v%d is now active", msg.version, msg.activeVersion)
+			return m, reload
+		case msg.desiredCount > 0 && msg.activeNodes >= int64(msg.desiredCount):
+			m.appRunRollout = nil
+			m.statusMessage = fmt.Sprintf("Rollout of v%d complete (%d/%d nodes)", msg.version, msg.activeNodes, msg.desiredCount)
+			return m, reload
+		case time.Since(rollout.started) > appRunRolloutTimeout:
+			m.appRunRollout = nil
+			m.statusMessage = fmt.Sprintf("Rollout of v%d not complete after %s (%d/%d nodes)",
+				msg.version, appRunRolloutTimeout, msg.activeNodes, msg.desiredCount)
+			return m, reload
+		}
+		m.statusMessage = fmt.Sprintf("Rolling out v%d: %d/%d nodes", msg.version, msg.activeNodes, msg.desiredCount)
+		return m, tea.Batch(reload, tea.Tick(5*time.Second, func(time.Time) tea.Msg {
+			return appRunRolloutTickMsg{}
+		}))
+
+	case appRunDrainingSetMsg:
+		name := msg.node.displayName()
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("Error: %v", msg.err)
+			return m, nil
+		}
+		if !msg.draining {
+			m.statusMessage = fmt.Sprintf("Node %s accepts containers again", name)
+			if m.appRunDrain != nil && m.appRunDrain.node.ID == msg.node.ID {
+				m.appRunDrain = nil
 			}
+			return m, checkAppRunDrain(m.client, appRunDrain{node: msg.node})
+		}
+		m.appRunDrain = &appRunDrain{node: msg.node, started: time.Now()}
+		m.statusMessage = fmt.Sprintf("Draining node %s...", name)
+		return m, checkAppRunDrain(m.client, *m.appRunDrain)
+
+	case appRunDrainTickMsg:
+		if m.appRunDrain == nil {
 			return m, nil
+		}
+		return m, checkAppRunDrain(m.client, *m.appRunDrain)
 
-		case "r":
-			slog.Info("User requested refresh", slog.String("zone", m.currentZone))
-			m.loading = true
-			// Refresh appropriate resources based on current type
-			switch m.resourceType {
-			case ResourceTypeServer:
-				return m, loadServers(m.client)
-			case ResourceTypeSwitch:
-				return m, loadSwitches(m.client)
-			case ResourceTypeDNS:
-				return m, loadDNS(m.client)
-			case ResourceTypeELB:
-				return m, loadELB(m.client)
-			case ResourceTypeGSLB:
-				return m, loadGSLB(m.client)
-			case ResourceTypeDB:
-				return m, loadDB(m.client)
-			case ResourceTypeDisk:
-				return m, loadDisks(m.client)
-			case ResourceTypeArchive:
-				return m, loadArchives(m.client)
-			case ResourceTypeInternet:
-				return m, loadInternet(m.client)
-			case ResourceTypeVPCRouter:
-				return m, loadVPCRouters(m.client)
-			case ResourceTypePacketFilter:
-				return m, loadPacketFilters(m.client)
-			case ResourceTypeLoadBalancer:
-				return m, loadLoadBalancers(m.client)
-			case ResourceTypeNFS:
-				return m, loadNFS(m.client)
-			case ResourceTypeSSHKey:
-				return m, loadSSHKeys(m.client)
-			case ResourceTypeAutoBackup:
-				return m, loadAutoBackups(m.client)
-			case ResourceTypeSimpleMonitor:
-				return m, loadSimpleMonitors(m.client)
-			case ResourceTypeBridge:
-				return m, loadBridges(m.client)
-			case ResourceTypeContainerRegistry:
-				return m, loadContainerRegistries(m.client)
-			case ResourceTypeAppRunDedicated:
-				return m, loadAppRunClusters(m.client)
+	case appRunDrainStatusMsg:
+		if msg.err == nil && m.appRunASGDetail != nil && m.appRunASGDetail.ID == msg.node.ASGID {
+			m.appRunWorkerNodes = msg.nodes
+			m.detailCursor = min(m.detailCursor, max(len(msg.nodes)-1, 0))
+			m.detailViewport.SetContent(renderAppRunASGDetail(m.appRunASGDetail, m.appRunWorkerNodes, m.detailCursor))
+		}
+		drain := m.appRunDrain
+		if drain == nil || drain.node.ID != msg.node.ID {
+			return m, nil
+		}
+		name := msg.node.displayName()
+		if msg.err != nil {
+			m.appRunDrain = nil
+			m.statusMessage = fmt.Sprintf("Error: drain of node %s: %v", name, msg.err)
+			return m, nil
+		}
+		remaining := 0
+		for _, node := range msg.nodes {
+			if node.ID == msg.node.ID {
+				remaining = len(node.Containers)
 			}
+		}
+		switch {
+		case remaining == 0:
+			m.appRunDrain = nil
+			m.statusMessage = fmt.Sprintf("Node %s drained: no containers left", name)
+			return m, nil
+		case time.Since(drain.started) > appRunDrainTimeout:
+			m.appRunDrain = nil
+			m.statusMessage = fmt.Sprintf("Node %s still has %d containers after %s", name, remaining, appRunDrainTimeout)
 			return m, nil
 		}
+		m.statusMessage = fmt.Sprintf("Draining node %s: %d containers remaining", name, remaining)
+		return m, tea.Tick(5*time.Second, func(time.Time) tea.Msg {
+			return appRunDrainTickMsg{}
+		})
 
-	case serversLoadedMsg:
+	case actionDoneMsg:
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("Error: %v", msg.err)
+			// Partially applied bulk actions still need a refresh
+			return m, msg.reload
+		}
+		m.statusMessage = msg.message
+		return m, msg.reload
+
+	case monitoringLogStoragesLoadedMsg:
 		m.loading = false
 		if msg.err != nil {
-			slog.Error("Failed to load servers", slog.Any("error", msg.err))
+			slog.Error("Failed to load monitoring log storages", slog.Any("error", msg.err))
 			m.err = msg.err
 			return m, nil
 		}
-		slog.Info("Servers loaded successfully", slog.Int("count", len(msg.servers)))
+		slog.Info("Monitoring log storages loaded successfully", slog.Int("count", len(msg.storages)))
 
-		// Convert servers to list items
-		items := make([]list.Item, len(msg.servers))
-		for i, server := range msg.servers {
-			items[i] = server
+		items := make([]list.Item, len(msg.storages))
+		for i, storage := range msg.storages {
+			items[i] = storage
 		}
 		m.list.SetItems(items)
 		return m, nil
 
-	case switchesLoadedMsg:
-		m.loading = false
+	case monitoringLogStorageDetailLoadedMsg:
+		m.detailLoading = false
 		if msg.err != nil {
-			slog.Error("Failed to load switches", slog.Any("error", msg.err))
+			slog.Error("Failed to load monitoring log storage detail", slog.Any("error", msg.err))
 			m.err = msg.err
+			m.detailMode = false
 			return m, nil
 		}
-		slog.Info("Switches loaded successfully", slog.Int("count", len(msg.switches)))
-
-		// Convert switches to list items
-		items := make([]list.Item, len(msg.switches))
-		for i, sw := range msg.switches {
-			items[i] = sw
-		}
-		m.list.SetItems(items)
+		m.monitoringLogStorageDetail = msg.detail
+		// Setup viewport for detail view
+		content := renderMonitoringLogStorageDetail(msg.detail)
+		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
+		m.detailViewport.SetContent(content)
 		return m, nil
 
-	case authStatusLoadedMsg:
+	case monitoringMetricsStoragesLoadedMsg:
+		m.loading = false
 		if msg.err != nil {
-			slog.Error("Failed to load auth status", slog.Any("error", msg.err))
+			slog.Error("Failed to load monitoring metrics storages", slog.Any("error", msg.err))
+			m.err = msg.err
 			return m, nil
 		}
-		slog.Info("Setting account name in model", slog.String("accountName", msg.accountName))
-		m.accountName = msg.accountName
+		slog.Info("Monitoring metrics storages loaded successfully", slog.Int("count", len(msg.storages)))
+
+		items := make([]list.Item, len(msg.storages))
+		for i, storage := range msg.storages {
+			items[i] = storage
+		}
+		m.list.SetItems(items)
 		return m, nil
 
-	case serverDetailLoadedMsg:
+	case monitoringMetricsStorageDetailLoadedMsg:
 		m.detailLoading = false
 		if msg.err != nil {
-			slog.Error("Failed to load server detail", slog.Any("error", msg.err))
+			slog.Error("Failed to load monitoring metrics storage detail", slog.Any("error", msg.err))
 			m.err = msg.err
 			m.detailMode = false
 			return m, nil
 		}
-		m.serverDetail = msg.detail
+		m.monitoringMetricsStorageDetail = msg.detail
 		// Setup viewport for detail view
-		content := renderServerDetail(msg.detail)
+		content := renderMonitoringMetricsStorageDetail(msg.detail)
 		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
 		m.detailViewport.SetContent(content)
 		return m, nil
 
-	case switchDetailLoadedMsg:
+	case monitoringTraceStoragesLoadedMsg:
+		m.loading = false
+		if msg.err != nil {
+			slog.Error("Failed to load monitoring trace storages", slog.Any("error", msg.err))
+			m.err = msg.err
+			return m, nil
+		}
+		slog.Info("Monitoring trace storages loaded successfully", slog.Int("count", len(msg.storages)))
+
+		items := make([]list.Item, len(msg.storages))
+		for i, storage := range msg.storages {
+			items[i] = storage
+		}
+		m.list.SetItems(items)
+		return m, nil
+
+	case monitoringTraceStorageDetailLoadedMsg:
 		m.detailLoading = false
 		if msg.err != nil {
-			slog.Error("Failed to load switch detail", slog.Any("error", msg.err))
+			slog.Error("Failed to load monitoring trace storage detail", slog.Any("error", msg.err))
 			m.err = msg.err
 			m.detailMode = false
 			return m, nil
 		}
-		m.switchDetail = msg.detail
+		m.monitoringTraceStorageDetail = msg.detail
 		// Setup viewport for detail view
-		content := renderSwitchDetail(msg.detail)
+		content := renderMonitoringTraceStorageDetail(msg.detail)
 		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
 		m.detailViewport.SetContent(content)
 		return m, nil
 
-	case dnsLoadedMsg:
+	case monitoringRoutingsLoadedMsg:
 		m.loading = false
 		if msg.err != nil {
-			slog.Error("Failed to load DNS zones", slog.Any("error", msg.err))
+			slog.Error("Failed to load monitoring routings", slog.Any("error", msg.err))
 			m.err = msg.err
 			return m, nil
 		}
-		slog.Info("DNS zones loaded successfully", slog.Int("count", len(msg.dnsList)))
+		slog.Info("Monitoring routings loaded successfully",
+			slog.Int("logs", len(msg.logRoutings)),
+			slog.Int("metrics", len(msg.metricsRoutings)))
+
+		items := make([]list.Item, 0, len(msg.logRoutings)+len(msg.metricsRoutings))
+		for _, r := range msg.logRoutings {
+			items = append(items, r)
+		}
+		for _, r := range msg.metricsRoutings {
+			items = append(items, r)
+		}
+		m.list.SetItems(items)
+		return m, nil
+
+	case routingChoicesLoadedMsg:
+		if msg.err != nil {
+			m.statusMessage = fmt.Sprintf("Error: %v", msg.err)
+			return m, nil
+		}
+		m.statusMessage = ""
+		client := m.client
+		m.form = newRoutingForm(msg.choices, func(spec RoutingSpec) tea.Cmd {
+			return createMonitoringRouting(client, spec)
+		})
+		return m, textinput.Blink
+	}
+
+	// Delegate to list for navigation
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m model) View() string {
+	if m.quitting {
+		return "Bye!\n"
+	}
+
+	var b strings.Builder
+
+	// Header
+	if m.accountName != "" {
+		b.WriteString(statusBarStyle.Render(fmt.Sprintf("Account: %s", m.accountName)))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	if m.form != nil {
+		b.WriteString(m.form.view())
+		return b.String()
+	}
+
+	// Detail mode view
+	if m.detailMode {
+		if m.detailLoading {
+			b.WriteString("Loading details...\n")
+		} else if m.serverDetail != nil || m.switchDetail != nil || m.dnsDetail != nil || m.elbDetail != nil || m.gslbDetail != nil || m.dbDetail != nil || m.diskDetail != nil || m.archiveDetail != nil || m.internetDetail != nil || m.vpcRouterDetail != nil || m.packetFilterDetail != nil || m.loadBalancerDetail != nil || m.nfsDetail != nil || m.sshKeyDetail != nil || m.autoBackupDetail != nil || m.simpleMonitorDetail != nil || m.bridgeDetail != nil || m.containerRegistryDetail != nil || m.appRunClusterDetail != nil || m.appRunLBDetail != nil || m.appRunASGDetail != nil || m.appRunVersionComparison != nil || m.appRunPlacement != nil || m.appRunCertificateDetail != nil || m.monitoringLogStorageDetail != nil || m.monitoringMetricsStorageDetail != nil || m.monitoringTraceStorageDetail != nil || m.topology != nil {
+			b.WriteString(m.detailViewport.View())
+			b.WriteString("\n")
+			if m.confirmMessage != "" {
+				b.WriteString(selectedStyle.Render(m.confirmMessage + " [y/N]"))
+				b.WriteString("\n")
+			} else if m.statusMessage != "" {
+				b.WriteString(statusBarStyle.Render(m.statusMessage))
+				b.WriteString("\n")
+			}
+			b.WriteString(helpStyle.Render(m.detailHelp()))
+		}
+		return b.String()
+	}
+
+	// Resource type selector mode
+	if m.resourceSelectMode {
+		b.WriteString(titleStyle.Render("Select Resource Type"))
+		b.WriteString("\n")
+		for i, rt := range AllResourceTypes {
+			if i == m.resourceSelectCursor {
+				b.WriteString(selectedItemStyle.Render(fmt.Sprintf("> %s", rt.String())))
+			} else {
+				b.WriteString(itemStyle.Render(fmt.Sprintf("  %s", rt.String())))
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render("↑/↓/j/k: move | Enter: select | Esc: cancel"))
+		return b.String()
+	}
+
+	// Zone selector and resource type
+	// Show "global" for global resources (DNS, ELB, GSLB, SSHKey, SimpleMonitor, ContainerRegistry, AppRunCluster, Monitoring)
+	if m.resourceType.IsGlobal() {
+		b.WriteString("Zone: ")
+		b.WriteString(zoneStyle.Render("global"))
+		b.WriteString(" | Type: ")
+		b.WriteString(selectedStyle.Render(m.resourceType.String()))
+		b.WriteString("\n")
+	} else {
+		b.WriteString("Zone: ")
+		for i, zone := range m.zones {
+			if i == m.cursor {
+				b.WriteString(selectedStyle.Render(fmt.Sprintf("[%s]", zone)))
+			} else {
+				b.WriteString(zoneStyle.Render(fmt.Sprintf(" %s ", zone)))
+			}
+			b.WriteString(" ")
+		}
+		b.WriteString(" | Type: ")
+		b.WriteString(selectedStyle.Render(m.resourceType.String()))
+		b.WriteString("\n")
+	}
+
+	// Search mode or search status
+	if m.searchMode {
+		b.WriteString("/" + m.searchInput.View())
+		b.WriteString("\n")
+	} else if m.searchQuery != "" {
+		matchInfo := fmt.Sprintf("Search: %s (%d matches)", m.searchQuery, len(m.searchMatches))
+		if len(m.searchMatches) > 0 {
+			matchInfo = fmt.Sprintf("Search: %s (%d/%d)", m.searchQuery, m.currentMatch+1, len(m.searchMatches))
+		}
+		b.WriteString(helpStyle.Render(matchInfo))
+		b.WriteString("\n")
+	} else {
+		b.WriteString("\n")
+	}
+
+	// Resource list or loading/error
+	if m.loading {
+		b.WriteString(fmt.Sprintf("Loading %s...\n", strings.ToLower(m.resourceType.String())))
+	} else if m.err != nil {
+		b.WriteString(fmt.Sprintf("Error: %v\n", m.err))
+	} else {
+		// Render table header based on resource type
+		header := m.getTableHeader()
+		if header != "" {
+			b.WriteString(zoneStyle.Render(header))
+			b.WriteString("\n")
+		}
+		b.WriteString(m.list.View())
+		b.WriteString("\n")
+		if m.confirmMessage != "" {
+			b.WriteString(selectedStyle.Render(m.confirmMessage + " [y/N]"))
+			b.WriteString("\n")
+		} else if m.statusMessage != "" {
+			b.WriteString(statusBarStyle.Render(m.statusMessage))
+			b.WriteString("\n")
+		}
+		help := "Enter: details | /: search | n/N: next/prev | t: type | z: zone | r: refresh | q: quit"
+		if m.resourceType == ResourceTypeSimpleMonitor {
+			filter := "all"
+			if m.simpleMonitorUnhealthyOnly {
+				filter = "unhealthy"
+			}
+			help += fmt.Sprintf(" | u: filter (%s) | space: mark | e: enable/disable", filter)
+		}
+		if m.resourceType == ResourceTypeAppRunDedicated {
+			help += m.appRunListHelp()
+		}
+		if m.resourceType == ResourceTypeMonitoringRouting {
+			help += " | A: create routing | x: delete"
+		}
+		if !m.resourceType.IsGlobal() {
+			help += " | T: topology"
+		}
+		switch m.resourceType {
+		case ResourceTypeMonitoringLogStorage, ResourceTypeMonitoringTraceStorage:
+			help += " | A: create | E: retention | x: delete"
+		case ResourceTypeMonitoringMetricsStorage:
+			help += " | A: create | x: delete"
+		}
+		b.WriteString(helpStyle.Render(help))
+	}
+
+	return b.String()
+}
+
+// detailHelp returns the key help for the current detail view
+func (m model) detailHelp() string {
+	help := "↑/↓/j/k: scroll | ESC/q: back"
+	if len(m.detailStack) > 0 {
+		help = "↑/↓/j/k: scroll | ESC/backspace: previous detail | q: back to list"
+	}
+	if len(m.relatedLinks()) > 0 {
+		help += " | tab/shift+tab: select related | Enter: open related"
+	}
+	if m.loadBalancerDetail != nil && m.loadBalancerDetail.RealServerCount() > 0 {
+		help += " | tab/shift+tab: select server | e: enable/disable"
+	}
+	if m.nfsDetail != nil {
+		help += " | b: boot | s: shutdown"
+	}
+	if m.containerRegistryDetail != nil {
+		switch {
+		case m.registryBrowser == nil:
+			help += " | i: browse images"
+		case m.registryBrowser.repository == "":
+			help = "↑/↓/j/k: scroll | tab/shift+tab: select | Enter: tags | r: reload | ESC/q: close"
+		default:
+			help = "↑/↓/j/k: scroll | tab/shift+tab: select | d: delete tag | r: reload | ESC/q/backspace: repositories"
+		}
+	}
+	if m.monitoringLogStorageDetail != nil {
+		if m.logViewer == nil {
+			help += " | L: view logs"
+		} else {
+			help = "↑/↓/j/k: scroll | f: filter | w: window | F: follow | s: save to file | r: reload | ESC/q/backspace: close"
+		}
+	}
+	if m.monitoringMetricsStorageDetail != nil {
+		if m.alertRules != nil {
+			help = "↑/↓/j/k: scroll | tab/shift+tab: select rule | A: create | E: edit | x: delete | r: reload | ESC/q/backspace: close"
+		} else if m.metricsQuery == nil {
+			help += " | Q: query | R: alert rules"
+		} else {
+			help = "↑/↓/j/k: scroll | tab/shift+tab: select saved query | Enter: run | e: edit query | w: window | v: table/chart | r: rerun | ESC/q/backspace: close"
+		}
+	}
+	if _, ok := m.monitoringStorageRef(); ok {
+		if m.storageKeys != nil {
+			return "↑/↓/j/k: scroll | tab/shift+tab: select key | A: create | x: revoke | o: copy OTel Collector config | f: copy Fluent Bit config | r: reload | ESC/q/backspace: close"
+		}
+		help += " | K: access keys"
+	}
+	if m.monitoringTraceStorageDetail != nil {
+		switch {
+		case m.traceExplorer == nil:
+			help += " | T: explore traces"
+		case m.traceExplorer.trace == nil:
+			help = "↑/↓/j/k: scroll | tab/shift+tab: select trace | Enter: open | e: search | w: window | r: reload | ESC/q: close"
+		default:
+			help = "↑/↓/j/k: scroll | tab/shift+tab: select span | r: reload | ESC/q/backspace: traces"
+		}
+	}
+	if m.simpleMonitorDetail != nil {
+		help += " | w: change window | e: enable/disable | E: edit | c: copy"
+	}
+	if m.appRunASGDetail != nil {
+		if len(m.appRunWorkerNodes) > 0 {
+			help += " | tab/shift+tab: select node | d: drain/undrain"
+		}
+		help += " | L: create LB | X: delete LB"
+	}
+	if m.appRunPlacement != nil {
+		help += " | tab/shift+tab: select node | Enter: open node in ASG | r: reload"
+	}
+	if m.topology != nil {
+		help += " | v: tree/DOT/Mermaid | s: save to file | r: reload"
+	}
+	if m.serverDetail != nil || m.dbDetail != nil || m.appRunPlacement != nil {
+		if m.observeView != nil {
+			return "↑/↓/j/k: scroll | tab/shift+tab: select storage | Enter: open storage | r: reload | ESC/q/backspace: close"
+		}
+		help += " | O: observe"
+	}
+	return help
+}
+
+// clearDetail drops the shown detail and every panel opened from it
+func (m *model) clearDetail() {
+	m.serverDetail = nil
+	m.switchDetail = nil
+	m.dnsDetail = nil
+	m.elbDetail = nil
+	m.gslbDetail = nil
+	m.dbDetail = nil
+	m.diskDetail = nil
+	m.archiveDetail = nil
+	m.internetDetail = nil
+	m.vpcRouterDetail = nil
+	m.packetFilterDetail = nil
+	m.loadBalancerDetail = nil
+	m.nfsDetail = nil
+	m.sshKeyDetail = nil
+	m.autoBackupDetail = nil
+	m.simpleMonitorDetail = nil
+	m.bridgeDetail = nil
+	m.containerRegistryDetail = nil
+	m.registryBrowser = nil
+	m.metricsQuery = nil
+	m.alertRules = nil
+	m.logViewer = nil
+	m.traceExplorer = nil
+	m.storageKeys = nil
+	m.appRunClusterDetail = nil
+	m.appRunLBDetail = nil
+	m.appRunASGDetail = nil
+	m.appRunWorkerNodes = nil
+	m.appRunVersionComparison = nil
+	m.appRunPlacement = nil
+	m.appRunCertificateDetail = nil
+	m.monitoringLogStorageDetail = nil
+	m.monitoringMetricsStorageDetail = nil
+	m.monitoringTraceStorageDetail = nil
+	m.observeView = nil
+	m.topology = nil
+}
+
+// moveDetailCursor moves the detail row selection, wrapping around within count rows
+func (m *model) moveDetailCursor(delta, count int) {
+	if count == 0 {
+		return
+	}
+	m.detailCursor = (m.detailCursor + delta + count) % count
+}
+
+// handleDetailAction handles action keys in detail views that support them.
+// It returns handled=false for keys that should fall through to the default detail handling.
+func (m model) handleDetailAction(key string) (model, tea.Cmd, bool) {
+	if m.detailLoading {
+		return m, nil, false
+	}
+
+	if lb := m.loadBalancerDetail; lb != nil {
+		switch key {
+		case "tab", "shift+tab":
+			delta := 1
+			if key == "shift+tab" {
+				delta = -1
+			}
+			m.moveDetailCursor(delta, lb.RealServerCount())
+			m.detailViewport.SetContent(renderLoadBalancerDetail(lb, m.detailCursor))
+			return m, nil, true
+		case "e":
+			vipIndex, serverIndex, ok := lb.RealServerAt(m.detailCursor)
+			if !ok {
+				return m, nil, true
+			}
+			vip := lb.VIPs[vipIndex]
+			srv := vip.Servers[serverIndex]
+			action := "Disable"
+			if !srv.Enabled {
+				action = "Enable"
+			}
+			m.confirmMessage = fmt.Sprintf("%s real server %s:%d on VIP %s:%d?",
+				action, srv.IPAddress, srv.Port, vip.VirtualIPAddress, vip.Port)
+			m.confirmCmd = setLoadBalancerServerEnabled(m.client, lb.ID, vip, srv, !srv.Enabled)
+			return m, nil, true
+		}
+	}
 
-		// Convert DNS to list items
-		items := make([]list.Item, len(msg.dnsList))
-		for i, dns := range msg.dnsList {
-			items[i] = dns
+	if cr := m.containerRegistryDetail; cr != nil {
+		if updated, cmd, handled := m.handleRegistryBrowserAction(cr, key); handled {
+			return updated, cmd, true
 		}
-		m.list.SetItems(items)
-		return m, nil
+	}
 
-	case dnsDetailLoadedMsg:
-		m.detailLoading = false
-		if msg.err != nil {
-			slog.Error("Failed to load DNS detail", slog.Any("error", msg.err))
-			m.err = msg.err
-			m.detailMode = false
-			return m, nil
+	if ref, ok := m.monitoringStorageRef(); ok {
+		// While the access keys are shown, other panels of the storage cannot be opened
+		if updated, cmd, handled := m.handleStorageKeyAction(ref, key); handled || m.storageKeys != nil {
+			return updated, cmd, handled
 		}
-		m.dnsDetail = msg.detail
-		// Setup viewport for detail view
-		content := renderDNSDetail(msg.detail)
-		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
-		m.detailViewport.SetContent(content)
-		return m, nil
+	}
 
-	case elbLoadedMsg:
-		m.loading = false
-		if msg.err != nil {
-			slog.Error("Failed to load ELBs", slog.Any("error", msg.err))
-			m.err = msg.err
-			return m, nil
+	if ls := m.monitoringLogStorageDetail; ls != nil {
+		if updated, cmd, handled := m.handleLogViewerAction(ls, key); handled {
+			return updated, cmd, true
 		}
-		slog.Info("ELBs loaded successfully", slog.Int("count", len(msg.elbList)))
+	}
 
-		// Convert ELBs to list items
-		items := make([]list.Item, len(msg.elbList))
-		for i, elb := range msg.elbList {
-			items[i] = elb
+	if ts := m.monitoringTraceStorageDetail; ts != nil {
+		if updated, cmd, handled := m.handleTraceExplorerAction(ts, key); handled {
+			return updated, cmd, true
 		}
-		m.list.SetItems(items)
-		return m, nil
+	}
 
-	case elbDetailLoadedMsg:
-		m.detailLoading = false
-		if msg.err != nil {
-			slog.Error("Failed to load ELB detail", slog.Any("error", msg.err))
-			m.err = msg.err
-			m.detailMode = false
-			return m, nil
+	if ms := m.monitoringMetricsStorageDetail; ms != nil {
+		if updated, cmd, handled := m.handleAlertRuleAction(ms, key); handled {
+			return updated, cmd, true
 		}
-		m.elbDetail = msg.detail
-		// Setup viewport for detail view
-		content := renderELBDetail(msg.detail)
-		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
-		m.detailViewport.SetContent(content)
-		return m, nil
-
-	case gslbLoadedMsg:
-		m.loading = false
-		if msg.err != nil {
-			slog.Error("Failed to load GSLBs", slog.Any("error", msg.err))
-			m.err = msg.err
-			return m, nil
+		if updated, cmd, handled := m.handleMetricsQueryAction(ms, key); handled {
+			return updated, cmd, true
 		}
-		slog.Info("GSLBs loaded successfully", slog.Int("count", len(msg.gslbList)))
+	}
 
-		// Convert GSLBs to list items
-		items := make([]list.Item, len(msg.gslbList))
-		for i, gslb := range msg.gslbList {
-			items[i] = gslb
+	if sm := m.simpleMonitorDetail; sm != nil {
+		switch key {
+		case "w":
+			m.simpleMonitorWindow = (m.simpleMonitorWindow + 1) % len(SimpleMonitorWindows)
+			m.detailLoading = true
+			return m, loadSimpleMonitorDetail(m.client, sm.ID, SimpleMonitorWindows[m.simpleMonitorWindow]), true
+		case "e":
+			action := "Disable"
+			if !sm.Enabled {
+				action = "Enable"
+			}
+			m.confirmMessage = fmt.Sprintf("%s simple monitor %s?", action, sm.Name)
+			m.confirmCmd = setSimpleMonitorEnabled(m.client, sm.ID, !sm.Enabled, SimpleMonitorWindows[m.simpleMonitorWindow])
+			return m, nil, true
+		case "E":
+			m.form = m.newSimpleMonitorForm(sm, false)
+			return m, textinput.Blink, true
+		case "c":
+			m.form = m.newSimpleMonitorForm(sm, true)
+			return m, textinput.Blink, true
 		}
-		m.list.SetItems(items)
-		return m, nil
+	}
 
-	case gslbDetailLoadedMsg:
-		m.detailLoading = false
-		if msg.err != nil {
-			slog.Error("Failed to load GSLB detail", slog.Any("error", msg.err))
-			m.err = msg.err
-			m.detailMode = false
-			return m, nil
+	if asg := m.appRunASGDetail; asg != nil {
+		switch key {
+		case "tab", "shift+tab":
+			delta := 1
+			if key == "shift+tab" {
+				delta = -1
+			}
+			m.moveDetailCursor(delta, len(m.appRunWorkerNodes))
+			m.detailViewport.SetContent(renderAppRunASGDetail(asg, m.appRunWorkerNodes, m.detailCursor))
+			return m, nil, true
+		case "d":
+			if m.detailCursor < 0 || m.detailCursor >= len(m.appRunWorkerNodes) {
+				return m, nil, true
+			}
+			node := m.appRunWorkerNodes[m.detailCursor]
+			if node.Draining {
+				m.confirmMessage = fmt.Sprintf("Undrain node %s? It will accept containers again", node.displayName())
+				m.confirmCmd = setAppRunWorkerNodeDraining(m.client, node, false)
+				return m, nil, true
+			}
+			moved := "no containers will be moved"
+			if len(node.Containers) > 0 {
+				moved = fmt.Sprintf("%d containers will be moved: %s", len(node.Containers), node.containerSummary())
+			}
+			m.confirmMessage = fmt.Sprintf("Drain node %s? %s", node.displayName(), moved)
+			m.confirmCmd = setAppRunWorkerNodeDraining(m.client, node, true)
+			return m, nil, true
+		case "L":
+			m.statusMessage = "Loading LB service classes..."
+			return m, loadAppRunLBServiceClasses(m.client, asg.AppRunASG), true
+		case "X":
+			if len(asg.LoadBalancers) == 0 {
+				m.statusMessage = "Error: this ASG has no load balancers"
+				return m, nil, true
+			}
+			names := make([]string, len(asg.LoadBalancers))
+			for i, lb := range asg.LoadBalancers {
+				names[i] = lb.Name
+			}
+			client, parent, lbs := m.client, asg.AppRunASG, asg.LoadBalancers
+			m.form = newTypedNameForm("Delete load balancer",
+				fmt.Sprintf("Type the name of the LB to delete: %s", strings.Join(names, ", ")),
+				names, func(name string) tea.Cmd {
+					for _, lb := range lbs {
+						if lb.Name == name {
+							return deleteAppRunLB(client, parent, lb)
+						}
+					}
+					return nil
+				})
+			return m, textinput.Blink, true
 		}
-		m.gslbDetail = msg.detail
-		// Setup viewport for detail view
-		content := renderGSLBDetail(msg.detail)
-		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
-		m.detailViewport.SetContent(content)
-		return m, nil
+	}
 
-	case dbLoadedMsg:
-		m.loading = false
-		if msg.err != nil {
-			slog.Error("Failed to load DBs", slog.Any("error", msg.err))
-			m.err = msg.err
-			return m, nil
+	if v := m.topology; v != nil && v.topology != nil {
+		switch key {
+		case "v":
+			v.format = (v.format + 1) % len(TopologyFormats)
+			m.detailViewport.SetContent(renderTopology(v))
+			m.detailViewport.GotoTop()
+			return m, nil, true
+		case "s":
+			topology, format := v.topology, TopologyFormats[v.format]
+			ext := map[string]string{"tree": "txt", "dot": "dot", "mermaid": "mmd"}[format]
+			f := newForm("Save topology", func(values map[string]string) (tea.Cmd, error) {
+				path := values["path"]
+				if path == "" {
+					return nil, fmt.Errorf("path is required")
+				}
+				return saveTopology(path, topology, format), nil
+			})
+			f.note = fmt.Sprintf("The topology is written in the %s format shown.", format)
+			f.addField("path", "File", fmt.Sprintf("topology-%s.%s", v.topology.Zone, ext))
+			m.form = f
+			return m, nil, true
+		case "r":
+			m.detailLoading = true
+			return m, loadTopology(m.client), true
 		}
-		slog.Info("DBs loaded successfully", slog.Int("count", len(msg.dbList)))
+	}
 
-		// Convert DBs to list items
-		items := make([]list.Item, len(msg.dbList))
-		for i, db := range msg.dbList {
-			items[i] = db
-		}
-		m.list.SetItems(items)
-		return m, nil
+	if updated, cmd, handled := m.handleRelatedLinkAction(key); handled {
+		return updated, cmd, true
+	}
 
-	case dbDetailLoadedMsg:
-		m.detailLoading = false
-		if msg.err != nil {
-			slog.Error("Failed to load DB detail", slog.Any("error", msg.err))
-			m.err = msg.err
-			m.detailMode = false
-			return m, nil
+	if m.serverDetail != nil || m.dbDetail != nil || m.appRunPlacement != nil {
+		// While the observe view is shown, the keys of the detail below it are disabled
+		if updated, cmd, handled := m.handleObserveAction(key); handled || m.observeView != nil {
+			return updated, cmd, handled
 		}
-		m.dbDetail = msg.detail
-		// Setup viewport for detail view
-		content := renderDBDetail(msg.detail)
-		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
-		m.detailViewport.SetContent(content)
-		return m, nil
+	}
 
-	case disksLoadedMsg:
-		m.loading = false
-		if msg.err != nil {
-			slog.Error("Failed to load disks", slog.Any("error", msg.err))
-			m.err = msg.err
-			return m, nil
+	if placement := m.appRunPlacement; placement != nil {
+		switch key {
+		case "tab", "shift+tab":
+			delta := 1
+			if key == "shift+tab" {
+				delta = -1
+			}
+			m.moveDetailCursor(delta, len(placement.Nodes))
+			m.detailViewport.SetContent(renderAppRunContainerPlacement(placement, m.detailCursor))
+			return m, nil, true
+		case "r":
+			m.detailLoading = true
+			return m, loadAppRunContainerPlacement(m.client, placement.App), true
+		case "enter":
+			// Cross-link to the worker node in its ASG detail
+			if m.detailCursor >= len(placement.Nodes) {
+				return m, nil, true
+			}
+			node := placement.Nodes[m.detailCursor].WorkerNode
+			if node == nil {
+				m.statusMessage = "Error: worker node not found in any ASG of the cluster"
+				return m, nil, true
+			}
+			m.appRunPlacement = nil
+			m.appRunFocusNodeID = node.ID
+			m.appRunSelectedASGID = node.ASGID
+			m.statusMessage = ""
+			m.detailLoading = true
+			return m, loadAppRunASGDetail(m.client, node.ClusterID, node.ASGID), true
 		}
-		slog.Info("Disks loaded successfully", slog.Int("count", len(msg.disks)))
+	}
 
-		// Convert disks to list items
-		items := make([]list.Item, len(msg.disks))
-		for i, disk := range msg.disks {
-			items[i] = disk
+	if nfs := m.nfsDetail; nfs != nil {
+		switch key {
+		case "b":
+			m.confirmMessage = fmt.Sprintf("Boot NFS %s?", nfs.Name)
+			m.confirmCmd = bootNFS(m.client, nfs.ID)
+			return m, nil, true
+		case "s":
+			m.confirmMessage = fmt.Sprintf("Shut down NFS %s?", nfs.Name)
+			m.confirmCmd = shutdownNFS(m.client, nfs.ID)
+			return m, nil, true
 		}
-		m.list.SetItems(items)
-		return m, nil
+	}
 
-	case diskDetailLoadedMsg:
-		m.detailLoading = false
-		if msg.err != nil {
-			slog.Error("Failed to load disk detail", slog.Any("error", msg.err))
-			m.err = msg.err
-			m.detailMode = false
-			return m, nil
-		}
-		m.diskDetail = msg.detail
-		// Setup viewport for detail view
-		content := renderDiskDetail(msg.detail)
-		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
-		m.detailViewport.SetContent(content)
-		return m, nil
+	return m, nil, false
+}
 
-	case archivesLoadedMsg:
-		m.loading = false
-		if msg.err != nil {
-			slog.Error("Failed to load archives", slog.Any("error", msg.err))
-			m.err = msg.err
-			return m, nil
-		}
-		slog.Info("Archives loaded successfully", slog.Int("count", len(msg.archives)))
+// handleRegistryBrowserAction handles keys of the container registry detail and its image browser
+func (m model) handleRegistryBrowserAction(cr *ContainerRegistryDetail, key string) (model, tea.Cmd, bool) {
+	browser := m.registryBrowser
+	if browser == nil {
+		if key != "i" {
+			return m, nil, false
+		}
+		rc := m.config.Registry(cr.FQDN, cr.Name)
+		endpoint := rc.URL
+		if endpoint == "" {
+			endpoint = cr.FQDN
+		}
+		client := NewRegistryClient(endpoint, rc.Username, rc.Password)
+		m.registryBrowser = &registryBrowser{
+			client:   client,
+			endpoint: client.baseURL,
+			username: rc.Username,
+		}
+		m.detailCursor = 0
+		m.statusMessage = ""
+		m.detailLoading = true
+		return m, loadRegistryRepositories(client), true
+	}
 
-		// Convert archives to list items
-		items := make([]list.Item, len(msg.archives))
-		for i, archive := range msg.archives {
-			items[i] = archive
+	switch key {
+	case "tab", "shift+tab":
+		delta := 1
+		if key == "shift+tab" {
+			delta = -1
+		}
+		m.moveDetailCursor(delta, browser.rowCount())
+		m.detailViewport.SetContent(renderRegistryBrowser(cr, browser, m.detailCursor))
+		return m, nil, true
+	case "enter":
+		if browser.repository != "" || m.detailCursor >= len(browser.repositories) {
+			return m, nil, true
+		}
+		m.statusMessage = ""
+		m.detailLoading = true
+		return m, loadRegistryTags(browser.client, browser.repositories[m.detailCursor]), true
+	case "r":
+		m.statusMessage = ""
+		m.detailLoading = true
+		if browser.repository != "" {
+			return m, loadRegistryTags(browser.client, browser.repository), true
+		}
+		return m, loadRegistryRepositories(browser.client), true
+	case "d":
+		if browser.repository == "" || m.detailCursor >= len(browser.tags) {
+			return m, nil, true
+		}
+		tag := browser.tags[m.detailCursor]
+		if tag.Digest == "" {
+			m.statusMessage = fmt.Sprintf("Error: digest of %s is unknown", tag.Name)
+			return m, nil, true
+		}
+		// Deleting by digest removes every tag that points at the same manifest
+		var shared []string
+		for _, t := range browser.tags {
+			if t.Digest == tag.Digest && t.Name != tag.Name {
+				shared = append(shared, t.Name)
+			}
 		}
-		m.list.SetItems(items)
-		return m, nil
+		m.confirmMessage = fmt.Sprintf("Delete %s:%s (%s)?", browser.repository, tag.Name, shortDigest(tag.Digest))
+		if len(shared) > 0 {
+			m.confirmMessage = fmt.Sprintf("Delete %s:%s (%s)? Also removes tags: %s",
+				browser.repository, tag.Name, shortDigest(tag.Digest), strings.Join(shared, ", "))
+		}
+		m.confirmCmd = deleteRegistryManifest(browser.client, browser.repository, tag)
+		return m, nil, true
+	case "esc", "q", "backspace":
+		m.statusMessage = ""
+		if browser.repository != "" {
+			// Back to the repository list, keeping the selected repository under the cursor
+			m.detailCursor = max(0, slices.Index(browser.repositories, browser.repository))
+			browser.repository = ""
+			browser.tags = nil
+			m.detailViewport.SetContent(renderRegistryBrowser(cr, browser, m.detailCursor))
+			return m, nil, true
+		}
+		m.registryBrowser = nil
+		m.detailCursor = 0
+		m.detailViewport.SetContent(renderContainerRegistryDetail(cr))
+		return m, nil, true
+	}
+	return m, nil, false
+}
 
-	case archiveDetailLoadedMsg:
-		m.detailLoading = false
-		if msg.err != nil {
-			slog.Error("Failed to load archive detail", slog.Any("error", msg.err))
-			m.err = msg.err
-			m.detailMode = false
-			return m, nil
+// handleMetricsQueryAction handles keys of the metrics storage detail and its PromQL query panel
+func (m model) handleMetricsQueryAction(ms *MonitoringMetricsStorageDetail, key string) (model, tea.Cmd, bool) {
+	q := m.metricsQuery
+	if q == nil {
+		if key != "Q" || m.alertRules != nil {
+			return m, nil, false
 		}
-		m.archiveDetail = msg.detail
-		// Setup viewport for detail view
-		content := renderArchiveDetail(msg.detail)
-		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
-		m.detailViewport.SetContent(content)
-		return m, nil
+		resourceID := getNilInt64AsString(ms.ResourceID)
+		mc := m.config.MetricsStorage(resourceID, getOptString(ms.Name))
+		endpoint := mc.URL
+		if endpoint == "" {
+			endpoint = ms.Endpoints.Address + "/prometheus"
+		}
+		q = &metricsQuery{endpoint: endpoint, saved: mc.Queries}
+		if len(mc.Queries) > 0 {
+			q.query = mc.Queries[0].Query
+		}
+		m.metricsQuery = q
+		m.detailCursor = 0
+		m.statusMessage = ""
+		m.detailViewport.SetContent(renderMetricsQuery(ms, q, m.detailCursor))
+		m.detailViewport.GotoTop()
+		cmd := m.connectStorageQuery(StorageKindMetrics, resourceID, mc.Token)
+		return m, cmd, true
+	}
 
-	case internetLoadedMsg:
-		m.loading = false
-		if msg.err != nil {
-			slog.Error("Failed to load internet", slog.Any("error", msg.err))
-			m.err = msg.err
-			return m, nil
-		}
-		slog.Info("Internet loaded successfully", slog.Int("count", len(msg.internet)))
+	switch key {
+	case "tab", "shift+tab":
+		delta := 1
+		if key == "shift+tab" {
+			delta = -1
+		}
+		m.moveDetailCursor(delta, len(q.saved))
+		m.detailViewport.SetContent(renderMetricsQuery(ms, q, m.detailCursor))
+		return m, nil, true
+	case "enter":
+		if m.detailCursor >= len(q.saved) {
+			return m, nil, true
+		}
+		q.query = q.saved[m.detailCursor].Query
+		cmd := m.runMetricsQuery()
+		return m, cmd, true
+	case "e":
+		if q.client == nil {
+			return m, nil, true
+		}
+		client := q.client
+		f := newForm("PromQL query", func(values map[string]string) (tea.Cmd, error) {
+			if values["query"] == "" {
+				return nil, fmt.Errorf("query is required")
+			}
+			return runMetricsQuery(client, values["query"], MetricsQueryWindows[q.window]), nil
+		})
+		f.note = fmt.Sprintf("Window: %s", MetricsQueryWindows[q.window].Label)
+		f.addField("query", "Query", q.query)
+		f.setPlaceholder(`e.g. sum by (instance) (rate(node_cpu_seconds_total{mode!="idle"}[5m]))`)
+		m.form = f
+		return m, nil, true
+	case "w":
+		q.window = (q.window + 1) % len(MetricsQueryWindows)
+		m.detailViewport.SetContent(renderMetricsQuery(ms, q, m.detailCursor))
+		cmd := m.runMetricsQuery()
+		return m, cmd, true
+	case "v":
+		q.chart = !q.chart
+		m.detailViewport.SetContent(renderMetricsQuery(ms, q, m.detailCursor))
+		return m, nil, true
+	case "r":
+		m.statusMessage = ""
+		cmd := m.runMetricsQuery()
+		return m, cmd, true
+	case "esc", "q", "backspace":
+		m.metricsQuery = nil
+		m.detailCursor = 0
+		m.statusMessage = ""
+		m.detailViewport.SetContent(renderMonitoringMetricsStorageDetail(ms))
+		m.detailViewport.GotoTop()
+		return m, nil, true
+	}
+	return m, nil, false
+}
 
-		// Convert internet to list items
-		items := make([]list.Item, len(msg.internet))
-		for i, internet := range msg.internet {
-			items[i] = internet
+// handleAlertRuleAction handles keys of the alert rule browser of the metrics storage detail
+func (m model) handleAlertRuleAction(ms *MonitoringMetricsStorageDetail, key string) (model, tea.Cmd, bool) {
+	storageID := getNilInt64AsString(ms.ResourceID)
+	b := m.alertRules
+	if b == nil {
+		if key != "R" || m.metricsQuery != nil {
+			return m, nil, false
+		}
+		m.alertRules = &alertRuleBrowser{}
+		m.detailCursor = 0
+		m.detailLoading = true
+		m.statusMessage = ""
+		m.detailViewport.SetContent(renderAlertRules(ms, m.alertRules, m.detailCursor))
+		m.detailViewport.GotoTop()
+		return m, loadAlertRules(m.client, storageID), true
+	}
+
+	var selected *MonitoringAlertRule
+	if b.rules != nil && m.detailCursor < len(b.rules.Rules) {
+		selected = &b.rules.Rules[m.detailCursor]
+	}
+	switch key {
+	case "tab", "shift+tab":
+		if b.rules == nil {
+			return m, nil, true
+		}
+		delta := 1
+		if key == "shift+tab" {
+			delta = -1
+		}
+		m.moveDetailCursor(delta, len(b.rules.Rules))
+		m.detailViewport.SetContent(renderAlertRules(ms, b, m.detailCursor))
+		return m, nil, true
+	case "A":
+		if b.rules == nil {
+			return m, nil, true
+		}
+		if len(b.rules.Projects) == 0 {
+			m.statusMessage = "Error: create an alert project first"
+			return m, nil, true
+		}
+		client := m.client
+		m.form = newAlertRuleForm(b.rules.Projects, nil, func(spec AlertRuleSpec) tea.Cmd {
+			return createAlertRule(client, storageID, spec)
+		})
+		return m, textinput.Blink, true
+	case "E":
+		if selected == nil {
+			return m, nil, true
+		}
+		client, rule := m.client, *selected
+		m.form = newAlertRuleForm(b.rules.Projects, &rule, func(spec AlertRuleSpec) tea.Cmd {
+			return updateAlertRule(client, storageID, rule, spec)
+		})
+		return m, textinput.Blink, true
+	case "x":
+		if selected == nil {
+			return m, nil, true
+		}
+		m.confirmMessage = fmt.Sprintf("Delete alert rule %s (%s)?", getOptString(selected.Name), selected.Query)
+		if len(selected.Firing) > 0 {
+			m.confirmMessage = fmt.Sprintf("Delete alert rule %s (%s)? It is firing %d alerts",
+				getOptString(selected.Name), selected.Query, len(selected.Firing))
+		}
+		m.confirmCmd = deleteAlertRule(m.client, storageID, *selected)
+		return m, nil, true
+	case "r":
+		m.detailLoading = true
+		m.statusMessage = ""
+		return m, loadAlertRules(m.client, storageID), true
+	case "esc", "q", "backspace":
+		m.alertRules = nil
+		m.detailCursor = 0
+		m.statusMessage = ""
+		m.detailViewport.SetContent(renderMonitoringMetricsStorageDetail(ms))
+		m.detailViewport.GotoTop()
+		return m, nil, true
+	}
+	return m, nil, false
+}
+
+// runMetricsQuery runs the current query of the query panel over the selected window.
+// It returns nil while the client or the query is not set yet.
+// storageQueryOpen reports whether the query panel of the given kind of storage is still open
+func (m *model) storageQueryOpen(kind StorageKind) bool {
+	switch kind {
+	case StorageKindMetrics:
+		return m.metricsQuery != nil && m.monitoringMetricsStorageDetail != nil
+	case StorageKindLogs:
+		return m.logViewer != nil && m.monitoringLogStorageDetail != nil
+	case StorageKindTraces:
+		return m.traceExplorer != nil && m.monitoringTraceStorageDetail != nil
+	}
+	return false
+}
+
+// connectStorageQuery creates the query client of the open storage panel and runs its first query.
+// Without a configured token, the first access key of the storage is fetched first.
+func (m *model) connectStorageQuery(kind StorageKind, resourceID, token string) tea.Cmd {
+	if token == "" {
+		m.detailLoading = true
+		return loadStorageToken(m.client, MonitoringStorageRef{Kind: kind, ResourceID: resourceID})
+	}
+	switch kind {
+	case StorageKindMetrics:
+		m.metricsQuery.client = NewPrometheusClient(m.metricsQuery.endpoint, token)
+		return m.runMetricsQuery()
+	case StorageKindLogs:
+		m.logViewer.client = NewLogQueryClient(m.logViewer.endpoint, token)
+		return m.reloadLogs()
+	case StorageKindTraces:
+		m.traceExplorer.client = NewTraceQueryClient(m.traceExplorer.endpoint, token)
+		m.detailLoading = true
+		return m.traceExplorer.searchCmd()
+	}
+	return nil
+}
+
+func (m *model) runMetricsQuery() tea.Cmd {
+	q := m.metricsQuery
+	if q == nil || q.client == nil || q.query == "" {
+		return nil
+	}
+	m.detailLoading = true
+	return runMetricsQuery(q.client, q.query, MetricsQueryWindows[q.window])
+}
+
+// handleLogViewerAction handles keys of the log storage detail and its log viewer
+func (m model) handleLogViewerAction(ls *MonitoringLogStorageDetail, key string) (model, tea.Cmd, bool) {
+	v := m.logViewer
+	if v == nil {
+		if key != "L" {
+			return m, nil, false
 		}
-		m.list.SetItems(items)
-		return m, nil
+		resourceID := getNilInt64AsString(ls.ResourceID)
+		lc := m.config.LogStorage(resourceID, getOptString(ls.Name))
+		endpoint := lc.URL
+		if endpoint == "" {
+			endpoint = ls.Endpoints.Ingester.Address
+		}
+		v = &logViewer{endpoint: endpoint, selector: lc.Selector}
+		m.logViewer = v
+		m.statusMessage = ""
+		m.detailViewport.SetContent(renderLogViewer(ls, v))
+		m.detailViewport.GotoTop()
+		cmd := m.connectStorageQuery(StorageKindLogs, resourceID, lc.Token)
+		return m, cmd, true
+	}
 
-	case internetDetailLoadedMsg:
-		m.detailLoading = false
-		if msg.err != nil {
-			slog.Error("Failed to load internet detail", slog.Any("error", msg.err))
-			m.err = msg.err
-			m.detailMode = false
-			return m, nil
+	switch key {
+	case "f":
+		if v.client == nil {
+			return m, nil, true
+		}
+		f := newForm("Filter logs", func(values map[string]string) (tea.Cmd, error) {
+			v.filter = values["filter"]
+			return v.reload(), nil
+		})
+		f.note = fmt.Sprintf("Selector: %s", buildLogQuery(v.selector, ""))
+		f.addField("filter", "Contains", v.filter)
+		f.setPlaceholder("case-sensitive text, empty for all lines")
+		m.form = f
+		return m, nil, true
+	case "w":
+		v.window = (v.window + 1) % len(LogViewWindows)
+		cmd := m.reloadLogs()
+		return m, cmd, true
+	case "F":
+		if v.client == nil {
+			return m, nil, true
+		}
+		v.follow = !v.follow
+		v.followSeq++
+		m.detailViewport.SetContent(renderLogViewer(ls, v))
+		if !v.follow {
+			return m, nil, true
+		}
+		m.detailViewport.GotoBottom()
+		return m, m.pollLogs(), true
+	case "s":
+		if len(v.entries) == 0 {
+			m.statusMessage = "No log entries to save"
+			return m, nil, true
+		}
+		entries := slices.Clone(v.entries)
+		f := newForm(fmt.Sprintf("Save %d log entries", len(entries)), func(values map[string]string) (tea.Cmd, error) {
+			path := values["path"]
+			if path == "" {
+				return nil, fmt.Errorf("path is required")
+			}
+			return saveLogEntries(path, entries), nil
+		})
+		f.note = "Lines are written as tab separated time, severity and message."
+		f.addField("path", "File", fmt.Sprintf("%s-%s.log", getOptString(ls.Name), time.Now().Format("20060102-150405")))
+		m.form = f
+		return m, nil, true
+	case "r":
+		cmd := m.reloadLogs()
+		return m, cmd, true
+	case "esc", "q", "backspace":
+		m.logViewer = nil
+		m.statusMessage = ""
+		m.detailViewport.SetContent(renderMonitoringLogStorageDetail(ls))
+		m.detailViewport.GotoTop()
+		return m, nil, true
+	}
+	return m, nil, false
+}
+
+// reloadLogs queries the whole window of the log viewer again
+func (m *model) reloadLogs() tea.Cmd {
+	v := m.logViewer
+	if v == nil || v.client == nil {
+		return nil
+	}
+	m.detailLoading = true
+	return v.reload()
+}
+
+// pollLogs fetches the entries newer than the last one shown
+func (m *model) pollLogs() tea.Cmd {
+	v := m.logViewer
+	start := time.Now().Add(-LogViewWindows[v.window].Duration)
+	if len(v.entries) > 0 {
+		start = v.entries[len(v.entries)-1].Time.Add(time.Nanosecond)
+	}
+	return queryLogs(v.client, v.query(), start, v.followSeq)
+}
+
+func saveLogEntries(path string, entries []LogEntry) tea.Cmd {
+	return func() tea.Msg {
+		if err := SaveLogEntries(path, entries); err != nil {
+			return actionDoneMsg{err: err}
 		}
-		m.internetDetail = msg.detail
-		// Setup viewport for detail view
-		content := renderInternetDetail(msg.detail)
-		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
-		m.detailViewport.SetContent(content)
-		return m, nil
+		return actionDoneMsg{message: fmt.Sprintf("Saved %d log entries to %s", len(entries), path)}
+	}
+}
+
+// monitoringStorageRef returns the storage shown in the log, metrics or trace storage detail
+func (m model) monitoringStorageRef() (MonitoringStorageRef, bool) {
+	switch {
+	case m.monitoringLogStorageDetail != nil:
+		return m.monitoringLogStorageDetail.StorageRef(), true
+	case m.monitoringMetricsStorageDetail != nil:
+		return m.monitoringMetricsStorageDetail.StorageRef(), true
+	case m.monitoringTraceStorageDetail != nil:
+		return m.monitoringTraceStorageDetail.StorageRef(), true
+	}
+	return MonitoringStorageRef{}, false
+}
+
+// renderMonitoringStorageDetail renders the log, metrics or trace storage detail
+func (m model) renderMonitoringStorageDetail() string {
+	switch {
+	case m.monitoringLogStorageDetail != nil:
+		return renderMonitoringLogStorageDetail(m.monitoringLogStorageDetail)
+	case m.monitoringMetricsStorageDetail != nil:
+		return renderMonitoringMetricsStorageDetail(m.monitoringMetricsStorageDetail)
+	case m.monitoringTraceStorageDetail != nil:
+		return renderMonitoringTraceStorageDetail(m.monitoringTraceStorageDetail)
+	}
+	return ""
+}
+
+// handleStorageKeyAction handles the access keys of a log, metrics or trace storage
+func (m model) handleStorageKeyAction(ref MonitoringStorageRef, key string) (model, tea.Cmd, bool) {
+	b := m.storageKeys
+	if b == nil {
+		if key != "K" || m.logViewer != nil || m.metricsQuery != nil || m.alertRules != nil || m.traceExplorer != nil {
+			return m, nil, false
+		}
+		m.storageKeys = &storageKeyBrowser{ref: ref}
+		m.detailCursor = 0
+		m.detailLoading = true
+		m.statusMessage = ""
+		m.detailViewport.SetContent(renderStorageKeys(m.storageKeys, m.detailCursor))
+		m.detailViewport.GotoTop()
+		return m, loadStorageKeys(m.client, ref), true
+	}
+
+	var selected *StorageAccessKey
+	if m.detailCursor < len(b.keys) {
+		selected = &b.keys[m.detailCursor]
+	}
+	switch key {
+	case "tab", "shift+tab":
+		delta := 1
+		if key == "shift+tab" {
+			delta = -1
+		}
+		m.moveDetailCursor(delta, len(b.keys))
+		m.detailViewport.SetContent(renderStorageKeys(b, m.detailCursor))
+		return m, nil, true
+	case "A":
+		client := m.client
+		f := newForm("Create access key for "+ref.Title(), func(values map[string]string) (tea.Cmd, error) {
+			return createStorageKey(client, ref, strings.TrimSpace(values["description"])), nil
+		})
+		f.note = "The secret is shown only once, right after the key is created."
+		f.addField("description", "Description", "")
+		f.setPlaceholder("e.g. fluent-bit on web1")
+		m.form = f
+		return m, textinput.Blink, true
+	case "x":
+		if selected == nil {
+			return m, nil, true
+		}
+		desc := selected.Description
+		if desc == "" {
+			desc = "no description"
+		}
+		m.confirmMessage = fmt.Sprintf("Revoke access key %s (%s) of %s? Agents using it can no longer send data",
+			selected.UID, desc, ref.Title())
+		if len(b.keys) == 1 {
+			m.confirmMessage += ". It is the last key of the storage"
+		}
+		m.confirmCmd = deleteStorageKey(m.client, ref, *selected)
+		return m, nil, true
+	case "o", "f":
+		if selected == nil {
+			m.statusMessage = "Error: create an access key first"
+			return m, nil, true
+		}
+		format := map[string]string{"o": "otel", "f": "fluentbit"}[key]
+		snippet, err := IngestionSnippet(ref, *selected, format)
+		if err != nil {
+			m.statusMessage = fmt.Sprintf("Error: %v", err)
+			return m, nil, true
+		}
+		b.snippet = snippet
+		m.detailViewport.SetContent(renderStorageKeys(b, m.detailCursor))
+		m.detailViewport.GotoBottom()
+		m.copyToClipboard(snippet, IngestionSnippetFormats[format]+" config")
+		return m, nil, true
+	case "r":
+		m.detailLoading = true
+		return m, loadStorageKeys(m.client, ref), true
+	case "esc", "q", "backspace":
+		m.storageKeys = nil
+		m.detailCursor = 0
+		m.statusMessage = ""
+		m.detailViewport.SetContent(m.renderMonitoringStorageDetail())
+		m.detailViewport.GotoTop()
+		return m, nil, true
+	}
+	return m, nil, false
+}
 
-	case vpcRoutersLoadedMsg:
-		m.loading = false
-		if msg.err != nil {
-			slog.Error("Failed to load VPC routers", slog.Any("error", msg.err))
-			m.err = msg.err
-			return m, nil
+// handleTraceExplorerAction handles keys of the trace storage detail and its trace explorer
+func (m model) handleTraceExplorerAction(ts *MonitoringTraceStorageDetail, key string) (model, tea.Cmd, bool) {
+	e := m.traceExplorer
+	if e == nil {
+		if key != "T" {
+			return m, nil, false
 		}
-		slog.Info("VPC routers loaded successfully", slog.Int("count", len(msg.vpcRouters)))
-
-		// Convert VPC routers to list items
-		items := make([]list.Item, len(msg.vpcRouters))
-		for i, vpcRouter := range msg.vpcRouters {
-			items[i] = vpcRouter
+		resourceID := strconv.FormatInt(ts.ResourceID, 10)
+		tc := m.config.TraceStorage(resourceID, getOptString(ts.Name))
+		endpoint := tc.URL
+		if endpoint == "" {
+			endpoint = ts.Endpoints.Ingester.Address
+		}
+		e = &traceExplorer{endpoint: endpoint, window: 1}
+		m.traceExplorer = e
+		m.detailCursor = 0
+		m.statusMessage = ""
+		m.detailViewport.SetContent(renderTraceExplorer(ts, e, m.detailCursor))
+		m.detailViewport.GotoTop()
+		cmd := m.connectStorageQuery(StorageKindTraces, resourceID, tc.Token)
+		return m, cmd, true
+	}
+	if e.client == nil {
+		if key == "esc" || key == "q" || key == "backspace" {
+			m.traceExplorer = nil
+			m.detailViewport.SetContent(renderMonitoringTraceStorageDetail(ts))
+			return m, nil, true
 		}
-		m.list.SetItems(items)
-		return m, nil
+		return m, nil, false
+	}
 
-	case vpcRouterDetailLoadedMsg:
-		m.detailLoading = false
-		if msg.err != nil {
-			slog.Error("Failed to load VPC router detail", slog.Any("error", msg.err))
-			m.err = msg.err
-			m.detailMode = false
-			return m, nil
-		}
-		m.vpcRouterDetail = msg.detail
-		// Setup viewport for detail view
-		content := renderVPCRouterDetail(msg.detail)
-		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
-		m.detailViewport.SetContent(content)
-		return m, nil
+	if e.trace != nil {
+		switch key {
+		case "tab", "shift+tab":
+			delta := 1
+			if key == "shift+tab" {
+				delta = -1
+			}
+			m.moveDetailCursor(delta, len(e.rows))
+			m.detailViewport.SetContent(renderTraceExplorer(ts, e, m.detailCursor))
+			return m, nil, true
+		case "r":
+			m.detailLoading = true
+			return m, loadTrace(e.client, e.trace.ID), true
+		case "esc", "q", "backspace":
+			// Back to the search results, keeping the trace under the cursor
+			e.trace = nil
+			e.rows = nil
+			m.detailCursor = e.traceCursor
+			m.statusMessage = ""
+			m.detailViewport.SetContent(renderTraceExplorer(ts, e, m.detailCursor))
+			m.detailViewport.GotoTop()
+			return m, nil, true
+		}
+		return m, nil, false
+	}
 
-	case packetFiltersLoadedMsg:
-		m.loading = false
-		if msg.err != nil {
-			slog.Error("Failed to load packet filters", slog.Any("error", msg.err))
-			m.err = msg.err
-			return m, nil
-		}
-		slog.Info("Packet filters loaded successfully", slog.Int("count", len(msg.packetFilters)))
+	switch key {
+	case "tab", "shift+tab":
+		delta := 1
+		if key == "shift+tab" {
+			delta = -1
+		}
+		m.moveDetailCursor(delta, len(e.traces))
+		m.detailViewport.SetContent(renderTraceExplorer(ts, e, m.detailCursor))
+		return m, nil, true
+	case "enter":
+		if m.detailCursor >= len(e.traces) {
+			return m, nil, true
+		}
+		m.statusMessage = ""
+		m.detailLoading = true
+		return m, loadTrace(e.client, e.traces[m.detailCursor].TraceID), true
+	case "e":
+		f := newForm("Search traces", func(values map[string]string) (tea.Cmd, error) {
+			search, err := parseTraceSearch(values)
+			if err != nil {
+				return nil, err
+			}
+			e.search = search
+			return e.searchCmd(), nil
+		})
+		f.note = "Empty fields match every trace. Durations apply to the whole trace."
+		f.addField("service", "Service", e.search.Service)
+		f.setPlaceholder("resource service.name, e.g. web")
+		f.addField("operation", "Operation", e.search.Operation)
+		f.setPlaceholder("span name, e.g. GET /api/users")
+		f.addField("min", "Min duration", formatSearchDuration(e.search.MinDuration))
+		f.setPlaceholder("e.g. 500ms")
+		f.addField("max", "Max duration", formatSearchDuration(e.search.MaxDuration))
+		f.setPlaceholder("e.g. 10s")
+		m.form = f
+		return m, textinput.Blink, true
+	case "w":
+		e.window = (e.window + 1) % len(TraceSearchWindows)
+		m.detailLoading = true
+		return m, e.searchCmd(), true
+	case "r":
+		m.detailLoading = true
+		return m, e.searchCmd(), true
+	case "esc", "q", "backspace":
+		m.traceExplorer = nil
+		m.detailCursor = 0
+		m.statusMessage = ""
+		m.detailViewport.SetContent(renderMonitoringTraceStorageDetail(ts))
+		m.detailViewport.GotoTop()
+		return m, nil, true
+	}
+	return m, nil, false
+}
 
-		// Convert packet filters to list items
-		items := make([]list.Item, len(msg.packetFilters))
-		for i, pf := range msg.packetFilters {
-			items[i] = pf
-		}
-		m.list.SetItems(items)
-		return m, nil
+// formatSearchDuration returns the form value of a search duration, empty when unset
+func formatSearchDuration(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return d.String()
+}
 
-	case packetFilterDetailLoadedMsg:
-		m.detailLoading = false
-		if msg.err != nil {
-			slog.Error("Failed to load packet filter detail", slog.Any("error", msg.err))
-			m.err = msg.err
-			m.detailMode = false
-			return m, nil
-		}
-		m.packetFilterDetail = msg.detail
-		// Setup viewport for detail view
-		content := renderPacketFilterDetail(msg.detail)
-		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
-		m.detailViewport.SetContent(content)
-		return m, nil
+// handleListAction handles resource specific action keys in the list view.
+// It returns handled=false for keys that should fall through to the default list handling.
+func (m model) handleListAction(key string) (model, tea.Cmd, bool) {
+	if key == "T" && !m.resourceType.IsGlobal() {
+		m.topology = &topologyView{}
+		m.detailMode = true
+		m.detailLoading = true
+		m.detailStack = nil
+		return m, loadTopology(m.client), true
+	}
+	switch m.resourceType {
+	case ResourceTypeAppRunDedicated:
+		return m.handleAppRunListAction(key)
+	case ResourceTypeMonitoringRouting:
+		return m.handleRoutingListAction(key)
+	case ResourceTypeMonitoringLogStorage:
+		return m.handleMonitoringStorageListAction(StorageKindLogs, key)
+	case ResourceTypeMonitoringMetricsStorage:
+		return m.handleMonitoringStorageListAction(StorageKindMetrics, key)
+	case ResourceTypeMonitoringTraceStorage:
+		return m.handleMonitoringStorageListAction(StorageKindTraces, key)
+	}
+	return m, nil, false
+}
 
-	case loadBalancersLoadedMsg:
-		m.loading = false
-		if msg.err != nil {
-			slog.Error("Failed to load load balancers", slog.Any("error", msg.err))
-			m.err = msg.err
-			return m, nil
-		}
-		slog.Info("Load balancers loaded successfully", slog.Int("count", len(msg.loadBalancers)))
+// selectedMonitoringStorage returns the storage under the cursor and its current retention
+func (m model) selectedMonitoringStorage() (MonitoringStorageRef, int, bool) {
+	switch s := m.list.SelectedItem().(type) {
+	case MonitoringLogStorage:
+		return s.StorageRef(), int(s.ExpireDay.Or(0)), true
+	case MonitoringMetricsStorage:
+		return s.StorageRef(), 0, true
+	case MonitoringTraceStorage:
+		return s.StorageRef(), s.RetentionPeriodDays, true
+	}
+	return MonitoringStorageRef{}, 0, false
+}
 
-		// Convert load balancers to list items
-		items := make([]list.Item, len(msg.loadBalancers))
-		for i, lb := range msg.loadBalancers {
-			items[i] = lb
-		}
-		m.list.SetItems(items)
-		return m, nil
+func (m model) handleMonitoringStorageListAction(kind StorageKind, key string) (model, tea.Cmd, bool) {
+	client := m.client
+	switch key {
+	case "A":
+		m.form = newMonitoringStorageForm(kind, func(spec MonitoringStorageSpec) tea.Cmd {
+			return createMonitoringStorage(client, spec)
+		})
+		return m, textinput.Blink, true
+	case "E":
+		ref, days, ok := m.selectedMonitoringStorage()
+		if !ok {
+			return m, nil, false
+		}
+		label := storageRetentionLabel(kind)
+		if label == "" {
+			m.statusMessage = "Error: the retention of metrics storages cannot be changed"
+			return m, nil, true
+		}
+		f := newForm("Change retention of "+ref.Title(), func(values map[string]string) (tea.Cmd, error) {
+			days, err := parseStorageRetention(kind, values["retention"])
+			if err != nil {
+				return nil, err
+			}
+			if days == 0 {
+				return nil, fmt.Errorf("%s is required", strings.ToLower(label))
+			}
+			return updateMonitoringStorageRetention(client, ref, days), nil
+		})
+		f.note = "Data older than the retention is removed."
+		f.addField("retention", label, strconv.Itoa(days))
+		m.form = f
+		return m, textinput.Blink, true
+	case "x":
+		ref, _, ok := m.selectedMonitoringStorage()
+		if !ok {
+			return m, nil, false
+		}
+		names := []string{ref.ResourceID}
+		if ref.Name != "" {
+			names = []string{ref.Name, ref.ResourceID}
+		}
+		note := fmt.Sprintf("All data in the storage is deleted. Type %s to confirm.", names[0])
+		if warning := storageRoutingWarning(m.list.SelectedItem()); warning != "" {
+			note = warning + "\n" + note
+		}
+		m.form = newTypedNameForm("Delete "+ref.Title(), note, names, func(string) tea.Cmd {
+			return deleteMonitoringStorage(client, ref)
+		})
+		return m, textinput.Blink, true
+	}
+	return m, nil, false
+}
 
-	case loadBalancerDetailLoadedMsg:
-		m.detailLoading = false
-		if msg.err != nil {
-			slog.Error("Failed to load load balancer detail", slog.Any("error", msg.err))
-			m.err = msg.err
-			m.detailMode = false
-			return m, nil
-		}
-		m.loadBalancerDetail = msg.detail
-		// Setup viewport for detail view
-		content := renderLoadBalancerDetail(msg.detail)
-		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
-		m.detailViewport.SetContent(content)
-		return m, nil
+func (m model) handleRoutingListAction(key string) (model, tea.Cmd, bool) {
+	switch key {
+	case "A":
+		m.statusMessage = fmt.Sprintf("Loading routable resources in %s...", m.currentZone)
+		return m, loadRoutingChoices(m.client), true
+	case "x":
+		item := m.list.SelectedItem()
+		switch r := item.(type) {
+		case MonitoringLogRouting:
+			m.confirmMessage = fmt.Sprintf("Delete log routing %s (resource %s -> %s, %s)?",
+				r.Title(), getOptNilInt64AsString(r.ResourceID), getOptString(r.LogStorage.Name), r.Variant)
+		case MonitoringMetricsRouting:
+			m.confirmMessage = fmt.Sprintf("Delete metrics routing %s (resource %s -> %s, %s)?",
+				r.Title(), getOptNilInt64AsString(r.ResourceID), getOptString(r.MetricsStorage.Name), r.Variant)
+		default:
+			return m, nil, false
+		}
+		m.confirmCmd = deleteMonitoringRouting(m.client, item)
+		return m, nil, true
+	}
+	return m, nil, false
+}
 
-	case nfsLoadedMsg:
-		m.loading = false
-		if msg.err != nil {
-			slog.Error("Failed to load NFS appliances", slog.Any("error", msg.err))
-			m.err = msg.err
-			return m, nil
-		}
-		slog.Info("NFS appliances loaded successfully", slog.Int("count", len(msg.nfsList)))
+// selectedAppRunApplication returns the application under the cursor, or the
+// application whose versions are being listed
+func (m model) selectedAppRunApplication() (AppRunApplication, bool) {
+	if app, ok := m.list.SelectedItem().(AppRunApplication); ok {
+		return app, true
+	}
+	if m.appRunDrilldownLevel == 2 && m.appRunSelectedAppID != "" {
+		return AppRunApplication{
+			ID:            m.appRunSelectedAppID,
+			Name:          m.appRunSelectedAppName,
+			ClusterID:     m.appRunSelectedClusterID,
+			ActiveVersion: m.appRunActiveVersion,
+		}, true
+	}
+	return AppRunApplication{}, false
+}
 
-		// Convert NFS to list items
-		items := make([]list.Item, len(msg.nfsList))
-		for i, nfs := range msg.nfsList {
-			items[i] = nfs
+func (m model) handleAppRunListAction(key string) (model, tea.Cmd, bool) {
+	switch key {
+	case "a":
+		// Make the selected version active (rollback/roll forward)
+		ver, ok := m.list.SelectedItem().(AppRunVersion)
+		if !ok {
+			return m, nil, false
 		}
-		m.list.SetItems(items)
-		return m, nil
+		if ver.IsActive {
+			m.statusMessage = fmt.Sprintf("v%d is already active", ver.Version)
+			return m, nil, true
+		}
+		app, _ := m.selectedAppRunApplication()
+		current := "(none)"
+		if active, ok := m.activeAppRunVersion(); ok {
+			current = fmt.Sprintf("v%d %s", active.Version, active.Image)
+		}
+		m.confirmMessage = fmt.Sprintf("Activate v%d? image: %s -> v%d %s", ver.Version, current, ver.Version, ver.Image)
+		m.confirmCmd = activateAppRunVersion(m.client, app, ver.Version)
+		return m, nil, true
+	case "A":
+		switch m.appRunDrilldownLevel {
+		case 0:
+			// Create a cluster
+			client := m.client
+			m.form = newAppRunClusterForm(func(spec AppRunClusterSpec) tea.Cmd {
+				return createAppRunCluster(client, spec)
+			})
+			return m, textinput.Blink, true
+		case 1:
+			// Create an auto scaling group in the cluster
+			m.statusMessage = "Loading worker service classes..."
+			return m, loadAppRunWorkerServiceClasses(m.client), true
+		}
+		return m, nil, false
+	case "x":
+		if cluster, ok := m.list.SelectedItem().(AppRunCluster); ok {
+			client := m.client
+			m.form = newTypedNameForm(fmt.Sprintf("Delete cluster %s", cluster.Name),
+				fmt.Sprintf("The cluster must not have applications left. Type %s to confirm.", cluster.Name),
+				[]string{cluster.Name}, func(string) tea.Cmd {
+					return deleteAppRunCluster(client, cluster)
+				})
+			return m, textinput.Blink, true
+		}
+		if asg, ok := m.list.SelectedItem().(AppRunASG); ok {
+			client := m.client
+			m.form = newTypedNameForm(fmt.Sprintf("Delete ASG %s", asg.Name),
+				fmt.Sprintf("Its worker nodes are removed. Type %s to confirm.", asg.Name),
+				[]string{asg.Name}, func(string) tea.Cmd {
+					return deleteAppRunASG(client, asg)
+				})
+			return m, textinput.Blink, true
+		}
+		if cert, ok := m.list.SelectedItem().(AppRunCertificate); ok {
+			m.confirmMessage = fmt.Sprintf("Delete certificate %s (CN=%s)?", cert.Name, cert.CommonName)
+			m.confirmCmd = deleteAppRunCertificate(m.client, cert)
+			return m, nil, true
+		}
+		// Delete an unused version
+		ver, ok := m.list.SelectedItem().(AppRunVersion)
+		if !ok {
+			return m, nil, false
+		}
+		if ver.IsActive || ver.ActiveNodeCount > 0 {
+			m.statusMessage = fmt.Sprintf("Error: v%d is in use and cannot be deleted", ver.Version)
+			return m, nil, true
+		}
+		app, _ := m.selectedAppRunApplication()
+		m.confirmMessage = fmt.Sprintf("Delete v%d (%s)?", ver.Version, ver.Image)
+		m.confirmCmd = deleteAppRunVersion(m.client, app, ver.Version)
+		return m, nil, true
+	case " ":
+		// Mark the selected version as the base for comparisons
+		ver, ok := m.list.SelectedItem().(AppRunVersion)
+		if !ok {
+			return m, nil, false
+		}
+		if m.appRunCompareVersion == ver.Version {
+			m.appRunCompareVersion = 0
+		} else {
+			m.appRunCompareVersion = ver.Version
+			m.appRunCompareAppID = ver.ApplicationID
+		}
+		m.setListDelegate()
+		return m, nil, true
+	case "c":
+		// Compare the selected version with the marked (or active) version
+		ver, ok := m.list.SelectedItem().(AppRunVersion)
+		if !ok {
+			return m, nil, false
+		}
+		app, _ := m.selectedAppRunApplication()
+		m.detailMode = true
+		m.detailLoading = true
+		return m, loadAppRunVersionComparison(m.client, app, m.appRunCompareBase(ver), ver.Version), true
+	case "C":
+		// Upload a new certificate to the cluster
+		if m.appRunDrilldownLevel != 1 {
+			return m, nil, false
+		}
+		client, clusterID := m.client, m.appRunSelectedClusterID
+		m.form = newAppRunCertificateForm("Upload certificate", nil, func(name string, upload *CertificateUpload) tea.Cmd {
+			return createAppRunCertificate(client, clusterID, name, upload)
+		})
+		return m, textinput.Blink, true
+	case "E":
+		if cluster, ok := m.list.SelectedItem().(AppRunCluster); ok {
+			m.statusMessage = fmt.Sprintf("Loading cluster %s...", cluster.Name)
+			return m, loadAppRunClusterForEdit(m.client, cluster.ID), true
+		}
+		// Replace the selected certificate with new PEM files
+		cert, ok := m.list.SelectedItem().(AppRunCertificate)
+		if !ok {
+			return m, nil, false
+		}
+		client := m.client
+		m.form = newAppRunCertificateForm(fmt.Sprintf("Replace certificate %s", cert.Name), &cert, func(name string, upload *CertificateUpload) tea.Cmd {
+			return updateAppRunCertificate(client, cert, name, upload)
+		})
+		return m, textinput.Blink, true
+	case "p":
+		// Show where the containers of the application run
+		app, ok := m.selectedAppRunApplication()
+		if !ok {
+			return m, nil, false
+		}
+		m.detailMode = true
+		m.detailLoading = true
+		m.detailCursor = 0
+		return m, loadAppRunContainerPlacement(m.client, app), true
+	case "D":
+		// Deploy a new version pre-filled from the active one
+		app, ok := m.selectedAppRunApplication()
+		if !ok {
+			return m, nil, false
+		}
+		if app.ActiveVersion == 0 {
+			m.form = m.newAppRunDeployForm(app, AppRunVersionSpec{})
+			return m, textinput.Blink, true
+		}
+		m.statusMessage = fmt.Sprintf("Loading v%d...", app.ActiveVersion)
+		return m, loadAppRunVersionSpec(m.client, app, app.ActiveVersion), true
+	}
+	return m, nil, false
+}
 
-	case nfsDetailLoadedMsg:
-		m.detailLoading = false
-		if msg.err != nil {
-			slog.Error("Failed to load NFS detail", slog.Any("error", msg.err))
-			m.err = msg.err
-			m.detailMode = false
-			return m, nil
-		}
-		m.nfsDetail = msg.detail
-		// Setup viewport for detail view
-		content := renderNFSDetail(msg.detail)
-		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
-		m.detailViewport.SetContent(content)
-		return m, nil
+// appRunListHelp returns the AppRun specific key help for the current drilldown level
+func (m model) appRunListHelp() string {
+	if _, ok := m.list.SelectedItem().(AppRunVersion); ok {
+		return " | D: deploy new version | p: containers | a: activate | x: delete | space: mark base | c: compare"
+	}
+	help := ""
+	if _, ok := m.selectedAppRunApplication(); ok {
+		help = " | D: deploy new version | p: containers"
+	}
+	if _, ok := m.list.SelectedItem().(AppRunCertificate); ok {
+		help = " | E: replace certificate | x: delete"
+	}
+	if _, ok := m.list.SelectedItem().(AppRunASG); ok {
+		help = " | x: delete ASG"
+	}
+	switch m.appRunDrilldownLevel {
+	case 0:
+		if _, ok := m.list.SelectedItem().(AppRunCluster); ok {
+			help = " | E: edit cluster | x: delete"
+		}
+		help += " | A: create cluster"
+	case 1:
+		help += " | A: create ASG | C: upload certificate"
+	}
+	return help
+}
 
-	case sshKeysLoadedMsg:
-		m.loading = false
-		if msg.err != nil {
-			slog.Error("Failed to load SSH keys", slog.Any("error", msg.err))
-			m.err = msg.err
-			return m, nil
+// appRunCompareBase returns the version to compare target with: the marked version,
+// or the active version when none is marked
+func (m model) appRunCompareBase(target AppRunVersion) int32 {
+	if m.appRunCompareVersion != 0 {
+		return m.appRunCompareVersion
+	}
+	base := int32(0)
+	for _, item := range m.list.Items() {
+		if v, ok := item.(AppRunVersion); ok && v.IsActive {
+			base = v.Version
 		}
-		slog.Info("SSH keys loaded successfully", slog.Int("count", len(msg.sshKeys)))
+	}
+	if base == 0 {
+		return target.Version
+	}
+	return base
+}
 
-		// Convert SSH keys to list items
-		items := make([]list.Item, len(msg.sshKeys))
-		for i, sshKey := range msg.sshKeys {
-			items[i] = sshKey
+// activeAppRunVersion returns the active version in the version list
+func (m model) activeAppRunVersion() (AppRunVersion, bool) {
+	for _, item := range m.list.Items() {
+		if ver, ok := item.(AppRunVersion); ok && ver.IsActive {
+			return ver, true
 		}
-		m.list.SetItems(items)
-		return m, nil
+	}
+	return AppRunVersion{}, false
+}
 
-	case sshKeyDetailLoadedMsg:
-		m.detailLoading = false
-		if msg.err != nil {
-			slog.Error("Failed to load SSH key detail", slog.Any("error", msg.err))
-			m.err = msg.err
-			m.detailMode = false
-			return m, nil
-		}
-		m.sshKeyDetail = msg.detail
-		// Setup viewport for detail view
-		content := renderSSHKeyDetail(msg.detail)
-		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
-		m.detailViewport.SetContent(content)
-		return m, nil
+// startAppRunRollout starts tracking the rollout of a newly activated version
+func (m model) startAppRunRollout(app AppRunApplication, version int32, message string) (model, tea.Cmd) {
+	if m.appRunSelectedAppID == app.ID {
+		m.appRunActiveVersion = version
+	}
+	m.appRunRollout = &appRunRollout{app: app, version: version, started: time.Now()}
+	m.statusMessage = message + ", waiting for rollout..."
+	return m, tea.Batch(m.reloadAppRunVersions(app.ID), checkAppRunRollout(m.client, *m.appRunRollout))
+}
 
-	case autoBackupsLoadedMsg:
-		m.loading = false
-		if msg.err != nil {
-			slog.Error("Failed to load auto backups", slog.Any("error", msg.err))
-			m.err = msg.err
-			return m, nil
-		}
-		slog.Info("Auto backups loaded successfully", slog.Int("count", len(msg.autoBackups)))
+// newAppRunDeployForm builds the form to create (and activate) a new version of app
+func (m model) newAppRunDeployForm(app AppRunApplication, base AppRunVersionSpec) *form {
+	client := m.client
+	title := fmt.Sprintf("New Version: %s", app.Name)
+	if app.ActiveVersion > 0 {
+		title += fmt.Sprintf(" (from v%d)", app.ActiveVersion)
+	}
+	return newAppRunVersionForm(title, base, func(spec AppRunVersionSpec, activate bool) tea.Cmd {
+		return createAppRunVersion(client, app, spec, activate)
+	})
+}
 
-		// Convert auto backups to list items
-		items := make([]list.Item, len(msg.autoBackups))
-		for i, ab := range msg.autoBackups {
-			items[i] = ab
-		}
-		m.list.SetItems(items)
-		return m, nil
+// reloadAppRunVersions reloads the version list if the versions of the application are shown
+func (m model) reloadAppRunVersions(applicationID string) tea.Cmd {
+	if m.resourceType != ResourceTypeAppRunDedicated || m.appRunDrilldownLevel != 2 || m.appRunSelectedAppID != applicationID {
+		return nil
+	}
+	return loadAppRunVersions(m.client, m.appRunSelectedAppID, m.appRunSelectedClusterID, m.appRunActiveVersion)
+}
 
-	case autoBackupDetailLoadedMsg:
-		m.detailLoading = false
-		if msg.err != nil {
-			slog.Error("Failed to load auto backup detail", slog.Any("error", msg.err))
-			m.err = msg.err
-			m.detailMode = false
-			return m, nil
-		}
-		m.autoBackupDetail = msg.detail
-		// Setup viewport for detail view
-		content := renderAutoBackupDetail(msg.detail)
-		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
-		m.detailViewport.SetContent(content)
-		return m, nil
+// toggleSimpleMonitorMark toggles the bulk-action mark of a SimpleMonitor
+func (m *model) toggleSimpleMonitorMark(id string) {
+	// Copy the set, as earlier models may still share it with the list delegate
+	marks := maps.Clone(m.simpleMonitorMarks)
+	if marks == nil {
+		marks = map[string]bool{}
+	}
+	if marks[id] {
+		delete(marks, id)
+	} else {
+		marks[id] = true
+	}
+	m.simpleMonitorMarks = marks
+	m.setSimpleMonitorItems()
+}
 
-	case simpleMonitorsLoadedMsg:
-		m.loading = false
-		if msg.err != nil {
-			slog.Error("Failed to load simple monitors", slog.Any("error", msg.err))
-			m.err = msg.err
-			return m, nil
+// simpleMonitorTargets returns the marked SimpleMonitors, or the selected one when none are marked
+func (m model) simpleMonitorTargets() []SimpleMonitor {
+	var targets []SimpleMonitor
+	for _, sm := range m.simpleMonitors {
+		if m.simpleMonitorMarks[sm.ID] {
+			targets = append(targets, sm)
 		}
-		slog.Info("Simple monitors loaded successfully", slog.Int("count", len(msg.simpleMonitors)))
+	}
+	if len(targets) > 0 {
+		return targets
+	}
+	if sm, ok := m.list.SelectedItem().(SimpleMonitor); ok {
+		return []SimpleMonitor{sm}
+	}
+	return nil
+}
 
-		// Convert simple monitors to list items
-		items := make([]list.Item, len(msg.simpleMonitors))
-		for i, sm := range msg.simpleMonitors {
-			items[i] = sm
-		}
-		m.list.SetItems(items)
-		return m, nil
+// newSimpleMonitorForm builds the edit form for a SimpleMonitor.
+// When asNew is true the form creates a new monitor for another target using sm as the template.
+func (m model) newSimpleMonitorForm(sm *SimpleMonitorDetail, asNew bool) *form {
+	client := m.client
+	window := SimpleMonitorWindows[m.simpleMonitorWindow]
+	params := sm.Params()
 
-	case simpleMonitorDetailLoadedMsg:
-		m.detailLoading = false
-		if msg.err != nil {
-			slog.Error("Failed to load simple monitor detail", slog.Any("error", msg.err))
-			m.err = msg.err
-			m.detailMode = false
-			return m, nil
-		}
-		m.simpleMonitorDetail = msg.detail
-		// Setup viewport for detail view
-		content := renderSimpleMonitorDetail(msg.detail)
-		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
-		m.detailViewport.SetContent(content)
-		return m, nil
+	title := fmt.Sprintf("Edit Simple Monitor: %s", sm.Name)
+	if asNew {
+		title = fmt.Sprintf("New Simple Monitor (from %s)", sm.Name)
+	}
 
-	case bridgesLoadedMsg:
-		m.loading = false
-		if msg.err != nil {
-			slog.Error("Failed to load bridges", slog.Any("error", msg.err))
-			m.err = msg.err
-			return m, nil
+	hasPort, isHTTP := simpleMonitorHasPort(sm.Protocol), simpleMonitorIsHTTP(sm.Protocol)
+
+	f := newForm(title, func(values map[string]string) (tea.Cmd, error) {
+		p := SimpleMonitorParams{
+			Description:    values["description"],
+			Path:           values["path"],
+			Host:           values["host"],
+			ContainsString: values["contains"],
+		}
+		var err error
+		if p.DelayLoop, err = parseFormInt(values, "delayLoop", "Interval"); err != nil {
+			return nil, err
+		}
+		if p.MaxCheckAttempts, err = parseFormInt(values, "maxCheckAttempts", "Max attempts"); err != nil {
+			return nil, err
+		}
+		if p.RetryInterval, err = parseFormInt(values, "retryInterval", "Retry interval"); err != nil {
+			return nil, err
+		}
+		if p.Timeout, err = parseFormInt(values, "timeout", "Timeout"); err != nil {
+			return nil, err
+		}
+		if hasPort {
+			if p.Port, err = parseFormInt(values, "port", "Port"); err != nil {
+				return nil, err
+			}
+		}
+		if p.DelayLoop < 60 {
+			return nil, fmt.Errorf("interval must be at least 60 seconds")
 		}
-		slog.Info("Bridges loaded successfully", slog.Int("count", len(msg.bridges)))
 
-		// Convert bridges to list items
-		items := make([]list.Item, len(msg.bridges))
-		for i, br := range msg.bridges {
-			items[i] = br
+		if asNew {
+			target := values["target"]
+			if target == "" {
+				return nil, fmt.Errorf("target is required")
+			}
+			return createSimpleMonitorFrom(client, sm.ID, target, p), nil
 		}
-		m.list.SetItems(items)
-		return m, nil
+		return updateSimpleMonitorParams(client, sm.ID, p, window), nil
+	})
 
-	case bridgeDetailLoadedMsg:
-		m.detailLoading = false
-		if msg.err != nil {
-			slog.Error("Failed to load bridge detail", slog.Any("error", msg.err))
-			m.err = msg.err
-			m.detailMode = false
-			return m, nil
+	if asNew {
+		f.addField("target", "Target", "")
+	}
+	f.addField("description", "Description", params.Description)
+	f.addField("delayLoop", "Interval (sec)", strconv.Itoa(params.DelayLoop))
+	f.addField("maxCheckAttempts", "Max attempts", strconv.Itoa(params.MaxCheckAttempts))
+	f.addField("retryInterval", "Retry interval (sec)", strconv.Itoa(params.RetryInterval))
+	f.addField("timeout", "Timeout (sec)", strconv.Itoa(params.Timeout))
+	// Only the fields the protocol of the monitor uses are shown
+	f.note = fmt.Sprintf("Protocol: %s", sm.Protocol)
+	if hasPort {
+		f.addField("port", "Port", strconv.Itoa(params.Port))
+	}
+	if isHTTP {
+		f.addField("path", "Path", params.Path)
+		f.addField("host", "Host header", params.Host)
+		f.addField("contains", "Contains string", params.ContainsString)
+	}
+	return f
+}
+
+// appRunEnvFieldPrefix prefixes form keys of existing environment variables
+const appRunEnvFieldPrefix = "env:"
+
+// newAppRunVersionForm builds the form for a new version pre-filled from base.
+// submit receives the resulting spec and whether to activate it.
+func newAppRunVersionForm(title string, base AppRunVersionSpec, submit func(spec AppRunVersionSpec, activate bool) tea.Cmd) *form {
+	f := newForm(title, func(values map[string]string) (tea.Cmd, error) {
+		spec, err := buildAppRunVersionSpec(base, values)
+		if err != nil {
+			return nil, err
 		}
-		m.bridgeDetail = msg.detail
-		// Setup viewport for detail view
-		content := renderBridgeDetail(msg.detail)
-		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
-		m.detailViewport.SetContent(content)
-		return m, nil
+		activate, err := parseFormBool(values, "activate", "Activate")
+		if err != nil {
+			return nil, err
+		}
+		return submit(spec, activate), nil
+	})
 
-	case containerRegistriesLoadedMsg:
-		m.loading = false
-		if msg.err != nil {
-			slog.Error("Failed to load container registries", slog.Any("error", msg.err))
-			m.err = msg.err
-			return m, nil
+	scalingMode := base.ScalingMode
+	if scalingMode == "" {
+		scalingMode = string(apprun.ScalingModeManual)
+	}
+
+	f.addField("image", "Image", base.Image)
+	f.addField("cpu", "CPU", formatOptionalInt(base.CPU))
+	f.addField("memory", "Memory", formatOptionalInt(base.Memory))
+	f.addField("scalingMode", "Scaling mode", scalingMode)
+	f.setPlaceholder("manual or cpu")
+	f.addField("fixedScale", "Fixed scale", formatOptionalInt(base.FixedScale))
+	f.addField("minScale", "Min scale", formatOptionalInt(base.MinScale))
+	f.addField("maxScale", "Max scale", formatOptionalInt(base.MaxScale))
+	f.addField("cmd", "Cmd", formatAppRunCmd(base.Cmd))
+	f.setPlaceholder(`shell quoting, e.g. sh -c 'echo a b'`)
+	for _, env := range base.Env {
+		label := "Env " + env.Key
+		if env.Secret {
+			label += " (secret)"
+		}
+		f.addField(appRunEnvFieldPrefix+env.Key, label, env.Value)
+		if env.Secret {
+			f.setPlaceholder("unchanged")
 		}
-		slog.Info("Container registries loaded successfully", slog.Int("count", len(msg.containerRegistries)))
+	}
+	f.addField("addEnv", "Add env", "")
+	f.setPlaceholder("KEY=VALUE")
+	f.addField("activate", "Activate", "yes")
+	f.setPlaceholder("yes/no")
+	return f
+}
 
-		// Convert container registries to list items
-		items := make([]list.Item, len(msg.containerRegistries))
-		for i, cr := range msg.containerRegistries {
-			items[i] = cr
+// buildAppRunVersionSpec applies edited form values to base.
+// Exposed ports and registry settings are carried over unchanged.
+// An environment variable whose value is "-" is removed; an empty secret keeps its previous value.
+func buildAppRunVersionSpec(base AppRunVersionSpec, values map[string]string) (AppRunVersionSpec, error) {
+	spec := base
+	spec.Image = values["image"]
+	if spec.Image == "" {
+		return spec, fmt.Errorf("image is required")
+	}
+
+	spec.ScalingMode = values["scalingMode"]
+	switch apprun.ScalingMode(spec.ScalingMode) {
+	case apprun.ScalingModeManual, apprun.ScalingModeCPU:
+	default:
+		return spec, fmt.Errorf("scaling mode must be manual or cpu")
+	}
+
+	cpu, err := parseFormInt(values, "cpu", "CPU")
+	if err != nil {
+		return spec, err
+	}
+	memory, err := parseFormInt(values, "memory", "Memory")
+	if err != nil {
+		return spec, err
+	}
+	if cpu <= 0 || memory <= 0 {
+		return spec, fmt.Errorf("CPU and memory are required")
+	}
+	spec.CPU = int64(cpu)
+	spec.Memory = int64(memory)
+
+	for _, field := range []struct {
+		key   string
+		label string
+		dest  *int32
+	}{
+		{"fixedScale", "Fixed scale", &spec.FixedScale},
+		{"minScale", "Min scale", &spec.MinScale},
+		{"maxScale", "Max scale", &spec.MaxScale},
+	} {
+		n, err := parseFormInt(values, field.key, field.label)
+		if err != nil {
+			return spec, err
 		}
-		m.list.SetItems(items)
-		return m, nil
+		*field.dest = int32(n)
+	}
+	if spec.ScalingMode == string(apprun.ScalingModeManual) && spec.FixedScale == 0 {
+		return spec, fmt.Errorf("fixed scale is required for manual scaling")
+	}
+	if spec.ScalingMode == string(apprun.ScalingModeCPU) && (spec.MinScale == 0 || spec.MaxScale < spec.MinScale) {
+		return spec, fmt.Errorf("min/max scale are required for cpu scaling")
+	}
 
-	case containerRegistryDetailLoadedMsg:
-		m.detailLoading = false
-		if msg.err != nil {
-			slog.Error("Failed to load container registry detail", slog.Any("error", msg.err))
-			m.err = msg.err
-			m.detailMode = false
-			return m, nil
+	// Keep the arguments as they are unless the command line was edited
+	if cmd := values["cmd"]; cmd != formatAppRunCmd(base.Cmd) {
+		if spec.Cmd, err = parseAppRunCmd(cmd); err != nil {
+			return spec, err
 		}
-		m.containerRegistryDetail = msg.detail
-		// Setup viewport for detail view
-		content := renderContainerRegistryDetail(msg.detail)
-		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
-		m.detailViewport.SetContent(content)
-		return m, nil
+	}
 
-	case appRunClustersLoadedMsg:
-		m.loading = false
-		if msg.err != nil {
-			slog.Error("Failed to load AppRun clusters", slog.Any("error", msg.err))
-			m.err = msg.err
-			return m, nil
+	spec.Env = nil
+	for _, env := range base.Env {
+		value := values[appRunEnvFieldPrefix+env.Key]
+		if value == "-" {
+			continue
 		}
-		slog.Info("AppRun clusters loaded successfully", slog.Int("count", len(msg.clusters)))
+		spec.Env = append(spec.Env, AppRunEnvVar{Key: env.Key, Value: value, Secret: env.Secret})
+	}
+	if added := values["addEnv"]; added != "" {
+		key, value, ok := strings.Cut(added, "=")
+		if !ok || key == "" {
+			return spec, fmt.Errorf("new env must be KEY=VALUE")
+		}
+		for _, env := range spec.Env {
+			if env.Key == key {
+				return spec, fmt.Errorf("env %s already exists", key)
+			}
+		}
+		spec.Env = append(spec.Env, AppRunEnvVar{Key: key, Value: value})
+	}
 
-		// Convert clusters to list items
-		items := make([]list.Item, len(msg.clusters))
-		for i, cluster := range msg.clusters {
-			items[i] = cluster
+	return spec, nil
+}
+
+// formatOptionalInt formats n, leaving zero (unset) values empty
+func formatOptionalInt[T int32 | int64](n T) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.FormatInt(int64(n), 10)
+}
+
+// newAppRunCertificateForm builds the form to upload a certificate from local PEM files.
+// existing is nil when a new certificate is created.
+func newAppRunCertificateForm(title string, existing *AppRunCertificate, submit func(name string, upload *CertificateUpload) tea.Cmd) *form {
+	f := newForm(title, func(values map[string]string) (tea.Cmd, error) {
+		name := values["name"]
+		if !appRunCertificateName.MatchString(name) {
+			return nil, fmt.Errorf("name must be 1-20 letters, digits, '_', '.' or '-'")
 		}
-		m.list.SetItems(items)
-		return m, nil
+		if values["cert"] == "" || values["key"] == "" {
+			return nil, fmt.Errorf("certificate and private key files are required")
+		}
+		upload, err := LoadCertificateFiles(values["cert"], values["key"], values["chain"])
+		if err != nil {
+			return nil, err
+		}
+		return submit(name, upload), nil
+	})
+	name := ""
+	if existing != nil {
+		name = existing.Name
+	}
+	f.addField("name", "Name", name)
+	f.addField("cert", "Certificate PEM", "")
+	f.setPlaceholder("~/certs/example.com/fullchain.pem")
+	f.addField("key", "Private key PEM", "")
+	f.setPlaceholder("~/certs/example.com/privkey.pem")
+	f.addField("chain", "Intermediate PEM", "")
+	f.setPlaceholder("optional; certificates after the first in the certificate file are used otherwise")
+	return f
+}
 
-	case appRunClusterDetailLoadedMsg:
-		m.detailLoading = false
-		if msg.err != nil {
-			slog.Error("Failed to load AppRun cluster detail", slog.Any("error", msg.err))
-			m.err = msg.err
-			m.detailMode = false
-			return m, nil
+// appRunNetwork is the addressing of an ASG or LB interface entered in a form
+type appRunNetwork struct {
+	upstream string
+	ipPool   []string
+	netmask  int16
+	gateway  string
+}
+
+// parseAppRunNetwork reads the "<prefix>.upstream/ipPool/netmask/gateway" fields of an interface.
+// Interfaces on the shared segment must not have addressing; others need an IP pool and netmask.
+func parseAppRunNetwork(values map[string]string, prefix string) (appRunNetwork, error) {
+	n := appRunNetwork{upstream: values[prefix+".upstream"], gateway: values[prefix+".gateway"]}
+	pool := values[prefix+".ipPool"]
+	netmask, err := parseFormInt(values, prefix+".netmask", prefix+" netmask")
+	if err != nil {
+		return n, err
+	}
+
+	if n.upstream == "shared" {
+		if pool != "" || netmask != 0 || n.gateway != "" {
+			return n, fmt.Errorf("%s: IP pool, netmask and gateway cannot be set on the shared segment", prefix)
 		}
-		m.appRunClusterDetail = msg.detail
-		// Setup viewport for detail view
-		content := renderAppRunClusterDetail(msg.detail)
-		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
-		m.detailViewport.SetContent(content)
-		return m, nil
+		return n, nil
+	}
 
-	case appRunASGsLoadedMsg:
-		m.loading = false
-		if msg.err != nil {
-			slog.Error("Failed to load AppRun ASGs", slog.Any("error", msg.err))
-			m.err = msg.err
-			return m, nil
+	if pool == "" || netmask == 0 {
+		return n, fmt.Errorf("%s: IP pool and netmask are required when connected to a switch", prefix)
+	}
+	if netmask < 8 || netmask > 29 {
+		return n, fmt.Errorf("%s: netmask must be between 8 and 29", prefix)
+	}
+	n.netmask = int16(netmask)
+	for _, r := range strings.Split(pool, ",") {
+		start, end, ok := strings.Cut(strings.TrimSpace(r), "-")
+		if !ok {
+			return n, fmt.Errorf("%s: IP pool ranges must be written as start-end", prefix)
 		}
-		slog.Info("AppRun ASGs loaded successfully", slog.Int("count", len(msg.asgs)))
+		if _, err := parseIPv4List(start+","+end, prefix+" IP pool"); err != nil {
+			return n, err
+		}
+		n.ipPool = append(n.ipPool, strings.TrimSpace(start)+"-"+strings.TrimSpace(end))
+	}
+	if n.gateway != "" {
+		if _, err := parseIPv4List(n.gateway, prefix+" gateway"); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
 
-		items := make([]list.Item, len(msg.asgs))
-		for i, asg := range msg.asgs {
-			items[i] = asg
+func addAppRunNetworkFields(f *form, prefix, upstream string) {
+	f.addField(prefix+".upstream", prefix+" upstream", upstream)
+	if prefix == "eth0" {
+		f.setPlaceholder("shared or switch ID")
+	} else {
+		f.setPlaceholder("optional switch ID")
+	}
+	f.addField(prefix+".ipPool", prefix+" IP pool", "")
+	f.setPlaceholder("192.168.0.10-192.168.0.20, ... (switch only)")
+	f.addField(prefix+".netmask", prefix+" netmask", "")
+	f.setPlaceholder("24 (switch only)")
+	f.addField(prefix+".gateway", prefix+" gateway", "")
+}
+
+// newAppRunASGForm builds the form to create an auto scaling group
+func newAppRunASGForm(zone string, classes []AppRunServiceClass, submit func(spec AppRunASGSpec) tea.Cmd) *form {
+	f := newForm("Create auto scaling group", func(values map[string]string) (tea.Cmd, error) {
+		spec, err := buildAppRunASGSpec(values, classes)
+		if err != nil {
+			return nil, err
 		}
-		m.list.SetItems(items)
-		return m, nil
+		return submit(spec), nil
+	})
+	f.note = serviceClassNote(classes)
 
-	case appRunLBsLoadedMsg:
-		m.loading = false
-		if msg.err != nil {
-			slog.Error("Failed to load AppRun LBs", slog.Any("error", msg.err))
-			m.err = msg.err
-			return m, nil
+	defaultClass := ""
+	if len(classes) > 0 {
+		defaultClass = classes[0].Name
+	}
+	f.addField("name", "Name", "")
+	f.addField("zone", "Zone", zone)
+	f.addField("serviceClass", "Service class", defaultClass)
+	f.addField("minNodes", "Min nodes", "1")
+	f.addField("maxNodes", "Max nodes", "2")
+	f.addField("nameServers", "Name servers", "")
+	f.setPlaceholder("optional, comma separated")
+	addAppRunNetworkFields(f, "eth0", "shared")
+	f.addField("eth0.lb", "eth0 connects to LB", "yes")
+	addAppRunNetworkFields(f, "eth1", "")
+	f.addField("eth1.lb", "eth1 connects to LB", "no")
+	return f
+}
+
+// buildAppRunASGSpec validates the ASG form values
+func buildAppRunASGSpec(values map[string]string, classes []AppRunServiceClass) (AppRunASGSpec, error) {
+	spec := AppRunASGSpec{Name: values["name"], Zone: values["zone"]}
+	if !appRunResourceName.MatchString(spec.Name) {
+		return spec, fmt.Errorf("name must be 1-20 letters, digits, '_' or '-'")
+	}
+	if spec.Zone == "" {
+		return spec, fmt.Errorf("zone is required")
+	}
+	var err error
+	if spec.ServiceClass, err = resolveServiceClass(classes, values["serviceClass"]); err != nil {
+		return spec, err
+	}
+
+	minNodes, err := parseFormInt(values, "minNodes", "Min nodes")
+	if err != nil {
+		return spec, err
+	}
+	maxNodes, err := parseFormInt(values, "maxNodes", "Max nodes")
+	if err != nil {
+		return spec, err
+	}
+	if minNodes < 1 || maxNodes > 10 || minNodes > maxNodes {
+		return spec, fmt.Errorf("node counts must satisfy 1 <= min <= max <= 10")
+	}
+	spec.MinNodes, spec.MaxNodes = int32(minNodes), int32(maxNodes)
+
+	if spec.NameServers, err = parseIPv4List(values["nameServers"], "Name servers"); err != nil {
+		return spec, err
+	}
+	if len(spec.NameServers) > 3 {
+		return spec, fmt.Errorf("at most 3 name servers can be set")
+	}
+
+	lbCount := 0
+	for i, prefix := range []string{"eth0", "eth1"} {
+		if values[prefix+".upstream"] == "" {
+			if i == 0 {
+				return spec, fmt.Errorf("eth0 upstream is required")
+			}
+			continue
 		}
-		slog.Info("AppRun LBs loaded successfully", slog.Int("count", len(msg.lbs)))
+		network, err := parseAppRunNetwork(values, prefix)
+		if err != nil {
+			return spec, err
+		}
+		if i > 0 && network.upstream == "shared" {
+			return spec, fmt.Errorf("only eth0 can be connected to the shared segment")
+		}
+		connectsToLB, err := parseFormBool(values, prefix+".lb", prefix+" connects to LB")
+		if err != nil {
+			return spec, err
+		}
+		if connectsToLB {
+			lbCount++
+		}
+		spec.Interfaces = append(spec.Interfaces, AppRunASGInterface{
+			Index:          int16(i),
+			Upstream:       network.upstream,
+			NetmaskLen:     network.netmask,
+			DefaultGateway: network.gateway,
+			ConnectsToLB:   connectsToLB,
+			IPPool:         network.ipPool,
+		})
+	}
+	if lbCount > 1 {
+		return spec, fmt.Errorf("only one interface can connect to the LB")
+	}
+	return spec, nil
+}
 
-		items := make([]list.Item, len(msg.lbs))
-		for i, lb := range msg.lbs {
-			items[i] = lb
+// newAppRunLBForm builds the form to create a load balancer for an ASG
+func newAppRunLBForm(asgName string, classes []AppRunServiceClass, submit func(spec AppRunLBSpec) tea.Cmd) *form {
+	f := newForm(fmt.Sprintf("Create load balancer for %s", asgName), func(values map[string]string) (tea.Cmd, error) {
+		spec, err := buildAppRunLBSpec(values, classes)
+		if err != nil {
+			return nil, err
 		}
-		m.list.SetItems(items)
-		return m, nil
+		return submit(spec), nil
+	})
+	f.note = serviceClassNote(classes)
 
-	case appRunClusterContentsLoadedMsg:
-		m.loading = false
-		if msg.err != nil {
-			slog.Error("Failed to load AppRun cluster contents", slog.Any("error", msg.err))
-			m.err = msg.err
-			return m, nil
+	defaultClass := ""
+	if len(classes) > 0 {
+		defaultClass = classes[0].Name
+	}
+	f.addField("name", "Name", "")
+	f.addField("serviceClass", "Service class", defaultClass)
+	f.addField("nameServers", "Name servers", "")
+	f.setPlaceholder("optional, comma separated")
+	addAppRunNetworkFields(f, "eth0", "shared")
+	f.addField("eth0.vip", "eth0 VIP", "")
+	f.setPlaceholder("optional (switch only)")
+	f.addField("eth0.vrid", "eth0 virtual router ID", "")
+	f.setPlaceholder("1-255, required with a VIP")
+	return f
+}
+
+// buildAppRunLBSpec validates the LB form values
+func buildAppRunLBSpec(values map[string]string, classes []AppRunServiceClass) (AppRunLBSpec, error) {
+	spec := AppRunLBSpec{Name: values["name"]}
+	if !appRunResourceName.MatchString(spec.Name) {
+		return spec, fmt.Errorf("name must be 1-20 letters, digits, '_' or '-'")
+	}
+	var err error
+	if spec.ServiceClass, err = resolveServiceClass(classes, values["serviceClass"]); err != nil {
+		return spec, err
+	}
+	if spec.NameServers, err = parseIPv4List(values["nameServers"], "Name servers"); err != nil {
+		return spec, err
+	}
+	if len(spec.NameServers) > 3 {
+		return spec, fmt.Errorf("at most 3 name servers can be set")
+	}
+
+	if values["eth0.upstream"] == "" {
+		return spec, fmt.Errorf("eth0 upstream is required")
+	}
+	network, err := parseAppRunNetwork(values, "eth0")
+	if err != nil {
+		return spec, err
+	}
+	iface := AppRunLBInterface{
+		Index:          0,
+		Upstream:       network.upstream,
+		DefaultGateway: network.gateway,
+		NetmaskLen:     network.netmask,
+		IPPool:         network.ipPool,
+	}
+	if vip := values["eth0.vip"]; vip != "" {
+		if network.upstream == "shared" {
+			return spec, fmt.Errorf("a VIP cannot be set on the shared segment")
 		}
-		slog.Info("AppRun cluster contents loaded", slog.Int("asgs", len(msg.asgs)), slog.Int("apps", len(msg.apps)))
+		if _, err := parseIPv4List(vip, "VIP"); err != nil {
+			return spec, err
+		}
+		vrid, err := strconv.Atoi(values["eth0.vrid"])
+		if err != nil || vrid < 1 || vrid > 255 {
+			return spec, fmt.Errorf("virtual router ID must be between 1 and 255 when a VIP is set")
+		}
+		iface.VIP = vip
+		iface.VirtualRouterID = int16(vrid)
+	}
+	spec.Interfaces = []AppRunLBInterface{iface}
+	return spec, nil
+}
 
-		// Combine ASGs and Applications into a single list
-		items := make([]list.Item, 0, len(msg.asgs)+len(msg.apps))
-		for _, asg := range msg.asgs {
-			items = append(items, asg)
+// newAppRunClusterForm builds the form to create a cluster
+func newAppRunClusterForm(submit func(spec AppRunClusterSpec) tea.Cmd) *form {
+	f := newForm("Create cluster", func(values map[string]string) (tea.Cmd, error) {
+		spec := AppRunClusterSpec{
+			Name:               values["name"],
+			LetsEncryptEmail:   values["letsEncryptEmail"],
+			ServicePrincipalID: values["servicePrincipalID"],
+		}
+		if !appRunResourceName.MatchString(spec.Name) {
+			return nil, fmt.Errorf("name must be 1-20 letters, digits, '_' or '-'")
+		}
+		var err error
+		if spec.Ports, err = parseAppRunPorts(values["ports"]); err != nil {
+			return nil, err
+		}
+		if err := validateLetsEncrypt(spec.LetsEncryptEmail, spec.Ports); err != nil {
+			return nil, err
+		}
+		if err := validateServicePrincipalID(spec.ServicePrincipalID); err != nil {
+			return nil, err
+		}
+		return submit(spec), nil
+	})
+	f.note = "Ports cannot be changed after the cluster is created."
+	f.addField("name", "Name", "")
+	f.addField("ports", "LB ports", "80/http, 443/https")
+	f.setPlaceholder("port/protocol (http, https or tcp), comma separated")
+	f.addField("letsEncryptEmail", "Let's Encrypt email", "")
+	f.setPlaceholder("optional, needs 80/http")
+	f.addField("servicePrincipalID", "Service principal ID", "")
+	return f
+}
+
+// newAppRunClusterEditForm builds the form to change the Let's Encrypt and service principal
+// settings of a cluster. The current email cannot be read back from the API, so it has to be
+// entered again to keep Let's Encrypt enabled.
+func newAppRunClusterEditForm(cluster *AppRunClusterDetail, submit func(email, servicePrincipalID string) tea.Cmd) *form {
+	f := newForm(fmt.Sprintf("Edit cluster %s", cluster.Name), func(values map[string]string) (tea.Cmd, error) {
+		email := values["letsEncryptEmail"]
+		switch {
+		case email == "-":
+			email = ""
+		case email == "" && cluster.HasLetsEncrypt:
+			return nil, fmt.Errorf("enter the email again to keep Let's Encrypt enabled, or '-' to disable it")
+		}
+		if err := validateLetsEncrypt(email, cluster.Ports); err != nil {
+			return nil, err
+		}
+		if err := validateServicePrincipalID(values["servicePrincipalID"]); err != nil {
+			return nil, err
+		}
+		return submit(email, values["servicePrincipalID"]), nil
+	})
+
+	letsEncrypt := "disabled"
+	if cluster.HasLetsEncrypt {
+		letsEncrypt = "enabled"
+	}
+	f.note = fmt.Sprintf("LB ports: %s (cannot be changed) | Let's Encrypt: %s", formatAppRunPorts(cluster.Ports), letsEncrypt)
+	f.addField("letsEncryptEmail", "Let's Encrypt email", "")
+	if cluster.HasLetsEncrypt {
+		f.setPlaceholder("required to keep it enabled, '-' to disable")
+	} else {
+		f.setPlaceholder("optional")
+	}
+	f.addField("servicePrincipalID", "Service principal ID", cluster.ServicePrincipal)
+	return f
+}
+
+// buildRoutingSpec resolves the routing form values against the choices
+func buildRoutingSpec(values map[string]string, choices *RoutingChoices) (RoutingSpec, error) {
+	var spec RoutingSpec
+	if value := values["source"]; value != "" {
+		source, err := choices.findSource(value)
+		if err != nil {
+			return spec, err
 		}
-		for _, app := range msg.apps {
-			items = append(items, app)
+		if source != nil {
+			spec.Source, spec.ResourceID = source, source.ID
+		} else if isNumeric(value) {
+			// Resources sact does not list (or of other zones) can be given by ID
+			spec.ResourceID = value
+		} else {
+			return spec, fmt.Errorf("unknown resource %q in %s", value, choices.Zone)
 		}
-		m.list.SetItems(items)
-		return m, nil
+	}
 
-	case appRunVersionsLoadedMsg:
-		m.loading = false
-		if msg.err != nil {
-			slog.Error("Failed to load AppRun versions", slog.Any("error", msg.err))
-			m.err = msg.err
-			return m, nil
+	publisher, err := choices.findPublisher(values["publisher"], spec.Source)
+	if err != nil {
+		return spec, err
+	}
+	spec.PublisherCode = publisher.Code
+
+	variants := publisher.Variants
+	if name := values["variant"]; name != "" {
+		variants = slices.DeleteFunc(slices.Clone(variants), func(v v1.PublisherVariant) bool {
+			return v.Name != name
+		})
+		if len(variants) == 0 {
+			return spec, fmt.Errorf("publisher %s has no variant %q (%s)", publisher.Code, name, variantNames(publisher.Variants))
+		}
+	} else if len(variants) != 1 {
+		return spec, fmt.Errorf("variant is required: %s", variantNames(publisher.Variants))
+	}
+	spec.Variant, spec.Storage = variants[0].Name, variants[0].Storage
+
+	spec.StorageID, spec.StorageName, err = choices.findStorage(spec.Storage, values["storage"])
+	return spec, err
+}
+
+// variantNames formats variants as "syslog (logs), metrics (metrics)"
+func variantNames(variants []v1.PublisherVariant) string {
+	names := make([]string, len(variants))
+	for i, v := range variants {
+		names[i] = fmt.Sprintf("%s (%s)", v.Name, v.Storage)
+	}
+	return strings.Join(names, ", ")
+}
+
+func isNumeric(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
+
+// routingFormNote lists the unrouted resources, publishers and storages to pick from
+func routingFormNote(choices *RoutingChoices) string {
+	var lines []string
+	for _, kind := range []v1.PublisherVariantStorage{v1.PublisherVariantStorageLogs, v1.PublisherVariantStorageMetrics} {
+		unrouted := choices.UnroutedSources(kind)
+		names := make([]string, len(unrouted))
+		for i, s := range unrouted {
+			names[i] = s.String()
 		}
-		slog.Info("AppRun versions loaded", slog.Int("count", len(msg.versions)))
+		if len(names) == 0 {
+			names = []string{"(none)"}
+		}
+		lines = append(lines, fmt.Sprintf("Without %s routing in %s: %s", kind, choices.Zone, strings.Join(names, ", ")))
+	}
+
+	for _, p := range choices.Publishers {
+		lines = append(lines, fmt.Sprintf("Publisher %s: %s", p.Code, variantNames(p.Variants)))
+	}
+
+	var logs, metrics []string
+	for _, s := range choices.LogStorages {
+		logs = append(logs, getOptString(s.Name))
+	}
+	for _, s := range choices.MetricsStorages {
+		metrics = append(metrics, getOptString(s.Name))
+	}
+	lines = append(lines, fmt.Sprintf("Log storages: %s | Metrics storages: %s", strings.Join(logs, ", "), strings.Join(metrics, ", ")))
+	return strings.Join(lines, "\n")
+}
 
-		items := make([]list.Item, len(msg.versions))
-		for i, ver := range msg.versions {
-			items[i] = ver
+// newRoutingForm builds the form to create a log or metrics routing.
+// The source defaults to the first resource without a log or metrics routing.
+func newRoutingForm(choices *RoutingChoices, submit func(spec RoutingSpec) tea.Cmd) *form {
+	f := newForm("Create routing", func(values map[string]string) (tea.Cmd, error) {
+		spec, err := buildRoutingSpec(values, choices)
+		if err != nil {
+			return nil, err
 		}
-		m.list.SetItems(items)
-		return m, nil
+		return submit(spec), nil
+	})
+	f.note = routingFormNote(choices)
 
-	case appRunLBDetailLoadedMsg:
-		m.detailLoading = false
-		if msg.err != nil {
-			slog.Error("Failed to load AppRun LB detail", slog.Any("error", msg.err))
-			m.err = msg.err
-			m.detailMode = false
-			return m, nil
-		}
-		m.appRunLBDetail = msg.detail
-		// Setup viewport for detail view
-		content := renderAppRunLBDetail(msg.detail)
-		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
-		m.detailViewport.SetContent(content)
-		return m, nil
+	source := ""
+	if unrouted, ok := choices.firstUnroutedSource(); ok {
+		source = unrouted.Name
+	}
+	f.addField("source", "Source resource", source)
+	f.setPlaceholder("name or resource ID")
+	f.addField("publisher", "Publisher", "")
+	f.setPlaceholder("guessed from the source")
+	f.addField("variant", "Variant", "")
+	f.setPlaceholder("optional when the publisher has one variant")
+	f.addField("storage", "Storage", "")
+	f.setPlaceholder("name or resource ID of a log/metrics storage")
+	return f
+}
 
-	case appRunASGDetailLoadedMsg:
-		m.detailLoading = false
-		if msg.err != nil {
-			slog.Error("Failed to load AppRun ASG detail", slog.Any("error", msg.err))
-			m.err = msg.err
-			m.detailMode = false
-			return m, nil
-		}
-		m.appRunASGDetail = msg.detail
-		m.appRunWorkerNodes = msg.workerNodes
-		// Setup viewport for detail view
-		content := renderAppRunASGDetail(msg.detail, msg.workerNodes)
-		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
-		m.detailViewport.SetContent(content)
-		return m, nil
+func parseAlertThreshold(values map[string]string, prefix, label string) (AlertThreshold, error) {
+	t := AlertThreshold{Threshold: values[prefix+".threshold"]}
+	var err error
+	if t.Enabled, err = parseFormBool(values, prefix+".enabled", label+" enabled"); err != nil {
+		return t, err
+	}
+	if t.Duration, err = parseAlertDuration(values[prefix+".duration"], label+" duration"); err != nil {
+		return t, err
+	}
+	if t.Enabled && t.Threshold == "" {
+		return t, fmt.Errorf("%s threshold is required when it is enabled", strings.ToLower(label))
+	}
+	return t, nil
+}
 
-	case monitoringLogStoragesLoadedMsg:
-		m.loading = false
-		if msg.err != nil {
-			slog.Error("Failed to load monitoring log storages", slog.Any("error", msg.err))
-			m.err = msg.err
-			return m, nil
-		}
-		slog.Info("Monitoring log storages loaded successfully", slog.Int("count", len(msg.storages)))
+// buildAlertRuleSpec validates the alert rule form values, including the PromQL syntax
+func buildAlertRuleSpec(values map[string]string, projects []MonitoringAlertProject) (AlertRuleSpec, error) {
+	spec := AlertRuleSpec{Name: values["name"], Query: strings.TrimSpace(values["query"])}
+	if project, ok := values["project"]; ok {
+		i := slices.IndexFunc(projects, func(p MonitoringAlertProject) bool {
+			return p.ID == project || p.Name == project
+		})
+		if i < 0 {
+			return spec, fmt.Errorf("unknown alert project %q", project)
+		}
+		spec.ProjectID = projects[i].ID
+	}
+	if err := validatePromQL(spec.Query); err != nil {
+		return spec, fmt.Errorf("invalid PromQL: %w", err)
+	}
+	var err error
+	if spec.Warning, err = parseAlertThreshold(values, "warning", "Warning"); err != nil {
+		return spec, err
+	}
+	if spec.Critical, err = parseAlertThreshold(values, "critical", "Critical"); err != nil {
+		return spec, err
+	}
+	return spec, nil
+}
 
-		items := make([]list.Item, len(msg.storages))
-		for i, storage := range msg.storages {
-			items[i] = storage
-		}
-		m.list.SetItems(items)
-		return m, nil
+func addAlertThresholdFields(f *form, prefix, label string, t AlertThreshold) {
+	f.addField(prefix+".enabled", label+" enabled", formatFormBool(t.Enabled))
+	f.addField(prefix+".threshold", label+" threshold", t.Threshold)
+	f.setPlaceholder("e.g. > 80")
+	duration := ""
+	if t.Duration > 0 {
+		duration = formatAlertDuration(t.Duration)
+	}
+	f.addField(prefix+".duration", label+" duration", duration)
+	f.setPlaceholder("e.g. 5m, how long the threshold must be exceeded")
+}
 
-	case monitoringLogStorageDetailLoadedMsg:
-		m.detailLoading = false
-		if msg.err != nil {
-			slog.Error("Failed to load monitoring log storage detail", slog.Any("error", msg.err))
-			m.err = msg.err
-			m.detailMode = false
-			return m, nil
-		}
-		m.monitoringLogStorageDetail = msg.detail
-		// Setup viewport for detail view
-		content := renderMonitoringLogStorageDetail(msg.detail)
-		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
-		m.detailViewport.SetContent(content)
-		return m, nil
+const alertQueryPlaceholder = `e.g. 100 - avg by (instance) (rate(node_cpu_seconds_total{mode="idle"}[5m])) * 100`
 
-	case monitoringMetricsStoragesLoadedMsg:
-		m.loading = false
-		if msg.err != nil {
-			slog.Error("Failed to load monitoring metrics storages", slog.Any("error", msg.err))
-			m.err = msg.err
-			return m, nil
-		}
-		slog.Info("Monitoring metrics storages loaded successfully", slog.Int("count", len(msg.storages)))
+// newAlertRuleForm builds the form to create an alert rule, or to edit rule when it is not nil
+func newAlertRuleForm(projects []MonitoringAlertProject, rule *MonitoringAlertRule, submit func(spec AlertRuleSpec) tea.Cmd) *form {
+	title := "Create alert rule"
+	if rule != nil {
+		title = fmt.Sprintf("Edit alert rule %s", getOptString(rule.Name))
+	}
+	f := newForm(title, func(values map[string]string) (tea.Cmd, error) {
+		spec, err := buildAlertRuleSpec(values, projects)
+		if err != nil {
+			return nil, err
+		}
+		return submit(spec), nil
+	})
+
+	if rule == nil {
+		names := make([]string, len(projects))
+		for i, p := range projects {
+			names[i] = fmt.Sprintf("%s (%s)", p.Name, p.ID)
+		}
+		f.note = "Alert projects: " + strings.Join(names, ", ")
+		project := ""
+		if len(projects) > 0 {
+			project = projects[0].Name
+		}
+		f.addField("project", "Alert project", project)
+		f.addField("name", "Name", "")
+		f.addField("query", "Query", "")
+		f.setPlaceholder(alertQueryPlaceholder)
+		addAlertThresholdFields(f, "warning", "Warning", AlertThreshold{Enabled: true})
+		addAlertThresholdFields(f, "critical", "Critical", AlertThreshold{})
+	} else {
+		f.note = fmt.Sprintf("Alert project: %s (%s)", rule.Project.Name, rule.Project.ID)
+		f.addField("name", "Name", getOptString(rule.Name))
+		f.addField("query", "Query", rule.Query)
+		f.setPlaceholder(alertQueryPlaceholder)
+		addAlertThresholdFields(f, "warning", "Warning", rule.Warning())
+		addAlertThresholdFields(f, "critical", "Critical", rule.Critical())
+	}
+	return f
+}
 
-		items := make([]list.Item, len(msg.storages))
-		for i, storage := range msg.storages {
-			items[i] = storage
+// buildMonitoringStorageSpec validates the values of the storage create form
+func buildMonitoringStorageSpec(kind StorageKind, values map[string]string) (MonitoringStorageSpec, error) {
+	spec := MonitoringStorageSpec{
+		Kind:        kind,
+		Name:        strings.TrimSpace(values["name"]),
+		Description: strings.TrimSpace(values["description"]),
+	}
+	if spec.Name == "" {
+		return spec, fmt.Errorf("name is required")
+	}
+	if storageRetentionLabel(kind) != "" {
+		days, err := parseStorageRetention(kind, values["retention"])
+		if err != nil {
+			return spec, err
 		}
-		m.list.SetItems(items)
-		return m, nil
+		spec.RetentionDays = days
+	}
+	return spec, nil
+}
 
-	case monitoringMetricsStorageDetailLoadedMsg:
-		m.detailLoading = false
-		if msg.err != nil {
-			slog.Error("Failed to load monitoring metrics storage detail", slog.Any("error", msg.err))
-			m.err = msg.err
-			m.detailMode = false
-			return m, nil
-		}
-		m.monitoringMetricsStorageDetail = msg.detail
-		// Setup viewport for detail view
-		content := renderMonitoringMetricsStorageDetail(msg.detail)
-		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
-		m.detailViewport.SetContent(content)
-		return m, nil
+// newMonitoringStorageForm builds the form to create a storage of kind
+func newMonitoringStorageForm(kind StorageKind, submit func(spec MonitoringStorageSpec) tea.Cmd) *form {
+	f := newForm("Create "+strings.ToLower(kind.Label()), func(values map[string]string) (tea.Cmd, error) {
+		spec, err := buildMonitoringStorageSpec(kind, values)
+		if err != nil {
+			return nil, err
+		}
+		return submit(spec), nil
+	})
+	f.addField("name", "Name", "")
+	f.addField("description", "Description", "")
+	if label := storageRetentionLabel(kind); label != "" {
+		f.addField("retention", label, "")
+		f.setPlaceholder("empty for the default")
+	} else {
+		f.note = "The retention of metrics storages is fixed by the service."
+	}
+	return f
+}
 
-	case monitoringTraceStoragesLoadedMsg:
-		m.loading = false
-		if msg.err != nil {
-			slog.Error("Failed to load monitoring trace storages", slog.Any("error", msg.err))
-			m.err = msg.err
-			return m, nil
+// setSimpleMonitorItems updates the list with SimpleMonitors matching the current filter
+func (m *model) setSimpleMonitorItems() {
+	items := make([]list.Item, 0, len(m.simpleMonitors))
+	for _, sm := range m.simpleMonitors {
+		if m.simpleMonitorUnhealthyOnly && !sm.IsUnhealthy() {
+			continue
 		}
-		slog.Info("Monitoring trace storages loaded successfully", slog.Int("count", len(msg.storages)))
+		items = append(items, sm)
+	}
+	m.list.SetItems(items)
+	m.setListDelegate()
+}
 
-		items := make([]list.Item, len(msg.storages))
-		for i, storage := range msg.storages {
-			items[i] = storage
-		}
-		m.list.SetItems(items)
-		return m, nil
+// setListDelegate passes the marks kept in the model to the list rows
+func (m *model) setListDelegate() {
+	m.list.SetDelegate(resourceDelegate{marked: m.simpleMonitorMarks, compareBase: m.appRunCompareVersion})
+}
 
-	case monitoringTraceStorageDetailLoadedMsg:
-		m.detailLoading = false
-		if msg.err != nil {
-			slog.Error("Failed to load monitoring trace storage detail", slog.Any("error", msg.err))
-			m.err = msg.err
-			m.detailMode = false
-			return m, nil
-		}
-		m.monitoringTraceStorageDetail = msg.detail
-		// Setup viewport for detail view
-		content := renderMonitoringTraceStorageDetail(msg.detail)
-		m.detailViewport = viewport.New(m.windowWidth, m.windowHeight-10)
-		m.detailViewport.SetContent(content)
-		return m, nil
+// observeTarget returns the resource shown in the detail that can be observed
+func (m model) observeTarget() (ObserveTarget, bool) {
+	switch {
+	case m.serverDetail != nil:
+		return ServerObserveTarget(m.serverDetail), true
+	case m.dbDetail != nil:
+		return DBObserveTarget(m.dbDetail), true
+	case m.appRunPlacement != nil:
+		return m.appRunPlacement.ObserveTarget(), true
 	}
+	return ObserveTarget{}, false
+}
 
-	// Delegate to list for navigation
-	var cmd tea.Cmd
-	m.list, cmd = m.list.Update(msg)
-	return m, cmd
+// renderObservedDetail renders the detail below the observe view
+func (m model) renderObservedDetail() string {
+	switch {
+	case m.serverDetail != nil:
+		return renderServerDetail(m.serverDetail)
+	case m.dbDetail != nil:
+		return renderDBDetail(m.dbDetail)
+	case m.appRunPlacement != nil:
+		return renderAppRunContainerPlacement(m.appRunPlacement, m.detailCursor)
+	}
+	return ""
 }
 
-func (m model) View() string {
-	if m.quitting {
-		return "Bye!\n"
+// handleObserveAction handles keys of the observe view of a server, database or AppRun application
+func (m model) handleObserveAction(key string) (model, tea.Cmd, bool) {
+	v := m.observeView
+	if v == nil {
+		if key != "O" {
+			return m, nil, false
+		}
+		target, ok := m.observeTarget()
+		if !ok {
+			return m, nil, false
+		}
+		if len(target.ResourceIDs) == 0 {
+			m.statusMessage = "Error: none of the containers runs on a known worker node"
+			return m, nil, true
+		}
+		m.observeView = &observeView{target: target, detailCursor: m.detailCursor}
+		m.detailCursor = 0
+		m.statusMessage = ""
+		m.detailLoading = true
+		m.detailViewport.GotoTop()
+		return m, observeResource(m.client, target, m.config), true
 	}
 
-	var b strings.Builder
+	switch key {
+	case "tab", "shift+tab":
+		if v.obs == nil {
+			return m, nil, true
+		}
+		delta := 1
+		if key == "shift+tab" {
+			delta = -1
+		}
+		m.moveDetailCursor(delta, len(v.obs.Storages))
+		m.detailViewport.SetContent(renderObservation(v, m.detailCursor, m.detailViewport.Width))
+		return m, nil, true
+	case "enter":
+		if v.obs == nil || m.detailCursor >= len(v.obs.Storages) {
+			return m, nil, true
+		}
+		cmd := m.openMonitoringStorageDetail(v.obs.Storages[m.detailCursor].Ref)
+		return m, cmd, true
+	case "r":
+		m.detailLoading = true
+		return m, observeResource(m.client, v.target, m.config), true
+	case "esc", "q", "backspace":
+		m.observeView = nil
+		m.detailCursor = v.detailCursor
+		m.statusMessage = ""
+		m.detailViewport.SetContent(m.renderObservedDetail())
+		m.detailViewport.GotoTop()
+		return m, nil, true
+	}
+	return m, nil, false
+}
 
-	// Header
-	if m.accountName != "" {
-		b.WriteString(statusBarStyle.Render(fmt.Sprintf("Account: %s", m.accountName)))
-		b.WriteString("\n")
+// openMonitoringStorageDetail opens the storage detail from the observe view
+func (m *model) openMonitoringStorageDetail(ref MonitoringStorageRef) tea.Cmd {
+	rt := ResourceTypeMonitoringTraceStorage
+	switch ref.Kind {
+	case StorageKindLogs:
+		rt = ResourceTypeMonitoringLogStorage
+	case StorageKindMetrics:
+		rt = ResourceTypeMonitoringMetricsStorage
 	}
-	b.WriteString("\n")
+	m.pushDetail()
+	return m.openDetail(rt, m.cursor, ref.ResourceID)
+}
 
-	// Detail mode view
-	if m.detailMode {
-		if m.detailLoading {
-			b.WriteString("Loading details...\n")
-		} else if m.serverDetail != nil || m.switchDetail != nil || m.dnsDetail != nil || m.elbDetail != nil || m.gslbDetail != nil || m.dbDetail != nil || m.diskDetail != nil || m.archiveDetail != nil || m.internetDetail != nil || m.vpcRouterDetail != nil || m.packetFilterDetail != nil || m.loadBalancerDetail != nil || m.nfsDetail != nil || m.sshKeyDetail != nil || m.autoBackupDetail != nil || m.simpleMonitorDetail != nil || m.bridgeDetail != nil || m.containerRegistryDetail != nil || m.appRunClusterDetail != nil || m.appRunLBDetail != nil || m.appRunASGDetail != nil || m.monitoringLogStorageDetail != nil || m.monitoringMetricsStorageDetail != nil || m.monitoringTraceStorageDetail != nil {
-			b.WriteString(m.detailViewport.View())
-			b.WriteString("\n")
-			b.WriteString(helpStyle.Render("↑/↓/j/k: scroll | ESC/q: back"))
+// relatedLink is an ID shown in a detail view that opens the detail of the resource it refers to
+type relatedLink struct {
+	Label        string
+	ResourceType ResourceType
+	ID           string
+	Zone         string // zone of the resource when it is known; empty for the zone of the shown detail
+}
+
+// detailLocation is a detail view on the back stack
+type detailLocation struct {
+	ResourceType ResourceType
+	Zone         int // index into zones
+	ID           string
+	Cursor       int
+}
+
+// relatedLinks returns the related IDs of the shown detail
+func (m model) relatedLinks() []relatedLink {
+	var links []relatedLink
+	add := func(label string, rt ResourceType, id, zone string) {
+		if id != "" && id != "0" {
+			links = append(links, relatedLink{Label: label, ResourceType: rt, ID: id, Zone: zone})
 		}
-		return b.String()
 	}
+	switch {
+	case m.diskDetail != nil:
+		add("Server", ResourceTypeServer, m.diskDetail.ServerID, "")
+	case m.archiveDetail != nil:
+		add("Source Disk", ResourceTypeDisk, m.archiveDetail.SourceDiskID, "")
+		add("Source Archive", ResourceTypeArchive, m.archiveDetail.SourceArchiveID, m.archiveDetail.SourceZone)
+	case m.internetDetail != nil:
+		add("Switch", ResourceTypeSwitch, m.internetDetail.SwitchID, "")
+	case m.vpcRouterDetail != nil:
+		for _, nic := range m.vpcRouterDetail.NICs {
+			add(fmt.Sprintf("NIC%d Switch", nic.Index), ResourceTypeSwitch, nic.SwitchID, "")
+		}
+	case m.nfsDetail != nil:
+		add("Switch", ResourceTypeSwitch, m.nfsDetail.SwitchID, "")
+	case m.autoBackupDetail != nil:
+		add("Disk", ResourceTypeDisk, m.autoBackupDetail.DiskID, m.autoBackupDetail.ZoneName)
+	}
+	return links
+}
 
-	// Resource type selector mode
-	if m.resourceSelectMode {
-		b.WriteString(titleStyle.Render("Select Resource Type"))
-		b.WriteString("\n")
-		for i, rt := range AllResourceTypes {
-			if i == m.resourceSelectCursor {
-				b.WriteString(selectedItemStyle.Render(fmt.Sprintf("> %s", rt.String())))
-			} else {
-				b.WriteString(itemStyle.Render(fmt.Sprintf("  %s", rt.String())))
-			}
-			b.WriteString("\n")
-		}
-		b.WriteString("\n")
-		b.WriteString(helpStyle.Render("↑/↓/j/k: move | Enter: select | Esc: cancel"))
-		return b.String()
+// renderLinkedDetail renders a detail that has related IDs, with the selected one highlighted
+func (m model) renderLinkedDetail() string {
+	var content string
+	switch {
+	case m.diskDetail != nil:
+		content = renderDiskDetail(m.diskDetail)
+	case m.archiveDetail != nil:
+		content = renderArchiveDetail(m.archiveDetail)
+	case m.internetDetail != nil:
+		content = renderInternetDetail(m.internetDetail)
+	case m.vpcRouterDetail != nil:
+		content = renderVPCRouterDetail(m.vpcRouterDetail)
+	case m.nfsDetail != nil:
+		content = renderNFSDetail(m.nfsDetail)
+	case m.autoBackupDetail != nil:
+		content = renderAutoBackupDetail(m.autoBackupDetail)
 	}
+	return content + renderRelatedLinks(m.relatedLinks(), m.detailCursor)
+}
 
-	// Zone selector and resource type
-	// Show "global" for global resources (DNS, ELB, GSLB, SSHKey, SimpleMonitor, ContainerRegistry, AppRunCluster, Monitoring)
-	if m.resourceType == ResourceTypeDNS || m.resourceType == ResourceTypeELB || m.resourceType == ResourceTypeGSLB || m.resourceType == ResourceTypeSSHKey || m.resourceType == ResourceTypeSimpleMonitor || m.resourceType == ResourceTypeContainerRegistry || m.resourceType == ResourceTypeAppRunDedicated || m.resourceType == ResourceTypeMonitoringLogStorage || m.resourceType == ResourceTypeMonitoringMetricsStorage || m.resourceType == ResourceTypeMonitoringTraceStorage {
-		b.WriteString("Zone: ")
-		b.WriteString(zoneStyle.Render("global"))
-		b.WriteString(" | Type: ")
-		b.WriteString(selectedStyle.Render(m.resourceType.String()))
-		b.WriteString("\n")
-	} else {
-		b.WriteString("Zone: ")
-		for i, zone := range m.zones {
-			if i == m.cursor {
-				b.WriteString(selectedStyle.Render(fmt.Sprintf("[%s]", zone)))
-			} else {
-				b.WriteString(zoneStyle.Render(fmt.Sprintf(" %s ", zone)))
-			}
-			b.WriteString(" ")
-		}
-		b.WriteString(" | Type: ")
-		b.WriteString(selectedStyle.Render(m.resourceType.String()))
-		b.WriteString("\n")
+// detailLocation returns where the shown detail can be reopened from, or false for details
+// that are not opened by resource type and ID
+func (m model) detailLocation() (detailLocation, bool) {
+	loc := detailLocation{Zone: m.cursor, Cursor: m.detailCursor}
+	switch {
+	case m.serverDetail != nil:
+		loc.ResourceType, loc.ID = ResourceTypeServer, m.serverDetail.ID
+	case m.switchDetail != nil:
+		loc.ResourceType, loc.ID = ResourceTypeSwitch, m.switchDetail.ID
+	case m.dbDetail != nil:
+		loc.ResourceType, loc.ID = ResourceTypeDB, m.dbDetail.ID
+	case m.diskDetail != nil:
+		loc.ResourceType, loc.ID = ResourceTypeDisk, m.diskDetail.ID
+	case m.archiveDetail != nil:
+		loc.ResourceType, loc.ID = ResourceTypeArchive, m.archiveDetail.ID
+	case m.internetDetail != nil:
+		loc.ResourceType, loc.ID = ResourceTypeInternet, m.internetDetail.ID
+	case m.vpcRouterDetail != nil:
+		loc.ResourceType, loc.ID = ResourceTypeVPCRouter, m.vpcRouterDetail.ID
+	case m.nfsDetail != nil:
+		loc.ResourceType, loc.ID = ResourceTypeNFS, m.nfsDetail.ID
+	case m.autoBackupDetail != nil:
+		loc.ResourceType, loc.ID = ResourceTypeAutoBackup, m.autoBackupDetail.ID
+	default:
+		ref, ok := m.monitoringStorageRef()
+		if !ok {
+			return loc, false
+		}
+		switch ref.Kind {
+		case StorageKindLogs:
+			loc.ResourceType = ResourceTypeMonitoringLogStorage
+		case StorageKindMetrics:
+			loc.ResourceType = ResourceTypeMonitoringMetricsStorage
+		default:
+			loc.ResourceType = ResourceTypeMonitoringTraceStorage
+		}
+		loc.ID = ref.ResourceID
 	}
+	// The observe view is reopened as the detail below it
+	if m.observeView != nil {
+		loc.Cursor = m.observeView.detailCursor
+	}
+	return loc, true
+}
 
-	// Search mode or search status
-	if m.searchMode {
-		b.WriteString("/" + m.searchInput.View())
-		b.WriteString("\n")
-	} else if m.searchQuery != "" {
-		matchInfo := fmt.Sprintf("Search: %s (%d matches)", m.searchQuery, len(m.searchMatches))
-		if len(m.searchMatches) > 0 {
-			matchInfo = fmt.Sprintf("Search: %s (%d/%d)", m.searchQuery, m.currentMatch+1, len(m.searchMatches))
-		}
-		b.WriteString(helpStyle.Render(matchInfo))
-		b.WriteString("\n")
-	} else {
-		b.WriteString("\n")
+// pushDetail puts the shown detail on the back stack before another detail is opened
+func (m *model) pushDetail() {
+	if loc, ok := m.detailLocation(); ok {
+		m.detailStack = append(m.detailStack, loc)
 	}
+}
 
-	// Resource list or loading/error
-	if m.loading {
-		b.WriteString(fmt.Sprintf("Loading %s...\n", strings.ToLower(m.resourceType.String())))
-	} else if m.err != nil {
-		b.WriteString(fmt.Sprintf("Error: %v\n", m.err))
-	} else {
-		// Render table header based on resource type
-		header := m.getTableHeader()
-		if header != "" {
-			b.WriteString(zoneStyle.Render(header))
-			b.WriteString("\n")
-		}
-		b.WriteString(m.list.View())
-		b.WriteString("\n")
-		b.WriteString(helpStyle.Render("Enter: details | /: search | n/N: next/prev | t: type | z: zone | r: refresh | q: quit"))
+// popDetail reopens the detail on top of the back stack
+func (m *model) popDetail() tea.Cmd {
+	loc := m.detailStack[len(m.detailStack)-1]
+	m.detailStack = m.detailStack[:len(m.detailStack)-1]
+	cmd := m.openDetail(loc.ResourceType, loc.Zone, loc.ID)
+	m.detailCursor = loc.Cursor
+	return cmd
+}
+
+// openDetail switches to the list of resource type rt in the zone at index zone and opens
+// the detail of the resource id
+func (m *model) openDetail(rt ResourceType, zone int, id string) tea.Cmd {
+	slog.Info("Opening detail", slog.String("type", rt.String()), slog.String("id", id))
+	m.clearDetail()
+	m.detailCursor = 0
+	m.statusMessage = ""
+	if zone != m.cursor {
+		m.cursor = zone
+		m.currentZone = m.zones[zone]
+		m.client.SetZone(m.currentZone)
 	}
+	m.resourceType = rt
+	m.searchQuery = ""
+	m.searchMatches = []int{}
+	m.currentMatch = -1
+	m.err = nil
+	m.loading = true
+	m.detailMode = true
+	m.detailLoading = true
+	return tea.Batch(loadResources(m.client, rt), loadResourceDetail(m.client, rt, id))
+}
 
-	return b.String()
+// handleRelatedLinkAction selects and follows the related IDs of the shown detail
+func (m model) handleRelatedLinkAction(key string) (model, tea.Cmd, bool) {
+	links := m.relatedLinks()
+	if len(links) == 0 {
+		return m, nil, false
+	}
+	switch key {
+	case "tab", "shift+tab":
+		delta := 1
+		if key == "shift+tab" {
+			delta = -1
+		}
+		m.moveDetailCursor(delta, len(links))
+		m.detailViewport.SetContent(m.renderLinkedDetail())
+		return m, nil, true
+	case "enter":
+		link := links[min(m.detailCursor, len(links)-1)]
+		zone := m.cursor
+		if link.Zone != "" {
+			zone = slices.Index(m.zones, link.Zone)
+			if zone < 0 {
+				m.statusMessage = fmt.Sprintf("Error: %s %s is in zone %s, which is not available", link.ResourceType, link.ID, link.Zone)
+				return m, nil, true
+			}
+		}
+		m.pushDetail()
+		cmd := m.openDetail(link.ResourceType, zone, link.ID)
+		return m, cmd, true
+	}
+	return m, nil, false
 }
@@ -1,12 +1,22 @@
 package internal
 
 import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/google/uuid"
+	"github.com/sacloud/iaas-api-go"
+	"github.com/sacloud/iaas-api-go/types"
+	v1 "github.com/sacloud/monitoring-suite-api-go/apis/v1"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestInitialModel(t *testing.T) {
@@ -599,3 +609,951 @@ func TestServerDetailLoadedMsgWithError(t *testing.T) {
 	assert.False(t, m.detailMode) // Should exit detail mode on error
 	assert.NotNil(t, m.err)
 }
+
+func TestLoadBalancerDetailToggleConfirmation(t *testing.T) {
+	client, _ := NewSakuraClient("tk1b")
+	m := InitialModel(client, "tk1b")
+	m.detailMode = true
+	m.loadBalancerDetail = &LoadBalancerDetail{
+		LoadBalancer: LoadBalancer{ID: "123", Name: "lb"},
+		VIPs: []LoadBalancerVIP{
+			{VirtualIPAddress: "192.0.2.10", Port: 80, Servers: []LoadBalancerServer{
+				{IPAddress: "192.0.2.11", Port: 80, Enabled: true},
+			}},
+			{VirtualIPAddress: "192.0.2.10", Port: 443, Servers: []LoadBalancerServer{
+				{IPAddress: "192.0.2.11", Port: 443, Enabled: true},
+				{IPAddress: "192.0.2.12", Port: 443, Enabled: false},
+			}},
+		},
+	}
+
+	// Select the third real server (second server of the second VIP)
+	for range 2 {
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyTab})
+		m = updated.(model)
+	}
+	assert.Equal(t, 2, m.detailCursor)
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+	m = updated.(model)
+	assert.Contains(t, m.confirmMessage, "Enable real server 192.0.2.12:443")
+	assert.NotNil(t, m.confirmCmd)
+
+	// Cancel keeps detail mode
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	m = updated.(model)
+	assert.Nil(t, cmd)
+	assert.Equal(t, "", m.confirmMessage)
+	assert.True(t, m.detailMode)
+}
+
+func TestLoadBalancerRealServerAt(t *testing.T) {
+	detail := &LoadBalancerDetail{
+		VIPs: []LoadBalancerVIP{
+			{Servers: []LoadBalancerServer{{IPAddress: "a"}}},
+			{Servers: []LoadBalancerServer{{IPAddress: "b"}, {IPAddress: "c"}}},
+		},
+	}
+
+	assert.Equal(t, 3, detail.RealServerCount())
+
+	vipIndex, serverIndex, ok := detail.RealServerAt(2)
+	assert.True(t, ok)
+	assert.Equal(t, 1, vipIndex)
+	assert.Equal(t, 1, serverIndex)
+
+	_, _, ok = detail.RealServerAt(3)
+	assert.False(t, ok)
+}
+
+func TestFindLoadBalancerServer(t *testing.T) {
+	// A VIP and a server were added in front of the ones shown in the detail
+	vips := []*iaas.LoadBalancerVirtualIPAddress{
+		{VirtualIPAddress: "192.168.0.100", Port: 443, Servers: []*iaas.LoadBalancerServer{{IPAddress: "192.168.0.11", Port: 443}}},
+		{VirtualIPAddress: "192.168.0.100", Port: 80, Servers: []*iaas.LoadBalancerServer{
+			{IPAddress: "192.168.0.13", Port: 80},
+			{IPAddress: "192.168.0.11", Port: 80},
+		}},
+	}
+	vip := LoadBalancerVIP{VirtualIPAddress: "192.168.0.100", Port: 80}
+
+	srv, err := findLoadBalancerServer(vips, vip, LoadBalancerServer{IPAddress: "192.168.0.11", Port: 80})
+	require.NoError(t, err)
+	assert.Same(t, vips[1].Servers[1], srv)
+
+	_, err = findLoadBalancerServer(vips, vip, LoadBalancerServer{IPAddress: "192.168.0.12", Port: 80})
+	assert.EqualError(t, err, "real server 192.168.0.12:80 not found on VIP 192.168.0.100:80")
+	_, err = findLoadBalancerServer(vips, LoadBalancerVIP{VirtualIPAddress: "192.168.0.101", Port: 80}, LoadBalancerServer{})
+	assert.EqualError(t, err, "VIP 192.168.0.101:80 not found")
+}
+
+func TestActionDoneMsgError(t *testing.T) {
+	client, _ := NewSakuraClient("tk1b")
+	m := InitialModel(client, "tk1b")
+	m.detailMode = true
+
+	updated, cmd := m.Update(actionDoneMsg{err: assert.AnError})
+	m = updated.(model)
+
+	assert.Nil(t, cmd)
+	assert.True(t, m.detailMode)
+	assert.Contains(t, m.statusMessage, "Error:")
+}
+
+func TestSimpleMonitorUnhealthyFilter(t *testing.T) {
+	client, _ := NewSakuraClient("tk1b")
+	m := InitialModel(client, "tk1b")
+	m.loading = false
+	m.resourceType = ResourceTypeSimpleMonitor
+
+	updated, _ := m.Update(simpleMonitorsLoadedMsg{simpleMonitors: []SimpleMonitor{
+		{ID: "1", Name: "ok", Enabled: true, Health: "UP"},
+		{ID: "2", Name: "ng", Enabled: true, Health: "DOWN"},
+		{ID: "3", Name: "disabled", Enabled: false},
+	}})
+	m = updated.(model)
+	assert.Equal(t, 3, len(m.list.Items()))
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'u'}})
+	m = updated.(model)
+	assert.True(t, m.simpleMonitorUnhealthyOnly)
+	assert.Equal(t, 1, len(m.list.Items()))
+	assert.Equal(t, "ng", m.list.Items()[0].(SimpleMonitor).Name)
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'u'}})
+	m = updated.(model)
+	assert.Equal(t, 3, len(m.list.Items()))
+}
+
+func TestBuildHealthTimeline(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	samples := []SimpleMonitorResponseTime{
+		{Time: base, Sec: 0.1},
+		{Time: base.Add(time.Minute), Sec: 0.2},
+		{Time: base.Add(2 * time.Minute), Sec: 0},
+		{Time: base.Add(3 * time.Minute), Sec: 0},
+		{Time: base.Add(4 * time.Minute), Sec: 0.1},
+	}
+
+	changes := buildHealthTimeline(samples)
+
+	assert.Equal(t, []SimpleMonitorHealthChange{
+		{Time: base, Health: "UP"},
+		{Time: base.Add(2 * time.Minute), Health: "DOWN"},
+		{Time: base.Add(4 * time.Minute), Health: "UP"},
+	}, changes)
+}
+
+func TestSimpleMonitorBulkEnableConfirmation(t *testing.T) {
+	client, _ := NewSakuraClient("tk1b")
+	m := InitialModel(client, "tk1b")
+	m.loading = false
+	m.resourceType = ResourceTypeSimpleMonitor
+
+	updated, _ := m.Update(simpleMonitorsLoadedMsg{simpleMonitors: []SimpleMonitor{
+		{ID: "1", Name: "a", Enabled: true},
+		{ID: "2", Name: "b", Enabled: false},
+		{ID: "3", Name: "c", Enabled: true},
+	}})
+	m = updated.(model)
+
+	// Without marks the selected monitor is the target
+	assert.Equal(t, []string{"a"}, simpleMonitorNames(m.simpleMonitorTargets()))
+
+	// Mark the first two monitors
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	m = updated.(model)
+	m.list.Select(1)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	m = updated.(model)
+	assert.Equal(t, []string{"a", "b"}, simpleMonitorNames(m.simpleMonitorTargets()))
+	assert.Equal(t, map[string]bool{"1": true, "2": true}, m.simpleMonitorMarks)
+	assert.Contains(t, m.View(), "*b")
+
+	// A mixed selection is disabled
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+	m = updated.(model)
+	assert.Equal(t, "Disable 2 simple monitors?", m.confirmMessage)
+	assert.NotNil(t, m.confirmCmd)
+	assert.Contains(t, m.View(), "[y/N]")
+}
+
+func simpleMonitorNames(monitors []SimpleMonitor) []string {
+	names := make([]string, 0, len(monitors))
+	for _, sm := range monitors {
+		names = append(names, sm.Name)
+	}
+	return names
+}
+
+func TestSimpleMonitorEditFormValidation(t *testing.T) {
+	client, _ := NewSakuraClient("tk1b")
+	m := InitialModel(client, "tk1b")
+	m.detailMode = true
+	m.simpleMonitorDetail = &SimpleMonitorDetail{
+		SimpleMonitor: SimpleMonitor{ID: "1", Name: "web", Protocol: "http", Enabled: true},
+		DelayLoop:     60,
+		Timeout:       10,
+		Path:          "/",
+	}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	m = updated.(model)
+	assert.NotNil(t, m.form)
+	assert.Equal(t, "target", m.form.fields[0].key)
+	assert.Equal(t, "/", m.form.values()["path"])
+
+	// Submitting without a target keeps the form open with an error
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	m = updated.(model)
+	assert.Nil(t, cmd)
+	assert.NotNil(t, m.form)
+	assert.Contains(t, m.form.err, "target")
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(model)
+	assert.Nil(t, m.form)
+	assert.True(t, m.detailMode)
+
+	// A ping monitor has neither a port nor the HTTP fields
+	m.simpleMonitorDetail.Protocol = "ping"
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'E'}})
+	m = updated.(model)
+	require.NotNil(t, m.form)
+	assert.NotContains(t, m.form.values(), "port")
+	assert.NotContains(t, m.form.values(), "path")
+}
+
+func TestSimpleMonitorParamsApply(t *testing.T) {
+	params := SimpleMonitorParams{DelayLoop: 60, Port: 8080, Path: "/health", Host: "example.com", ContainsString: "ok"}
+
+	ping := &iaas.SimpleMonitor{HealthCheck: &iaas.SimpleMonitorHealthCheck{Protocol: types.SimpleMonitorProtocols.Ping}}
+	params.apply(ping)
+	assert.Equal(t, 60, ping.DelayLoop)
+	assert.Equal(t, &iaas.SimpleMonitorHealthCheck{Protocol: types.SimpleMonitorProtocols.Ping}, ping.HealthCheck)
+
+	tcp := &iaas.SimpleMonitor{HealthCheck: &iaas.SimpleMonitorHealthCheck{Protocol: types.SimpleMonitorProtocols.TCP}}
+	params.apply(tcp)
+	assert.Equal(t, 8080, tcp.HealthCheck.Port.Int())
+	assert.Empty(t, tcp.HealthCheck.Path)
+
+	https := &iaas.SimpleMonitor{HealthCheck: &iaas.SimpleMonitorHealthCheck{Protocol: types.SimpleMonitorProtocols.HTTPS}}
+	params.apply(https)
+	assert.Equal(t, "/health", https.HealthCheck.Path)
+	assert.Equal(t, "ok", https.HealthCheck.ContainsString)
+}
+
+func TestRegistryBrowserNavigation(t *testing.T) {
+	server, _ := newFakeRegistry(t)
+
+	client, _ := NewSakuraClient("tk1b")
+	m := InitialModel(client, "tk1b").WithConfig(&Config{
+		Registries: map[string]RegistryConfig{
+			"example.sakuracr.jp": {Username: "reader", Password: "secret", URL: server.URL},
+		},
+	})
+	m.detailMode = true
+	m.containerRegistryDetail = &ContainerRegistryDetail{
+		ContainerRegistry: ContainerRegistry{ID: "1", Name: "example", FQDN: "example.sakuracr.jp"},
+	}
+
+	// Open the browser and load the catalog
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'i'}})
+	m = updated.(model)
+	require.NotNil(t, m.registryBrowser)
+	updated, _ = m.Update(cmd())
+	m = updated.(model)
+	assert.Equal(t, []string{"app", "web/nginx"}, m.registryBrowser.repositories)
+
+	// Select the second repository and load its tags
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = updated.(model)
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	updated, _ = m.Update(cmd())
+	m = updated.(model)
+	assert.Equal(t, "web/nginx", m.registryBrowser.repository)
+	assert.Len(t, m.registryBrowser.tags, 2)
+	assert.Equal(t, 0, m.detailCursor)
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	m = updated.(model)
+	assert.Equal(t, "Delete web/nginx:latest (0123456789ab)?", m.confirmMessage)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	m = updated.(model)
+
+	// esc returns to the repository list, then closes the browser
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(model)
+	assert.Equal(t, "", m.registryBrowser.repository)
+	assert.Equal(t, 1, m.detailCursor)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(model)
+	assert.Nil(t, m.registryBrowser)
+	assert.True(t, m.detailMode)
+}
+
+func TestMetricsQueryPanel(t *testing.T) {
+	server, _ := newFakePrometheus(t)
+
+	client, _ := NewSakuraClient("tk1b")
+	m := InitialModel(client, "tk1b").WithConfig(&Config{
+		MetricsStorages: map[string]MetricsStorageConfig{
+			"113000000001": {
+				URL:   server.URL + "/prometheus",
+				Token: "t0ken",
+				Queries: []SavedQuery{
+					{Name: "up", Query: "up"},
+					{Name: "broken", Query: "bad("},
+				},
+			},
+		},
+	})
+	m.detailMode = true
+	m.monitoringMetricsStorageDetail = &MonitoringMetricsStorageDetail{MetricsStorage: v1.MetricsStorage{
+		Name:       v1.NewOptString("app"),
+		ResourceID: v1.NewNilInt64(113000000001),
+	}}
+	assert.Contains(t, m.detailHelp(), "Q: query")
+
+	// Opening the panel runs the first saved query with the configured token
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'Q'}})
+	m = updated.(model)
+	require.NotNil(t, m.metricsQuery)
+	require.NotNil(t, cmd)
+	updated, _ = m.Update(cmd())
+	m = updated.(model)
+	require.NoError(t, m.metricsQuery.err)
+	require.Len(t, m.metricsQuery.result.Series, 2)
+	content := renderMetricsQuery(m.monitoringMetricsStorageDetail, m.metricsQuery, m.detailCursor)
+	assert.Contains(t, content, "vector, 2 series")
+	assert.Contains(t, content, `up{instance="web2", job="node"}`)
+
+	// Switching to a time window reruns the query as a range query
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'w'}})
+	m = updated.(model)
+	updated, _ = m.Update(cmd())
+	m = updated.(model)
+	assert.Equal(t, "matrix", m.metricsQuery.result.ResultType)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'v'}})
+	m = updated.(model)
+	assert.Contains(t, renderMetricsQuery(m.monitoringMetricsStorageDetail, m.metricsQuery, m.detailCursor), "last: 1  max: 1.5")
+
+	// Errors from the server are shown in the panel
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = updated.(model)
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	updated, _ = m.Update(cmd())
+	m = updated.(model)
+	assert.Equal(t, "bad(", m.metricsQuery.query)
+	assert.ErrorContains(t, m.metricsQuery.err, "bad_data")
+
+	// An ad-hoc query entered in the form
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+	m = updated.(model)
+	require.NotNil(t, m.form)
+	m.form.fields[0].input.SetValue("scalar(1)")
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	m = updated.(model)
+	updated, _ = m.Update(cmd())
+	m = updated.(model)
+	assert.Equal(t, "scalar(1)", m.metricsQuery.query)
+	assert.Equal(t, "scalar", m.metricsQuery.result.ResultType)
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(model)
+	assert.Nil(t, m.metricsQuery)
+	assert.True(t, m.detailMode)
+}
+
+func TestLogViewer(t *testing.T) {
+	server, fake := newFakeLoki(t)
+	now := time.Now()
+	fake.add(now.Add(-2*time.Minute), "info", "GET /api 200")
+	fake.add(now.Add(-time.Minute), "error", "GET /api 500")
+
+	client, _ := NewSakuraClient("tk1b")
+	m := InitialModel(client, "tk1b").WithConfig(&Config{
+		LogStorages: map[string]LogStorageConfig{
+			"app-logs": {URL: server.URL, Token: "t0ken"},
+		},
+	})
+	m.detailMode = true
+	m.monitoringLogStorageDetail = &MonitoringLogStorageDetail{LogStorage: v1.LogStorage{
+		Name:       v1.NewOptString("app-logs"),
+		ResourceID: v1.NewNilInt64(113000000002),
+	}}
+	assert.Contains(t, m.detailHelp(), "L: view logs")
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'L'}})
+	m = updated.(model)
+	require.NotNil(t, m.logViewer)
+	updated, _ = m.Update(cmd())
+	m = updated.(model)
+	require.NoError(t, m.logViewer.err)
+	require.Len(t, m.logViewer.entries, 2)
+	assert.Contains(t, renderLogViewer(m.monitoringLogStorageDetail, m.logViewer), "ERROR GET /api 500")
+
+	// The filter is applied by the server
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+	m = updated.(model)
+	require.NotNil(t, m.form)
+	m.form.fields[0].input.SetValue("500")
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	m = updated.(model)
+	updated, _ = m.Update(cmd())
+	m = updated.(model)
+	require.Len(t, m.logViewer.entries, 1)
+	assert.Equal(t, `{service_name=~".+"} |= "500"`, m.logViewer.query())
+
+	// Following appends only the lines newer than the last one shown
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'F'}})
+	m = updated.(model)
+	fake.add(time.Now(), "error", "POST /api 500")
+	updated, tick := m.Update(cmd())
+	m = updated.(model)
+	require.Len(t, m.logViewer.entries, 2)
+	assert.Equal(t, "POST /api 500", m.logViewer.entries[1].Line)
+	assert.NotNil(t, tick)
+
+	// Stopping discards polls that are still in flight
+	seq := m.logViewer.followSeq
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'F'}})
+	m = updated.(model)
+	updated, cmd = m.Update(logFollowTickMsg{seq: seq})
+	m = updated.(model)
+	assert.Nil(t, cmd)
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	m = updated.(model)
+	require.NotNil(t, m.form)
+	path := filepath.Join(t.TempDir(), "logs.txt")
+	m.form.fields[0].input.SetValue(path)
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	m = updated.(model)
+	done := cmd().(actionDoneMsg)
+	require.NoError(t, done.err)
+	assert.Equal(t, "Saved 2 log entries to "+path, done.message)
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(model)
+	assert.Nil(t, m.logViewer)
+	assert.True(t, m.detailMode)
+}
+
+func TestStorageQueryFetchedToken(t *testing.T) {
+	server, fake := newFakeLoki(t)
+	fake.add(time.Now().Add(-time.Minute), "info", "GET /api 200")
+
+	client, _ := NewSakuraClient("tk1b")
+	m := InitialModel(client, "tk1b").WithConfig(&Config{
+		LogStorages: map[string]LogStorageConfig{"app-logs": {URL: server.URL}},
+	})
+	m.detailMode = true
+	m.monitoringLogStorageDetail = &MonitoringLogStorageDetail{LogStorage: v1.LogStorage{
+		Name:       v1.NewOptString("app-logs"),
+		ResourceID: v1.NewNilInt64(113000000002),
+	}}
+
+	// Without a configured token the viewer waits for the first access key of the storage
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'L'}})
+	m = updated.(model)
+	require.NotNil(t, cmd)
+	require.NotNil(t, m.logViewer)
+	assert.Nil(t, m.logViewer.client)
+	assert.True(t, m.detailLoading)
+
+	// A token for a panel that is not open is ignored
+	updated, cmd = m.Update(storageTokenLoadedMsg{kind: StorageKindMetrics, token: "t0ken"})
+	m = updated.(model)
+	assert.Nil(t, cmd)
+	assert.Nil(t, m.logViewer.client)
+
+	updated, cmd = m.Update(storageTokenLoadedMsg{kind: StorageKindLogs, token: "t0ken"})
+	m = updated.(model)
+	require.NotNil(t, m.logViewer.client)
+	updated, _ = m.Update(cmd())
+	m = updated.(model)
+	require.NoError(t, m.logViewer.err)
+	assert.Len(t, m.logViewer.entries, 1)
+}
+
+func TestTraceExplorer(t *testing.T) {
+	server, searches := newFakeTempo(t)
+
+	client, _ := NewSakuraClient("tk1b")
+	m := InitialModel(client, "tk1b").WithConfig(&Config{
+		TraceStorages: map[string]TraceStorageConfig{
+			"113000000003": {URL: server.URL, Token: "t0ken"},
+		},
+	})
+	m.detailMode = true
+	m.monitoringTraceStorageDetail = &MonitoringTraceStorageDetail{TraceStorage: v1.TraceStorage{
+		Name:       v1.NewOptString("app-traces"),
+		ResourceID: 113000000003,
+	}}
+	assert.Contains(t, m.detailHelp(), "T: explore traces")
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'T'}})
+	m = updated.(model)
+	require.NotNil(t, m.traceExplorer)
+	updated, _ = m.Update(cmd())
+	m = updated.(model)
+	require.Len(t, m.traceExplorer.traces, 2)
+	assert.Contains(t, renderTraceExplorer(m.monitoringTraceStorageDetail, m.traceExplorer, m.detailCursor), "GET /api/users")
+
+	// The search conditions are sent to the server
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+	m = updated.(model)
+	require.NotNil(t, m.form)
+	m.form.fields[0].input.SetValue("web")
+	m.form.fields[2].input.SetValue("100ms")
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	m = updated.(model)
+	updated, _ = m.Update(cmd())
+	m = updated.(model)
+	assert.Equal(t, `{ resource.service.name = "web" } min=100ms`, (*searches)[1])
+
+	// Open the older trace as a waterfall
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = updated.(model)
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	updated, _ = m.Update(cmd())
+	m = updated.(model)
+	require.NotNil(t, m.traceExplorer.trace)
+	assert.Equal(t, 0, m.detailCursor)
+	assert.Contains(t, m.detailHelp(), "select span")
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = updated.(model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = updated.(model)
+	out := renderTraceExplorer(m.monitoringTraceStorageDetail, m.traceExplorer, m.detailCursor)
+	assert.Contains(t, out, "    SELECT users")
+	assert.Contains(t, out, "  ListUsers [users]")
+	assert.Contains(t, out, "Status:    error: deadlock")
+	assert.Contains(t, out, "  db.system = mysql")
+
+	// Back to the results with the opened trace selected, then close the explorer
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(model)
+	assert.Nil(t, m.traceExplorer.trace)
+	assert.Equal(t, 1, m.detailCursor)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(model)
+	assert.Nil(t, m.traceExplorer)
+	assert.True(t, m.detailMode)
+}
+
+func TestStorageKeyBrowser(t *testing.T) {
+	client, _ := NewSakuraClient("tk1b")
+	m := InitialModel(client, "tk1b")
+	m.detailMode = true
+	m.monitoringLogStorageDetail = &MonitoringLogStorageDetail{LogStorage: v1.LogStorage{
+		Name:       v1.NewOptString("app-logs"),
+		ResourceID: v1.NewNilInt64(113000000002),
+		Endpoints:  v1.LogStorageEndpoints{Ingester: v1.LogStorageEndpointsIngester{Address: "logs.example.jp"}},
+	}}
+	assert.Contains(t, m.detailHelp(), "K: access keys")
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'K'}})
+	m = updated.(model)
+	require.NotNil(t, m.storageKeys)
+	assert.NotNil(t, cmd)
+	first, second := uuid.New(), uuid.New()
+	updated, _ = m.Update(storageKeysLoadedMsg{keys: []StorageAccessKey{
+		{UID: first, Token: "token-of-first-key", Description: "fluent-bit"},
+	}})
+	m = updated.(model)
+	assert.Contains(t, renderStorageKeys(m.storageKeys, m.detailCursor), "token-of********")
+
+	// Other panels of the storage stay closed while the keys are shown
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'L'}})
+	m = updated.(model)
+	assert.Nil(t, m.logViewer)
+
+	// The secret of a new key is shown, and the new key is selected
+	updated, _ = m.Update(storageKeyCreatedMsg{key: &StorageAccessKey{UID: second, Token: "token-of-second-key", Secret: "s3cret"}})
+	m = updated.(model)
+	assert.Contains(t, m.statusMessage, "copy the secret now")
+	updated, _ = m.Update(storageKeysLoadedMsg{keys: []StorageAccessKey{
+		{UID: first, Token: "token-of-first-key"},
+		{UID: second, Token: "token-of-second-key"},
+	}})
+	m = updated.(model)
+	assert.Equal(t, 1, m.detailCursor)
+	assert.Contains(t, renderStorageKeys(m.storageKeys, m.detailCursor), "Secret: s3cret")
+
+	var clipboard bytes.Buffer
+	clipboardOutput = &clipboard
+	t.Cleanup(func() { clipboardOutput = os.Stdout })
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+	m = updated.(model)
+	assert.Contains(t, m.storageKeys.snippet, "Header    Authorization Bearer token-of-second-key")
+	// The snippet is copied with OSC 52 as the key is handled
+	assert.Equal(t, "\x1b]52;c;"+base64.StdEncoding.EncodeToString([]byte(m.storageKeys.snippet))+"\x07", clipboard.String())
+	assert.Equal(t, "Copied Fluent Bit config to the clipboard", m.statusMessage)
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	m = updated.(model)
+	assert.Contains(t, m.confirmMessage, "Revoke access key "+second.String())
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	m = updated.(model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(model)
+	assert.Nil(t, m.storageKeys)
+	assert.True(t, m.detailMode)
+}
+
+func TestMonitoringStorageListActions(t *testing.T) {
+	client, _ := NewSakuraClient("tk1b")
+	m := InitialModel(client, "tk1b")
+	m.loading = false
+	m.resourceType = ResourceTypeMonitoringLogStorage
+	updated, _ := m.Update(monitoringLogStoragesLoadedMsg{storages: []MonitoringLogStorage{{LogStorage: v1.LogStorage{
+		Name:       v1.NewOptString("app-logs"),
+		ResourceID: v1.NewNilInt64(113000000002),
+		ExpireDay:  v1.NewOptInt64(14),
+		Usage:      v1.LogStorageUsage{LogRoutings: 2},
+	}}}})
+	m = updated.(model)
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'A'}})
+	m = updated.(model)
+	require.NotNil(t, m.form)
+	assert.Equal(t, "Create log storage", m.form.title)
+	assert.Len(t, m.form.fields, 3)
+	m.form = nil
+
+	// The retention form starts from the current value
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'E'}})
+	m = updated.(model)
+	require.NotNil(t, m.form)
+	assert.Equal(t, "14", m.form.values()["retention"])
+	m.form = nil
+
+	// Deleting warns about the routings and needs the name typed
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	m = updated.(model)
+	require.NotNil(t, m.form)
+	assert.Contains(t, m.form.note, "2 log routings still use this storage")
+	m.form.fields[0].input.SetValue("app-log")
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	m = updated.(model)
+	assert.Nil(t, cmd)
+	require.NotNil(t, m.form)
+	m.form = nil
+
+	m.resourceType = ResourceTypeMonitoringMetricsStorage
+	updated, _ = m.Update(monitoringMetricsStoragesLoadedMsg{storages: []MonitoringMetricsStorage{{MetricsStorage: v1.MetricsStorage{
+		Name:       v1.NewOptString("app-metrics"),
+		ResourceID: v1.NewNilInt64(113000000001),
+	}}}})
+	m = updated.(model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'E'}})
+	m = updated.(model)
+	assert.Nil(t, m.form)
+	assert.Equal(t, "Error: the retention of metrics storages cannot be changed", m.statusMessage)
+}
+
+func TestRoutingListActions(t *testing.T) {
+	client, _ := NewSakuraClient("tk1b")
+	m := InitialModel(client, "tk1b")
+	m.loading = false
+	m.resourceType = ResourceTypeMonitoringRouting
+
+	updated, _ := m.Update(monitoringRoutingsLoadedMsg{
+		logRoutings: []MonitoringLogRouting{{LogRouting: v1.LogRouting{
+			ResourceID: v1.NewOptNilInt64(113000000101),
+			Publisher:  v1.Publisher{Code: "server"},
+			Variant:    "syslog",
+			LogStorage: v1.LogStorage{Name: v1.NewOptString("app-logs")},
+		}}},
+		metricsRoutings: []MonitoringMetricsRouting{{}},
+	})
+	m = updated.(model)
+	require.Len(t, m.list.Items(), 2)
+	assert.Contains(t, m.View(), "A: create routing")
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	m = updated.(model)
+	assert.Equal(t, "Delete log routing 00000000 (resource 113000000101 -> app-logs, syslog)?", m.confirmMessage)
+	assert.NotNil(t, m.confirmCmd)
+	m.confirmMessage, m.confirmCmd = "", nil
+
+	// The form opens with the first resource lacking a routing and submits the resolved routing.
+	// web1 already ships logs but has no metrics routing.
+	updated, _ = m.Update(routingChoicesLoadedMsg{choices: testRoutingChoices()})
+	m = updated.(model)
+	require.NotNil(t, m.form)
+	assert.Equal(t, "web1", m.form.values()["source"])
+	m.form.fields[2].input.SetValue("node")
+	m.form.fields[3].input.SetValue("app-metrics")
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	m = updated.(model)
+	assert.Nil(t, m.form)
+	assert.NotNil(t, cmd)
+	assert.Equal(t, "Running...", m.statusMessage)
+}
+
+func TestAlertRuleBrowser(t *testing.T) {
+	client, _ := NewSakuraClient("tk1b")
+	m := InitialModel(client, "tk1b")
+	m.detailMode = true
+	m.monitoringMetricsStorageDetail = &MonitoringMetricsStorageDetail{MetricsStorage: v1.MetricsStorage{
+		Name:       v1.NewOptString("app-metrics"),
+		ResourceID: v1.NewNilInt64(113000000001),
+	}}
+	assert.Contains(t, m.detailHelp(), "R: alert rules")
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'R'}})
+	m = updated.(model)
+	require.NotNil(t, m.alertRules)
+	assert.NotNil(t, cmd)
+
+	rule := func(name string, firing ...v1.History) MonitoringAlertRule {
+		return MonitoringAlertRule{
+			AlertRule: v1.AlertRule{
+				Name:             v1.NewOptString(name),
+				Query:            "up == 0",
+				EnabledWarning:   v1.NewOptBool(true),
+				ThresholdWarning: v1.NewOptNilString("> 0"),
+			},
+			Project: testAlertProjects[0],
+			Firing:  firing,
+		}
+	}
+	updated, _ = m.Update(alertRulesLoadedMsg{rules: &MonitoringAlertRules{
+		Projects: testAlertProjects,
+		Rules: []MonitoringAlertRule{
+			rule("node-up"),
+			rule("web-down", v1.History{Severity: "critical", Labels: `{"severity":"critical","instance":"web1"}`}),
+		},
+	}})
+	m = updated.(model)
+	m.detailLoading = false
+	content := renderAlertRules(m.monitoringMetricsStorageDetail, m.alertRules, m.detailCursor)
+	assert.Contains(t, content, "Alert Rules: 2 (1 firing)")
+	assert.Contains(t, content, `critical since`)
+	assert.Contains(t, content, "notified: ops, https://notice.example/all")
+
+	// Edit the selected rule with its current values
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = updated.(model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'E'}})
+	m = updated.(model)
+	require.NotNil(t, m.form)
+	assert.Equal(t, "Edit alert rule web-down", m.form.title)
+	assert.Equal(t, "up == 0", m.form.values()["query"])
+
+	// Invalid PromQL keeps the form open
+	m.form.fields[1].input.SetValue("up ==")
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	m = updated.(model)
+	require.NotNil(t, m.form)
+	assert.Nil(t, cmd)
+	m.form = nil
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	m = updated.(model)
+	assert.Equal(t, "Delete alert rule web-down (up == 0)? It is firing 1 alerts", m.confirmMessage)
+	m.confirmMessage, m.confirmCmd = "", nil
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(model)
+	assert.Nil(t, m.alertRules)
+	assert.True(t, m.detailMode)
+}
+
+func TestObserveView(t *testing.T) {
+	client, _ := NewSakuraClient("tk1b")
+	m := InitialModel(client, "tk1b")
+	m.detailMode = true
+	m.serverDetail = &ServerDetail{Server: Server{ID: "113000000001", Name: "web-1"}}
+	assert.Contains(t, m.detailHelp(), "O: observe")
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'O'}})
+	m = updated.(model)
+	require.NotNil(t, m.observeView)
+	assert.NotNil(t, cmd)
+	assert.Equal(t, []string{"113000000001"}, m.observeView.target.ResourceIDs)
+
+	updated, _ = m.Update(observationLoadedMsg{obs: &Observation{
+		Target: m.observeView.target,
+		Storages: []ObservedStorage{
+			{Ref: MonitoringStorageRef{Kind: StorageKindLogs, ResourceID: "900", Name: "app-logs"}, Variants: []string{"syslog"}},
+			{Ref: MonitoringStorageRef{Kind: StorageKindMetrics, ResourceID: "901", Name: "app-metrics"}, Variants: []string{"node"}},
+		},
+	}})
+	m = updated.(model)
+	assert.False(t, m.detailLoading)
+	assert.Contains(t, m.detailHelp(), "Enter: open storage")
+
+	// esc closes the view and returns to the server detail
+	closed, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Nil(t, closed.(model).observeView)
+	assert.NotNil(t, closed.(model).serverDetail)
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = updated.(model)
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	assert.NotNil(t, cmd)
+	assert.Equal(t, ResourceTypeMonitoringMetricsStorage, m.resourceType)
+	assert.True(t, m.detailMode)
+	assert.True(t, m.detailLoading)
+	assert.Nil(t, m.serverDetail)
+	assert.Nil(t, m.observeView)
+	// esc/backspace returns to the server detail
+	assert.Equal(t, []detailLocation{{ResourceType: ResourceTypeServer, Zone: m.cursor, ID: "113000000001"}}, m.detailStack)
+}
+
+func TestObserveAppRunWithoutWorkerNodes(t *testing.T) {
+	client, _ := NewSakuraClient("tk1b")
+	m := InitialModel(client, "tk1b")
+	m.detailMode = true
+	m.appRunPlacement = &AppRunContainerPlacement{App: AppRunApplication{Name: "web"}, Nodes: []AppRunContainerNode{{NodeID: "gone"}}}
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'O'}})
+	m = updated.(model)
+	assert.Nil(t, cmd)
+	assert.Nil(t, m.observeView)
+	assert.Contains(t, m.statusMessage, "worker node")
+}
+
+func TestRelatedLinkNavigation(t *testing.T) {
+	client, _ := NewSakuraClient("tk1b")
+	m := InitialModel(client, "tk1b")
+	m.resourceType = ResourceTypeDisk
+	m.detailMode = true
+	m.detailLoading = true
+	updated, _ := m.Update(diskDetailLoadedMsg{detail: &DiskDetail{Disk: Disk{ID: "113000000100", Name: "web-disk", ServerID: "113000000001"}}})
+	m = updated.(model)
+	assert.Contains(t, m.renderLinkedDetail(), "Server:        113000000001 (Server)")
+	assert.Contains(t, m.detailHelp(), "Enter: open related")
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	assert.NotNil(t, cmd)
+	assert.Equal(t, ResourceTypeServer, m.resourceType)
+	assert.True(t, m.detailLoading)
+	assert.Nil(t, m.diskDetail)
+	require.Len(t, m.detailStack, 1)
+	assert.Equal(t, detailLocation{ResourceType: ResourceTypeDisk, Zone: m.cursor, ID: "113000000100"}, m.detailStack[0])
+
+	updated, _ = m.Update(serverDetailLoadedMsg{detail: &ServerDetail{Server: Server{ID: "113000000001", Name: "web-1"}}})
+	m = updated.(model)
+	assert.Contains(t, m.detailHelp(), "ESC/backspace: previous detail")
+
+	// backspace returns to the disk, esc on the disk goes back to the list
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	m = updated.(model)
+	assert.NotNil(t, cmd)
+	assert.Equal(t, ResourceTypeDisk, m.resourceType)
+	assert.Empty(t, m.detailStack)
+	assert.True(t, m.detailMode)
+	updated, _ = m.Update(diskDetailLoadedMsg{detail: &DiskDetail{Disk: Disk{ID: "113000000100", ServerID: "113000000001"}}})
+	m = updated.(model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(model)
+	assert.False(t, m.detailMode)
+}
+
+func TestRelatedLinkBackRestoresZone(t *testing.T) {
+	client, _ := NewSakuraClient("tk1b")
+	m := InitialModel(client, "tk1b")
+	require.Greater(t, len(m.zones), 1)
+	m.cursor = 1
+	m.currentZone = m.zones[1]
+	m.resourceType = ResourceTypeSwitch
+	m.detailMode = true
+	m.vpcRouterDetail = &VPCRouterDetail{VPCRouter: VPCRouter{ID: "113000000200"}, NICs: []VPCRouterNIC{
+		{Index: 0},
+		{Index: 1, SwitchID: "113000000301"},
+		{Index: 2, SwitchID: "113000000302"},
+	}}
+	links := m.relatedLinks()
+	require.Len(t, links, 2)
+	assert.Equal(t, "NIC2 Switch", links[1].Label)
+
+	m.detailStack = []detailLocation{{ResourceType: ResourceTypeDisk, Zone: 0, ID: "113000000100", Cursor: 1}}
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(model)
+	assert.Equal(t, 0, m.cursor)
+	assert.Equal(t, m.zones[0], m.currentZone)
+	assert.Equal(t, m.zones[0], m.client.zone)
+	assert.Equal(t, ResourceTypeDisk, m.resourceType)
+	assert.Equal(t, 1, m.detailCursor)
+	assert.Nil(t, m.vpcRouterDetail)
+}
+
+func TestRelatedLinkOpensInItsZone(t *testing.T) {
+	client, _ := NewSakuraClient("tk1b")
+	m := InitialModel(client, "tk1b")
+	require.Greater(t, len(m.zones), 1)
+	start := m.cursor
+	other := (start + 1) % len(m.zones)
+	m.resourceType = ResourceTypeArchive
+	m.detailMode = true
+	m.archiveDetail = &ArchiveDetail{Archive: Archive{ID: "113000000400"}, SourceArchiveID: "113000000401", SourceZone: m.zones[other]}
+	assert.Contains(t, m.renderLinkedDetail(), "Source Archive: 113000000401 (Archive in "+m.zones[other]+")")
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	assert.NotNil(t, cmd)
+	assert.Equal(t, other, m.cursor)
+	assert.Equal(t, m.zones[other], m.client.zone)
+	assert.Equal(t, ResourceTypeArchive, m.resourceType)
+	require.Len(t, m.detailStack, 1)
+	assert.Equal(t, start, m.detailStack[0].Zone)
+
+	// A zone sact does not know is reported instead of opening the ID in the wrong zone
+	m.detailLoading = false
+	m.archiveDetail = &ArchiveDetail{Archive: Archive{ID: "113000000401"}, SourceArchiveID: "113000000402", SourceZone: "xx1a"}
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	assert.Nil(t, cmd)
+	assert.Equal(t, other, m.cursor)
+	assert.Contains(t, m.statusMessage, "is in zone xx1a")
+}
+
+func TestTopologyView(t *testing.T) {
+	client, _ := NewSakuraClient("tk1b")
+	m := InitialModel(client, "tk1b")
+	m.loading = false
+	m.windowWidth, m.windowHeight = 120, 40
+	assert.True(t, ResourceTypeDNS.IsGlobal())
+	assert.False(t, ResourceTypeSwitch.IsGlobal())
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'T'}})
+	m = updated.(model)
+	require.NotNil(t, m.topology)
+	assert.NotNil(t, cmd)
+	assert.True(t, m.detailMode)
+	assert.True(t, m.detailLoading)
+
+	updated, _ = m.Update(topologyLoadedMsg{topology: testTopology()})
+	m = updated.(model)
+	assert.False(t, m.detailLoading)
+	assert.Contains(t, m.detailHelp(), "v: tree/DOT/Mermaid")
+	assert.Contains(t, renderTopology(m.topology), "Format: tree")
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'v'}})
+	m = updated.(model)
+	assert.Equal(t, "dot", TopologyFormats[m.topology.format])
+	assert.Contains(t, renderTopology(m.topology), `graph "tk1b" {`)
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	m = updated.(model)
+	require.NotNil(t, m.form)
+	assert.Equal(t, "topology-tk1b.dot", m.form.fields[0].input.Value())
+	m.form = nil
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(model)
+	assert.False(t, m.detailMode)
+	assert.Nil(t, m.topology)
+}
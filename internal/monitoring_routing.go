@@ -0,0 +1,380 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/sacloud/iaas-api-go"
+	monitoringsuite "github.com/sacloud/monitoring-suite-api-go"
+	v1 "github.com/sacloud/monitoring-suite-api-go/apis/v1"
+)
+
+// RoutingSource is a resource of the current zone that can send logs or metrics
+type RoutingSource struct {
+	Kind string // server, database, loadbalancer, nfs, vpcrouter, apprun-worker or apprun-lb
+	ID   string
+	Name string
+}
+
+func (s RoutingSource) String() string {
+	return fmt.Sprintf("%s (%s %s)", s.Name, s.Kind, s.ID)
+}
+
+// routingSourcePublishers are the publisher codes tried, in order, for each kind of source
+var routingSourcePublishers = map[string][]string{
+	"server":       {"server"},
+	"database":     {"database", "appliance"},
+	"loadbalancer": {"loadbalancer", "appliance"},
+	"nfs":          {"nfs", "appliance"},
+	"vpcrouter":    {"vpcrouter", "appliance"},
+	// AppRun Dedicated nodes are servers and appliances under the hood
+	"apprun-worker": {"apprun-dedicated", "apprun", "server"},
+	"apprun-lb":     {"apprun-dedicated", "apprun", "loadbalancer", "appliance"},
+}
+
+// RoutingChoices is what the routing form picks from
+type RoutingChoices struct {
+	Zone            string
+	Publishers      []v1.Publisher
+	Sources         []RoutingSource
+	LogStorages     []MonitoringLogStorage
+	MetricsStorages []MonitoringMetricsStorage
+	LogRoutings     []MonitoringLogRouting
+	MetricsRoutings []MonitoringMetricsRouting
+}
+
+// RoutingSpec is a routing to create
+type RoutingSpec struct {
+	PublisherCode string
+	Variant       string
+	Storage       v1.PublisherVariantStorage
+	Source        *RoutingSource // nil when the routing has no source resource
+	ResourceID    string
+	StorageID     string
+	StorageName   string
+}
+
+// Describe returns "web1 (server 113...) -> app-logs (syslog)"
+func (s RoutingSpec) Describe() string {
+	source := s.PublisherCode
+	if s.Source != nil {
+		source = s.Source.String()
+	} else if s.ResourceID != "" {
+		source = s.ResourceID
+	}
+	return fmt.Sprintf("%s -> %s (%s)", source, s.StorageName, s.Variant)
+}
+
+// UnroutedSources returns the sources that no routing to a storage of the given kind
+// points at, so a server that only ships logs is still a candidate for metrics
+func (c *RoutingChoices) UnroutedSources(kind v1.PublisherVariantStorage) []RoutingSource {
+	routed := map[string]bool{}
+	if kind == v1.PublisherVariantStorageLogs {
+		for _, r := range c.LogRoutings {
+			routed[getOptNilInt64AsString(r.ResourceID)] = true
+		}
+	} else {
+		for _, r := range c.MetricsRoutings {
+			routed[getOptNilInt64AsString(r.ResourceID)] = true
+		}
+	}
+	var unrouted []RoutingSource
+	for _, s := range c.Sources {
+		if !routed[s.ID] {
+			unrouted = append(unrouted, s)
+		}
+	}
+	return unrouted
+}
+
+// firstUnroutedSource returns the first source that lacks a log or a metrics routing
+func (c *RoutingChoices) firstUnroutedSource() (RoutingSource, bool) {
+	logs := c.UnroutedSources(v1.PublisherVariantStorageLogs)
+	metrics := c.UnroutedSources(v1.PublisherVariantStorageMetrics)
+	for _, s := range c.Sources {
+		if slices.Contains(logs, s) || slices.Contains(metrics, s) {
+			return s, true
+		}
+	}
+	return RoutingSource{}, false
+}
+
+// findSource returns the source with the given ID or name
+func (c *RoutingChoices) findSource(value string) (*RoutingSource, error) {
+	var found []RoutingSource
+	for _, s := range c.Sources {
+		if s.ID == value {
+			return &s, nil
+		}
+		if s.Name == value {
+			found = append(found, s)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, nil
+	case 1:
+		return &found[0], nil
+	}
+	return nil, fmt.Errorf("%d resources are named %q, use the resource ID", len(found), value)
+}
+
+// findPublisher returns the publisher given by code, or the one guessed from the source kind
+func (c *RoutingChoices) findPublisher(code string, source *RoutingSource) (*v1.Publisher, error) {
+	codes := []string{code}
+	if code == "" {
+		if source == nil {
+			return nil, fmt.Errorf("publisher is required when no source resource is given")
+		}
+		codes = routingSourcePublishers[source.Kind]
+	}
+	for _, code := range codes {
+		for _, p := range c.Publishers {
+			if p.Code == code {
+				return &p, nil
+			}
+		}
+	}
+	if code == "" {
+		return nil, fmt.Errorf("no publisher found for %s, enter one of: %s", source.Kind, strings.Join(c.publisherCodes(), ", "))
+	}
+	return nil, fmt.Errorf("unknown publisher %q", code)
+}
+
+func (c *RoutingChoices) publisherCodes() []string {
+	codes := make([]string, len(c.Publishers))
+	for i, p := range c.Publishers {
+		codes[i] = p.Code
+	}
+	return codes
+}
+
+// findStorage returns the resource ID and name of the log or metrics storage given by ID or name.
+// An empty value selects the only storage of that kind.
+func (c *RoutingChoices) findStorage(kind v1.PublisherVariantStorage, value string) (id, name string, err error) {
+	type storage struct{ id, name string }
+	var storages []storage
+	if kind == v1.PublisherVariantStorageLogs {
+		for _, s := range c.LogStorages {
+			storages = append(storages, storage{getNilInt64AsString(s.ResourceID), getOptString(s.Name)})
+		}
+	} else {
+		for _, s := range c.MetricsStorages {
+			storages = append(storages, storage{getNilInt64AsString(s.ResourceID), getOptString(s.Name)})
+		}
+	}
+	if value == "" {
+		if len(storages) == 1 {
+			return storages[0].id, storages[0].name, nil
+		}
+		return "", "", fmt.Errorf("storage is required, there are %d %s storages", len(storages), kind)
+	}
+	for _, s := range storages {
+		if s.id == value || s.name == value {
+			return s.id, s.name, nil
+		}
+	}
+	return "", "", fmt.Errorf("unknown %s storage %q", kind, value)
+}
+
+// LoadRoutingChoices fetches the publishers, storages, routings and the routable
+// resources of the current zone
+func (c *SakuraClient) LoadRoutingChoices(ctx context.Context) (*RoutingChoices, error) {
+	slog.Info("Fetching routing choices", slog.String("zone", c.zone))
+
+	monClient, err := c.getMonitoringClient()
+	if err != nil {
+		return nil, err
+	}
+	publishers, err := monitoringsuite.NewPublisherOp(monClient).List(ctx, nil, nil)
+	if err != nil {
+		slog.Error("Failed to fetch publishers", slog.Any("error", err))
+		return nil, err
+	}
+
+	choices := &RoutingChoices{Zone: c.zone, Publishers: publishers}
+	if choices.LogStorages, err = c.ListMonitoringLogStorages(ctx); err != nil {
+		return nil, err
+	}
+	if choices.MetricsStorages, err = c.ListMonitoringMetricsStorages(ctx); err != nil {
+		return nil, err
+	}
+	if choices.LogRoutings, err = c.ListMonitoringLogRoutings(ctx); err != nil {
+		return nil, err
+	}
+	if choices.MetricsRoutings, err = c.ListMonitoringMetricsRoutings(ctx); err != nil {
+		return nil, err
+	}
+
+	servers, err := c.ListServers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range servers {
+		choices.Sources = append(choices.Sources, RoutingSource{Kind: "server", ID: s.ID, Name: s.Name})
+	}
+	dbs, err := c.ListDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range dbs {
+		choices.Sources = append(choices.Sources, RoutingSource{Kind: "database", ID: d.ID, Name: d.Name})
+	}
+	lbs, err := c.ListLoadBalancers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, lb := range lbs {
+		choices.Sources = append(choices.Sources, RoutingSource{Kind: "loadbalancer", ID: lb.ID, Name: lb.Name})
+	}
+	// ListNFS also looks up the plan and disk usage of each appliance, which the form does not need
+	nfsList, err := iaas.NewNFSOp(c.caller).Find(ctx, c.zone, &iaas.FindCondition{})
+	if err != nil {
+		slog.Error("Failed to fetch NFS appliances", slog.String("zone", c.zone), slog.Any("error", err))
+		return nil, err
+	}
+	for _, n := range nfsList.NFS {
+		choices.Sources = append(choices.Sources, RoutingSource{Kind: "nfs", ID: n.ID.String(), Name: n.Name})
+	}
+	routers, err := c.ListVPCRouters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range routers {
+		choices.Sources = append(choices.Sources, RoutingSource{Kind: "vpcrouter", ID: r.ID, Name: r.Name})
+	}
+	// AppRun is a separate API: without access to it the form still offers the IaaS resources
+	appRunSources, err := c.listAppRunRoutingSources(ctx)
+	if err != nil {
+		slog.Warn("Failed to fetch AppRun nodes for routing", slog.Any("error", err))
+	}
+	choices.Sources = append(choices.Sources, appRunSources...)
+
+	slog.Info("Successfully fetched routing choices",
+		slog.Int("publishers", len(publishers)),
+		slog.Int("sources", len(choices.Sources)))
+	return choices, nil
+}
+
+// listAppRunRoutingSources returns the worker and load balancer nodes of the AppRun
+// clusters' ASGs in the current zone, named cluster/asg[/lb]/<node ID prefix>
+func (c *SakuraClient) listAppRunRoutingSources(ctx context.Context) ([]RoutingSource, error) {
+	clusters, err := c.ListAppRunClusters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []RoutingSource
+	add := func(kind, resourceID, name, nodeID string) {
+		// Nodes still being created have no resource ID yet
+		if resourceID == "" {
+			return
+		}
+		sources = append(sources, RoutingSource{Kind: kind, ID: resourceID, Name: name + "/" + nodeID[:min(8, len(nodeID))]})
+	}
+	for _, cluster := range clusters {
+		asgs, err := c.ListAppRunASGs(ctx, cluster.ID)
+		if err != nil {
+			return sources, err
+		}
+		for _, asg := range asgs {
+			if asg.Zone != c.zone {
+				continue
+			}
+			prefix := cluster.Name + "/" + asg.Name
+			workers, err := c.ListAppRunWorkerNodes(ctx, cluster.ID, asg.ID)
+			if err != nil {
+				return sources, err
+			}
+			for _, w := range workers {
+				add("apprun-worker", w.ResourceID, prefix, w.ID)
+			}
+			lbs, err := c.ListAppRunLBs(ctx, cluster.ID, asg.ID)
+			if err != nil {
+				return sources, err
+			}
+			for _, lb := range lbs {
+				nodes, err := c.ListAppRunLBNodes(ctx, cluster.ID, asg.ID, lb.ID)
+				if err != nil {
+					return sources, err
+				}
+				for _, n := range nodes {
+					add("apprun-lb", n.ResourceID, prefix+"/"+lb.Name, n.ID)
+				}
+			}
+		}
+	}
+	return sources, nil
+}
+
+// CreateMonitoringRouting creates a log or metrics routing, depending on the variant's storage
+func (c *SakuraClient) CreateMonitoringRouting(ctx context.Context, spec RoutingSpec) error {
+	slog.Info("Creating routing",
+		slog.String("publisher", spec.PublisherCode),
+		slog.String("variant", spec.Variant),
+		slog.String("resourceID", spec.ResourceID),
+		slog.String("storageID", spec.StorageID))
+
+	monClient, err := c.getMonitoringClient()
+	if err != nil {
+		return err
+	}
+
+	var resourceID *string
+	if spec.ResourceID != "" {
+		resourceID = &spec.ResourceID
+	}
+	if spec.Storage == v1.PublisherVariantStorageLogs {
+		_, err = monitoringsuite.NewLogRoutingOp(monClient).Create(ctx, monitoringsuite.LogsRoutingCreateParams{
+			PublisherCode: spec.PublisherCode,
+			ResourceID:    resourceID,
+			Variant:       spec.Variant,
+			LogStorageID:  spec.StorageID,
+		})
+	} else {
+		_, err = monitoringsuite.NewMetricsRoutingOp(monClient).Create(ctx, monitoringsuite.MetricsRoutingCreateParams{
+			PublisherCode:    spec.PublisherCode,
+			ResourceID:       resourceID,
+			Variant:          spec.Variant,
+			MetricsStorageID: spec.StorageID,
+		})
+	}
+	if err != nil {
+		slog.Error("Failed to create routing", slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+// DeleteMonitoringLogRouting deletes a log routing
+func (c *SakuraClient) DeleteMonitoringLogRouting(ctx context.Context, r MonitoringLogRouting) error {
+	slog.Info("Deleting log routing", slog.String("uid", r.UID.String()))
+
+	monClient, err := c.getMonitoringClient()
+	if err != nil {
+		return err
+	}
+	if err := monitoringsuite.NewLogRoutingOp(monClient).Delete(ctx, r.UID); err != nil {
+		slog.Error("Failed to delete log routing", slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+// DeleteMonitoringMetricsRouting deletes a metrics routing
+func (c *SakuraClient) DeleteMonitoringMetricsRouting(ctx context.Context, r MonitoringMetricsRouting) error {
+	slog.Info("Deleting metrics routing", slog.String("uid", r.UID.String()))
+
+	monClient, err := c.getMonitoringClient()
+	if err != nil {
+		return err
+	}
+	if err := monitoringsuite.NewMetricsRoutingOp(monClient).Delete(ctx, r.UID); err != nil {
+		slog.Error("Failed to delete metrics routing", slog.Any("error", err))
+		return err
+	}
+	return nil
+}
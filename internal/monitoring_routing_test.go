@@ -0,0 +1,128 @@
+package internal
+
+import (
+	"testing"
+
+	v1 "github.com/sacloud/monitoring-suite-api-go/apis/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRoutingChoices() *RoutingChoices {
+	return &RoutingChoices{
+		Zone: "tk1b",
+		Publishers: []v1.Publisher{
+			{Code: "server", Variants: []v1.PublisherVariant{
+				{Name: "syslog", Storage: v1.PublisherVariantStorageLogs},
+				{Name: "node", Storage: v1.PublisherVariantStorageMetrics},
+			}},
+			{Code: "appliance", Variants: []v1.PublisherVariant{
+				{Name: "metrics", Storage: v1.PublisherVariantStorageMetrics},
+			}},
+		},
+		Sources: []RoutingSource{
+			{Kind: "server", ID: "113000000101", Name: "web1"},
+			{Kind: "server", ID: "113000000102", Name: "web2"},
+			{Kind: "database", ID: "113000000201", Name: "db1"},
+		},
+		LogStorages: []MonitoringLogStorage{
+			{LogStorage: v1.LogStorage{Name: v1.NewOptString("app-logs"), ResourceID: v1.NewNilInt64(113000000002)}},
+		},
+		MetricsStorages: []MonitoringMetricsStorage{
+			{MetricsStorage: v1.MetricsStorage{Name: v1.NewOptString("app-metrics"), ResourceID: v1.NewNilInt64(113000000001)}},
+			{MetricsStorage: v1.MetricsStorage{Name: v1.NewOptString("db-metrics"), ResourceID: v1.NewNilInt64(113000000003)}},
+		},
+		LogRoutings: []MonitoringLogRouting{
+			{LogRouting: v1.LogRouting{ResourceID: v1.NewOptNilInt64(113000000101), Variant: "syslog"}},
+		},
+	}
+}
+
+func TestUnroutedSources(t *testing.T) {
+	choices := testRoutingChoices()
+	assert.Equal(t, []string{"web2", "db1"}, sourceNames(choices.UnroutedSources(v1.PublisherVariantStorageLogs)))
+	// web1 only ships logs, so it is still a candidate for a metrics routing
+	assert.Equal(t, []string{"web1", "web2", "db1"}, sourceNames(choices.UnroutedSources(v1.PublisherVariantStorageMetrics)))
+
+	choices.MetricsRoutings = []MonitoringMetricsRouting{
+		{MetricsRouting: v1.MetricsRouting{ResourceID: v1.NewOptNilInt64(113000000201), Variant: "metrics"}},
+	}
+	assert.Equal(t, []string{"web2", "db1"}, sourceNames(choices.UnroutedSources(v1.PublisherVariantStorageLogs)))
+	assert.Equal(t, []string{"web1", "web2"}, sourceNames(choices.UnroutedSources(v1.PublisherVariantStorageMetrics)))
+	note := routingFormNote(choices)
+	assert.Contains(t, note, "Without logs routing in tk1b: web2 (server 113000000102), db1 (database 113000000201)")
+	assert.Contains(t, note, "Without metrics routing in tk1b: web1 (server 113000000101), web2 (server 113000000102)")
+
+	source, ok := choices.firstUnroutedSource()
+	require.True(t, ok)
+	assert.Equal(t, "web1", source.Name)
+}
+
+func sourceNames(sources []RoutingSource) []string {
+	var names []string
+	for _, s := range sources {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+func TestBuildRoutingSpec(t *testing.T) {
+	choices := testRoutingChoices()
+
+	// The publisher is guessed from the source and the only log storage is used
+	spec, err := buildRoutingSpec(map[string]string{"source": "web2", "variant": "syslog"}, choices)
+	require.NoError(t, err)
+	assert.Equal(t, "server", spec.PublisherCode)
+	assert.Equal(t, "113000000102", spec.ResourceID)
+	assert.Equal(t, v1.PublisherVariantStorageLogs, spec.Storage)
+	assert.Equal(t, "113000000002", spec.StorageID)
+	assert.Equal(t, "web2 (server 113000000102) -> app-logs (syslog)", spec.Describe())
+
+	// Databases fall back to the appliance publisher, whose only variant is picked
+	spec, err = buildRoutingSpec(map[string]string{"source": "113000000201", "storage": "db-metrics"}, choices)
+	require.NoError(t, err)
+	assert.Equal(t, "appliance", spec.PublisherCode)
+	assert.Equal(t, "metrics", spec.Variant)
+	assert.Equal(t, "113000000003", spec.StorageID)
+
+	// Resources that are not listed can be given by ID with an explicit publisher
+	spec, err = buildRoutingSpec(map[string]string{"source": "999", "publisher": "server", "variant": "node", "storage": "app-metrics"}, choices)
+	require.NoError(t, err)
+	assert.Nil(t, spec.Source)
+	assert.Equal(t, "999", spec.ResourceID)
+
+	for _, tt := range []struct {
+		values map[string]string
+		err    string
+	}{
+		{map[string]string{"source": "web9"}, `unknown resource "web9" in tk1b`},
+		{map[string]string{"source": "web2"}, "variant is required: syslog (logs), node (metrics)"},
+		{map[string]string{"source": "web2", "variant": "apache"}, `publisher server has no variant "apache"`},
+		{map[string]string{"source": "web2", "variant": "node"}, "storage is required, there are 2 metrics storages"},
+		{map[string]string{"source": "web2", "variant": "syslog", "storage": "app-metrics"}, `unknown logs storage "app-metrics"`},
+		{map[string]string{"variant": "syslog"}, "publisher is required"},
+		{map[string]string{"source": "web2", "publisher": "apprun"}, `unknown publisher "apprun"`},
+	} {
+		_, err := buildRoutingSpec(tt.values, choices)
+		require.Error(t, err, tt.values)
+		assert.Contains(t, err.Error(), tt.err)
+	}
+}
+
+func TestListAppRunRoutingSources(t *testing.T) {
+	client := newFakeAppRunClient(t, newTestFakeAppRun().withWorkerNodes())
+
+	sources, err := client.listAppRunRoutingSources(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, []RoutingSource{
+		{Kind: "apprun-worker", ID: "113000000001", Name: "prod/workers/44444444"},
+		{Kind: "apprun-worker", ID: "113000000002", Name: "prod/workers/44444444"},
+		{Kind: "apprun-lb", ID: "113000000101", Name: "prod/workers/web-lb/77777777"},
+	}, sources)
+
+	// ASGs of other zones are not routable from this zone's form
+	client.zone = "is1a"
+	sources, err = client.listAppRunRoutingSources(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, sources)
+}
@@ -0,0 +1,36 @@
+package internal
+
+import (
+	"testing"
+
+	v1 "github.com/sacloud/monitoring-suite-api-go/apis/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildMonitoringStorageSpec(t *testing.T) {
+	spec, err := buildMonitoringStorageSpec(StorageKindLogs, map[string]string{"name": " app-logs ", "description": "web", "retention": "30"})
+	require.NoError(t, err)
+	assert.Equal(t, MonitoringStorageSpec{Kind: StorageKindLogs, Name: "app-logs", Description: "web", RetentionDays: 30}, spec)
+
+	// Metrics storages have no retention setting
+	spec, err = buildMonitoringStorageSpec(StorageKindMetrics, map[string]string{"name": "app-metrics", "retention": "30"})
+	require.NoError(t, err)
+	assert.Equal(t, 0, spec.RetentionDays)
+
+	_, err = buildMonitoringStorageSpec(StorageKindLogs, map[string]string{"description": "web"})
+	assert.EqualError(t, err, "name is required")
+	_, err = buildMonitoringStorageSpec(StorageKindTraces, map[string]string{"name": "app-traces", "retention": "-1"})
+	assert.EqualError(t, err, "retention days must be a positive number of days")
+}
+
+func TestStorageRoutingWarning(t *testing.T) {
+	logs := MonitoringLogStorage{LogStorage: v1.LogStorage{Usage: v1.LogStorageUsage{LogRoutings: 2}}}
+	assert.Equal(t, "Warning: 2 log routings still use this storage and stop working once it is deleted.", storageRoutingWarning(logs))
+
+	metrics := MonitoringMetricsStorage{MetricsStorage: v1.MetricsStorage{Usage: v1.MetricsStorageUsage{MetricsRoutings: 1, AlertRules: 3}}}
+	assert.Contains(t, storageRoutingWarning(metrics), "1 metrics routings and 3 alert rules")
+
+	assert.Empty(t, storageRoutingWarning(MonitoringLogStorage{}))
+	assert.Empty(t, storageRoutingWarning(MonitoringTraceStorage{}))
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sync"
+	"time"
 
 	"github.com/sacloud/iaas-api-go"
+	"github.com/sacloud/iaas-api-go/helper/query"
 	"github.com/sacloud/iaas-api-go/search"
 	"github.com/sacloud/iaas-api-go/types"
 )
@@ -19,17 +22,70 @@ type NFS struct {
 	InstanceStatus string
 	SwitchName     string
 	CreatedAt      string
+	SizeGB         int
+	UsedPercent    float64
+	HasUsage       bool // true if UsedPercent was fetched from the free disk size monitor
 }
 
 type NFSDetail struct {
 	NFS
-	Tags           []string
-	PlanID         string
-	SwitchID       string
-	DefaultRoute   string
-	NetworkMaskLen int
-	IPAddresses    []string
-	CreatedAt      string
+	Tags            []string
+	PlanID          string
+	Plan            string // "HDD" or "SSD"
+	SwitchID        string
+	DefaultRoute    string
+	NetworkMaskLen  int
+	IPAddresses     []string
+	CreatedAt       string
+	UsedHistory     []float64 // used percent over the last 24 hours
+	ReceiveHistory  []float64 // bps over the last 24 hours
+	SendHistory     []float64 // bps over the last 24 hours
+	MonitorErrorMsg string
+}
+
+// nfsMonitorWindow is the time range used for NFS activity charts
+const nfsMonitorWindow = 24 * time.Hour
+
+// nfsUsedPercent converts a free disk size in KiB to a used percentage of sizeGB
+func nfsUsedPercent(freeKiB float64, sizeGB int) float64 {
+	if sizeGB <= 0 {
+		return 0
+	}
+	total := float64(sizeGB) * 1024 * 1024
+	used := (total - freeKiB) / total * 100
+	if used < 0 {
+		return 0
+	}
+	return used
+}
+
+// nfsPlanName returns the disk plan name for the disk plan ID of query.NFSPlanInfo,
+// which uses types.NFSPlans rather than types.DiskPlans
+func nfsPlanName(diskPlanID types.ID) string {
+	switch diskPlanID {
+	case types.NFSPlans.SSD:
+		return "SSD"
+	case types.NFSPlans.HDD:
+		return "HDD"
+	default:
+		return diskPlanID.String()
+	}
+}
+
+// nfsPlanNote remembers the plans note so that the plan of every NFS in a list is resolved
+// with a single note lookup. query.GetNFSPlanInfo always searches the same note.
+type nfsPlanNote struct {
+	finder query.NoteFinder
+	once   sync.Once
+	result *iaas.NoteFindResult
+	err    error
+}
+
+func (n *nfsPlanNote) Find(ctx context.Context, conditions *iaas.FindCondition) (*iaas.NoteFindResult, error) {
+	n.once.Do(func() {
+		n.result, n.err = n.finder.Find(ctx, conditions)
+	})
+	return n.result, n.err
 }
 
 // Implement list.Item interface for NFS
@@ -72,6 +128,8 @@ func (c *SakuraClient) ListNFS(ctx context.Context) ([]NFS, error) {
 		return nil, err
 	}
 
+	plans := &nfsPlanNote{finder: iaas.NewNoteOp(c.caller)}
+
 	nfsList := make([]NFS, 0, len(searched.NFS))
 	for _, nfs := range searched.NFS {
 		// Format created at
@@ -80,7 +138,7 @@ func (c *SakuraClient) ListNFS(ctx context.Context) ([]NFS, error) {
 			createdAt = nfs.CreatedAt.Format("2006-01-02")
 		}
 
-		nfsList = append(nfsList, NFS{
+		item := NFS{
 			ID:             nfs.ID.String(),
 			Name:           nfs.Name,
 			Desc:           nfs.Description,
@@ -88,9 +146,41 @@ func (c *SakuraClient) ListNFS(ctx context.Context) ([]NFS, error) {
 			InstanceStatus: string(nfs.InstanceStatus),
 			SwitchName:     nfs.SwitchName,
 			CreatedAt:      createdAt,
-		})
+		}
+
+		if planInfo, err := query.GetNFSPlanInfo(ctx, plans, nfs.PlanID); err != nil {
+			slog.Warn("Failed to fetch NFS plan info",
+				slog.String("nfsID", nfs.ID.String()),
+				slog.Any("error", err))
+		} else {
+			item.SizeGB = planInfo.Size.Int()
+		}
+
+		nfsList = append(nfsList, item)
 	}
 
+	// Fetch the latest free disk size only for running appliances
+	now := time.Now()
+	forEachConcurrently(len(nfsList), func(i int) {
+		item := &nfsList[i]
+		if item.SizeGB <= 0 || !searched.NFS[i].InstanceStatus.IsUp() {
+			return
+		}
+		activity, err := nfsOp.MonitorFreeDiskSize(ctx, c.zone, searched.NFS[i].ID, &iaas.MonitorCondition{
+			Start: now.Add(-1 * time.Hour),
+			End:   now,
+		})
+		if err != nil {
+			slog.Warn("Failed to fetch NFS free disk size",
+				slog.String("nfsID", item.ID),
+				slog.Any("error", err))
+		} else if len(activity.Values) > 0 {
+			latest := activity.Values[len(activity.Values)-1]
+			item.UsedPercent = nfsUsedPercent(latest.FreeDiskSize, item.SizeGB)
+			item.HasUsage = true
+		}
+	})
+
 	slog.Info("Successfully fetched NFS appliances",
 		slog.String("zone", c.zone),
 		slog.Int("count", len(nfsList)))
@@ -150,9 +240,98 @@ func (c *SakuraClient) GetNFSDetail(ctx context.Context, nfsID string) (*NFSDeta
 		CreatedAt:      createdAt,
 	}
 
+	planInfo, err := query.GetNFSPlanInfo(ctx, iaas.NewNoteOp(c.caller), nfs.PlanID)
+	if err != nil {
+		slog.Warn("Failed to fetch NFS plan info",
+			slog.String("nfsID", nfsID),
+			slog.Any("error", err))
+	} else {
+		detail.SizeGB = planInfo.Size.Int()
+		detail.Plan = nfsPlanName(planInfo.DiskPlanID)
+	}
+
+	// Fetch activity charts; failures are shown in the detail view instead of failing the whole view
+	now := time.Now()
+	condition := &iaas.MonitorCondition{Start: now.Add(-nfsMonitorWindow), End: now}
+
+	if detail.SizeGB > 0 {
+		freeDisk, err := nfsOp.MonitorFreeDiskSize(ctx, c.zone, id, condition)
+		if err != nil {
+			slog.Warn("Failed to fetch NFS free disk size",
+				slog.String("nfsID", nfsID),
+				slog.Any("error", err))
+			detail.MonitorErrorMsg = err.Error()
+		} else {
+			for _, v := range freeDisk.Values {
+				detail.UsedHistory = append(detail.UsedHistory, nfsUsedPercent(v.FreeDiskSize, detail.SizeGB))
+			}
+			if len(detail.UsedHistory) > 0 {
+				detail.UsedPercent = detail.UsedHistory[len(detail.UsedHistory)-1]
+				detail.HasUsage = true
+			}
+		}
+	}
+
+	iface, err := nfsOp.MonitorInterface(ctx, c.zone, id, condition)
+	if err != nil {
+		slog.Warn("Failed to fetch NFS interface activity",
+			slog.String("nfsID", nfsID),
+			slog.Any("error", err))
+		detail.MonitorErrorMsg = err.Error()
+	} else {
+		for _, v := range iface.Values {
+			detail.ReceiveHistory = append(detail.ReceiveHistory, v.Receive)
+			detail.SendHistory = append(detail.SendHistory, v.Send)
+		}
+	}
+
 	slog.Info("Successfully fetched NFS detail",
 		slog.String("zone", c.zone),
 		slog.String("nfsID", nfsID))
 
 	return detail, nil
 }
+
+// BootNFS powers on a NFS appliance
+func (c *SakuraClient) BootNFS(ctx context.Context, nfsID string) error {
+	if c.zone == "" {
+		slog.Error("Zone is not set in client")
+		return fmt.Errorf("zone is not set")
+	}
+
+	slog.Info("Booting NFS appliance",
+		slog.String("zone", c.zone),
+		slog.String("nfsID", nfsID))
+
+	nfsOp := iaas.NewNFSOp(c.caller)
+	if err := nfsOp.Boot(ctx, c.zone, types.StringID(nfsID)); err != nil {
+		slog.Error("Failed to boot NFS appliance",
+			slog.String("zone", c.zone),
+			slog.String("nfsID", nfsID),
+			slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+// ShutdownNFS gracefully shuts down a NFS appliance
+func (c *SakuraClient) ShutdownNFS(ctx context.Context, nfsID string) error {
+	if c.zone == "" {
+		slog.Error("Zone is not set in client")
+		return fmt.Errorf("zone is not set")
+	}
+
+	slog.Info("Shutting down NFS appliance",
+		slog.String("zone", c.zone),
+		slog.String("nfsID", nfsID))
+
+	nfsOp := iaas.NewNFSOp(c.caller)
+	if err := nfsOp.Shutdown(ctx, c.zone, types.StringID(nfsID), &iaas.ShutdownOption{Force: false}); err != nil {
+		slog.Error("Failed to shut down NFS appliance",
+			slog.String("zone", c.zone),
+			slog.String("nfsID", nfsID),
+			slog.Any("error", err))
+		return err
+	}
+	return nil
+}
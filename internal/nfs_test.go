@@ -0,0 +1,38 @@
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sacloud/iaas-api-go"
+	"github.com/sacloud/iaas-api-go/helper/query"
+	"github.com/sacloud/iaas-api-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingNoteFinder struct {
+	calls int
+}
+
+func (f *countingNoteFinder) Find(ctx context.Context, conditions *iaas.FindCondition) (*iaas.NoteFindResult, error) {
+	f.calls++
+	return &iaas.NoteFindResult{Total: 1, Count: 1, Notes: []*iaas.Note{{
+		Name:    "sys-nfs",
+		Content: `{"plans":{"HDD":[{"size":100,"availability":"available","planId":1001}],"SSD":[{"size":20,"availability":"available","planId":2001}]}}`,
+	}}}, nil
+}
+
+func TestNFSPlanNote(t *testing.T) {
+	finder := &countingNoteFinder{}
+	plans := &nfsPlanNote{finder: finder}
+
+	hdd, err := query.GetNFSPlanInfo(t.Context(), plans, types.ID(1001))
+	require.NoError(t, err)
+	assert.Equal(t, 100, hdd.Size.Int())
+	ssd, err := query.GetNFSPlanInfo(t.Context(), plans, types.ID(2001))
+	require.NoError(t, err)
+	assert.Equal(t, "SSD", nfsPlanName(ssd.DiskPlanID))
+
+	assert.Equal(t, 1, finder.calls)
+}
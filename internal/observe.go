@@ -0,0 +1,279 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// observeResourceLabel is the label that carries the resource ID of data sent through a routing
+const observeResourceLabel = "resource_id"
+
+// observeLogLimit is the number of latest log entries shown in the observe view
+const observeLogLimit = 100
+
+// observeSeriesLimit caps the series shown for each metrics expression of the observe view
+const observeSeriesLimit = 4
+
+// defaultObserveQueries are the metrics expressions of the observe view unless [observe] in the
+// config sets them for the kind of resource. $resource is replaced with the matcher of the
+// resource ID label, e.g. resource_id="123", and $label with the name of that label.
+var defaultObserveQueries = []SavedQuery{
+	{Name: "CPU busy (cores)", Query: `sum by ($label) (rate(node_cpu_seconds_total{mode!="idle", $resource}[5m]))`},
+	{Name: "Memory used (bytes)", Query: `sum by ($label) (node_memory_MemTotal_bytes{$resource} - node_memory_MemAvailable_bytes{$resource})`},
+	{Name: "Disk I/O (bytes/s)", Query: `sum by ($label) (rate(node_disk_read_bytes_total{$resource}[5m]) + rate(node_disk_written_bytes_total{$resource}[5m]))`},
+	{Name: "Network (bytes/s)", Query: `sum by ($label) (rate(node_network_receive_bytes_total{$resource}[5m]) + rate(node_network_transmit_bytes_total{$resource}[5m]))`},
+}
+
+// observeWindow is how far back the observe view looks
+var observeWindow = MetricsQueryWindow{Label: "1h", Duration: time.Hour, Step: time.Minute}
+
+// ObserveTarget is a resource whose logs and metrics are looked up through its routings
+type ObserveTarget struct {
+	Kind string // Server, Database or AppRun
+	Name string
+	// ResourceIDs are matched against the ResourceID of the routings. An AppRun application
+	// is observed through the worker nodes its containers run on.
+	ResourceIDs []string
+}
+
+// Title returns e.g. "Server: web-1"
+func (t ObserveTarget) Title() string {
+	return fmt.Sprintf("%s: %s", t.Kind, t.Name)
+}
+
+// ObservedStorage is a storage that receives data of the observed resource
+type ObservedStorage struct {
+	Ref      MonitoringStorageRef
+	Variants []string // variants of the routings into the storage
+}
+
+// ObservedMetric is the result of one metrics expression of the observe view
+type ObservedMetric struct {
+	Name   string
+	Series []PromSeries
+	// Total is the number of series before they were capped to observeSeriesLimit
+	Total int
+	Err   error
+}
+
+// Observation holds the recent logs and metrics of a resource
+type Observation struct {
+	Target     ObserveTarget
+	Storages   []ObservedStorage
+	Logs       []LogEntry // oldest first
+	LogsErr    error
+	Metrics    []ObservedMetric // in the order of the expressions
+	MetricsErr error            // set when a metrics storage could not be queried at all
+}
+
+// observeSource is a storage resolved to its query endpoint and credentials
+type observeSource struct {
+	ref      MonitoringStorageRef
+	endpoint string
+	token    string
+	label    string
+	err      error // set when the access key could not be fetched
+}
+
+// observedStorages returns the storages that routings send data of the target to, logs first
+func observedStorages(target ObserveTarget, logRoutings []MonitoringLogRouting, metricsRoutings []MonitoringMetricsRouting) []ObservedStorage {
+	var storages []ObservedStorage
+	add := func(ref MonitoringStorageRef, variant string) {
+		for i := range storages {
+			if storages[i].Ref.Kind == ref.Kind && storages[i].Ref.ResourceID == ref.ResourceID {
+				if !slices.Contains(storages[i].Variants, variant) {
+					storages[i].Variants = append(storages[i].Variants, variant)
+				}
+				return
+			}
+		}
+		storages = append(storages, ObservedStorage{Ref: ref, Variants: []string{variant}})
+	}
+	for _, r := range logRoutings {
+		if !slices.Contains(target.ResourceIDs, getOptNilInt64AsString(r.ResourceID)) {
+			continue
+		}
+		ref := MonitoringLogStorage{LogStorage: r.LogStorage}.StorageRef()
+		if ref.ResourceID == "" || ref.ResourceID == "0" {
+			ref.ResourceID = getOptNilInt64AsString(r.LogStorageID)
+		}
+		add(ref, r.Variant)
+	}
+	for _, r := range metricsRoutings {
+		if !slices.Contains(target.ResourceIDs, getOptNilInt64AsString(r.ResourceID)) {
+			continue
+		}
+		ref := MonitoringMetricsStorage{MetricsStorage: r.MetricsStorage}.StorageRef()
+		if ref.ResourceID == "" || ref.ResourceID == "0" {
+			ref.ResourceID = getOptNilInt64AsString(r.MetricsStorageID)
+		}
+		add(ref, r.Variant)
+	}
+	return storages
+}
+
+// observeMatcher returns the label matcher of the given resources, e.g. resource_id="123"
+func observeMatcher(label string, resourceIDs []string) string {
+	if label == "" {
+		label = observeResourceLabel
+	}
+	if len(resourceIDs) == 1 {
+		return fmt.Sprintf("%s=%s", label, strconv.Quote(resourceIDs[0]))
+	}
+	return fmt.Sprintf("%s=~%s", label, strconv.Quote(strings.Join(resourceIDs, "|")))
+}
+
+// observeSelector returns the selector matching data of the given resources, e.g. {resource_id="123"}.
+// The same syntax is a LogQL stream selector and a PromQL vector selector.
+func observeSelector(label string, resourceIDs []string) string {
+	return "{" + observeMatcher(label, resourceIDs) + "}"
+}
+
+// observeQuery fills the $resource and $label placeholders of a metrics expression
+func observeQuery(query, label string, resourceIDs []string) string {
+	matcher := observeMatcher(label, resourceIDs)
+	if label == "" {
+		label = observeResourceLabel
+	}
+	return strings.NewReplacer("$resource", matcher, "$label", label).Replace(query)
+}
+
+// collectObservation queries the recent logs and the metrics expressions of the target from the sources.
+// A failing storage or expression does not stop the others; the first error of each is kept.
+func collectObservation(ctx context.Context, obs *Observation, sources []observeSource, queries []SavedQuery, now time.Time) {
+	obs.Metrics = make([]ObservedMetric, len(queries))
+	for i, q := range queries {
+		obs.Metrics[i].Name = q.Name
+	}
+	for _, src := range sources {
+		switch src.ref.Kind {
+		case StorageKindLogs:
+			entries, err := src.queryLogs(ctx, obs.Target, now)
+			if err != nil {
+				if obs.LogsErr == nil {
+					obs.LogsErr = fmt.Errorf("%s: %w", src.ref.Title(), err)
+				}
+				continue
+			}
+			obs.Logs = append(obs.Logs, entries...)
+		case StorageKindMetrics:
+			if src.err != nil {
+				if obs.MetricsErr == nil {
+					obs.MetricsErr = fmt.Errorf("%s: %w", src.ref.Title(), src.err)
+				}
+				continue
+			}
+			for i, q := range queries {
+				series, err := src.queryMetrics(ctx, obs.Target, q.Query, now)
+				if err != nil {
+					if obs.Metrics[i].Err == nil {
+						obs.Metrics[i].Err = fmt.Errorf("%s: %w", src.ref.Title(), err)
+					}
+					continue
+				}
+				obs.Metrics[i].Series = append(obs.Metrics[i].Series, series...)
+			}
+		}
+	}
+
+	slices.SortStableFunc(obs.Logs, func(a, b LogEntry) int {
+		return a.Time.Compare(b.Time)
+	})
+	if len(obs.Logs) > observeLogLimit {
+		obs.Logs = obs.Logs[len(obs.Logs)-observeLogLimit:]
+	}
+	for i := range obs.Metrics {
+		m := &obs.Metrics[i]
+		slices.SortStableFunc(m.Series, func(a, b PromSeries) int {
+			return strings.Compare(formatPromLabels(a.Labels), formatPromLabels(b.Labels))
+		})
+		m.Total = len(m.Series)
+		if len(m.Series) > observeSeriesLimit {
+			m.Series = m.Series[:observeSeriesLimit]
+		}
+	}
+}
+
+func (s observeSource) queryLogs(ctx context.Context, target ObserveTarget, now time.Time) ([]LogEntry, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	client := NewLogQueryClient(s.endpoint, s.token)
+	return client.Query(ctx, observeSelector(s.label, target.ResourceIDs), now.Add(-observeWindow.Duration), now, observeLogLimit)
+}
+
+func (s observeSource) queryMetrics(ctx context.Context, target ObserveTarget, query string, now time.Time) ([]PromSeries, error) {
+	result, err := NewPrometheusClient(s.endpoint, s.token).Query(ctx, observeQuery(query, s.label, target.ResourceIDs), observeWindow, now)
+	if err != nil {
+		return nil, err
+	}
+	return result.Series, nil
+}
+
+// ObserveResource finds the Monitoring Suite routings of the target and queries the recent
+// logs and metrics of the storages they send to
+func (c *SakuraClient) ObserveResource(ctx context.Context, target ObserveTarget, cfg *Config) (*Observation, error) {
+	slog.Info("Observing resource", slog.String("kind", target.Kind), slog.Any("resourceIDs", target.ResourceIDs))
+
+	logRoutings, err := c.ListMonitoringLogRoutings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	metricsRoutings, err := c.ListMonitoringMetricsRoutings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	obs := &Observation{Target: target, Storages: observedStorages(target, logRoutings, metricsRoutings)}
+	var sources []observeSource
+	for _, s := range obs.Storages {
+		src := observeSource{ref: s.Ref}
+		switch s.Ref.Kind {
+		case StorageKindLogs:
+			lc := cfg.LogStorage(s.Ref.ResourceID, s.Ref.Name)
+			src.endpoint, src.token, src.label = lc.URL, lc.Token, lc.ResourceLabel
+			if src.endpoint == "" {
+				src.endpoint = s.Ref.Address
+			}
+		case StorageKindMetrics:
+			mc := cfg.MetricsStorage(s.Ref.ResourceID, s.Ref.Name)
+			src.endpoint, src.token, src.label = mc.URL, mc.Token, mc.ResourceLabel
+			if src.endpoint == "" {
+				src.endpoint = s.Ref.Address + "/prometheus"
+			}
+		}
+		if src.token == "" {
+			src.token, src.err = c.GetMonitoringStorageToken(ctx, s.Ref)
+		}
+		sources = append(sources, src)
+	}
+	collectObservation(ctx, obs, sources, cfg.ObserveQueries(target.Kind), time.Now())
+	return obs, nil
+}
+
+// ServerObserveTarget returns the observe target of a server
+func ServerObserveTarget(s *ServerDetail) ObserveTarget {
+	return ObserveTarget{Kind: "Server", Name: s.Name, ResourceIDs: []string{s.ID}}
+}
+
+// DBObserveTarget returns the observe target of a database appliance
+func DBObserveTarget(db *DBDetail) ObserveTarget {
+	return ObserveTarget{Kind: "Database", Name: db.Name, ResourceIDs: []string{db.ID}}
+}
+
+// ObserveTarget returns the worker nodes the containers of the application run on as the
+// observe target; AppRun routings are registered per worker node
+func (p *AppRunContainerPlacement) ObserveTarget() ObserveTarget {
+	target := ObserveTarget{Kind: "AppRun", Name: p.App.Name}
+	for _, node := range p.Nodes {
+		if node.WorkerNode != nil && node.WorkerNode.ResourceID != "" && !slices.Contains(target.ResourceIDs, node.WorkerNode.ResourceID) {
+			target.ResourceIDs = append(target.ResourceIDs, node.WorkerNode.ResourceID)
+		}
+	}
+	return target
+}
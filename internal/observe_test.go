@@ -0,0 +1,106 @@
+package internal
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	v1 "github.com/sacloud/monitoring-suite-api-go/apis/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObservedStorages(t *testing.T) {
+	logs := v1.LogStorage{Name: v1.NewOptString("app-logs"), ResourceID: v1.NewNilInt64(900)}
+	logRoutings := []MonitoringLogRouting{
+		{LogRouting: v1.LogRouting{ResourceID: v1.NewOptNilInt64(111), Variant: "syslog", LogStorage: logs}},
+		{LogRouting: v1.LogRouting{ResourceID: v1.NewOptNilInt64(222), Variant: "syslog", LogStorage: logs}},
+		// The second routing of the same storage only adds its variant
+		{LogRouting: v1.LogRouting{ResourceID: v1.NewOptNilInt64(222), Variant: "audit", LogStorage: logs}},
+		{LogRouting: v1.LogRouting{ResourceID: v1.NewOptNilInt64(333), Variant: "syslog", LogStorage: logs}},
+	}
+	metricsRoutings := []MonitoringMetricsRouting{
+		{MetricsRouting: v1.MetricsRouting{ResourceID: v1.NewOptNilInt64(222), Variant: "node",
+			MetricsStorageID: v1.NewOptNilInt64(901), MetricsStorage: v1.MetricsStorage{Name: v1.NewOptString("app-metrics")}}},
+	}
+
+	target := ObserveTarget{Kind: "AppRun", Name: "web", ResourceIDs: []string{"111", "222"}}
+	storages := observedStorages(target, logRoutings, metricsRoutings)
+	require.Len(t, storages, 2)
+	assert.Equal(t, "Log Storage: app-logs", storages[0].Ref.Title())
+	assert.Equal(t, []string{"syslog", "audit"}, storages[0].Variants)
+	// The storage ID of the routing is used when the embedded storage has none
+	assert.Equal(t, StorageKindMetrics, storages[1].Ref.Kind)
+	assert.Equal(t, "901", storages[1].Ref.ResourceID)
+
+	assert.Empty(t, observedStorages(ObserveTarget{ResourceIDs: []string{"444"}}, logRoutings, metricsRoutings))
+}
+
+func TestObserveSelector(t *testing.T) {
+	assert.Equal(t, `{resource_id="111"}`, observeSelector("", []string{"111"}))
+	assert.Equal(t, `{host_id=~"111|222"}`, observeSelector("host_id", []string{"111", "222"}))
+}
+
+func TestObserveQuery(t *testing.T) {
+	assert.Equal(t, `sum by (resource_id) (rate(node_cpu_seconds_total{mode!="idle", resource_id="111"}[5m]))`,
+		observeQuery(defaultObserveQueries[0].Query, "", []string{"111"}))
+	assert.Equal(t, `sum by (host_id) (up{host_id=~"111|222"})`, observeQuery("sum by ($label) (up{$resource})", "host_id", []string{"111", "222"}))
+
+	cfg := &Config{Observe: map[string][]SavedQuery{"database": {{Name: "Connections", Query: "pg_stat_activity_count{$resource}"}}}}
+	assert.Equal(t, "Connections", cfg.ObserveQueries("Database")[0].Name)
+	assert.Equal(t, defaultObserveQueries, cfg.ObserveQueries("Server"))
+}
+
+func TestCollectObservation(t *testing.T) {
+	loki, fake := newFakeLoki(t)
+	prometheus, params := newFakePrometheus(t)
+	now := time.Now()
+	fake.add(now.Add(-2*time.Minute), "info", "started")
+	fake.add(now.Add(-time.Minute), "error", "connection refused")
+
+	obs := &Observation{
+		Target:   ObserveTarget{Kind: "Server", Name: "web-1", ResourceIDs: []string{"111"}},
+		Storages: []ObservedStorage{{Ref: MonitoringStorageRef{Kind: StorageKindLogs, Name: "app-logs"}, Variants: []string{"syslog"}}},
+	}
+	queries := []SavedQuery{{Name: "Broken", Query: "bad("}, {Name: "Up", Query: "up{$resource}"}}
+	collectObservation(t.Context(), obs, []observeSource{
+		{ref: MonitoringStorageRef{Kind: StorageKindLogs, Name: "app-logs"}, endpoint: loki.URL, token: "t0ken"},
+		{ref: MonitoringStorageRef{Kind: StorageKindLogs, Name: "audit-logs"}, err: errors.New("log storage 902 has no access key")},
+		{ref: MonitoringStorageRef{Kind: StorageKindMetrics, Name: "app-metrics"}, endpoint: prometheus.URL + "/prometheus", token: "t0ken", label: "host_id"},
+	}, queries, now)
+
+	require.Len(t, obs.Logs, 2)
+	assert.Equal(t, "connection refused", obs.Logs[1].Line)
+	assert.Equal(t, []string{`{resource_id="111"}`}, fake.queries)
+	assert.EqualError(t, obs.LogsErr, "Log Storage: audit-logs: log storage 902 has no access key")
+
+	require.NoError(t, obs.MetricsErr)
+	assert.Equal(t, `up{host_id="111"}`, params.Get("query"))
+	assert.Equal(t, "60", params.Get("step"))
+	require.Len(t, obs.Metrics, 2)
+	// A failing expression does not hide the others
+	assert.ErrorContains(t, obs.Metrics[0].Err, "Metrics Storage: app-metrics: ")
+	require.NoError(t, obs.Metrics[1].Err)
+	require.Len(t, obs.Metrics[1].Series, 1)
+	assert.Equal(t, 1, obs.Metrics[1].Total)
+	assert.Equal(t, `{instance="web1"}`, formatPromLabels(obs.Metrics[1].Series[0].Labels))
+
+	view := renderObservation(&observeView{target: obs.Target, obs: obs}, 0, 160)
+	assert.Contains(t, view, "Observe Server: web-1")
+	assert.Contains(t, view, "Logs: 2 entries")
+	assert.Contains(t, view, "Metrics: 2 expressions")
+	assert.Contains(t, view, "bad_data")
+}
+
+func TestAppRunObserveTarget(t *testing.T) {
+	node := &AppRunWorkerNode{ID: "n1", ResourceID: "113000000010"}
+	p := &AppRunContainerPlacement{
+		App: AppRunApplication{Name: "web"},
+		Nodes: []AppRunContainerNode{
+			{NodeID: "n1", WorkerNode: node},
+			{NodeID: "n1-again", WorkerNode: node},
+			{NodeID: "gone"},
+		},
+	}
+	assert.Equal(t, ObserveTarget{Kind: "AppRun", Name: "web", ResourceIDs: []string{"113000000010"}}, p.ObserveTarget())
+}
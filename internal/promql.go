@@ -0,0 +1,227 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// promDuration matches PromQL durations such as 5m or 1h30m
+var promDuration = regexp.MustCompile(`^([0-9]+(ms|s|m|h|d|w|y))+$`)
+
+// promMatcherOps are the label matching operators allowed inside {}
+var promMatcherOps = []string{"=~", "!~", "!=", "="}
+
+// promBinaryOps are the operators that need an operand on both sides
+var promBinaryOps = []string{"==", "!=", ">=", "<=", ">", "<", "+", "-", "*", "/", "%", "^", "@"}
+
+// promToken is a lexical token of a PromQL expression
+type promToken struct {
+	kind  string // ident, number, string, op, ( ) { } [ ] or ,
+	value string
+	pos   int // 1-based column
+}
+
+// validatePromQL checks the syntax of a PromQL expression: balanced brackets, closed
+// strings, well formed label matchers and range durations, and operators with operands.
+// It does not know function names or types, which are left to the server.
+func validatePromQL(query string) error {
+	tokens, err := lexPromQL(query)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return fmt.Errorf("query is required")
+	}
+
+	var stack []promToken
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		var prev *promToken
+		if i > 0 {
+			prev = &tokens[i-1]
+		}
+		switch t.kind {
+		case "(", "[":
+			if t.kind == "[" && (prev == nil || !endsOperand(*prev)) {
+				return fmt.Errorf("col %d: range must follow a selector", t.pos)
+			}
+			stack = append(stack, t)
+			if t.kind == "[" {
+				end, err := checkPromRange(tokens, i)
+				if err != nil {
+					return err
+				}
+				i = end - 1
+			}
+		case "{":
+			end, err := checkPromMatchers(tokens, i)
+			if err != nil {
+				return err
+			}
+			i = end
+		case ")", "]", "}":
+			open := map[string]string{")": "(", "]": "[", "}": "{"}[t.kind]
+			if len(stack) == 0 || stack[len(stack)-1].kind != open {
+				return fmt.Errorf("col %d: unexpected %q", t.pos, t.kind)
+			}
+			if t.kind == ")" && prev != nil && (prev.kind == "op" || prev.kind == ",") {
+				return fmt.Errorf("col %d: missing operand before %q", t.pos, t.kind)
+			}
+			if t.kind == ")" && prev != nil && prev.kind == "(" && !(i >= 2 && tokens[i-2].kind == "ident") {
+				return fmt.Errorf("col %d: empty parentheses", prev.pos)
+			}
+			stack = stack[:len(stack)-1]
+		case "op":
+			if t.value == "=" || t.value == "=~" || t.value == "!~" {
+				return fmt.Errorf("col %d: %q is only valid in label matchers, use == to compare", t.pos, t.value)
+			}
+			unary := t.value == "-" || t.value == "+"
+			if !unary && (prev == nil || !endsOperand(*prev)) {
+				return fmt.Errorf("col %d: missing operand before %q", t.pos, t.value)
+			}
+		}
+	}
+	if len(stack) > 0 {
+		open := stack[len(stack)-1]
+		return fmt.Errorf("col %d: unclosed %q", open.pos, open.kind)
+	}
+	if last := tokens[len(tokens)-1]; last.kind == "op" || last.kind == "," {
+		return fmt.Errorf("col %d: missing operand after %q", last.pos, last.value)
+	}
+	return nil
+}
+
+// endsOperand reports whether an operator may follow the token
+func endsOperand(t promToken) bool {
+	switch t.kind {
+	case "ident", "number", "string", ")", "]", "}":
+		return true
+	}
+	return false
+}
+
+// checkPromMatchers checks the label matchers starting at tokens[start] ("{") and
+// returns the index of the closing "}"
+func checkPromMatchers(tokens []promToken, start int) (int, error) {
+	i := start + 1
+	for i < len(tokens) && tokens[i].kind != "}" {
+		name := tokens[i]
+		if name.kind != "ident" && name.kind != "string" {
+			return 0, fmt.Errorf("col %d: expected a label name, got %q", name.pos, name.value)
+		}
+		// {"metric"} selects a metric by its quoted name
+		if name.kind == "string" && i+1 < len(tokens) && (tokens[i+1].kind == "," || tokens[i+1].kind == "}") {
+			i++
+		} else {
+			if i+2 >= len(tokens) || tokens[i+1].kind != "op" || !slices.Contains(promMatcherOps, tokens[i+1].value) {
+				return 0, fmt.Errorf("col %d: expected =, !=, =~ or !~ after label %s", name.pos, name.value)
+			}
+			op, value := tokens[i+1], tokens[i+2]
+			if value.kind != "string" {
+				return 0, fmt.Errorf("col %d: label value must be a quoted string", value.pos)
+			}
+			if op.value == "=~" || op.value == "!~" {
+				if _, err := regexp.Compile("^(?:" + value.value + ")$"); err != nil {
+					return 0, fmt.Errorf("col %d: invalid regular expression: %v", value.pos, err)
+				}
+			}
+			i += 3
+		}
+		if i < len(tokens) && tokens[i].kind == "," {
+			i++
+		} else if i < len(tokens) && tokens[i].kind != "}" {
+			return 0, fmt.Errorf("col %d: expected , or } after label matcher", tokens[i].pos)
+		}
+	}
+	if i >= len(tokens) {
+		return 0, fmt.Errorf("col %d: unclosed \"{\"", tokens[start].pos)
+	}
+	return i, nil
+}
+
+// checkPromRange checks a [5m] range or [5m:1m] subquery starting at tokens[start] ("[")
+// and returns the index of the closing "]"
+func checkPromRange(tokens []promToken, start int) (int, error) {
+	var parts []string
+	i := start + 1
+	for ; i < len(tokens) && tokens[i].kind != "]"; i++ {
+		parts = append(parts, tokens[i].value)
+	}
+	if i >= len(tokens) {
+		return 0, fmt.Errorf("col %d: unclosed \"[\"", tokens[start].pos)
+	}
+	rangeExpr := strings.Join(parts, "")
+	d, step, subquery := strings.Cut(rangeExpr, ":")
+	if !promDuration.MatchString(d) || (subquery && step != "" && !promDuration.MatchString(step)) {
+		return 0, fmt.Errorf("col %d: invalid range [%s], use a duration such as [5m]", tokens[start].pos, rangeExpr)
+	}
+	return i, nil
+}
+
+// lexPromQL splits a PromQL expression into tokens
+func lexPromQL(query string) ([]promToken, error) {
+	runes := []rune(query)
+	var tokens []promToken
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '#':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '"' || r == '\'' || r == '`':
+			start := i
+			var b strings.Builder
+			for i++; i < len(runes) && runes[i] != r; i++ {
+				if runes[i] == '\\' && r != '`' && i+1 < len(runes) {
+					i++
+				}
+				b.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("col %d: unterminated string", start+1)
+			}
+			i++
+			tokens = append(tokens, promToken{kind: "string", value: b.String(), pos: start + 1})
+		case strings.ContainsRune("(){}[],:", r):
+			kind := string(r)
+			if r == ':' {
+				// Only valid inside a subquery range, checked there
+				kind = "op"
+			}
+			tokens = append(tokens, promToken{kind: kind, value: string(r), pos: i + 1})
+			i++
+		case unicode.IsDigit(r) || r == '.':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '.' ||
+				((runes[i] == '+' || runes[i] == '-') && (runes[i-1] == 'e' || runes[i-1] == 'E'))) {
+				i++
+			}
+			tokens = append(tokens, promToken{kind: "number", value: string(runes[start:i]), pos: start + 1})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == ':') {
+				i++
+			}
+			tokens = append(tokens, promToken{kind: "ident", value: string(runes[start:i]), pos: start + 1})
+		default:
+			op := ""
+			for _, candidate := range slices.Concat(promMatcherOps, promBinaryOps) {
+				if strings.HasPrefix(string(runes[i:]), candidate) && len(candidate) > len(op) {
+					op = candidate
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("col %d: unexpected character %q", i+1, r)
+			}
+			tokens = append(tokens, promToken{kind: "op", value: op, pos: i + 1})
+			i += len([]rune(op))
+		}
+	}
+	return tokens, nil
+}
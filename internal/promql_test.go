@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePromQL(t *testing.T) {
+	for _, query := range []string{
+		`up`,
+		`up == 0`,
+		`sum by (instance) (rate(node_cpu_seconds_total{mode!="idle", cpu=~"0|1"}[5m])) > 0.8`,
+		`max_over_time(up[1h:5m]) offset 1h`,
+		`-rate(errors_total[5m]) / ignoring(code) group_left rate(requests_total[5m])`,
+		`{"http.server.duration", service_name='web'}`,
+		`time() - process_start_time_seconds{job="node"} < 1e3 # restarted`,
+		`avg(job:request_latency:mean5m) @ 1609746000`,
+	} {
+		assert.NoError(t, validatePromQL(query), query)
+	}
+
+	for _, tt := range []struct {
+		query string
+		err   string
+	}{
+		{``, "query is required"},
+		{`sum(rate(x[5m])`, `col 4: unclosed "("`},
+		{`up{job="node"`, `col 3: unclosed "{"`},
+		{`up{job="node}`, "col 8: unterminated string"},
+		{`up{job=node}`, "col 8: label value must be a quoted string"},
+		{`up{job=="node"}`, "expected =, !=, =~ or !~ after label job"},
+		{`up{job=~"("}`, "invalid regular expression"},
+		{`rate(x[5])`, "col 7: invalid range [5]"},
+		{`up > `, `missing operand after ">"`},
+		{`(* up)`, `col 2: missing operand before "*"`},
+		{`up = 1`, `"=" is only valid in label matchers`},
+		{`up)`, `col 3: unexpected ")"`},
+		{`up ; 1`, `col 4: unexpected character ';'`},
+	} {
+		err := validatePromQL(tt.query)
+		if assert.Error(t, err, tt.query) {
+			assert.Contains(t, err.Error(), tt.err, tt.query)
+		}
+	}
+}
@@ -0,0 +1,333 @@
+package internal
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Manifest media types accepted when resolving a tag
+var registryManifestTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// RegistryClient talks to a Docker Registry HTTP API v2 endpoint
+type RegistryClient struct {
+	httpAPIClient
+	// mu guards the token, which requests running from concurrent commands, e.g. a tag
+	// load overlapping a delete, replace after an auth challenge
+	mu       sync.Mutex
+	username string
+	password string
+}
+
+// RegistryTag is a tag in a repository and the manifest it points at
+type RegistryTag struct {
+	Name      string
+	Digest    string
+	MediaType string
+	Size      int64 // total of config and layer sizes, 0 for multi-platform indexes
+	Platforms int   // number of platform manifests for multi-platform indexes
+}
+
+// NewRegistryClient creates a client for the registry at baseURL.
+// baseURL may omit the scheme, in which case https is used.
+func NewRegistryClient(baseURL, username, password string) *RegistryClient {
+	return &RegistryClient{
+		httpAPIClient: newHTTPAPIClient(baseURL, ""),
+		username:      username,
+		password:      password,
+	}
+}
+
+// do sends a request, retrying once with a bearer token when the registry asks for one
+func (r *RegistryClient) do(ctx context.Context, method, path string, header http.Header) (*http.Response, error) {
+	newRequest := func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, method, r.baseURL+path, nil)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		if token := r.bearerToken(); token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		} else if r.username != "" {
+			req.SetBasicAuth(r.username, r.password)
+		}
+		return req, nil
+	}
+
+	req, err := newRequest()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		return resp, nil
+	}
+
+	challenge := resp.Header.Get("WWW-Authenticate")
+	_ = resp.Body.Close()
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return nil, fmt.Errorf("%s %s: unauthorized", method, path)
+	}
+	token, err := r.fetchToken(ctx, challenge)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	r.token = token
+	r.mu.Unlock()
+
+	req, err = newRequest()
+	if err != nil {
+		return nil, err
+	}
+	return r.httpClient.Do(req)
+}
+
+var registryChallengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// bearerToken returns the last token obtained from the registry's auth service
+func (r *RegistryClient) bearerToken() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.token
+}
+
+// fetchToken obtains a bearer token as described by a WWW-Authenticate challenge
+func (r *RegistryClient) fetchToken(ctx context.Context, challenge string) (string, error) {
+	params := map[string]string{}
+	for _, m := range registryChallengeParam.FindAllStringSubmatch(challenge, -1) {
+		params[m[1]] = m[2]
+	}
+	if params["realm"] == "" {
+		return "", fmt.Errorf("registry auth challenge has no realm")
+	}
+	realm, err := url.Parse(params["realm"])
+	if err != nil {
+		return "", fmt.Errorf("invalid registry auth realm: %w", err)
+	}
+
+	// Keep any parameters the realm already has
+	query := realm.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	if scope := params["scope"]; scope != "" {
+		query.Set("scope", scope)
+	}
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if r.username != "" {
+		req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(r.username+":"+r.password)))
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("registry token request failed: %s", resp.Status)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	return body.AccessToken, nil
+}
+
+// getJSON fetches path and decodes the JSON response into v, returning the response headers
+func (r *RegistryClient) getJSON(ctx context.Context, path string, header http.Header, v any) (http.Header, error) {
+	resp, err := r.do(ctx, http.MethodGet, path, header)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("GET %s: %s %s", path, resp.Status, strings.TrimSpace(string(body)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return nil, fmt.Errorf("GET %s: %w", path, err)
+	}
+	return resp.Header, nil
+}
+
+var registryNextLink = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// ListRepositories returns all repositories in the registry catalog
+func (r *RegistryClient) ListRepositories(ctx context.Context) ([]string, error) {
+	slog.Info("Fetching registry catalog", slog.String("registry", r.baseURL))
+
+	var repositories []string
+	path := "/v2/_catalog?n=100"
+	for path != "" {
+		var body struct {
+			Repositories []string `json:"repositories"`
+		}
+		header, err := r.getJSON(ctx, path, nil, &body)
+		if err != nil {
+			slog.Error("Failed to fetch registry catalog",
+				slog.String("registry", r.baseURL),
+				slog.Any("error", err))
+			return nil, err
+		}
+		repositories = append(repositories, body.Repositories...)
+
+		path = ""
+		if m := registryNextLink.FindStringSubmatch(header.Get("Link")); m != nil {
+			path = m[1]
+		}
+	}
+
+	return repositories, nil
+}
+
+// ListTags returns the tags of a repository with their manifest digests and sizes
+func (r *RegistryClient) ListTags(ctx context.Context, repository string) ([]RegistryTag, error) {
+	slog.Info("Fetching registry tags",
+		slog.String("registry", r.baseURL),
+		slog.String("repository", repository))
+
+	var body struct {
+		Tags []string `json:"tags"`
+	}
+	if _, err := r.getJSON(ctx, "/v2/"+repository+"/tags/list", nil, &body); err != nil {
+		slog.Error("Failed to fetch registry tags",
+			slog.String("repository", repository),
+			slog.Any("error", err))
+		return nil, err
+	}
+
+	tags := make([]RegistryTag, 0, len(body.Tags))
+	for _, name := range body.Tags {
+		tag, err := r.GetTag(ctx, repository, name)
+		if err != nil {
+			slog.Warn("Failed to fetch manifest",
+				slog.String("repository", repository),
+				slog.String("tag", name),
+				slog.Any("error", err))
+			tag = RegistryTag{Name: name}
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+// GetTag resolves a tag to its manifest digest and size
+func (r *RegistryClient) GetTag(ctx context.Context, repository, tag string) (RegistryTag, error) {
+	header := http.Header{}
+	header.Set("Accept", strings.Join(registryManifestTypes, ", "))
+
+	var manifest struct {
+		MediaType string `json:"mediaType"`
+		Config    struct {
+			Size int64 `json:"size"`
+		} `json:"config"`
+		Layers []struct {
+			Size int64 `json:"size"`
+		} `json:"layers"`
+		Manifests []json.RawMessage `json:"manifests"`
+	}
+	respHeader, err := r.getJSON(ctx, "/v2/"+repository+"/manifests/"+tag, header, &manifest)
+	if err != nil {
+		return RegistryTag{}, err
+	}
+
+	result := RegistryTag{
+		Name:      tag,
+		Digest:    respHeader.Get("Docker-Content-Digest"),
+		MediaType: manifest.MediaType,
+		Platforms: len(manifest.Manifests),
+	}
+	if result.MediaType == "" {
+		result.MediaType = respHeader.Get("Content-Type")
+	}
+	if len(manifest.Manifests) == 0 {
+		result.Size = manifest.Config.Size
+		for _, layer := range manifest.Layers {
+			result.Size += layer.Size
+		}
+	}
+	return result, nil
+}
+
+// DeleteManifest deletes a manifest by digest. All tags pointing at it are removed.
+// The registry must have deletion enabled.
+func (r *RegistryClient) DeleteManifest(ctx context.Context, repository, digest string) error {
+	slog.Info("Deleting registry manifest",
+		slog.String("registry", r.baseURL),
+		slog.String("repository", repository),
+		slog.String("digest", digest))
+
+	if digest == "" {
+		return fmt.Errorf("manifest digest is unknown")
+	}
+
+	resp, err := r.do(ctx, http.MethodDelete, "/v2/"+repository+"/manifests/"+digest, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		err := fmt.Errorf("DELETE %s@%s: %s %s", repository, digest, resp.Status, strings.TrimSpace(string(body)))
+		slog.Error("Failed to delete registry manifest", slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+// formatBytes formats a byte count with a binary unit
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// shortDigest abbreviates a sha256 digest for display
+func shortDigest(digest string) string {
+	digest = strings.TrimPrefix(digest, "sha256:")
+	if len(digest) > 12 {
+		return digest[:12]
+	}
+	return digest
+}
@@ -0,0 +1,132 @@
+package internal
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeRegistry starts a minimal Docker Registry v2 stand-in protected by basic auth
+func newFakeRegistry(t *testing.T) (*httptest.Server, *[]string) {
+	var deleted []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/_catalog", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("last") == "" {
+			w.Header().Set("Link", `</v2/_catalog?last=app&n=100>; rel="next"`)
+			_ = json.NewEncoder(w).Encode(map[string][]string{"repositories": {"app"}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string][]string{"repositories": {"web/nginx"}})
+	})
+	mux.HandleFunc("/v2/web/nginx/tags/list", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"name": "web/nginx", "tags": []string{"latest", "multi"}})
+	})
+	mux.HandleFunc("/v2/web/nginx/manifests/", func(w http.ResponseWriter, r *http.Request) {
+		ref := strings.TrimPrefix(r.URL.Path, "/v2/web/nginx/manifests/")
+		if r.Method == http.MethodDelete {
+			deleted = append(deleted, ref)
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		assert.Contains(t, r.Header.Get("Accept"), "application/vnd.docker.distribution.manifest.v2+json")
+		switch ref {
+		case "latest":
+			w.Header().Set("Docker-Content-Digest", "sha256:0123456789abcdef0123")
+			_, _ = w.Write([]byte(`{"mediaType":"application/vnd.docker.distribution.manifest.v2+json","config":{"size":100},"layers":[{"size":1000},{"size":24}]}`))
+		case "multi":
+			w.Header().Set("Docker-Content-Digest", "sha256:fedcba9876543210fedc")
+			_, _ = w.Write([]byte(`{"mediaType":"application/vnd.oci.image.index.v1+json","manifests":[{},{}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "reader" || pass != "secret" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server, &deleted
+}
+
+func TestRegistryClient(t *testing.T) {
+	server, deleted := newFakeRegistry(t)
+	client := NewRegistryClient(server.URL, "reader", "secret")
+
+	repositories, err := client.ListRepositories(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app", "web/nginx"}, repositories)
+
+	tags, err := client.ListTags(t.Context(), "web/nginx")
+	require.NoError(t, err)
+	require.Len(t, tags, 2)
+	assert.Equal(t, RegistryTag{
+		Name:      "latest",
+		Digest:    "sha256:0123456789abcdef0123",
+		MediaType: "application/vnd.docker.distribution.manifest.v2+json",
+		Size:      1124,
+	}, tags[0])
+	assert.Equal(t, 2, tags[1].Platforms)
+	assert.Zero(t, tags[1].Size)
+
+	require.NoError(t, client.DeleteManifest(t.Context(), "web/nginx", tags[0].Digest))
+	assert.Equal(t, []string{"sha256:0123456789abcdef0123"}, *deleted)
+}
+
+func TestRegistryClientUnauthorized(t *testing.T) {
+	server, _ := newFakeRegistry(t)
+	client := NewRegistryClient(server.URL, "reader", "wrong")
+
+	_, err := client.ListRepositories(t.Context())
+	assert.ErrorContains(t, err, "unauthorized")
+}
+
+func TestRegistryClientBearerToken(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			user, pass, _ := r.BasicAuth()
+			assert.Equal(t, "reader", user)
+			assert.Equal(t, "secret", pass)
+			assert.Equal(t, "registry:catalog:*", r.URL.Query().Get("scope"))
+			// The query of the realm is kept
+			assert.Equal(t, "reader", r.URL.Query().Get("account"))
+			_, _ = w.Write([]byte(`{"token":"abc"}`))
+		case r.Header.Get("Authorization") == "Bearer abc":
+			_, _ = w.Write([]byte(`{"repositories":["app"]}`))
+		default:
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token?account=reader",service="registry",scope="registry:catalog:*"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	client := NewRegistryClient(server.URL, "reader", "secret")
+	repositories, err := client.ListRepositories(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app"}, repositories)
+
+	// Overlapping requests share the token safely
+	forEachConcurrently(4, func(int) {
+		repositories, err := client.ListRepositories(t.Context())
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"app"}, repositories)
+	})
+}
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "512 B", formatBytes(512))
+	assert.Equal(t, "1.5 KiB", formatBytes(1536))
+	assert.Equal(t, "20.0 MiB", formatBytes(20*1024*1024))
+}
@@ -3,6 +3,7 @@ package internal
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/table"
 	"github.com/charmbracelet/lipgloss"
@@ -466,7 +467,11 @@ func renderArchiveDetail(detail *ArchiveDetail) string {
 		b.WriteString(fmt.Sprintf("\nSource Disk: %s\n", detail.SourceDiskID))
 	}
 	if detail.SourceArchiveID != "" {
-		b.WriteString(fmt.Sprintf("Source Archive: %s\n", detail.SourceArchiveID))
+		if detail.SourceZone != "" {
+			b.WriteString(fmt.Sprintf("Source Archive: %s (zone %s)\n", detail.SourceArchiveID, detail.SourceZone))
+		} else {
+			b.WriteString(fmt.Sprintf("Source Archive: %s\n", detail.SourceArchiveID))
+		}
 	}
 
 	if len(detail.Tags) > 0 {
@@ -611,7 +616,7 @@ func renderPacketFilterDetail(detail *PacketFilterDetail) string {
 	return b.String()
 }
 
-func renderLoadBalancerDetail(detail *LoadBalancerDetail) string {
+func renderLoadBalancerDetail(detail *LoadBalancerDetail, cursor int) string {
 	var b strings.Builder
 
 	b.WriteString(selectedStyle.Render(fmt.Sprintf("Load Balancer: %s", detail.Name)))
@@ -640,8 +645,9 @@ func renderLoadBalancerDetail(detail *LoadBalancerDetail) string {
 	// Display VIPs
 	if len(detail.VIPs) > 0 {
 		b.WriteString("\nVirtual IPs:\n")
+		row := 0
 		for i, vip := range detail.VIPs {
-			b.WriteString(fmt.Sprintf("\n  VIP %d: %s:%d\n", i+1, vip.VirtualIPAddress, vip.Port))
+			b.WriteString(fmt.Sprintf("\n  VIP %d: %s:%d (CPS: %d)\n", i+1, vip.VirtualIPAddress, vip.Port, vip.CPS))
 			if vip.Description != "" {
 				b.WriteString(fmt.Sprintf("    Description: %s\n", vip.Description))
 			}
@@ -653,12 +659,36 @@ func renderLoadBalancerDetail(detail *LoadBalancerDetail) string {
 			// Display real servers
 			if len(vip.Servers) > 0 {
 				b.WriteString("    Real Servers:\n")
+				b.WriteString(fmt.Sprintf("      %-22s %-9s %-8s %8s %6s\n", "Address", "Config", "Status", "Conns", "CPS"))
 				for _, srv := range vip.Servers {
 					enabled := "enabled"
 					if !srv.Enabled {
 						enabled = "disabled"
 					}
-					b.WriteString(fmt.Sprintf("      - %s:%d (%s)\n", srv.IPAddress, srv.Port, enabled))
+					status := srv.Status
+					if status == "" {
+						status = "-"
+					}
+					statusStyle := otherStatusStyle
+					switch status {
+					case "up":
+						statusStyle = upStatusStyle
+					case "down":
+						statusStyle = downStatusStyle
+					}
+					line := fmt.Sprintf("%-22s %-9s %s %8d %6d",
+						fmt.Sprintf("%s:%d", srv.IPAddress, srv.Port),
+						enabled,
+						statusStyle.Render(fmt.Sprintf("%-8s", status)),
+						srv.ActiveConn,
+						srv.CPS)
+					if row == cursor {
+						b.WriteString(selectedItemStyle.Render("   > " + line))
+					} else {
+						b.WriteString("      " + line)
+					}
+					b.WriteString("\n")
+					row++
 				}
 			}
 		}
@@ -694,6 +724,14 @@ func renderNFSDetail(detail *NFSDetail) string {
 		b.WriteString(fmt.Sprintf("Plan ID:     %s\n", detail.PlanID))
 	}
 
+	if detail.SizeGB > 0 {
+		b.WriteString(fmt.Sprintf("Plan:        %s %dGB\n", detail.Plan, detail.SizeGB))
+	}
+
+	if detail.HasUsage {
+		b.WriteString(fmt.Sprintf("Used:        %.1f%%\n", detail.UsedPercent))
+	}
+
 	if len(detail.IPAddresses) > 0 {
 		b.WriteString(fmt.Sprintf("IP Addresses: %s\n", strings.Join(detail.IPAddresses, ", ")))
 	}
@@ -714,6 +752,17 @@ func renderNFSDetail(detail *NFSDetail) string {
 		b.WriteString(fmt.Sprintf("Switch Name: %s\n", detail.SwitchName))
 	}
 
+	// Display activity charts
+	b.WriteString("\nActivity (last 24h):\n")
+	if detail.SizeGB > 0 {
+		b.WriteString(renderSeries("Disk used", detail.UsedHistory, formatPercent))
+	}
+	b.WriteString(renderSeries("Receive", detail.ReceiveHistory, formatBPS))
+	b.WriteString(renderSeries("Send", detail.SendHistory, formatBPS))
+	if detail.MonitorErrorMsg != "" {
+		b.WriteString(fmt.Sprintf("  Error: %s\n", detail.MonitorErrorMsg))
+	}
+
 	if len(detail.Tags) > 0 {
 		b.WriteString(fmt.Sprintf("\nTags:        %s\n", strings.Join(detail.Tags, ", ")))
 	}
@@ -873,6 +922,39 @@ func renderSimpleMonitorDetail(detail *SimpleMonitorDetail) string {
 		b.WriteString(fmt.Sprintf("Health Changed: %s\n", detail.LastHealthChangedAt))
 	}
 
+	// Display response time history and health timeline
+	b.WriteString(fmt.Sprintf("\nResponse Time (last %s):\n", formatWindow(detail.Window)))
+	responseTimes := make([]float64, len(detail.ResponseTimes))
+	for i, rt := range detail.ResponseTimes {
+		responseTimes[i] = rt.Sec
+	}
+	b.WriteString(renderSeries("Response", responseTimes, func(v float64) string {
+		return fmt.Sprintf("%.3fs", v)
+	}))
+	if len(detail.ResponseTimes) > 0 {
+		b.WriteString(fmt.Sprintf("  %-10s %s\n", "Health", renderHealthTimeline(detail.ResponseTimes)))
+	}
+	if detail.MonitorErrorMsg != "" {
+		b.WriteString(fmt.Sprintf("  Error: %s\n", detail.MonitorErrorMsg))
+	}
+
+	if len(detail.HealthChanges) > 0 {
+		b.WriteString("\nHealth Changes:\n")
+		changes := detail.HealthChanges
+		if len(changes) > 10 {
+			changes = changes[len(changes)-10:]
+		}
+		for _, change := range changes {
+			healthStyle := upStatusStyle
+			if change.Health != "UP" {
+				healthStyle = errorStyle
+			}
+			b.WriteString(fmt.Sprintf("  %s  %s\n",
+				change.Time.Format("2006-01-02 15:04:05"),
+				healthStyle.Render(change.Health)))
+		}
+	}
+
 	if len(detail.LatestLogs) > 0 {
 		b.WriteString("\nLatest Logs:\n")
 		for _, log := range detail.LatestLogs {
@@ -987,6 +1069,112 @@ func renderContainerRegistryDetail(detail *ContainerRegistryDetail) string {
 	return b.String()
 }
 
+// renderRegistryBrowser renders the repositories of a container registry, or the tags of the selected repository
+func renderRegistryBrowser(detail *ContainerRegistryDetail, browser *registryBrowser, cursor int) string {
+	var b strings.Builder
+
+	b.WriteString(selectedStyle.Render(fmt.Sprintf("Container Registry: %s", detail.Name)))
+	b.WriteString("\n\n")
+
+	b.WriteString(fmt.Sprintf("Endpoint:      %s\n", browser.endpoint))
+	user := browser.username
+	if user == "" {
+		user = "(anonymous)"
+	}
+	b.WriteString(fmt.Sprintf("User:          %s\n", user))
+
+	row := func(i int, line string) {
+		if i == cursor {
+			b.WriteString(selectedItemStyle.Render("  > " + line))
+		} else {
+			b.WriteString("    " + line)
+		}
+		b.WriteString("\n")
+	}
+
+	if browser.repository == "" {
+		b.WriteString(fmt.Sprintf("\nRepositories:  %d\n", len(browser.repositories)))
+		for i, repo := range browser.repositories {
+			row(i, repo)
+		}
+		return b.String()
+	}
+
+	b.WriteString(fmt.Sprintf("\nRepository:    %s\n", browser.repository))
+	b.WriteString(fmt.Sprintf("Tags:          %d\n\n", len(browser.tags)))
+	if len(browser.tags) == 0 {
+		return b.String()
+	}
+	b.WriteString(fmt.Sprintf("    %-30s %-14s %-12s %s\n", "Tag", "Digest", "Size", "Type"))
+	for i, tag := range browser.tags {
+		digest := "-"
+		if tag.Digest != "" {
+			digest = shortDigest(tag.Digest)
+		}
+		size := "-"
+		if tag.Platforms > 0 {
+			size = fmt.Sprintf("%d platforms", tag.Platforms)
+		} else if tag.Size > 0 {
+			size = formatBytes(tag.Size)
+		}
+		row(i, fmt.Sprintf("%-30s %-14s %-12s %s", tag.Name, digest, size, tag.MediaType))
+	}
+
+	return b.String()
+}
+
+// renderAppRunVersionComparison renders two application versions side by side,
+// or a single version when both sides are the same
+func renderAppRunVersionComparison(c *AppRunVersionComparison) string {
+	var b strings.Builder
+
+	const fieldWidth = 22
+	const valueWidth = 44
+
+	truncate := func(s string) string {
+		if len(s) > valueWidth {
+			return s[:valueWidth-3] + "..."
+		}
+		return s
+	}
+
+	if c.Base.Version == c.Target.Version {
+		b.WriteString(selectedStyle.Render(fmt.Sprintf("AppRun Version: %s v%d", c.AppName, c.Target.Version)))
+		b.WriteString("\n\n")
+		b.WriteString(fmt.Sprintf("  %-*s %s\n", fieldWidth, "Active Nodes", fmt.Sprint(c.Target.ActiveNodeCount)))
+		b.WriteString(fmt.Sprintf("  %-*s %s\n", fieldWidth, "Created", c.Target.CreatedAt))
+		for _, row := range c.Rows() {
+			b.WriteString(fmt.Sprintf("  %-*s %s\n", fieldWidth, row.Field, row.Target))
+		}
+		return b.String()
+	}
+
+	b.WriteString(selectedStyle.Render(fmt.Sprintf("AppRun Version Diff: %s v%d -> v%d", c.AppName, c.Base.Version, c.Target.Version)))
+	b.WriteString("\n\n")
+
+	rows := c.Rows()
+	changed := 0
+	for _, row := range rows {
+		if row.Changed {
+			changed++
+		}
+	}
+	b.WriteString(fmt.Sprintf("Changed: %d of %d fields\n\n", changed, len(rows)))
+
+	b.WriteString(fmt.Sprintf("  %-*s %-*s %s\n", fieldWidth, "Field", valueWidth, fmt.Sprintf("v%d", c.Base.Version), fmt.Sprintf("v%d", c.Target.Version)))
+	for _, row := range rows {
+		line := fmt.Sprintf("%-*s %-*s %s", fieldWidth, row.Field, valueWidth, truncate(row.Base), truncate(row.Target))
+		if row.Changed {
+			b.WriteString(otherStatusStyle.Render("* " + line))
+		} else {
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
 func renderAppRunClusterDetail(detail *AppRunClusterDetail) string {
 	var b strings.Builder
 
@@ -1061,6 +1249,50 @@ func renderAppRunLBDetail(detail *AppRunLBDetail) string {
 		}
 	}
 
+	// Display LB nodes, flagging the ones in an error state
+	if len(detail.Nodes) > 0 {
+		b.WriteString(fmt.Sprintf("\nNodes: %d", len(detail.Nodes)))
+		if errors := detail.ErrorNodeCount(); errors > 0 {
+			b.WriteString(errorStyle.Render(fmt.Sprintf(" (%d in error)", errors)))
+		}
+		b.WriteString("\n")
+		b.WriteString(fmt.Sprintf("    %-24s %-10s %-15s %-19s %s\n", "Resource ID", "Status", "Archive", "Created", "IPs"))
+		b.WriteString(fmt.Sprintf("    %-24s %-10s %-15s %-19s %s\n", "-----------", "------", "-------", "-------", "---"))
+		for _, node := range detail.Nodes {
+			resourceID := node.ResourceID
+			if resourceID == "" {
+				resourceID = "(creating)"
+			}
+			ips := strings.Join(node.IPAddresses, ", ")
+			if len(node.VIPs) > 0 {
+				ips += fmt.Sprintf(" (VIP: %s)", strings.Join(node.VIPs, ", "))
+			}
+			if ips == "" {
+				ips = "-"
+			}
+			statusStyle := otherStatusStyle
+			if node.Status == "healthy" {
+				statusStyle = upStatusStyle
+			}
+			marker := "  "
+			if node.HasError() {
+				marker = errorStyle.Render("! ")
+				statusStyle = errorStyle
+			}
+			b.WriteString(fmt.Sprintf("  %s%-24s %s %-15s %-19s %s\n",
+				marker,
+				resourceID,
+				statusStyle.Render(fmt.Sprintf("%-10s", node.Status)),
+				node.ArchiveVersion,
+				node.CreatedAt,
+				ips))
+			if node.ErrorMessage != "" {
+				b.WriteString(errorStyle.Render(fmt.Sprintf("      Error: %s", node.ErrorMessage)))
+				b.WriteString("\n")
+			}
+		}
+	}
+
 	if detail.CreatedAt != "" {
 		b.WriteString(fmt.Sprintf("\nCreated: %s\n", detail.CreatedAt))
 	}
@@ -1068,7 +1300,9 @@ func renderAppRunLBDetail(detail *AppRunLBDetail) string {
 	return b.String()
 }
 
-func renderAppRunASGDetail(detail *AppRunASGDetail, workerNodes []AppRunWorkerNode) string {
+// renderAppRunASGDetail renders an ASG with its worker nodes, the containers on them and its LBs.
+// cursor selects a worker node, -1 for none.
+func renderAppRunASGDetail(detail *AppRunASGDetail, workerNodes []AppRunWorkerNode, cursor int) string {
 	var b strings.Builder
 
 	b.WriteString(selectedStyle.Render(fmt.Sprintf("AppRun ASG: %s", detail.Name)))
@@ -1116,9 +1350,9 @@ func renderAppRunASGDetail(detail *AppRunASGDetail, workerNodes []AppRunWorkerNo
 	// Display worker nodes
 	if len(workerNodes) > 0 {
 		b.WriteString(fmt.Sprintf("\nWorker Nodes: %d\n", len(workerNodes)))
-		b.WriteString(fmt.Sprintf("  %-24s %-10s %-8s %-15s %s\n", "Resource ID", "Status", "Drain", "Archive", "IPs"))
-		b.WriteString(fmt.Sprintf("  %-24s %-10s %-8s %-15s %s\n", "-----------", "------", "-----", "-------", "---"))
-		for _, node := range workerNodes {
+		b.WriteString(fmt.Sprintf("    %-24s %-10s %-8s %-15s %s\n", "Resource ID", "Status", "Drain", "Archive", "IPs"))
+		b.WriteString(fmt.Sprintf("    %-24s %-10s %-8s %-15s %s\n", "-----------", "------", "-----", "-------", "---"))
+		for i, node := range workerNodes {
 			drainStr := "-"
 			if node.Draining {
 				drainStr = "Yes"
@@ -1131,14 +1365,23 @@ func renderAppRunASGDetail(detail *AppRunASGDetail, workerNodes []AppRunWorkerNo
 			if ips == "" {
 				ips = "-"
 			}
-			b.WriteString(fmt.Sprintf("  %-24s %-10s %-8s %-15s %s\n",
+			line := fmt.Sprintf("%-24s %-10s %-8s %-15s %s",
 				resourceID,
 				node.Status,
 				drainStr,
 				node.ArchiveVersion,
-				ips))
+				ips)
+			if i == cursor {
+				b.WriteString(selectedItemStyle.Render("  > " + line))
+			} else {
+				b.WriteString("    " + line)
+			}
+			b.WriteString("\n")
 			if node.ErrorMessage != "" {
-				b.WriteString(fmt.Sprintf("    Error: %s\n", node.ErrorMessage))
+				b.WriteString(fmt.Sprintf("      Error: %s\n", node.ErrorMessage))
+			}
+			for _, ct := range node.Containers {
+				b.WriteString(fmt.Sprintf("      %s v%d %s (%s)\n", ct.ApplicationName, ct.Version, ct.State, ct.Status))
 			}
 		}
 	}
@@ -1157,6 +1400,14 @@ func renderAppRunASGDetail(detail *AppRunASGDetail, workerNodes []AppRunWorkerNo
 				b.WriteString("    ** DELETING **\n")
 			}
 
+			if len(lb.Nodes) > 0 {
+				b.WriteString(fmt.Sprintf("    Nodes:         %d", len(lb.Nodes)))
+				if errors := lb.ErrorNodeCount(); errors > 0 {
+					b.WriteString(errorStyle.Render(fmt.Sprintf(" (%d in error)", errors)))
+				}
+				b.WriteString("\n")
+			}
+
 			// Display name servers
 			if len(lb.NameServers) > 0 {
 				b.WriteString(fmt.Sprintf("    Name Servers:  %s\n", strings.Join(lb.NameServers, ", ")))
@@ -1237,6 +1488,59 @@ func renderMonitoringLogStorageDetail(detail *MonitoringLogStorageDetail) string
 	return b.String()
 }
 
+// renderLogViewer renders the entries of the log viewer, coloured by severity
+func renderLogViewer(detail *MonitoringLogStorageDetail, v *logViewer) string {
+	var b strings.Builder
+
+	b.WriteString(selectedStyle.Render(fmt.Sprintf("Log Storage: %s", getOptString(detail.Name))))
+	b.WriteString("\n\n")
+
+	follow := "off"
+	if v.follow {
+		follow = "on (every 5s)"
+	}
+	b.WriteString(fmt.Sprintf("Endpoint:  %s\n", v.endpoint))
+	b.WriteString(fmt.Sprintf("Query:     %s\n", v.query()))
+	b.WriteString(fmt.Sprintf("Window:    last %s\n", LogViewWindows[v.window].Label))
+	b.WriteString(fmt.Sprintf("Follow:    %s\n", follow))
+
+	if v.err != nil {
+		b.WriteString(fmt.Sprintf("\nError: %v\n", v.err))
+		return b.String()
+	}
+	if v.client == nil {
+		return b.String()
+	}
+	b.WriteString(fmt.Sprintf("Entries:   %d\n\n", len(v.entries)))
+	if len(v.entries) == logQueryLimit && !v.follow {
+		b.WriteString(fmt.Sprintf("  (showing the latest %d entries; narrow the filter or window to see older ones)\n", logQueryLimit))
+	}
+
+	for _, e := range v.entries {
+		b.WriteString(renderLogLine(e, "01-02 15:04:05", 0))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// renderLogLine renders a log entry coloured by its severity, truncated to width unless it is 0
+func renderLogLine(e LogEntry, timeLayout string, width int) string {
+	severity := e.Severity()
+	line := fmt.Sprintf("%s %-5s %s", e.Time.Format(timeLayout), strings.ToUpper(severity), e.Line)
+	if width > 0 {
+		line = truncateText(line, width)
+	}
+	switch severity {
+	case "error":
+		return errorStyle.Render(line)
+	case "warn":
+		return otherStatusStyle.Render(line)
+	case "debug":
+		return downStatusStyle.Render(line)
+	}
+	return line
+}
+
 func renderMonitoringMetricsStorageDetail(detail *MonitoringMetricsStorageDetail) string {
 	var b strings.Builder
 
@@ -1282,6 +1586,154 @@ func renderMonitoringMetricsStorageDetail(detail *MonitoringMetricsStorageDetail
 	return b.String()
 }
 
+// renderAlertRules renders the alert rules of a metrics storage with their firing alerts
+// and the notification targets they are sent to
+func renderAlertRules(detail *MonitoringMetricsStorageDetail, b *alertRuleBrowser, cursor int) string {
+	var sb strings.Builder
+
+	sb.WriteString(selectedStyle.Render(fmt.Sprintf("Metrics Storage: %s", getOptString(detail.Name))))
+	sb.WriteString("\n\n")
+
+	switch {
+	case b.err != nil:
+		sb.WriteString(fmt.Sprintf("Error: %v\n", b.err))
+		return sb.String()
+	case b.rules == nil:
+		sb.WriteString("Loading alert rules...\n")
+		return sb.String()
+	}
+
+	firing := 0
+	for _, r := range b.rules.Rules {
+		if len(r.Firing) > 0 {
+			firing++
+		}
+	}
+	sb.WriteString(fmt.Sprintf("Alert Rules: %d (%d firing)\n", len(b.rules.Rules), firing))
+	if len(b.rules.Rules) == 0 {
+		sb.WriteString("\n  (no alert rules evaluate this storage, press A to create one)\n")
+	}
+
+	for i, r := range b.rules.Rules {
+		state := upStatusStyle.Render("OK")
+		if len(r.Firing) > 0 {
+			state = errorStyle.Render("FIRING")
+		}
+		line := fmt.Sprintf("%-40s", getOptString(r.Name))
+		if i == cursor {
+			sb.WriteString("\n" + selectedItemStyle.Render("> "+line) + " " + state + "\n")
+		} else {
+			sb.WriteString("\n   " + line + " " + state + "\n")
+		}
+		sb.WriteString(fmt.Sprintf("    Query:     %s\n", r.Query))
+		sb.WriteString(fmt.Sprintf("    Warning:   %s\n", r.Warning()))
+		sb.WriteString(fmt.Sprintf("    Critical:  %s\n", r.Critical()))
+		sb.WriteString(fmt.Sprintf("    Project:   %s (%s)\n", r.Project.Name, r.Project.ID))
+
+		if len(r.Project.Notifications) == 0 {
+			sb.WriteString("    Notify:    (no notification routings)\n")
+		}
+		for j, n := range r.Project.Notifications {
+			label := "Notify:"
+			if j > 0 {
+				label = ""
+			}
+			sb.WriteString(fmt.Sprintf("    %-10s %s -> %s\n", label, formatMatchLabels(n.MatchLabels), formatNotificationTarget(n.NotificationTarget)))
+		}
+
+		for _, alert := range r.Firing {
+			value := ""
+			if v, ok := alert.Value.Get(); ok {
+				value = " value " + formatPromValue(v)
+			}
+			labels := alert.Labels
+			if parsed := parseAlertLabels(alert.Labels); parsed != nil {
+				labels = formatPromLabels(parsed)
+			}
+			sb.WriteString(errorStyle.Render(fmt.Sprintf("    Firing:    %s since %s%s %s",
+				alert.Severity, alert.StartsAt.Local().Format("01-02 15:04"), value, labels)))
+			sb.WriteString("\n")
+			var targets []string
+			for _, t := range r.Project.NotifiedTargets(alert) {
+				targets = append(targets, formatNotificationTarget(t))
+			}
+			if len(targets) > 0 {
+				sb.WriteString(fmt.Sprintf("               notified: %s\n", strings.Join(targets, ", ")))
+			}
+		}
+	}
+	return sb.String()
+}
+
+// renderMetricsQuery renders the PromQL query panel of a metrics storage
+func renderMetricsQuery(detail *MonitoringMetricsStorageDetail, q *metricsQuery, cursor int) string {
+	var b strings.Builder
+
+	b.WriteString(selectedStyle.Render(fmt.Sprintf("Metrics Storage: %s", getOptString(detail.Name))))
+	b.WriteString("\n\n")
+
+	b.WriteString(fmt.Sprintf("Endpoint:  %s\n", q.endpoint))
+	b.WriteString(fmt.Sprintf("Window:    %s\n", MetricsQueryWindows[q.window].Label))
+
+	if len(q.saved) > 0 {
+		b.WriteString("\nSaved queries:\n")
+		for i, saved := range q.saved {
+			line := fmt.Sprintf("%-20s %s", saved.Name, saved.Query)
+			if i == cursor {
+				b.WriteString(selectedItemStyle.Render("  > " + line))
+			} else {
+				b.WriteString("    " + line)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if q.query == "" {
+		b.WriteString("\nQuery:     (none, press e to enter one)\n")
+		return b.String()
+	}
+	b.WriteString(fmt.Sprintf("\nQuery:     %s\n", q.query))
+
+	switch {
+	case q.err != nil:
+		b.WriteString(fmt.Sprintf("\nError: %v\n", q.err))
+		return b.String()
+	case q.result == nil:
+		return b.String()
+	}
+	b.WriteString(fmt.Sprintf("Result:    %s, %d series\n\n", q.result.ResultType, len(q.result.Series)))
+	if len(q.result.Series) == 0 {
+		return b.String()
+	}
+
+	if q.chart {
+		if MetricsQueryWindows[q.window].Duration == 0 {
+			b.WriteString("  (instant queries have a single sample; press w to pick a time window)\n\n")
+		}
+		for _, series := range q.result.Series {
+			values := make([]float64, len(series.Samples))
+			maxV := series.Samples[0].Value
+			for i, sample := range series.Samples {
+				values[i] = sample.Value
+				maxV = max(maxV, sample.Value)
+			}
+			b.WriteString(fmt.Sprintf("  %s\n", formatPromLabels(series.Labels)))
+			b.WriteString(fmt.Sprintf("  %s\n  last: %s  max: %s\n\n",
+				sparkline(values, chartWidth), formatPromValue(values[len(values)-1]), formatPromValue(maxV)))
+		}
+		return b.String()
+	}
+
+	// Table of the latest sample of each series
+	b.WriteString(fmt.Sprintf("  %-14s %-19s %s\n", "Value", "Time", "Series"))
+	for _, series := range q.result.Series {
+		last := series.Samples[len(series.Samples)-1]
+		b.WriteString(fmt.Sprintf("  %-14s %-19s %s\n",
+			formatPromValue(last.Value), last.Time.Format("2006-01-02 15:04:05"), formatPromLabels(series.Labels)))
+	}
+	return b.String()
+}
+
 func renderMonitoringTraceStorageDetail(detail *MonitoringTraceStorageDetail) string {
 	var b strings.Builder
 
@@ -1310,3 +1762,478 @@ func renderMonitoringTraceStorageDetail(detail *MonitoringTraceStorageDetail) st
 
 	return b.String()
 }
+
+// renderStorageKeys renders the access keys of a monitoring storage
+func renderStorageKeys(browser *storageKeyBrowser, cursor int) string {
+	var b strings.Builder
+
+	b.WriteString(selectedStyle.Render(browser.ref.Title()))
+	b.WriteString("\n\n")
+	b.WriteString(fmt.Sprintf("Ingestion:   %s\n", browser.ref.Address))
+
+	if k := browser.created; k != nil {
+		b.WriteString("\n")
+		b.WriteString(otherStatusStyle.Render("New access key (the secret is not shown again):"))
+		b.WriteString("\n")
+		b.WriteString(fmt.Sprintf("  UID:    %s\n", k.UID))
+		b.WriteString(fmt.Sprintf("  Token:  %s\n", k.Token))
+		b.WriteString(fmt.Sprintf("  Secret: %s\n", k.Secret))
+	}
+
+	switch {
+	case browser.err != nil:
+		b.WriteString(fmt.Sprintf("\nError: %v\n", browser.err))
+		return b.String()
+	case !browser.loaded:
+		b.WriteString("\nLoading access keys...\n")
+		return b.String()
+	}
+
+	b.WriteString(fmt.Sprintf("\nAccess Keys: %d\n", len(browser.keys)))
+	if len(browser.keys) > 0 {
+		b.WriteString(fmt.Sprintf("    %-36s %-18s %s\n", "UID", "Token", "Description"))
+	}
+	for i, k := range browser.keys {
+		line := fmt.Sprintf("%-36s %-18s %s", k.UID, k.MaskedToken(), k.Description)
+		if i == cursor {
+			b.WriteString(selectedItemStyle.Render("  > " + line))
+		} else {
+			b.WriteString("    " + line)
+		}
+		b.WriteString("\n")
+	}
+
+	if browser.snippet != "" {
+		b.WriteString("\nIngestion config (copied to the clipboard):\n\n")
+		b.WriteString(browser.snippet)
+	}
+	return b.String()
+}
+
+// traceNameWidth is the width of the indented span names in the waterfall
+const traceNameWidth = 40
+
+// renderTraceExplorer renders the trace search results, or the span waterfall of the selected trace
+func renderTraceExplorer(detail *MonitoringTraceStorageDetail, e *traceExplorer, cursor int) string {
+	var b strings.Builder
+
+	b.WriteString(selectedStyle.Render(fmt.Sprintf("Trace Storage: %s", getOptString(detail.Name))))
+	b.WriteString("\n\n")
+
+	row := func(i int, line string) {
+		if i == cursor {
+			b.WriteString(selectedItemStyle.Render("  > " + line))
+		} else {
+			b.WriteString("    " + line)
+		}
+		b.WriteString("\n")
+	}
+
+	if t := e.trace; t != nil {
+		b.WriteString(fmt.Sprintf("Trace:     %s\n", t.ID))
+		b.WriteString(fmt.Sprintf("Started:   %s\n", t.Start().Format("2006-01-02 15:04:05.000")))
+		b.WriteString(fmt.Sprintf("Duration:  %s\n", formatTraceDuration(t.Duration())))
+		b.WriteString(fmt.Sprintf("Spans:     %d (%s)\n\n", len(t.Spans), strings.Join(t.Services(), ", ")))
+
+		start, total := t.Start(), t.Duration()
+		b.WriteString(fmt.Sprintf("    %-*s %9s %9s  %s\n", traceNameWidth, "Span", "Offset", "Duration", "Timeline"))
+		for i, r := range e.rows {
+			name := strings.Repeat("  ", r.Depth) + r.Span.Name
+			if r.Span.Service != "" && (r.Depth == 0 || r.Span.Service != e.rows[parentRow(e.rows, i)].Span.Service) {
+				// Show the service where the trace enters it
+				name += " [" + r.Span.Service + "]"
+			}
+			line := fmt.Sprintf("%-*s %9s %9s  %s", traceNameWidth, truncateText(name, traceNameWidth),
+				formatTraceDuration(r.Span.Start.Sub(start)), formatTraceDuration(r.Span.Duration()),
+				spanBar(r.Span, start, total, chartWidth))
+			if r.Span.Status == "error" && i != cursor {
+				line = errorStyle.Render(line)
+			}
+			row(i, line)
+		}
+
+		if cursor < len(e.rows) {
+			s := e.rows[cursor].Span
+			b.WriteString(fmt.Sprintf("\nSpan:      %s\n", s.Name))
+			b.WriteString(fmt.Sprintf("Service:   %s\n", s.Service))
+			if s.Kind != "" {
+				b.WriteString(fmt.Sprintf("Kind:      %s\n", s.Kind))
+			}
+			b.WriteString(fmt.Sprintf("Span ID:   %s\n", s.SpanID))
+			b.WriteString(fmt.Sprintf("Timing:    +%s, %s\n", formatTraceDuration(s.Start.Sub(start)), formatTraceDuration(s.Duration())))
+			if s.Status != "" {
+				status := s.Status
+				if s.StatusMessage != "" {
+					status += ": " + s.StatusMessage
+				}
+				if s.Status == "error" {
+					status = errorStyle.Render(status)
+				}
+				b.WriteString(fmt.Sprintf("Status:    %s\n", status))
+			}
+			if len(s.Attributes) > 0 {
+				b.WriteString("Attributes:\n")
+				for _, a := range s.Attributes {
+					b.WriteString(fmt.Sprintf("  %s = %s\n", a.Key, a.Value))
+				}
+			}
+		}
+		return b.String()
+	}
+
+	b.WriteString(fmt.Sprintf("Endpoint:  %s\n", e.endpoint))
+	b.WriteString(fmt.Sprintf("Search:    %s\n", e.search))
+	b.WriteString(fmt.Sprintf("Window:    last %s\n", TraceSearchWindows[e.window].Label))
+
+	if e.err != nil {
+		b.WriteString(fmt.Sprintf("\nError: %v\n", e.err))
+		return b.String()
+	}
+	if e.client == nil {
+		return b.String()
+	}
+	b.WriteString(fmt.Sprintf("Traces:    %d\n\n", len(e.traces)))
+	if len(e.traces) == 0 {
+		return b.String()
+	}
+	if len(e.traces) == traceSearchLimit {
+		b.WriteString(fmt.Sprintf("  (showing the latest %d traces; narrow the search or window to see older ones)\n", traceSearchLimit))
+	}
+	b.WriteString(fmt.Sprintf("    %-19s %-16s %-32s %10s  %s\n", "Started", "Service", "Root span", "Duration", "Trace ID"))
+	for i, t := range e.traces {
+		row(i, fmt.Sprintf("%-19s %-16s %-32s %10s  %s", t.Start.Format("2006-01-02 15:04:05"),
+			truncateText(t.RootService, 16), truncateText(t.RootName, 32), formatTraceDuration(t.Duration), t.TraceID))
+	}
+	return b.String()
+}
+
+// truncateText shortens s to width runes, marking the cut with "..."
+func truncateText(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	return string(runes[:width-3]) + "..."
+}
+
+// parentRow returns the index of the row of the parent of rows[i], or i for a root
+func parentRow(rows []SpanRow, i int) int {
+	for j := i - 1; j >= 0; j-- {
+		if rows[j].Depth < rows[i].Depth {
+			return j
+		}
+	}
+	return i
+}
+
+// spanBar draws the time a span took within a trace on a timeline of the given width
+func spanBar(s Span, traceStart time.Time, total time.Duration, width int) string {
+	if total <= 0 {
+		return strings.Repeat("█", width)
+	}
+	from := int(int64(width) * int64(s.Start.Sub(traceStart)) / int64(total))
+	to := int(int64(width) * int64(s.End.Sub(traceStart)) / int64(total))
+	from = min(max(from, 0), width-1)
+	to = min(max(to, from+1), width)
+	return strings.Repeat("·", from) + strings.Repeat("█", to-from) + strings.Repeat("·", width-to)
+}
+
+// renderHealthTimeline renders response time samples as a coloured up/down bar
+func renderHealthTimeline(samples []SimpleMonitorResponseTime) string {
+	width := chartWidth
+	if len(samples) < width {
+		width = len(samples)
+	}
+
+	var b strings.Builder
+	for i := 0; i < width; i++ {
+		start := i * len(samples) / width
+		end := (i + 1) * len(samples) / width
+		down := false
+		for _, sample := range samples[start:end] {
+			if sample.Sec <= 0 {
+				down = true
+				break
+			}
+		}
+		if down {
+			b.WriteString(errorStyle.Render("█"))
+		} else {
+			b.WriteString(upStatusStyle.Render("█"))
+		}
+	}
+	return b.String()
+}
+
+// formatWindow formats a time window as a short string such as "6h" or "7d"
+func formatWindow(d time.Duration) string {
+	if d >= 24*time.Hour && d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	}
+	return fmt.Sprintf("%dh", int(d/time.Hour))
+}
+
+// renderAppRunContainerPlacement renders the containers of an application grouped by worker node
+func renderAppRunContainerPlacement(p *AppRunContainerPlacement, cursor int) string {
+	var b strings.Builder
+
+	b.WriteString(selectedStyle.Render(fmt.Sprintf("AppRun Containers: %s", p.App.Name)))
+	b.WriteString("\n\n")
+
+	b.WriteString(fmt.Sprintf("Application:    %s\n", p.App.ID))
+	b.WriteString(fmt.Sprintf("Active Version: v%d\n", p.App.ActiveVersion))
+	b.WriteString(fmt.Sprintf("Containers:     %d running on %d nodes (desired: %d)\n", p.ContainerCount(), len(p.Nodes), p.App.DesiredCount))
+
+	if len(p.Nodes) == 0 {
+		b.WriteString("\nNo containers are placed.\n")
+		return b.String()
+	}
+
+	for i, node := range p.Nodes {
+		name := node.NodeID
+		location := "(unknown node)"
+		if wn := node.WorkerNode; wn != nil {
+			if wn.ResourceID != "" {
+				name = wn.ResourceID
+			}
+			location = fmt.Sprintf("ASG %s, %s", node.ASGName, wn.Status)
+			if wn.Draining {
+				location += ", draining"
+			}
+		}
+		line := fmt.Sprintf("Node %s [%s]", name, location)
+		b.WriteString("\n")
+		if i == cursor {
+			b.WriteString(selectedItemStyle.Render("> " + line))
+		} else {
+			b.WriteString("  " + line)
+		}
+		b.WriteString("\n")
+
+		if len(node.Containers) > 0 {
+			b.WriteString(fmt.Sprintf("    %-14s %-8s %-10s %-20s %6s  %s\n", "Container", "Version", "State", "Status", "CPU", "Image"))
+		}
+		for _, ct := range node.Containers {
+			id := ct.ID
+			if len(id) > 12 {
+				id = id[:12]
+			}
+			stateStyle := otherStatusStyle
+			switch ct.State {
+			case "running":
+				stateStyle = upStatusStyle
+			case "exited", "dead":
+				stateStyle = errorStyle
+			}
+			versionStr := fmt.Sprintf("v%d", ct.Version)
+			if ct.Version == int64(p.App.ActiveVersion) {
+				versionStr += "*"
+			}
+			b.WriteString(fmt.Sprintf("    %-14s %-8s %s %-20s %5.1f%%  %s\n",
+				id,
+				versionStr,
+				stateStyle.Render(fmt.Sprintf("%-10s", ct.State)),
+				ct.Status,
+				ct.CPUUsagePercent,
+				ct.Image))
+		}
+		for _, d := range node.Desired {
+			b.WriteString(fmt.Sprintf("    %-14s %-8s %s %dm CPU, %d MB  %s\n",
+				"(pending)",
+				fmt.Sprintf("v%d", d.Version),
+				otherStatusStyle.Render(fmt.Sprintf("%-10s", "scheduled")),
+				d.CPUMillis,
+				d.MemoryMB,
+				d.Image))
+		}
+		if node.CollectedAt != "" {
+			b.WriteString(fmt.Sprintf("    collected at %s\n", node.CollectedAt))
+		}
+	}
+
+	b.WriteString("\n* active version\n")
+	return b.String()
+}
+
+// renderAppRunCertificateDetail renders a cluster certificate with its validity period
+func renderAppRunCertificateDetail(cert *AppRunCertificate, now time.Time) string {
+	var b strings.Builder
+
+	b.WriteString(selectedStyle.Render(fmt.Sprintf("AppRun Certificate: %s", cert.Name)))
+	b.WriteString("\n\n")
+
+	b.WriteString(fmt.Sprintf("ID:          %s\n", cert.ID))
+	b.WriteString(fmt.Sprintf("Common Name: %s\n", cert.CommonName))
+	if len(cert.SANs) > 0 {
+		b.WriteString("SANs:\n")
+		for _, san := range cert.SANs {
+			b.WriteString(fmt.Sprintf("  - %s\n", san))
+		}
+	}
+
+	b.WriteString("\n")
+	if !cert.NotBefore.IsZero() {
+		b.WriteString(fmt.Sprintf("Not Before:  %s\n", cert.NotBefore.Local().Format("2006-01-02 15:04:05")))
+	}
+	if !cert.NotAfter.IsZero() {
+		b.WriteString(fmt.Sprintf("Not After:   %s\n", cert.NotAfter.Local().Format("2006-01-02 15:04:05")))
+	}
+	status := cert.ExpiryStatus(now)
+	switch left := cert.NotAfter.Sub(now); {
+	case cert.NotAfter.IsZero():
+	case left <= 0:
+		status = errorStyle.Render(status)
+	case left < appRunCertificateExpiryWarning:
+		status = otherStatusStyle.Render(status)
+	}
+	b.WriteString(fmt.Sprintf("Status:      %s\n", status))
+
+	if cert.CreatedAt != "" {
+		b.WriteString(fmt.Sprintf("\nCreated:     %s\n", cert.CreatedAt))
+	}
+	if cert.UpdatedAt != "" {
+		b.WriteString(fmt.Sprintf("Updated:     %s\n", cert.UpdatedAt))
+	}
+
+	return b.String()
+}
+
+// observeDefaultWidth is used to lay out the observe view before the window size is known
+const observeDefaultWidth = 120
+
+// renderObservation renders the storages a resource is routed to, and its recent logs and
+// metrics side by side
+func renderObservation(v *observeView, cursor, width int) string {
+	var b strings.Builder
+
+	b.WriteString(selectedStyle.Render("Observe " + v.target.Title()))
+	b.WriteString("\n\n")
+	b.WriteString(fmt.Sprintf("Resource IDs: %s\n", strings.Join(v.target.ResourceIDs, ", ")))
+	b.WriteString(fmt.Sprintf("Window:       last %s\n", observeWindow.Label))
+
+	if v.err != nil {
+		b.WriteString(fmt.Sprintf("\nError: %v\n", v.err))
+		return b.String()
+	}
+	obs := v.obs
+	if obs == nil {
+		return b.String()
+	}
+
+	b.WriteString(fmt.Sprintf("\nStorages: %d\n", len(obs.Storages)))
+	if len(obs.Storages) == 0 {
+		b.WriteString("  (no log or metrics routing sends data of this resource; create one in the Monitoring Routing list)\n")
+		return b.String()
+	}
+	for i, s := range obs.Storages {
+		line := fmt.Sprintf("%-48s via %s", s.Ref.Title(), strings.Join(s.Variants, ", "))
+		if i == cursor {
+			b.WriteString(selectedItemStyle.Render("> "+line) + "\n")
+		} else {
+			b.WriteString("   " + line + "\n")
+		}
+	}
+	b.WriteString("\n")
+
+	if width <= 0 {
+		width = observeDefaultWidth
+	}
+	colWidth := max((width-2)/2, 20)
+	logs := renderObservedLogs(obs, colWidth)
+	metrics := renderObservedMetrics(obs, colWidth)
+	column := lipgloss.NewStyle().Width(colWidth)
+	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, column.Render(logs), "  ", column.Render(metrics)))
+	b.WriteString("\n")
+	return b.String()
+}
+
+func renderObservedLogs(obs *Observation, width int) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Logs: %d entries\n", len(obs.Logs)))
+	if obs.LogsErr != nil {
+		b.WriteString(errorStyle.Render(truncateText(fmt.Sprintf("Error: %v", obs.LogsErr), width)))
+		b.WriteString("\n")
+	}
+	// Newest first, so that the latest lines are visible without scrolling
+	for i := len(obs.Logs) - 1; i >= 0; i-- {
+		b.WriteString(renderLogLine(obs.Logs[i], "15:04:05", width))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func renderObservedMetrics(obs *Observation, width int) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Metrics: %d expressions\n", len(obs.Metrics)))
+	if obs.MetricsErr != nil {
+		b.WriteString(errorStyle.Render(truncateText(fmt.Sprintf("Error: %v", obs.MetricsErr), width)))
+		b.WriteString("\n")
+	}
+	for _, metric := range obs.Metrics {
+		name := metric.Name
+		if metric.Total > len(metric.Series) {
+			name += fmt.Sprintf(" (%d of %d series)", len(metric.Series), metric.Total)
+		}
+		b.WriteString(selectedStyle.Render(truncateText(name, width)))
+		b.WriteString("\n")
+		if metric.Err != nil {
+			b.WriteString(errorStyle.Render(truncateText(fmt.Sprintf("  Error: %v", metric.Err), width)))
+			b.WriteString("\n")
+		}
+		if len(metric.Series) == 0 && metric.Err == nil {
+			b.WriteString("  (no data)\n")
+		}
+		for _, series := range metric.Series {
+			b.WriteString(truncateText("  "+formatPromLabels(series.Labels), width))
+			b.WriteString("\n")
+			values := make([]float64, len(series.Samples))
+			for i, s := range series.Samples {
+				values[i] = s.Value
+			}
+			if len(values) == 0 {
+				b.WriteString("    (no data)\n")
+				continue
+			}
+			last := formatPromValue(values[len(values)-1])
+			b.WriteString(fmt.Sprintf("    %s %s\n", sparkline(values, max(width-len(last)-5, 1)), last))
+		}
+	}
+	return b.String()
+}
+
+// renderRelatedLinks renders the related IDs of a detail with the selected one highlighted
+func renderRelatedLinks(links []relatedLink, cursor int) string {
+	if len(links) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\nRelated:\n")
+	for i, link := range links {
+		line := fmt.Sprintf("%-14s %s (%s)", link.Label+":", link.ID, link.ResourceType)
+		if link.Zone != "" {
+			line = fmt.Sprintf("%-14s %s (%s in %s)", link.Label+":", link.ID, link.ResourceType, link.Zone)
+		}
+		if i == cursor {
+			b.WriteString(selectedItemStyle.Render("> "+line) + "\n")
+		} else {
+			b.WriteString("   " + line + "\n")
+		}
+	}
+	return b.String()
+}
+
+// renderTopology renders the network topology of a zone in the selected format
+func renderTopology(v *topologyView) string {
+	var b strings.Builder
+	t := v.topology
+
+	b.WriteString(selectedStyle.Render(fmt.Sprintf("Topology: %s", t.Zone)))
+	b.WriteString("\n\n")
+	b.WriteString(fmt.Sprintf("Switches: %d | Shared segment interfaces: %d | Format: %s\n\n", len(t.Segments), len(t.Shared), TopologyFormats[v.format]))
+	if len(t.Segments) == 0 && len(t.Shared) == 0 {
+		b.WriteString("  (no switches or connected resources in this zone)\n")
+		return b.String()
+	}
+	b.WriteString(t.Render(TopologyFormats[v.format]))
+	return b.String()
+}
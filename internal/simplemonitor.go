@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/sacloud/iaas-api-go"
 	"github.com/sacloud/iaas-api-go/search"
@@ -41,6 +42,87 @@ type SimpleMonitorDetail struct {
 	LastCheckedAt       string
 	LastHealthChangedAt string
 	LatestLogs          []string
+	Window              time.Duration
+	ResponseTimes       []SimpleMonitorResponseTime
+	HealthChanges       []SimpleMonitorHealthChange
+	MonitorErrorMsg     string
+}
+
+// SimpleMonitorResponseTime is a single response time sample
+type SimpleMonitorResponseTime struct {
+	Time time.Time
+	Sec  float64 // 0 when the check did not respond
+}
+
+// SimpleMonitorHealthChange marks the start of a period with the given health
+type SimpleMonitorHealthChange struct {
+	Time   time.Time
+	Health string // "UP" or "DOWN"
+}
+
+// SimpleMonitorWindows are the selectable time ranges for response time history
+var SimpleMonitorWindows = []time.Duration{
+	time.Hour,
+	6 * time.Hour,
+	24 * time.Hour,
+	7 * 24 * time.Hour,
+}
+
+// IsUnhealthy returns true if the last health check did not succeed
+func (s SimpleMonitor) IsUnhealthy() bool {
+	return s.Health != "" && s.Health != string(types.SimpleMonitorHealth.Up)
+}
+
+// buildHealthTimeline derives health changes from response time samples.
+// A sample without a response is treated as DOWN.
+func buildHealthTimeline(samples []SimpleMonitorResponseTime) []SimpleMonitorHealthChange {
+	var changes []SimpleMonitorHealthChange
+	for _, sample := range samples {
+		health := string(types.SimpleMonitorHealth.Up)
+		if sample.Sec <= 0 {
+			health = string(types.SimpleMonitorHealth.Down)
+		}
+		if len(changes) == 0 || changes[len(changes)-1].Health != health {
+			changes = append(changes, SimpleMonitorHealthChange{Time: sample.Time, Health: health})
+		}
+	}
+	return changes
+}
+
+// simpleMonitorHasPort reports whether the health check of the protocol connects to a port
+func simpleMonitorHasPort(protocol string) bool {
+	switch types.ESimpleMonitorProtocol(protocol) {
+	case types.SimpleMonitorProtocols.HTTP, types.SimpleMonitorProtocols.HTTPS, types.SimpleMonitorProtocols.TCP,
+		types.SimpleMonitorProtocols.SSH, types.SimpleMonitorProtocols.SMTP, types.SimpleMonitorProtocols.POP3,
+		types.SimpleMonitorProtocols.FTP, types.SimpleMonitorProtocols.SSLCertificate:
+		return true
+	}
+	return false
+}
+
+// simpleMonitorIsHTTP reports whether the protocol checks a HTTP response, which is what
+// the path, host header and contains string apply to
+func simpleMonitorIsHTTP(protocol string) bool {
+	switch types.ESimpleMonitorProtocol(protocol) {
+	case types.SimpleMonitorProtocols.HTTP, types.SimpleMonitorProtocols.HTTPS:
+		return true
+	}
+	return false
+}
+
+// Params returns the editable check parameters of the monitor
+func (d *SimpleMonitorDetail) Params() SimpleMonitorParams {
+	return SimpleMonitorParams{
+		Description:      d.Desc,
+		DelayLoop:        d.DelayLoop,
+		MaxCheckAttempts: d.MaxCheckAttempts,
+		RetryInterval:    d.RetryInterval,
+		Timeout:          d.Timeout,
+		Port:             d.Port,
+		Path:             d.Path,
+		Host:             d.Host,
+		ContainsString:   d.ContainsString,
+	}
 }
 
 // Implement list.Item interface for SimpleMonitor
@@ -91,20 +173,37 @@ func (c *SakuraClient) ListSimpleMonitors(ctx context.Context) ([]SimpleMonitor,
 			Target:       sm.Target,
 			Protocol:     protocol,
 			Enabled:      bool(sm.Enabled),
-			Health:       "",
 			Availability: string(sm.Availability),
 		})
 	}
 
+	// Health is only reported for enabled monitors. It is fetched for the whole list, as the
+	// unhealthy filter needs it for every row.
+	forEachConcurrently(len(simpleMonitors), func(i int) {
+		sm := searched.SimpleMonitors[i]
+		if !sm.Enabled {
+			return
+		}
+		healthStatus, err := simpleMonitorOp.HealthStatus(ctx, sm.ID)
+		if err != nil {
+			slog.Warn("Failed to fetch simple monitor health status",
+				slog.String("simpleMonitorID", sm.ID.String()),
+				slog.Any("error", err))
+			return
+		}
+		simpleMonitors[i].Health = string(healthStatus.Health)
+	})
+
 	slog.Info("Successfully fetched simple monitors",
 		slog.Int("count", len(simpleMonitors)))
 
 	return simpleMonitors, nil
 }
 
-func (c *SakuraClient) GetSimpleMonitorDetail(ctx context.Context, simpleMonitorID string) (*SimpleMonitorDetail, error) {
+func (c *SakuraClient) GetSimpleMonitorDetail(ctx context.Context, simpleMonitorID string, window time.Duration) (*SimpleMonitorDetail, error) {
 	slog.Info("Fetching simple monitor detail from Sakura Cloud",
-		slog.String("simpleMonitorID", simpleMonitorID))
+		slog.String("simpleMonitorID", simpleMonitorID),
+		slog.Duration("window", window))
 
 	simpleMonitorOp := iaas.NewSimpleMonitorOp(c.caller)
 
@@ -196,8 +295,185 @@ func (c *SakuraClient) GetSimpleMonitorDetail(ctx context.Context, simpleMonitor
 		detail.LatestLogs = healthStatus.LatestLogs
 	}
 
+	// Get response time history
+	detail.Window = window
+	now := time.Now()
+	responseTime, err := simpleMonitorOp.MonitorResponseTime(ctx, id, &iaas.MonitorCondition{
+		Start: now.Add(-window),
+		End:   now,
+	})
+	if err != nil {
+		slog.Warn("Failed to fetch simple monitor response time",
+			slog.String("simpleMonitorID", simpleMonitorID),
+			slog.Any("error", err))
+		detail.MonitorErrorMsg = err.Error()
+	} else {
+		for _, v := range responseTime.Values {
+			detail.ResponseTimes = append(detail.ResponseTimes, SimpleMonitorResponseTime{
+				Time: v.Time,
+				Sec:  v.ResponseTimeSec,
+			})
+		}
+		detail.HealthChanges = buildHealthTimeline(detail.ResponseTimes)
+	}
+
 	slog.Info("Successfully fetched simple monitor detail",
 		slog.String("simpleMonitorID", simpleMonitorID))
 
 	return detail, nil
 }
+
+// SimpleMonitorParams holds the editable check parameters of a simple monitor
+type SimpleMonitorParams struct {
+	Description      string
+	DelayLoop        int
+	MaxCheckAttempts int
+	RetryInterval    int
+	Timeout          int
+	Port             int
+	Path             string
+	Host             string
+	ContainsString   string
+}
+
+// apply copies the parameters onto a simple monitor settings. The port and HTTP fields are
+// only written when the protocol of the health check uses them.
+func (p SimpleMonitorParams) apply(sm *iaas.SimpleMonitor) {
+	sm.Description = p.Description
+	sm.DelayLoop = p.DelayLoop
+	sm.MaxCheckAttempts = p.MaxCheckAttempts
+	sm.RetryInterval = p.RetryInterval
+	sm.Timeout = p.Timeout
+	if sm.HealthCheck == nil {
+		sm.HealthCheck = &iaas.SimpleMonitorHealthCheck{}
+	}
+	protocol := string(sm.HealthCheck.Protocol)
+	if simpleMonitorHasPort(protocol) {
+		sm.HealthCheck.Port = types.StringNumber(p.Port)
+	}
+	if simpleMonitorIsHTTP(protocol) {
+		sm.HealthCheck.Path = p.Path
+		sm.HealthCheck.Host = p.Host
+		sm.HealthCheck.ContainsString = p.ContainsString
+	}
+}
+
+// simpleMonitorUpdateRequest builds an update request that keeps all current settings
+func simpleMonitorUpdateRequest(sm *iaas.SimpleMonitor) *iaas.SimpleMonitorUpdateRequest {
+	return &iaas.SimpleMonitorUpdateRequest{
+		Description:        sm.Description,
+		Tags:               sm.Tags,
+		IconID:             sm.IconID,
+		MaxCheckAttempts:   sm.MaxCheckAttempts,
+		RetryInterval:      sm.RetryInterval,
+		DelayLoop:          sm.DelayLoop,
+		Enabled:            sm.Enabled,
+		HealthCheck:        sm.HealthCheck,
+		NotifyEmailEnabled: sm.NotifyEmailEnabled,
+		NotifyEmailHTML:    sm.NotifyEmailHTML,
+		NotifySlackEnabled: sm.NotifySlackEnabled,
+		SlackWebhooksURL:   sm.SlackWebhooksURL,
+		NotifyInterval:     sm.NotifyInterval,
+		Timeout:            sm.Timeout,
+		MonitoringSuiteLog: sm.MonitoringSuiteLog,
+		SettingsHash:       sm.SettingsHash,
+	}
+}
+
+// updateSimpleMonitor reads the current settings, applies modify and writes them back
+func (c *SakuraClient) updateSimpleMonitor(ctx context.Context, simpleMonitorID string, modify func(sm *iaas.SimpleMonitor)) error {
+	simpleMonitorOp := iaas.NewSimpleMonitorOp(c.caller)
+
+	id := types.StringID(simpleMonitorID)
+
+	sm, err := simpleMonitorOp.Read(ctx, id)
+	if err != nil {
+		slog.Error("Failed to fetch simple monitor",
+			slog.String("simpleMonitorID", simpleMonitorID),
+			slog.Any("error", err))
+		return err
+	}
+
+	modify(sm)
+
+	if _, err := simpleMonitorOp.Update(ctx, id, simpleMonitorUpdateRequest(sm)); err != nil {
+		slog.Error("Failed to update simple monitor",
+			slog.String("simpleMonitorID", simpleMonitorID),
+			slog.Any("error", err))
+		return err
+	}
+	return nil
+}
+
+// SetSimpleMonitorEnabled enables or disables a simple monitor
+func (c *SakuraClient) SetSimpleMonitorEnabled(ctx context.Context, simpleMonitorID string, enabled bool) error {
+	slog.Info("Updating simple monitor enabled flag",
+		slog.String("simpleMonitorID", simpleMonitorID),
+		slog.Bool("enabled", enabled))
+
+	return c.updateSimpleMonitor(ctx, simpleMonitorID, func(sm *iaas.SimpleMonitor) {
+		sm.Enabled = types.StringFlag(enabled)
+	})
+}
+
+// UpdateSimpleMonitorParams updates the check parameters of a simple monitor
+func (c *SakuraClient) UpdateSimpleMonitorParams(ctx context.Context, simpleMonitorID string, params SimpleMonitorParams) error {
+	slog.Info("Updating simple monitor parameters",
+		slog.String("simpleMonitorID", simpleMonitorID))
+
+	return c.updateSimpleMonitor(ctx, simpleMonitorID, params.apply)
+}
+
+// CreateSimpleMonitorFrom creates a new simple monitor for target, copying settings from an existing one
+func (c *SakuraClient) CreateSimpleMonitorFrom(ctx context.Context, sourceID, target string, params SimpleMonitorParams) (string, error) {
+	slog.Info("Creating simple monitor from template",
+		slog.String("sourceID", sourceID),
+		slog.String("target", target))
+
+	simpleMonitorOp := iaas.NewSimpleMonitorOp(c.caller)
+
+	source, err := simpleMonitorOp.Read(ctx, types.StringID(sourceID))
+	if err != nil {
+		slog.Error("Failed to fetch template simple monitor",
+			slog.String("sourceID", sourceID),
+			slog.Any("error", err))
+		return "", err
+	}
+
+	// Copy the health check so that the template is not modified
+	if source.HealthCheck != nil {
+		healthCheck := *source.HealthCheck
+		source.HealthCheck = &healthCheck
+	}
+	params.apply(source)
+
+	created, err := simpleMonitorOp.Create(ctx, &iaas.SimpleMonitorCreateRequest{
+		Target:             target,
+		MaxCheckAttempts:   source.MaxCheckAttempts,
+		RetryInterval:      source.RetryInterval,
+		DelayLoop:          source.DelayLoop,
+		Enabled:            source.Enabled,
+		HealthCheck:        source.HealthCheck,
+		NotifyEmailEnabled: source.NotifyEmailEnabled,
+		NotifyEmailHTML:    source.NotifyEmailHTML,
+		NotifySlackEnabled: source.NotifySlackEnabled,
+		SlackWebhooksURL:   source.SlackWebhooksURL,
+		NotifyInterval:     source.NotifyInterval,
+		Timeout:            source.Timeout,
+		MonitoringSuiteLog: source.MonitoringSuiteLog,
+		Description:        source.Description,
+		Tags:               source.Tags,
+		IconID:             source.IconID,
+	})
+	if err != nil {
+		slog.Error("Failed to create simple monitor",
+			slog.String("target", target),
+			slog.Any("error", err))
+		return "", err
+	}
+
+	slog.Info("Successfully created simple monitor",
+		slog.String("simpleMonitorID", created.ID.String()))
+
+	return created.ID.String(), nil
+}
@@ -0,0 +1,317 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	monitoringsuite "github.com/sacloud/monitoring-suite-api-go"
+)
+
+// StorageKind is the kind of data a monitoring storage holds
+type StorageKind string
+
+const (
+	StorageKindLogs    StorageKind = "logs"
+	StorageKindMetrics StorageKind = "metrics"
+	StorageKindTraces  StorageKind = "traces"
+)
+
+// MonitoringStorageRef identifies a log, metrics or trace storage whose access keys are managed
+type MonitoringStorageRef struct {
+	Kind       StorageKind
+	ResourceID string
+	Name       string
+	// Address is the ingestion endpoint, host[:port] or a URL
+	Address  string
+	Insecure bool
+}
+
+// Label returns e.g. "Log Storage"
+func (k StorageKind) Label() string {
+	return map[StorageKind]string{
+		StorageKindLogs:    "Log Storage",
+		StorageKindMetrics: "Metrics Storage",
+		StorageKindTraces:  "Trace Storage",
+	}[k]
+}
+
+// Title returns e.g. "Log Storage: app-logs"
+func (r MonitoringStorageRef) Title() string {
+	return fmt.Sprintf("%s: %s", r.Kind.Label(), r.Name)
+}
+
+// StorageAccessKey is an access key of a monitoring storage
+type StorageAccessKey struct {
+	UID         uuid.UUID
+	Description string
+	Token       string
+	Secret      string
+}
+
+// MaskedToken returns the token with all but its first characters hidden
+func (k StorageAccessKey) MaskedToken() string {
+	if len(k.Token) <= 8 {
+		return strings.Repeat("*", len(k.Token))
+	}
+	return k.Token[:8] + strings.Repeat("*", 8)
+}
+
+// IngestionSnippetFormats are the config formats of the ingestion snippets
+var IngestionSnippetFormats = map[string]string{
+	"otel":      "OpenTelemetry Collector",
+	"fluentbit": "Fluent Bit",
+}
+
+// ingestionTarget splits a storage address into scheme, host and port
+func ingestionTarget(address string, insecure bool) (scheme, host, port string) {
+	scheme = "https"
+	if insecure {
+		scheme = "http"
+	}
+	if s, rest, ok := strings.Cut(address, "://"); ok {
+		scheme, address = s, rest
+	}
+	address = strings.TrimRight(address, "/")
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		host, port = address, "443"
+		if scheme == "http" {
+			port = "80"
+		}
+	}
+	return scheme, host, port
+}
+
+// IngestionSnippet returns a ready-to-paste config block that sends data to the storage with key.
+// Logs and traces are sent over OTLP/HTTP and metrics with Prometheus remote write.
+func IngestionSnippet(ref MonitoringStorageRef, key StorageAccessKey, format string) (string, error) {
+	scheme, host, port := ingestionTarget(ref.Address, ref.Insecure)
+	if host == "" {
+		return "", fmt.Errorf("%s has no ingestion endpoint", ref.Title())
+	}
+	hostPort := host
+	if !(scheme == "https" && port == "443") && !(scheme == "http" && port == "80") {
+		hostPort = net.JoinHostPort(host, port)
+	}
+	name := strings.ReplaceAll(ref.Name, " ", "_")
+	if name == "" {
+		name = ref.ResourceID
+	}
+
+	var b strings.Builder
+	switch format {
+	case "otel":
+		exporter := "otlphttp/" + name
+		endpoint := fmt.Sprintf("%s://%s", scheme, hostPort)
+		if ref.Kind == StorageKindMetrics {
+			exporter = "prometheusremotewrite/" + name
+			endpoint += "/prometheus/api/v1/write"
+		}
+		b.WriteString("exporters:\n")
+		fmt.Fprintf(&b, "  %s:\n", exporter)
+		fmt.Fprintf(&b, "    endpoint: %s\n", endpoint)
+		b.WriteString("    headers:\n")
+		fmt.Fprintf(&b, "      Authorization: \"Bearer %s\"\n", key.Token)
+		b.WriteString("\nservice:\n")
+		b.WriteString("  pipelines:\n")
+		fmt.Fprintf(&b, "    %s:\n", ref.Kind)
+		b.WriteString("      receivers: [otlp]\n")
+		fmt.Fprintf(&b, "      exporters: [%s]\n", exporter)
+	case "fluentbit":
+		output, uriKey, uri := "opentelemetry", "Logs_uri", "/v1/logs"
+		switch ref.Kind {
+		case StorageKindMetrics:
+			output, uriKey, uri = "prometheus_remote_write", "Uri", "/prometheus/api/v1/write"
+		case StorageKindTraces:
+			uriKey, uri = "Traces_uri", "/v1/traces"
+		}
+		tls := "On"
+		if scheme == "http" {
+			tls = "Off"
+		}
+		b.WriteString("[OUTPUT]\n")
+		fmt.Fprintf(&b, "    Name      %s\n", output)
+		b.WriteString("    Match     *\n")
+		fmt.Fprintf(&b, "    Host      %s\n", host)
+		fmt.Fprintf(&b, "    Port      %s\n", port)
+		fmt.Fprintf(&b, "    %-9s %s\n", uriKey, uri)
+		fmt.Fprintf(&b, "    Header    Authorization Bearer %s\n", key.Token)
+		fmt.Fprintf(&b, "    Tls       %s\n", tls)
+	default:
+		return "", fmt.Errorf("unknown snippet format %q", format)
+	}
+	return b.String(), nil
+}
+
+// ListMonitoringStorageKeys returns the access keys of a storage
+func (c *SakuraClient) ListMonitoringStorageKeys(ctx context.Context, ref MonitoringStorageRef) ([]StorageAccessKey, error) {
+	slog.Info("Fetching storage access keys", slog.String("kind", string(ref.Kind)), slog.String("resourceID", ref.ResourceID))
+
+	monClient, err := c.getMonitoringClient()
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []StorageAccessKey
+	switch ref.Kind {
+	case StorageKindLogs:
+		list, err := monitoringsuite.NewLogsStorageOp(monClient).ListKeys(ctx, ref.ResourceID, nil, nil)
+		if err != nil {
+			slog.Error("Failed to fetch log storage access keys", slog.Any("error", err))
+			return nil, err
+		}
+		for _, k := range list {
+			keys = append(keys, StorageAccessKey{UID: k.UID, Description: getOptString(k.Description), Token: k.Token, Secret: k.Secret.String()})
+		}
+	case StorageKindMetrics:
+		list, err := monitoringsuite.NewMetricsStorageOp(monClient).ListKeys(ctx, ref.ResourceID, nil, nil)
+		if err != nil {
+			slog.Error("Failed to fetch metrics storage access keys", slog.Any("error", err))
+			return nil, err
+		}
+		for _, k := range list {
+			keys = append(keys, StorageAccessKey{UID: k.UID, Description: getOptString(k.Description), Token: k.Token, Secret: k.Secret.String()})
+		}
+	case StorageKindTraces:
+		list, err := monitoringsuite.NewTracesStorageOp(monClient).ListKeys(ctx, ref.ResourceID, nil, nil)
+		if err != nil {
+			slog.Error("Failed to fetch trace storage access keys", slog.Any("error", err))
+			return nil, err
+		}
+		for _, k := range list {
+			keys = append(keys, StorageAccessKey{UID: k.UID, Description: getOptString(k.Description), Token: k.Token, Secret: k.Secret})
+		}
+	default:
+		return nil, fmt.Errorf("unknown storage kind %q", ref.Kind)
+	}
+	return keys, nil
+}
+
+// GetMonitoringStorageToken returns the token of the first access key of a storage,
+// which the query APIs are called with when no token is configured
+func (c *SakuraClient) GetMonitoringStorageToken(ctx context.Context, ref MonitoringStorageRef) (string, error) {
+	keys, err := c.ListMonitoringStorageKeys(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	if len(keys) == 0 {
+		return "", fmt.Errorf("%s %s has no access key", strings.ToLower(ref.Kind.Label()), ref.ResourceID)
+	}
+	return keys[0].Token, nil
+}
+
+// CreateMonitoringStorageKey creates an access key. The secret is only returned here.
+func (c *SakuraClient) CreateMonitoringStorageKey(ctx context.Context, ref MonitoringStorageRef, description string) (*StorageAccessKey, error) {
+	slog.Info("Creating storage access key", slog.String("kind", string(ref.Kind)), slog.String("resourceID", ref.ResourceID))
+
+	monClient, err := c.getMonitoringClient()
+	if err != nil {
+		return nil, err
+	}
+
+	var desc *string
+	if description != "" {
+		desc = &description
+	}
+	var key StorageAccessKey
+	switch ref.Kind {
+	case StorageKindLogs:
+		k, err := monitoringsuite.NewLogsStorageOp(monClient).CreateKey(ctx, ref.ResourceID, desc)
+		if err != nil {
+			slog.Error("Failed to create log storage access key", slog.Any("error", err))
+			return nil, err
+		}
+		key = StorageAccessKey{UID: k.UID, Description: getOptString(k.Description), Token: k.Token, Secret: k.Secret.String()}
+	case StorageKindMetrics:
+		k, err := monitoringsuite.NewMetricsStorageOp(monClient).CreateKey(ctx, ref.ResourceID, desc)
+		if err != nil {
+			slog.Error("Failed to create metrics storage access key", slog.Any("error", err))
+			return nil, err
+		}
+		key = StorageAccessKey{UID: k.UID, Description: getOptString(k.Description), Token: k.Token, Secret: k.Secret.String()}
+	case StorageKindTraces:
+		k, err := monitoringsuite.NewTracesStorageOp(monClient).CreateKey(ctx, ref.ResourceID, desc)
+		if err != nil {
+			slog.Error("Failed to create trace storage access key", slog.Any("error", err))
+			return nil, err
+		}
+		key = StorageAccessKey{UID: k.UID, Description: getOptString(k.Description), Token: k.Token, Secret: k.Secret}
+	default:
+		return nil, fmt.Errorf("unknown storage kind %q", ref.Kind)
+	}
+	return &key, nil
+}
+
+// DeleteMonitoringStorageKey revokes an access key
+func (c *SakuraClient) DeleteMonitoringStorageKey(ctx context.Context, ref MonitoringStorageRef, uid uuid.UUID) error {
+	slog.Info("Deleting storage access key", slog.String("kind", string(ref.Kind)), slog.String("resourceID", ref.ResourceID), slog.String("uid", uid.String()))
+
+	monClient, err := c.getMonitoringClient()
+	if err != nil {
+		return err
+	}
+
+	switch ref.Kind {
+	case StorageKindLogs:
+		err = monitoringsuite.NewLogsStorageOp(monClient).DeleteKey(ctx, ref.ResourceID, uid)
+	case StorageKindMetrics:
+		err = monitoringsuite.NewMetricsStorageOp(monClient).DeleteKey(ctx, ref.ResourceID, uid)
+	case StorageKindTraces:
+		err = monitoringsuite.NewTracesStorageOp(monClient).DeleteKey(ctx, ref.ResourceID, uid)
+	default:
+		return fmt.Errorf("unknown storage kind %q", ref.Kind)
+	}
+	if err != nil {
+		slog.Error("Failed to delete storage access key", slog.Any("error", err))
+	}
+	return err
+}
+
+// StorageRef returns the reference used to manage the storage and its access keys
+func (s MonitoringLogStorage) StorageRef() MonitoringStorageRef {
+	return MonitoringStorageRef{
+		Kind:       StorageKindLogs,
+		ResourceID: getNilInt64AsString(s.ResourceID),
+		Name:       getOptString(s.Name),
+		Address:    s.Endpoints.Ingester.Address,
+		Insecure:   s.Endpoints.Ingester.Insecure.Or(false),
+	}
+}
+
+// StorageRef returns the reference used to manage the storage and its access keys
+func (s MonitoringMetricsStorage) StorageRef() MonitoringStorageRef {
+	return MonitoringStorageRef{
+		Kind:       StorageKindMetrics,
+		ResourceID: getNilInt64AsString(s.ResourceID),
+		Name:       getOptString(s.Name),
+		Address:    s.Endpoints.Address,
+	}
+}
+
+// StorageRef returns the reference used to manage the storage and its access keys
+func (s MonitoringTraceStorage) StorageRef() MonitoringStorageRef {
+	return MonitoringStorageRef{
+		Kind:       StorageKindTraces,
+		ResourceID: strconv.FormatInt(s.ResourceID, 10),
+		Name:       getOptString(s.Name),
+		Address:    s.Endpoints.Ingester.Address,
+		Insecure:   s.Endpoints.Ingester.Insecure.Or(false),
+	}
+}
+
+func (d *MonitoringLogStorageDetail) StorageRef() MonitoringStorageRef {
+	return MonitoringLogStorage{LogStorage: d.LogStorage}.StorageRef()
+}
+
+func (d *MonitoringMetricsStorageDetail) StorageRef() MonitoringStorageRef {
+	return MonitoringMetricsStorage{MetricsStorage: d.MetricsStorage}.StorageRef()
+}
+
+func (d *MonitoringTraceStorageDetail) StorageRef() MonitoringStorageRef {
+	return MonitoringTraceStorage{TraceStorage: d.TraceStorage}.StorageRef()
+}
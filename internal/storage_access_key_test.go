@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIngestionSnippet(t *testing.T) {
+	key := StorageAccessKey{Token: "t0ken"}
+
+	logs := MonitoringStorageRef{Kind: StorageKindLogs, Name: "app-logs", Address: "logs.example.jp"}
+	snippet, err := IngestionSnippet(logs, key, "otel")
+	require.NoError(t, err)
+	assert.Equal(t, `exporters:
+  otlphttp/app-logs:
+    endpoint: https://logs.example.jp
+    headers:
+      Authorization: "Bearer t0ken"
+
+service:
+  pipelines:
+    logs:
+      receivers: [otlp]
+      exporters: [otlphttp/app-logs]
+`, snippet)
+
+	snippet, err = IngestionSnippet(logs, key, "fluentbit")
+	require.NoError(t, err)
+	assert.Equal(t, `[OUTPUT]
+    Name      opentelemetry
+    Match     *
+    Host      logs.example.jp
+    Port      443
+    Logs_uri  /v1/logs
+    Header    Authorization Bearer t0ken
+    Tls       On
+`, snippet)
+
+	// Metrics are sent with Prometheus remote write
+	metrics := MonitoringStorageRef{Kind: StorageKindMetrics, Name: "app metrics", Address: "https://metrics.example.jp:8443/"}
+	snippet, err = IngestionSnippet(metrics, key, "otel")
+	require.NoError(t, err)
+	assert.Contains(t, snippet, "  prometheusremotewrite/app_metrics:\n    endpoint: https://metrics.example.jp:8443/prometheus/api/v1/write\n")
+	assert.Contains(t, snippet, "    metrics:\n")
+	snippet, err = IngestionSnippet(metrics, key, "fluentbit")
+	require.NoError(t, err)
+	assert.Contains(t, snippet, "Name      prometheus_remote_write\n")
+	assert.Contains(t, snippet, "Port      8443\n    Uri       /prometheus/api/v1/write\n")
+
+	traces := MonitoringStorageRef{Kind: StorageKindTraces, Name: "app-traces", Address: "localhost:4318", Insecure: true}
+	snippet, err = IngestionSnippet(traces, key, "fluentbit")
+	require.NoError(t, err)
+	assert.Contains(t, snippet, "Traces_uri /v1/traces\n")
+	assert.Contains(t, snippet, "Tls       Off\n")
+	snippet, err = IngestionSnippet(traces, key, "otel")
+	require.NoError(t, err)
+	assert.Contains(t, snippet, "endpoint: http://localhost:4318\n")
+
+	_, err = IngestionSnippet(MonitoringStorageRef{Kind: StorageKindLogs, Name: "empty"}, key, "otel")
+	assert.EqualError(t, err, "Log Storage: empty has no ingestion endpoint")
+}
+
+func TestMaskedToken(t *testing.T) {
+	assert.Equal(t, "abcdefgh********", StorageAccessKey{Token: "abcdefghijklmnop"}.MaskedToken())
+	assert.Equal(t, "****", StorageAccessKey{Token: "abcd"}.MaskedToken())
+}
@@ -0,0 +1,402 @@
+package internal
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sacloud/iaas-api-go"
+	"github.com/sacloud/iaas-api-go/types"
+)
+
+// TopologyFormats are the formats the topology screen shows and saves, in the order cycled through
+var TopologyFormats = []string{"tree", "dot", "mermaid"}
+
+// topologyKindOrder is the order nodes are listed in within a segment
+var topologyKindOrder = []string{"Router", "VPCRouter", "LoadBalancer", "Database", "NFS", "Server", "Bridge"}
+
+// TopologyNode is a resource connected to a network segment
+type TopologyNode struct {
+	Kind      string // Router, VPCRouter, LoadBalancer, Database, NFS, Server or Bridge
+	ID        string
+	Name      string
+	Interface string // e.g. eth1 or nic2
+	IPAddress string
+	Note      string
+}
+
+// TopologySegment is a switch and the resources connected to it
+type TopologySegment struct {
+	SwitchID   string
+	SwitchName string
+	Subnet     string
+	Nodes      []TopologyNode
+}
+
+// Topology is the network of a zone
+type Topology struct {
+	Zone     string
+	Segments []TopologySegment
+	// Shared are the interfaces connected to the shared segment (the internet)
+	Shared []TopologyNode
+}
+
+// topologySources are the resources of a zone a topology is built from
+type topologySources struct {
+	Switches      []*iaas.Switch
+	Servers       []*iaas.Server
+	Internet      []*iaas.Internet
+	VPCRouters    []VPCRouterDetail
+	LoadBalancers []*iaas.LoadBalancer
+	NFS           []*iaas.NFS
+	Databases     []*iaas.Database
+	Bridges       []BridgeDetail
+}
+
+// buildTopology groups the resources by the switch they are connected to
+func buildTopology(zone string, src topologySources) *Topology {
+	t := &Topology{Zone: zone}
+	index := map[string]int{}
+	segment := func(switchID string) *TopologySegment {
+		i, ok := index[switchID]
+		if !ok {
+			i = len(t.Segments)
+			index[switchID] = i
+			t.Segments = append(t.Segments, TopologySegment{SwitchID: switchID})
+		}
+		return &t.Segments[i]
+	}
+	connect := func(switchID string, node TopologyNode) {
+		if switchID == "" || switchID == "0" {
+			return
+		}
+		seg := segment(switchID)
+		seg.Nodes = append(seg.Nodes, node)
+	}
+
+	for _, sw := range src.Switches {
+		seg := segment(sw.ID.String())
+		seg.SwitchName = sw.Name
+		if len(sw.Subnets) > 0 && sw.Subnets[0].NetworkAddress != "" {
+			seg.Subnet = fmt.Sprintf("%s/%d", sw.Subnets[0].NetworkAddress, sw.Subnets[0].NetworkMaskLen)
+		}
+	}
+	for _, r := range src.Internet {
+		if r.Switch == nil {
+			continue
+		}
+		seg := segment(r.Switch.ID.String())
+		if seg.SwitchName == "" {
+			seg.SwitchName = r.Switch.Name
+		}
+		if seg.Subnet == "" && len(r.Switch.Subnets) > 0 {
+			seg.Subnet = fmt.Sprintf("%s/%d", r.Switch.Subnets[0].NetworkAddress, r.Switch.Subnets[0].NetworkMaskLen)
+		}
+		connect(r.Switch.ID.String(), TopologyNode{Kind: "Router", ID: r.ID.String(), Name: r.Name, Note: fmt.Sprintf("%d Mbps", r.BandWidthMbps)})
+	}
+	for _, s := range src.Servers {
+		for i, iface := range s.Interfaces {
+			node := TopologyNode{Kind: "Server", ID: s.ID.String(), Name: s.Name, Interface: fmt.Sprintf("eth%d", i), IPAddress: iface.IPAddress}
+			if iface.UpstreamType == types.UpstreamNetworkTypes.Shared {
+				t.Shared = append(t.Shared, node)
+				continue
+			}
+			if iface.UserIPAddress != "" {
+				node.IPAddress = iface.UserIPAddress
+			}
+			connect(iface.SwitchID.String(), node)
+		}
+	}
+	for _, v := range src.VPCRouters {
+		for _, nic := range v.NICs {
+			node := TopologyNode{Kind: "VPCRouter", ID: v.ID, Name: v.Name, Interface: fmt.Sprintf("nic%d", nic.Index), IPAddress: nic.IPAddress}
+			if nic.Shared {
+				t.Shared = append(t.Shared, node)
+				continue
+			}
+			connect(nic.SwitchID, node)
+		}
+	}
+	for _, lb := range src.LoadBalancers {
+		connect(lb.SwitchID.String(), TopologyNode{Kind: "LoadBalancer", ID: lb.ID.String(), Name: lb.Name, IPAddress: strings.Join(lb.IPAddresses, ", ")})
+	}
+	for _, db := range src.Databases {
+		connect(db.SwitchID.String(), TopologyNode{Kind: "Database", ID: db.ID.String(), Name: db.Name, IPAddress: strings.Join(db.IPAddresses, ", ")})
+	}
+	for _, nfs := range src.NFS {
+		connect(nfs.SwitchID.String(), TopologyNode{Kind: "NFS", ID: nfs.ID.String(), Name: nfs.Name, IPAddress: strings.Join(nfs.IPAddresses, ", ")})
+	}
+	for _, b := range src.Bridges {
+		for _, sw := range b.Switches {
+			if _, ok := index[sw.ID]; !ok {
+				continue
+			}
+			// Note the switches of the other zones the segment is bridged to
+			var others []string
+			for _, other := range b.Switches {
+				if other.ID != sw.ID {
+					others = append(others, fmt.Sprintf("%s: %s", other.ZoneName, other.Name))
+				}
+			}
+			connect(sw.ID, TopologyNode{Kind: "Bridge", ID: b.ID, Name: b.Name, Note: strings.Join(others, ", ")})
+		}
+	}
+
+	for i := range t.Segments {
+		sortTopologyNodes(t.Segments[i].Nodes)
+	}
+	sortTopologyNodes(t.Shared)
+	slices.SortStableFunc(t.Segments, func(a, b TopologySegment) int {
+		return cmp.Or(cmp.Compare(a.SwitchName, b.SwitchName), cmp.Compare(a.SwitchID, b.SwitchID))
+	})
+	return t
+}
+
+func sortTopologyNodes(nodes []TopologyNode) {
+	slices.SortStableFunc(nodes, func(a, b TopologyNode) int {
+		return cmp.Or(
+			cmp.Compare(slices.Index(topologyKindOrder, a.Kind), slices.Index(topologyKindOrder, b.Kind)),
+			cmp.Compare(a.Name, b.Name),
+			cmp.Compare(a.Interface, b.Interface))
+	})
+}
+
+// Title returns e.g. "app-net (113000000301) 192.168.0.0/24"
+func (s TopologySegment) Title() string {
+	name := s.SwitchName
+	if name == "" {
+		name = "(unknown switch)"
+	}
+	title := fmt.Sprintf("%s (%s)", name, s.SwitchID)
+	if s.Subnet != "" {
+		title += " " + s.Subnet
+	}
+	return title
+}
+
+// Label returns the resource and how it is connected, e.g. "web-1 eth1 192.168.0.11"
+func (n TopologyNode) Label() string {
+	parts := []string{n.Name}
+	for _, p := range []string{n.Interface, n.IPAddress} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	if n.Note != "" {
+		parts = append(parts, "("+n.Note+")")
+	}
+	return strings.Join(parts, " ")
+}
+
+// edgeLabel is the interface and address shown on the edge to the segment
+func (n TopologyNode) edgeLabel() string {
+	return strings.TrimSpace(n.Interface + " " + n.IPAddress)
+}
+
+// graphID returns the node ID used in the DOT and Mermaid output
+func (n TopologyNode) graphID() string {
+	return strings.ToLower(n.Kind) + "_" + n.ID
+}
+
+// Tree renders the topology as an ASCII tree per switch
+func (t *Topology) Tree() string {
+	var b strings.Builder
+	writeNodes := func(nodes []TopologyNode) {
+		if len(nodes) == 0 {
+			b.WriteString("└── (nothing connected)\n")
+		}
+		for i, n := range nodes {
+			branch := "├── "
+			if i == len(nodes)-1 {
+				branch = "└── "
+			}
+			fmt.Fprintf(&b, "%s%-12s %s\n", branch, n.Kind, n.Label())
+		}
+	}
+	for i, seg := range t.Segments {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(seg.Title())
+		b.WriteString("\n")
+		writeNodes(seg.Nodes)
+	}
+	if len(t.Shared) > 0 {
+		if len(t.Segments) > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("Shared segment (internet)\n")
+		writeNodes(t.Shared)
+	}
+	return b.String()
+}
+
+// DOT renders the topology as a Graphviz graph with the switches as ellipses
+func (t *Topology) DOT() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "graph %s {\n", strconv.Quote(t.Zone))
+	b.WriteString("  node [shape=box];\n")
+	declared := map[string]bool{}
+	declare := func(n TopologyNode) {
+		if !declared[n.graphID()] {
+			declared[n.graphID()] = true
+			fmt.Fprintf(&b, "  %s [label=%s];\n", strconv.Quote(n.graphID()), strconv.Quote(n.Kind+"\n"+n.Name))
+		}
+	}
+	edges := func(segmentID string, nodes []TopologyNode) {
+		for _, n := range nodes {
+			declare(n)
+			fmt.Fprintf(&b, "  %s -- %s", strconv.Quote(segmentID), strconv.Quote(n.graphID()))
+			if label := n.edgeLabel(); label != "" {
+				fmt.Fprintf(&b, " [label=%s]", strconv.Quote(label))
+			}
+			b.WriteString(";\n")
+		}
+	}
+	for _, seg := range t.Segments {
+		label := strings.TrimSpace(seg.SwitchName + "\n" + seg.Subnet)
+		fmt.Fprintf(&b, "  %s [label=%s, shape=ellipse];\n", strconv.Quote("switch_"+seg.SwitchID), strconv.Quote(label))
+		edges("switch_"+seg.SwitchID, seg.Nodes)
+	}
+	if len(t.Shared) > 0 {
+		b.WriteString("  \"shared\" [label=\"Shared segment\", shape=ellipse];\n")
+		edges("shared", t.Shared)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid renders the topology as a Mermaid flowchart with the switches as stadiums
+func (t *Topology) Mermaid() string {
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	quote := func(s string) string {
+		return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+	}
+	declared := map[string]bool{}
+	edges := func(segmentID string, nodes []TopologyNode) {
+		for _, n := range nodes {
+			if !declared[n.graphID()] {
+				declared[n.graphID()] = true
+				fmt.Fprintf(&b, "  %s[%s]\n", n.graphID(), quote(n.Kind+"<br/>"+n.Name))
+			}
+			if label := n.edgeLabel(); label != "" {
+				fmt.Fprintf(&b, "  %s ---|%s| %s\n", segmentID, quote(label), n.graphID())
+			} else {
+				fmt.Fprintf(&b, "  %s --- %s\n", segmentID, n.graphID())
+			}
+		}
+	}
+	for _, seg := range t.Segments {
+		label := seg.SwitchName
+		if seg.Subnet != "" {
+			label += "<br/>" + seg.Subnet
+		}
+		fmt.Fprintf(&b, "  switch_%s([%s])\n", seg.SwitchID, quote(label))
+		edges("switch_"+seg.SwitchID, seg.Nodes)
+	}
+	if len(t.Shared) > 0 {
+		b.WriteString("  shared([\"Shared segment\"])\n")
+		edges("shared", t.Shared)
+	}
+	return b.String()
+}
+
+// Render returns the topology in one of TopologyFormats
+func (t *Topology) Render(format string) string {
+	switch format {
+	case "dot":
+		return t.DOT()
+	case "mermaid":
+		return t.Mermaid()
+	}
+	return t.Tree()
+}
+
+// SaveTopology writes the topology to path in one of TopologyFormats
+func SaveTopology(path string, t *Topology, format string) error {
+	return os.WriteFile(expandHome(path), []byte(t.Render(format)), 0o644)
+}
+
+// GetTopology fetches the switches of the zone and everything that can be connected to them
+func (c *SakuraClient) GetTopology(ctx context.Context) (*Topology, error) {
+	if c.zone == "" {
+		slog.Error("Zone is not set in client")
+		return nil, fmt.Errorf("zone is not set")
+	}
+
+	slog.Info("Fetching topology from Sakura Cloud",
+		slog.String("zone", c.zone))
+
+	var src topologySources
+	cond := &iaas.FindCondition{}
+
+	switches, err := iaas.NewSwitchOp(c.caller).Find(ctx, c.zone, cond)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch switches: %w", err)
+	}
+	src.Switches = switches.Switches
+
+	servers, err := iaas.NewServerOp(c.caller).Find(ctx, c.zone, cond)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch servers: %w", err)
+	}
+	src.Servers = servers.Servers
+
+	internet, err := iaas.NewInternetOp(c.caller).Find(ctx, c.zone, cond)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch routers: %w", err)
+	}
+	src.Internet = internet.Internet
+
+	vpcRouters, err := iaas.NewVPCRouterOp(c.caller).Find(ctx, c.zone, cond)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch VPC routers: %w", err)
+	}
+	for _, v := range vpcRouters.VPCRouters {
+		src.VPCRouters = append(src.VPCRouters, VPCRouterDetail{
+			VPCRouter: VPCRouter{ID: v.ID.String(), Name: v.Name},
+			NICs:      vpcRouterNICs(v),
+		})
+	}
+
+	loadBalancers, err := iaas.NewLoadBalancerOp(c.caller).Find(ctx, c.zone, cond)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch load balancers: %w", err)
+	}
+	src.LoadBalancers = loadBalancers.LoadBalancers
+
+	nfs, err := iaas.NewNFSOp(c.caller).Find(ctx, c.zone, cond)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch NFS: %w", err)
+	}
+	src.NFS = nfs.NFS
+
+	databases, err := iaas.NewDatabaseOp(c.caller).Find(ctx, c.zone, cond)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch databases: %w", err)
+	}
+	src.Databases = databases.Databases
+
+	bridges, err := iaas.NewBridgeOp(c.caller).Find(ctx, c.zone, cond)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch bridges: %w", err)
+	}
+	for _, br := range bridges.Bridges {
+		src.Bridges = append(src.Bridges, BridgeDetail{
+			Bridge:   Bridge{ID: br.ID.String(), Name: br.Name},
+			Switches: bridgeSwitches(br),
+		})
+	}
+
+	t := buildTopology(c.zone, src)
+	slog.Info("Successfully fetched topology",
+		slog.String("zone", c.zone),
+		slog.Int("segments", len(t.Segments)))
+	return t, nil
+}
@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sacloud/iaas-api-go"
+	"github.com/sacloud/iaas-api-go/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTopology() *Topology {
+	return buildTopology("tk1b", topologySources{
+		Switches: []*iaas.Switch{
+			{ID: 301, Name: "app-net", Subnets: []*iaas.SwitchSubnet{{NetworkAddress: "192.168.0.0", NetworkMaskLen: 24}}},
+			{ID: 302, Name: "empty-net"},
+		},
+		Internet: []*iaas.Internet{
+			{ID: 401, Name: "edge", BandWidthMbps: 100, Switch: &iaas.SwitchInfo{ID: 303, Name: "edge-sw", Subnets: []*iaas.InternetSubnet{{NetworkAddress: "203.0.113.0", NetworkMaskLen: 28}}}},
+		},
+		Servers: []*iaas.Server{
+			{ID: 101, Name: "web-1", Interfaces: []*iaas.InterfaceView{
+				{UpstreamType: types.UpstreamNetworkTypes.Shared, IPAddress: "198.51.100.10"},
+				{SwitchID: 301, UpstreamType: types.UpstreamNetworkTypes.Switch, UserIPAddress: "192.168.0.11"},
+			}},
+			{ID: 102, Name: "gw", Interfaces: []*iaas.InterfaceView{
+				{SwitchID: 303, UpstreamType: types.UpstreamNetworkTypes.Switch, IPAddress: "203.0.113.5"},
+			}},
+		},
+		VPCRouters: []VPCRouterDetail{{
+			VPCRouter: VPCRouter{ID: "501", Name: "vpc"},
+			NICs:      []VPCRouterNIC{{Index: 0, Shared: true, IPAddress: "198.51.100.20"}, {Index: 1, SwitchID: "301", IPAddress: "192.168.0.1"}},
+		}},
+		LoadBalancers: []*iaas.LoadBalancer{{ID: 601, Name: "lb", SwitchID: 301, IPAddresses: []string{"192.168.0.2"}}},
+		Databases:     []*iaas.Database{{ID: 701, Name: "db", SwitchID: 301, IPAddresses: []string{"192.168.0.3"}}},
+		NFS:           []*iaas.NFS{{ID: 801, Name: "nfs", SwitchID: 9999}},
+		Bridges: []BridgeDetail{{
+			Bridge:   Bridge{ID: "901", Name: "br"},
+			Switches: []BridgeSwitchInfo{{ID: "301", Name: "app-net", ZoneName: "tk1b"}, {ID: "111", Name: "dr-net", ZoneName: "is1b"}},
+		}},
+	})
+}
+
+func TestBuildTopology(t *testing.T) {
+	topo := testTopology()
+
+	require.Len(t, topo.Segments, 4)
+	// A switch only known from a connected resource is kept without a name
+	assert.Equal(t, "(unknown switch) (9999)", topo.Segments[0].Title())
+	assert.Equal(t, "app-net (301) 192.168.0.0/24", topo.Segments[1].Title())
+	assert.Equal(t, "edge-sw (303) 203.0.113.0/28", topo.Segments[2].Title())
+	assert.Empty(t, topo.Segments[3].Nodes)
+
+	var kinds []string
+	for _, n := range topo.Segments[1].Nodes {
+		kinds = append(kinds, n.Kind)
+	}
+	assert.Equal(t, []string{"VPCRouter", "LoadBalancer", "Database", "Server", "Bridge"}, kinds)
+	assert.Equal(t, "web-1 eth1 192.168.0.11", topo.Segments[1].Nodes[3].Label())
+	assert.Equal(t, "br (is1b: dr-net)", topo.Segments[1].Nodes[4].Label())
+	assert.Equal(t, "edge (100 Mbps)", topo.Segments[2].Nodes[0].Label())
+
+	require.Len(t, topo.Shared, 2)
+	assert.Equal(t, "vpc nic0 198.51.100.20", topo.Shared[0].Label())
+	assert.Equal(t, "web-1 eth0 198.51.100.10", topo.Shared[1].Label())
+}
+
+func TestTopologyRender(t *testing.T) {
+	topo := testTopology()
+
+	tree := topo.Render("tree")
+	assert.Contains(t, tree, "app-net (301) 192.168.0.0/24\n├── VPCRouter    vpc nic1 192.168.0.1\n")
+	assert.Contains(t, tree, "└── Bridge       br (is1b: dr-net)\n")
+	assert.Contains(t, tree, "empty-net (302)\n└── (nothing connected)\n")
+	assert.Contains(t, tree, "Shared segment (internet)\n├── VPCRouter")
+
+	dot := topo.Render("dot")
+	assert.Contains(t, dot, `graph "tk1b" {`)
+	assert.Contains(t, dot, `"switch_301" [label="app-net\n192.168.0.0/24", shape=ellipse];`)
+	assert.Contains(t, dot, `"switch_301" -- "server_101" [label="eth1 192.168.0.11"];`)
+	// A resource on several segments is declared once
+	assert.Equal(t, 1, strings.Count(dot, "\n  \"server_101\" [label="))
+
+	mermaid := topo.Render("mermaid")
+	assert.Contains(t, mermaid, "graph LR\n")
+	assert.Contains(t, mermaid, `switch_301(["app-net<br/>192.168.0.0/24"])`)
+	assert.Contains(t, mermaid, `shared ---|"eth0 198.51.100.10"| server_101`)
+	assert.Contains(t, mermaid, "switch_301 --- bridge_901")
+
+	path := filepath.Join(t.TempDir(), "topology.dot")
+	require.NoError(t, SaveTopology(path, topo, "dot"))
+	saved, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, dot, string(saved))
+}
@@ -0,0 +1,463 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// traceSearchLimit is the maximum number of traces returned by one search
+const traceSearchLimit = 50
+
+// TraceQueryClient queries a Tempo-compatible trace query HTTP API
+type TraceQueryClient struct {
+	httpAPIClient
+}
+
+// TraceSearchWindows are the time ranges cycled through in the trace explorer
+var TraceSearchWindows = []struct {
+	Label    string
+	Duration time.Duration
+}{
+	{"15m", 15 * time.Minute},
+	{"1h", time.Hour},
+	{"6h", 6 * time.Hour},
+	{"24h", 24 * time.Hour},
+}
+
+// TraceSearch holds the conditions of a trace search. Zero values match everything.
+type TraceSearch struct {
+	Service     string
+	Operation   string
+	MinDuration time.Duration
+	MaxDuration time.Duration
+}
+
+// TraceSummary is a trace found by a search
+type TraceSummary struct {
+	TraceID     string
+	RootService string
+	RootName    string
+	Start       time.Time
+	Duration    time.Duration
+}
+
+// SpanAttribute is a span or resource attribute formatted for display
+type SpanAttribute struct {
+	Key   string
+	Value string
+}
+
+// Span is a single operation of a trace
+type Span struct {
+	SpanID        string
+	ParentSpanID  string
+	Name          string
+	Service       string
+	Kind          string // server, client, internal, producer or consumer; empty when unspecified
+	Start         time.Time
+	End           time.Time
+	Status        string // ok or error; empty when unset
+	StatusMessage string
+	Attributes    []SpanAttribute
+}
+
+// Duration returns the time the span took
+func (s Span) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// Trace is a trace with all of its spans
+type Trace struct {
+	ID    string
+	Spans []Span
+}
+
+// SpanRow is a span placed in the waterfall at the given depth
+type SpanRow struct {
+	Span  Span
+	Depth int
+}
+
+// NewTraceQueryClient creates a client for the trace query API at baseURL.
+// baseURL may omit the scheme, in which case https is used.
+func NewTraceQueryClient(baseURL, token string) *TraceQueryClient {
+	return &TraceQueryClient{newHTTPAPIClient(baseURL, token)}
+}
+
+// TraceQL returns the TraceQL query for the service and operation conditions
+func (s TraceSearch) TraceQL() string {
+	var conds []string
+	if s.Service != "" {
+		conds = append(conds, "resource.service.name = "+strconv.Quote(s.Service))
+	}
+	if s.Operation != "" {
+		conds = append(conds, "name = "+strconv.Quote(s.Operation))
+	}
+	if len(conds) == 0 {
+		return "{}"
+	}
+	return "{ " + strings.Join(conds, " && ") + " }"
+}
+
+// String describes the conditions for display
+func (s TraceSearch) String() string {
+	desc := s.TraceQL()
+	switch {
+	case s.MinDuration > 0 && s.MaxDuration > 0:
+		desc += fmt.Sprintf(", duration %s-%s", s.MinDuration, s.MaxDuration)
+	case s.MinDuration > 0:
+		desc += fmt.Sprintf(", duration >= %s", s.MinDuration)
+	case s.MaxDuration > 0:
+		desc += fmt.Sprintf(", duration <= %s", s.MaxDuration)
+	}
+	return desc
+}
+
+// Search returns up to limit traces that started between start and end, newest first
+func (c *TraceQueryClient) Search(ctx context.Context, search TraceSearch, start, end time.Time, limit int) ([]TraceSummary, error) {
+	slog.Info("Searching traces", slog.String("query", search.String()), slog.Time("start", start), slog.Time("end", end))
+
+	params := url.Values{
+		"q":     {search.TraceQL()},
+		"start": {strconv.FormatInt(start.Unix(), 10)},
+		"end":   {strconv.FormatInt(end.Unix(), 10)},
+		"limit": {strconv.Itoa(limit)},
+	}
+	if search.MinDuration > 0 {
+		params.Set("minDuration", search.MinDuration.String())
+	}
+	if search.MaxDuration > 0 {
+		params.Set("maxDuration", search.MaxDuration.String())
+	}
+	body, err := c.get(ctx, "/api/search?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+	return parseTraceSearchResponse(body)
+}
+
+// Trace returns the trace with all of its spans
+func (c *TraceQueryClient) Trace(ctx context.Context, traceID string) (*Trace, error) {
+	slog.Info("Fetching trace", slog.String("traceID", traceID))
+
+	body, err := c.get(ctx, "/api/traces/"+url.PathEscape(traceID))
+	if err != nil {
+		return nil, err
+	}
+	spans, err := parseOTLPTrace(body)
+	if err != nil {
+		return nil, err
+	}
+	if len(spans) == 0 {
+		return nil, fmt.Errorf("trace %s has no spans", traceID)
+	}
+	return &Trace{ID: traceID, Spans: spans}, nil
+}
+
+func (c *TraceQueryClient) get(ctx context.Context, path string) ([]byte, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		slog.Error("Failed to query traces", slog.Any("error", err))
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		// Tempo returns the reason as plain text
+		return nil, fmt.Errorf("trace query failed: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
+
+// parseTraceSearchResponse decodes a search result, newest trace first
+func parseTraceSearchResponse(body []byte) ([]TraceSummary, error) {
+	var resp struct {
+		Traces []struct {
+			TraceID           string `json:"traceID"`
+			RootServiceName   string `json:"rootServiceName"`
+			RootTraceName     string `json:"rootTraceName"`
+			StartTimeUnixNano string `json:"startTimeUnixNano"`
+			DurationMs        int64  `json:"durationMs"`
+		} `json:"traces"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("invalid trace search response: %w", err)
+	}
+
+	traces := make([]TraceSummary, 0, len(resp.Traces))
+	for _, t := range resp.Traces {
+		ns, err := strconv.ParseInt(t.StartTimeUnixNano, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trace start time %q", t.StartTimeUnixNano)
+		}
+		traces = append(traces, TraceSummary{
+			TraceID:     t.TraceID,
+			RootService: t.RootServiceName,
+			RootName:    t.RootTraceName,
+			Start:       time.Unix(0, ns),
+			Duration:    time.Duration(t.DurationMs) * time.Millisecond,
+		})
+	}
+	slices.SortStableFunc(traces, func(a, b TraceSummary) int {
+		return b.Start.Compare(a.Start)
+	})
+	return traces, nil
+}
+
+// otlpAttribute is an OTLP/JSON key-value pair
+type otlpAttribute struct {
+	Key   string         `json:"key"`
+	Value map[string]any `json:"value"`
+}
+
+// parseOTLPTrace decodes the spans of a trace in OTLP/JSON, as returned by
+// /api/traces (batches) or /api/v2/traces (trace.resourceSpans)
+func parseOTLPTrace(body []byte) ([]Span, error) {
+	type resourceSpans struct {
+		Resource struct {
+			Attributes []otlpAttribute `json:"attributes"`
+		} `json:"resource"`
+		ScopeSpans []struct {
+			Spans []struct {
+				SpanID            string          `json:"spanId"`
+				ParentSpanID      string          `json:"parentSpanId"`
+				Name              string          `json:"name"`
+				Kind              any             `json:"kind"`
+				StartTimeUnixNano string          `json:"startTimeUnixNano"`
+				EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+				Attributes        []otlpAttribute `json:"attributes"`
+				Status            struct {
+					Code    any    `json:"code"`
+					Message string `json:"message"`
+				} `json:"status"`
+			} `json:"spans"`
+		} `json:"scopeSpans"`
+	}
+	var resp struct {
+		Batches       []resourceSpans `json:"batches"`
+		ResourceSpans []resourceSpans `json:"resourceSpans"`
+		Trace         struct {
+			ResourceSpans []resourceSpans `json:"resourceSpans"`
+		} `json:"trace"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("invalid trace response: %w", err)
+	}
+
+	var spans []Span
+	for _, rs := range slices.Concat(resp.Batches, resp.ResourceSpans, resp.Trace.ResourceSpans) {
+		service := ""
+		for _, a := range rs.Resource.Attributes {
+			if a.Key == "service.name" {
+				service = formatOTLPValue(a.Value)
+			}
+		}
+		for _, ss := range rs.ScopeSpans {
+			for _, s := range ss.Spans {
+				start, err := strconv.ParseInt(s.StartTimeUnixNano, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid start time %q of span %s", s.StartTimeUnixNano, s.Name)
+				}
+				end, err := strconv.ParseInt(s.EndTimeUnixNano, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid end time %q of span %s", s.EndTimeUnixNano, s.Name)
+				}
+				span := Span{
+					SpanID:        s.SpanID,
+					ParentSpanID:  s.ParentSpanID,
+					Name:          s.Name,
+					Service:       service,
+					Kind:          otlpEnumName(s.Kind, "SPAN_KIND_", []string{"", "internal", "server", "client", "producer", "consumer"}),
+					Start:         time.Unix(0, start),
+					End:           time.Unix(0, end),
+					Status:        otlpEnumName(s.Status.Code, "STATUS_CODE_", []string{"", "ok", "error"}),
+					StatusMessage: s.Status.Message,
+				}
+				for _, a := range s.Attributes {
+					span.Attributes = append(span.Attributes, SpanAttribute{Key: a.Key, Value: formatOTLPValue(a.Value)})
+				}
+				slices.SortFunc(span.Attributes, func(a, b SpanAttribute) int {
+					return strings.Compare(a.Key, b.Key)
+				})
+				spans = append(spans, span)
+			}
+		}
+	}
+	return spans, nil
+}
+
+// otlpEnumName returns the lower case name of an OTLP enum, which OTLP/JSON encodes
+// either as a number or as its name such as SPAN_KIND_SERVER. Unspecified is "".
+func otlpEnumName(v any, prefix string, names []string) string {
+	switch v := v.(type) {
+	case float64:
+		if i := int(v); i >= 0 && i < len(names) {
+			return names[i]
+		}
+	case string:
+		name := strings.ToLower(strings.TrimPrefix(v, prefix))
+		if name == "unspecified" || name == "unset" {
+			return ""
+		}
+		return name
+	}
+	return ""
+}
+
+// formatOTLPValue formats an OTLP AnyValue such as {"stringValue": "GET"}
+func formatOTLPValue(v map[string]any) string {
+	for key, value := range v {
+		switch key {
+		case "stringValue", "intValue", "doubleValue", "boolValue", "bytesValue":
+			// int64 values are encoded as strings in OTLP/JSON
+			return fmt.Sprint(value)
+		case "arrayValue":
+			arr, _ := value.(map[string]any)
+			items, _ := arr["values"].([]any)
+			var values []string
+			for _, item := range items {
+				if m, ok := item.(map[string]any); ok {
+					values = append(values, formatOTLPValue(m))
+				}
+			}
+			return "[" + strings.Join(values, ", ") + "]"
+		case "kvlistValue":
+			b, _ := json.Marshal(value)
+			return string(b)
+		}
+	}
+	return ""
+}
+
+// Start returns the start of the earliest span
+func (t *Trace) Start() time.Time {
+	start := t.Spans[0].Start
+	for _, s := range t.Spans {
+		if s.Start.Before(start) {
+			start = s.Start
+		}
+	}
+	return start
+}
+
+// Duration returns the time from the earliest span start to the latest span end
+func (t *Trace) Duration() time.Duration {
+	end := t.Spans[0].End
+	for _, s := range t.Spans {
+		if s.End.After(end) {
+			end = s.End
+		}
+	}
+	return end.Sub(t.Start())
+}
+
+// Services returns the names of the services that took part in the trace
+func (t *Trace) Services() []string {
+	var services []string
+	for _, s := range t.Spans {
+		if s.Service != "" && !slices.Contains(services, s.Service) {
+			services = append(services, s.Service)
+		}
+	}
+	return services
+}
+
+// Rows returns the spans depth first, children ordered by start time. Spans whose
+// parent is not part of the trace (e.g. not yet received) are shown as roots.
+func (t *Trace) Rows() []SpanRow {
+	ids := map[string]bool{}
+	for _, s := range t.Spans {
+		ids[s.SpanID] = true
+	}
+	children := map[string][]Span{}
+	var roots []Span
+	for _, s := range t.Spans {
+		if s.ParentSpanID == "" || !ids[s.ParentSpanID] || s.ParentSpanID == s.SpanID {
+			roots = append(roots, s)
+		} else {
+			children[s.ParentSpanID] = append(children[s.ParentSpanID], s)
+		}
+	}
+
+	byStart := func(a, b Span) int {
+		return a.Start.Compare(b.Start)
+	}
+	rows := make([]SpanRow, 0, len(t.Spans))
+	visited := map[string]bool{}
+	var walk func(spans []Span, depth int)
+	walk = func(spans []Span, depth int) {
+		slices.SortStableFunc(spans, byStart)
+		for _, s := range spans {
+			if visited[s.SpanID] {
+				continue
+			}
+			visited[s.SpanID] = true
+			rows = append(rows, SpanRow{Span: s, Depth: depth})
+			walk(children[s.SpanID], depth+1)
+		}
+	}
+	walk(roots, 0)
+	return rows
+}
+
+// parseTraceSearch builds the search conditions from the search form
+func parseTraceSearch(values map[string]string) (TraceSearch, error) {
+	search := TraceSearch{
+		Service:   strings.TrimSpace(values["service"]),
+		Operation: strings.TrimSpace(values["operation"]),
+	}
+	var err error
+	if search.MinDuration, err = parseTraceDuration("min duration", values["min"]); err != nil {
+		return TraceSearch{}, err
+	}
+	if search.MaxDuration, err = parseTraceDuration("max duration", values["max"]); err != nil {
+		return TraceSearch{}, err
+	}
+	if search.MinDuration > 0 && search.MaxDuration > 0 && search.MinDuration > search.MaxDuration {
+		return TraceSearch{}, fmt.Errorf("min duration %s is longer than max duration %s", search.MinDuration, search.MaxDuration)
+	}
+	return search, nil
+}
+
+func parseTraceDuration(label, value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid %s %q, use a duration such as 250ms or 1.5s", label, value)
+	}
+	return d, nil
+}
+
+// formatTraceDuration formats span timings with a precision that suits their size
+func formatTraceDuration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return fmt.Sprintf("%.2fs", d.Seconds())
+	case d >= time.Millisecond:
+		return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+	default:
+		return fmt.Sprintf("%dµs", d.Microseconds())
+	}
+}
@@ -0,0 +1,144 @@
+package internal
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTraceStart is the start of the trace served by newFakeTempo
+var testTraceStart = time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+
+// newFakeTempo is a minimal Tempo query API stand-in serving a single AppRun request trace
+func newFakeTempo(t *testing.T) (*httptest.Server, *[]string) {
+	var searches []string
+	ns := func(offset time.Duration) string {
+		return fmt.Sprint(testTraceStart.Add(offset).UnixNano())
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer t0ken" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/search":
+			q := r.URL.Query()
+			searches = append(searches, q.Get("q")+" min="+q.Get("minDuration"))
+			if strings.Contains(q.Get("q"), "nothing") {
+				_, _ = fmt.Fprint(w, `{"traces":[]}`)
+				return
+			}
+			_, _ = fmt.Fprintf(w, `{"traces":[
+				{"traceID":"1a2b","rootServiceName":"web","rootTraceName":"GET /api/users","startTimeUnixNano":"%s","durationMs":120},
+				{"traceID":"3c4d","rootServiceName":"web","rootTraceName":"GET /healthz","startTimeUnixNano":"%s","durationMs":2}
+			]}`, ns(-time.Minute), ns(0))
+		case "/api/traces/1a2b":
+			// Spans arrive out of order and split by service; the db span has kind and status as names
+			_, _ = fmt.Fprintf(w, `{"batches":[
+				{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"users"}}]},
+				 "scopeSpans":[{"spans":[
+					{"spanId":"s3","parentSpanId":"s2","name":"SELECT users","kind":"SPAN_KIND_CLIENT","startTimeUnixNano":"%s","endTimeUnixNano":"%s",
+					 "status":{"code":"STATUS_CODE_ERROR","message":"deadlock"},
+					 "attributes":[{"key":"db.system","value":{"stringValue":"mysql"}},{"key":"db.rows","value":{"intValue":"3"}}]},
+					{"spanId":"s2","parentSpanId":"s1","name":"ListUsers","kind":2,"startTimeUnixNano":"%s","endTimeUnixNano":"%s"}
+				]}]},
+				{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"web"}}]},
+				 "scopeSpans":[{"spans":[
+					{"spanId":"s4","parentSpanId":"s1","name":"render","kind":1,"startTimeUnixNano":"%s","endTimeUnixNano":"%s"},
+					{"spanId":"s1","name":"GET /api/users","kind":2,"startTimeUnixNano":"%s","endTimeUnixNano":"%s",
+					 "attributes":[{"key":"http.status_code","value":{"intValue":"500"}}]}
+				]}]}
+			]}`,
+				ns(20*time.Millisecond), ns(80*time.Millisecond),
+				ns(10*time.Millisecond), ns(90*time.Millisecond),
+				ns(95*time.Millisecond), ns(120*time.Millisecond),
+				ns(0), ns(120*time.Millisecond))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &searches
+}
+
+func TestTraceQueryClient(t *testing.T) {
+	server, searches := newFakeTempo(t)
+	client := NewTraceQueryClient(server.URL, "t0ken")
+
+	search := TraceSearch{Service: "web", MinDuration: 100 * time.Millisecond}
+	traces, err := client.Search(t.Context(), search, time.Now().Add(-time.Hour), time.Now(), traceSearchLimit)
+	require.NoError(t, err)
+	require.Len(t, traces, 2)
+	// Newest first
+	assert.Equal(t, "3c4d", traces[0].TraceID)
+	assert.Equal(t, 120*time.Millisecond, traces[1].Duration)
+	assert.Equal(t, []string{`{ resource.service.name = "web" } min=100ms`}, *searches)
+
+	trace, err := client.Trace(t.Context(), "1a2b")
+	require.NoError(t, err)
+	assert.Equal(t, testTraceStart, trace.Start().UTC())
+	assert.Equal(t, 120*time.Millisecond, trace.Duration())
+	assert.Equal(t, []string{"users", "web"}, trace.Services())
+
+	var names []string
+	for _, r := range trace.Rows() {
+		names = append(names, fmt.Sprintf("%d %s", r.Depth, r.Span.Name))
+	}
+	assert.Equal(t, []string{"0 GET /api/users", "1 ListUsers", "2 SELECT users", "1 render"}, names)
+
+	db := trace.Rows()[2].Span
+	assert.Equal(t, "client", db.Kind)
+	assert.Equal(t, "error", db.Status)
+	assert.Equal(t, "deadlock", db.StatusMessage)
+	assert.Equal(t, []SpanAttribute{{"db.rows", "3"}, {"db.system", "mysql"}}, db.Attributes)
+	assert.Equal(t, "server", trace.Rows()[1].Span.Kind)
+
+	_, err = client.Trace(t.Context(), "ffff")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "404")
+
+	_, err = NewTraceQueryClient(server.URL, "wrong").Search(t.Context(), TraceSearch{}, time.Now(), time.Now(), 1)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unauthorized")
+}
+
+func TestTraceRowsWithMissingParent(t *testing.T) {
+	trace := &Trace{ID: "x", Spans: []Span{
+		{SpanID: "b", ParentSpanID: "gone", Name: "orphan", Start: testTraceStart.Add(time.Second)},
+		{SpanID: "c", ParentSpanID: "b", Name: "child", Start: testTraceStart.Add(2 * time.Second)},
+		{SpanID: "a", Name: "root", Start: testTraceStart},
+	}}
+	var names []string
+	for _, r := range trace.Rows() {
+		names = append(names, fmt.Sprintf("%d %s", r.Depth, r.Span.Name))
+	}
+	assert.Equal(t, []string{"0 root", "0 orphan", "1 child"}, names)
+}
+
+func TestParseTraceSearch(t *testing.T) {
+	search, err := parseTraceSearch(map[string]string{"service": " web ", "operation": `GET "/"`, "min": "250ms", "max": "2s"})
+	require.NoError(t, err)
+	assert.Equal(t, `{ resource.service.name = "web" && name = "GET \"/\"" }`, search.TraceQL())
+	assert.Equal(t, `{ resource.service.name = "web" && name = "GET \"/\"" }, duration 250ms-2s`, search.String())
+
+	search, err = parseTraceSearch(map[string]string{})
+	require.NoError(t, err)
+	assert.Equal(t, "{}", search.String())
+
+	_, err = parseTraceSearch(map[string]string{"min": "fast"})
+	assert.EqualError(t, err, `invalid min duration "fast", use a duration such as 250ms or 1.5s`)
+	_, err = parseTraceSearch(map[string]string{"min": "2s", "max": "1s"})
+	assert.EqualError(t, err, "min duration 2s is longer than max duration 1s")
+}
+
+func TestFormatTraceDuration(t *testing.T) {
+	assert.Equal(t, "1.50s", formatTraceDuration(1500*time.Millisecond))
+	assert.Equal(t, "12.3ms", formatTraceDuration(12300*time.Microsecond))
+	assert.Equal(t, "850µs", formatTraceDuration(850*time.Microsecond))
+}
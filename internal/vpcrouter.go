@@ -34,6 +34,7 @@ type VPCRouterNIC struct {
 	Index     int
 	SwitchID  string
 	IPAddress string
+	Shared    bool // connected to the shared segment
 }
 
 // Implement list.Item interface for VPCRouter
@@ -147,18 +148,7 @@ func (c *SakuraClient) GetVPCRouterDetail(ctx context.Context, vpcRouterID strin
 	}
 
 	// Get NICs
-	nics := make([]VPCRouterNIC, 0)
-	for i, iface := range v.Interfaces {
-		switchID := ""
-		if iface.SwitchID != 0 {
-			switchID = fmt.Sprintf("%d", iface.SwitchID)
-		}
-		nics = append(nics, VPCRouterNIC{
-			Index:     i,
-			SwitchID:  switchID,
-			IPAddress: iface.IPAddress,
-		})
-	}
+	nics := vpcRouterNICs(v)
 
 	detail := &VPCRouterDetail{
 		VPCRouter: VPCRouter{
@@ -183,3 +173,21 @@ func (c *SakuraClient) GetVPCRouterDetail(ctx context.Context, vpcRouterID strin
 
 	return detail, nil
 }
+
+// vpcRouterNICs returns the NICs of a VPC router with the switches they are connected to
+func vpcRouterNICs(v *iaas.VPCRouter) []VPCRouterNIC {
+	nics := make([]VPCRouterNIC, 0)
+	for i, iface := range v.Interfaces {
+		switchID := ""
+		if iface.SwitchID != 0 {
+			switchID = fmt.Sprintf("%d", iface.SwitchID)
+		}
+		nics = append(nics, VPCRouterNIC{
+			Index:     i,
+			SwitchID:  switchID,
+			IPAddress: iface.IPAddress,
+			Shared:    iface.SwitchScope == types.Scopes.Shared,
+		})
+	}
+	return nics
+}